@@ -0,0 +1,247 @@
+package calendar
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	// Exchange time zones must resolve even where the system has no zoneinfo
+	_ "time/tzdata"
+)
+
+// Date is a day on a calendar, without a time of day or zone
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf is the date of t in its own location
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{year, month, day}
+}
+
+// ParseDate reads a date in YYYY-MM-DD form
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %s - use YYYY-MM-DD", value)
+	}
+	return DateOf(t), nil
+}
+
+func (date Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", date.Year, date.Month, date.Day)
+}
+
+// In is midnight at the start of the date in a location
+func (date Date) In(location *time.Location) time.Time {
+	return date.At(0, location)
+}
+
+// At is the wall clock time offset from midnight on the date in a location
+func (date Date) At(offset time.Duration, location *time.Location) time.Time {
+	return time.Date(date.Year, date.Month, date.Day, 0, 0, 0, int(offset), location)
+}
+
+// AddDays moves the date by a number of days, normalising across months and years
+func (date Date) AddDays(days int) Date {
+	return DateOf(time.Date(date.Year, date.Month, date.Day+days, 0, 0, 0, 0, time.UTC))
+}
+
+func (date Date) Weekday() time.Weekday {
+	return date.In(time.UTC).Weekday()
+}
+
+func (date Date) Before(other Date) bool {
+	if date.Year != other.Year {
+		return date.Year < other.Year
+	}
+	if date.Month != other.Month {
+		return date.Month < other.Month
+	}
+	return date.Day < other.Day
+}
+
+// Holiday is a date a calendar does not trade on
+type Holiday struct {
+	Date Date
+	Name string
+}
+
+// EarlyClose is a trading date whose session ends before the regular close
+type EarlyClose struct {
+	Date  Date
+	Close time.Duration
+}
+
+// yearRules lists the holidays and early closes a calendar's rules give for a year
+type yearRules func(year int) ([]Holiday, []EarlyClose)
+
+// ruleYear caches what the rules give for one year
+type ruleYear struct {
+	holidays    map[Date]string
+	earlyCloses map[Date]time.Duration
+}
+
+// Calendar is an exchange's trading days and session times.  Holidays come from
+// rules, such as the third Monday of January, and from explicit dates.  Session
+// times are offsets from midnight in the calendar's location.
+type Calendar struct {
+	Name     string
+	Location *time.Location
+	Open     time.Duration
+	Close    time.Duration
+	weekend  map[time.Weekday]bool
+	rules    yearRules
+	holidays map[Date]string
+	closes   map[Date]time.Duration
+	years    sync.Map
+}
+
+// New creates a calendar trading from open to close on every day but the weekend
+// days.  With a base calendar its holiday and early close rules apply too.
+func New(name string, location *time.Location, open, close time.Duration, weekend []time.Weekday, base *Calendar) (*Calendar, error) {
+	if location == nil {
+		return nil, fmt.Errorf("calendar %s needs a time zone", name)
+	}
+	if open < 0 || close <= open || close > 24*time.Hour {
+		return nil, fmt.Errorf("calendar %s session must open before it closes within the day, got %v to %v", name, open, close)
+	}
+	calendar := Calendar{
+		Name:     name,
+		Location: location,
+		Open:     open,
+		Close:    close,
+		weekend:  make(map[time.Weekday]bool),
+		holidays: make(map[Date]string),
+		closes:   make(map[Date]time.Duration),
+	}
+	for _, day := range weekend {
+		calendar.weekend[day] = true
+	}
+	if len(calendar.weekend) == 7 {
+		return nil, fmt.Errorf("calendar %s has no trading days in the week", name)
+	}
+	if base != nil {
+		calendar.rules = base.rules
+		for date, holiday := range base.holidays {
+			calendar.holidays[date] = holiday
+		}
+		for date, close := range base.closes {
+			calendar.closes[date] = close
+		}
+	}
+	return &calendar, nil
+}
+
+// AddHoliday closes the calendar on a date.  Calendars are not safe to change
+// once in use.
+func (calendar *Calendar) AddHoliday(date Date, name string) {
+	calendar.holidays[date] = name
+}
+
+// AddEarlyClose ends the session of a date at close instead of the regular close
+func (calendar *Calendar) AddEarlyClose(date Date, close time.Duration) error {
+	if close <= calendar.Open || close > calendar.Close {
+		return fmt.Errorf("early close on %v must be after the open and no later than the regular close", date)
+	}
+	calendar.closes[date] = close
+	return nil
+}
+
+// ruleYear is what the calendar's rules give for a year, computed once
+func (calendar *Calendar) ruleYear(year int) *ruleYear {
+	if cached, ok := calendar.years.Load(year); ok {
+		return cached.(*ruleYear)
+	}
+	result := ruleYear{holidays: make(map[Date]string), earlyCloses: make(map[Date]time.Duration)}
+	if calendar.rules != nil {
+		holidays, earlyCloses := calendar.rules(year)
+		for _, holiday := range holidays {
+			result.holidays[holiday.Date] = holiday.Name
+		}
+		for _, earlyClose := range earlyCloses {
+			result.earlyCloses[earlyClose.Date] = earlyClose.Close
+		}
+	}
+	cached, _ := calendar.years.LoadOrStore(year, &result)
+	return cached.(*ruleYear)
+}
+
+// holiday names the holiday on a date, if there is one
+func (calendar *Calendar) holiday(date Date) (string, bool) {
+	if name, ok := calendar.holidays[date]; ok {
+		return name, true
+	}
+	name, ok := calendar.ruleYear(date.Year).holidays[date]
+	return name, ok
+}
+
+// IsTradingDay reports whether the calendar trades on a date
+func (calendar *Calendar) IsTradingDay(date Date) bool {
+	if calendar.weekend[date.Weekday()] {
+		return false
+	}
+	_, closed := calendar.holiday(date)
+	return !closed
+}
+
+// Session is the open and close of a trading date; ok is false on other dates
+func (calendar *Calendar) Session(date Date) (open, close time.Time, ok bool) {
+	if !calendar.IsTradingDay(date) {
+		return time.Time{}, time.Time{}, false
+	}
+	return date.At(calendar.Open, calendar.Location), date.At(calendar.closeOffset(date), calendar.Location), true
+}
+
+// closeOffset is when the session of a trading date ends, allowing for early closes
+func (calendar *Calendar) closeOffset(date Date) time.Duration {
+	if early, found := calendar.closes[date]; found {
+		return early
+	}
+	if early, found := calendar.ruleYear(date.Year).earlyCloses[date]; found {
+		return early
+	}
+	return calendar.Close
+}
+
+// PreviousTradingDay is the latest trading date on or before date
+func (calendar *Calendar) PreviousTradingDay(date Date) Date {
+	for !calendar.IsTradingDay(date) {
+		date = date.AddDays(-1)
+	}
+	return date
+}
+
+// Holidays lists the weekday holidays and the early closes of a year in date order
+func (calendar *Calendar) Holidays(year int) ([]Holiday, []EarlyClose) {
+	var holidays []Holiday
+	var earlyCloses []EarlyClose
+	for date := (Date{year, time.January, 1}); date.Year == year; date = date.AddDays(1) {
+		if calendar.weekend[date.Weekday()] {
+			continue
+		}
+		if name, closed := calendar.holiday(date); closed {
+			holidays = append(holidays, Holiday{date, name})
+			continue
+		}
+		if close := calendar.closeOffset(date); close < calendar.Close {
+			earlyCloses = append(earlyCloses, EarlyClose{date, close})
+		}
+	}
+	return holidays, earlyCloses
+}
+
+// nthWeekday is the nth given weekday of a month, counting from 1
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) Date {
+	first := Date{year, month, 1}
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDays(offset + 7*(n-1))
+}
+
+// MonthlyExpiration is the standard listed option expiration of a month: the third
+// Friday, or the trading day before it when the Friday is a holiday
+func (calendar *Calendar) MonthlyExpiration(year int, month time.Month) Date {
+	return calendar.PreviousTradingDay(nthWeekday(year, month, time.Friday, 3))
+}
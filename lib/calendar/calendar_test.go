@@ -0,0 +1,107 @@
+package calendar
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// NYSE holidays and early closes as the exchange published them
+func TestNYSEHolidays(t *testing.T) {
+	tests := []struct {
+		year        int
+		holidays    []Date
+		earlyCloses []Date
+	}{
+		// New Year's Day on a Saturday is not observed, Juneteenth is observed on the Monday
+		{2022, []Date{{2022, 1, 17}, {2022, 2, 21}, {2022, 4, 15}, {2022, 5, 30}, {2022, 6, 20}, {2022, 7, 4},
+			{2022, 9, 5}, {2022, 11, 24}, {2022, 12, 26}},
+			[]Date{{2022, 11, 25}}},
+		{2025, []Date{{2025, 1, 1}, {2025, 1, 20}, {2025, 2, 17}, {2025, 4, 18}, {2025, 5, 26}, {2025, 6, 19},
+			{2025, 7, 4}, {2025, 9, 1}, {2025, 11, 27}, {2025, 12, 25}},
+			[]Date{{2025, 7, 3}, {2025, 11, 28}, {2025, 12, 24}}},
+		// Independence Day on a Saturday is observed on the Friday, which then has no early close
+		{2026, []Date{{2026, 1, 1}, {2026, 1, 19}, {2026, 2, 16}, {2026, 4, 3}, {2026, 5, 25}, {2026, 6, 19},
+			{2026, 7, 3}, {2026, 9, 7}, {2026, 11, 26}, {2026, 12, 25}},
+			[]Date{{2026, 11, 27}, {2026, 12, 24}}},
+	}
+	nyse := NYSE()
+	for _, test := range tests {
+		holidays, earlyCloses := nyse.Holidays(test.year)
+		var holidayDates, earlyCloseDates []Date
+		for _, holiday := range holidays {
+			holidayDates = append(holidayDates, holiday.Date)
+		}
+		for _, earlyClose := range earlyCloses {
+			earlyCloseDates = append(earlyCloseDates, earlyClose.Date)
+			if earlyClose.Close != nyseEarlyClose {
+				t.Errorf("%v: early close at %v, want %v", earlyClose.Date, earlyClose.Close, nyseEarlyClose)
+			}
+		}
+		if !reflect.DeepEqual(holidayDates, test.holidays) {
+			t.Errorf("%d holidays: got %v, want %v", test.year, holidayDates, test.holidays)
+		}
+		if !reflect.DeepEqual(earlyCloseDates, test.earlyCloses) {
+			t.Errorf("%d early closes: got %v, want %v", test.year, earlyCloseDates, test.earlyCloses)
+		}
+	}
+}
+
+func TestMonthlyExpiration(t *testing.T) {
+	nyse := NYSE()
+	tests := []struct {
+		year  int
+		month time.Month
+		want  Date
+	}{
+		{2025, time.January, Date{2025, 1, 17}},
+		// The third Friday is Good Friday
+		{2025, time.April, Date{2025, 4, 17}},
+		{2026, time.October, Date{2026, 10, 16}},
+	}
+	for _, test := range tests {
+		if got := nyse.MonthlyExpiration(test.year, test.month); got != test.want {
+			t.Errorf("%d %v: got %v, want %v", test.year, test.month, got, test.want)
+		}
+	}
+}
+
+// From 14:30 on a Friday to the close a week later, past no holiday
+func TestYearFraction(t *testing.T) {
+	nyse := NYSE()
+	from := time.Date(2025, 1, 10, 14, 30, 0, 0, nyse.Location)
+	expiry, err := nyse.ExpiryTime(Date{2025, 1, 17}, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	days := 7.0 + 1.5/24
+	tradingDays := 1.5/6.5 + 5
+	tests := []struct {
+		convention int
+		want       float64
+	}{
+		{Actual365, days / 365},
+		{Actual360, days / 360},
+		{Business252, tradingDays / 252},
+	}
+	for _, test := range tests {
+		got, err := nyse.YearFraction(test.convention, from, expiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("convention %d: got %v, want %v", test.convention, got, test.want)
+		}
+	}
+
+	// A session cut short by an early close counts in full
+	from = time.Date(2025, 12, 24, 9, 30, 0, 0, nyse.Location)
+	to := time.Date(2025, 12, 26, 16, 0, 0, 0, nyse.Location)
+	if got := nyse.TradingDays(from, to); math.Abs(got-2) > 1e-12 {
+		t.Errorf("Christmas Eve to Boxing Day: got %v trading days, want 2", got)
+	}
+	if _, err := nyse.ExpiryTime(Date{2025, 12, 25}, -1); err == nil {
+		t.Errorf("expiry on Christmas Day, want an error")
+	}
+}
@@ -0,0 +1,73 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// Conventions for measuring time to expiry in years
+const (
+	// Actual elapsed time over a 365 day year
+	Actual365 = iota
+	// Actual elapsed time over a 360 day year
+	Actual360
+	// Trading sessions over a 252 day year, counting part sessions pro rata
+	Business252
+)
+
+const hoursPerDay = 24.0
+
+// TradingDays counts the trading sessions between two times on the calendar.  A
+// session partly between them counts as the fraction of its trading time that
+// is, so an option expiring at the close counts the rest of today's session.
+func (calendar *Calendar) TradingDays(from, to time.Time) float64 {
+	if !to.After(from) {
+		return 0.0
+	}
+	days := 0.0
+	last := DateOf(to.In(calendar.Location))
+	for date := DateOf(from.In(calendar.Location)); !last.Before(date); date = date.AddDays(1) {
+		open, close, ok := calendar.Session(date)
+		if !ok {
+			continue
+		}
+		start, end := open, close
+		if from.After(start) {
+			start = from
+		}
+		if to.Before(end) {
+			end = to
+		}
+		if end.After(start) {
+			days += float64(end.Sub(start)) / float64(close.Sub(open))
+		}
+	}
+	return days
+}
+
+// YearFraction is the time from one moment to another in years under a convention
+func (calendar *Calendar) YearFraction(convention int, from, to time.Time) (float64, error) {
+	switch convention {
+	case Actual365:
+		return to.Sub(from).Hours() / hoursPerDay / 365, nil
+	case Actual360:
+		return to.Sub(from).Hours() / hoursPerDay / 360, nil
+	case Business252:
+		return calendar.TradingDays(from, to) / 252, nil
+	}
+	return 0.0, fmt.Errorf("unrecognized day count convention %d", convention)
+}
+
+// ExpiryTime is when an option expiring on a date stops trading: at offset past
+// midnight, or at the session close when offset is negative.  The date must be
+// a trading day.
+func (calendar *Calendar) ExpiryTime(date Date, offset time.Duration) (time.Time, error) {
+	_, close, ok := calendar.Session(date)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%v is not a trading day on the %s calendar", date, calendar.Name)
+	}
+	if offset < 0 {
+		return close, nil
+	}
+	return date.At(offset, calendar.Location), nil
+}
@@ -0,0 +1,117 @@
+package calendar
+
+import (
+	"time"
+)
+
+// Regular and early sessions of the New York Stock Exchange and of Cboe index
+// options, as offsets from midnight New York time
+const (
+	nyseOpen       = 9*time.Hour + 30*time.Minute
+	nyseClose      = 16 * time.Hour
+	nyseEarlyClose = 13 * time.Hour
+	// Cboe index options trade for a quarter of an hour after the stock market closes
+	cboeClose      = 16*time.Hour + 15*time.Minute
+	cboeEarlyClose = 13*time.Hour + 15*time.Minute
+)
+
+// easter is Easter Sunday in the Gregorian calendar, by the anonymous Gregorian algorithm
+func easter(year int) Date {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return Date{year, time.Month(month), day}
+}
+
+// lastWeekday is the last given weekday of a month
+func lastWeekday(year int, month time.Month, weekday time.Weekday) Date {
+	last := Date{year, month + 1, 1}.AddDays(-1)
+	return last.AddDays(-((int(last.Weekday()) - int(weekday) + 7) % 7))
+}
+
+// observed moves a fixed date holiday falling on Saturday to Friday and on Sunday to Monday
+func observed(date Date) Date {
+	switch date.Weekday() {
+	case time.Saturday:
+		return date.AddDays(-1)
+	case time.Sunday:
+		return date.AddDays(1)
+	}
+	return date
+}
+
+// nyseRules gives the NYSE holidays of a year and its 1pm closes on the day before
+// Independence Day, the day after Thanksgiving and Christmas Eve.  Early closes
+// are given as the offset of the stock market close, shifted by closeShift.
+func nyseRules(closeShift time.Duration) yearRules {
+	return func(year int) ([]Holiday, []EarlyClose) {
+		var holidays []Holiday
+		// New Year's Day falling on a Saturday is not observed on the Friday before
+		if newYear := (Date{year, time.January, 1}); newYear.Weekday() != time.Saturday {
+			holidays = append(holidays, Holiday{observed(newYear), "New Year's Day"})
+		}
+		if year >= 1998 {
+			holidays = append(holidays, Holiday{nthWeekday(year, time.January, time.Monday, 3), "Martin Luther King Jr. Day"})
+		}
+		holidays = append(holidays,
+			Holiday{nthWeekday(year, time.February, time.Monday, 3), "Washington's Birthday"},
+			Holiday{easter(year).AddDays(-2), "Good Friday"},
+			Holiday{lastWeekday(year, time.May, time.Monday), "Memorial Day"},
+		)
+		if year >= 2022 {
+			holidays = append(holidays, Holiday{observed(Date{year, time.June, 19}), "Juneteenth National Independence Day"})
+		}
+		independenceDay := observed(Date{year, time.July, 4})
+		thanksgiving := nthWeekday(year, time.November, time.Thursday, 4)
+		christmas := observed(Date{year, time.December, 25})
+		holidays = append(holidays,
+			Holiday{independenceDay, "Independence Day"},
+			Holiday{nthWeekday(year, time.September, time.Monday, 1), "Labor Day"},
+			Holiday{thanksgiving, "Thanksgiving Day"},
+			Holiday{christmas, "Christmas Day"},
+		)
+
+		var earlyCloses []EarlyClose
+		earlyClose := nyseEarlyClose + closeShift
+		for _, date := range []Date{{year, time.July, 3}, thanksgiving.AddDays(1), {year, time.December, 24}} {
+			weekday := date.Weekday()
+			if weekday == time.Saturday || weekday == time.Sunday || date == independenceDay || date == christmas {
+				continue
+			}
+			earlyCloses = append(earlyCloses, EarlyClose{date, earlyClose})
+		}
+		return holidays, earlyCloses
+	}
+}
+
+func newYork() *time.Location {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		// The zone is embedded with time/tzdata, so this cannot happen
+		panic(err)
+	}
+	return location
+}
+
+// NYSE is the New York Stock Exchange calendar, trading 9:30 to 16:00 New York time
+func NYSE() *Calendar {
+	calendar, _ := New("NYSE", newYork(), nyseOpen, nyseClose, []time.Weekday{time.Saturday, time.Sunday}, nil)
+	calendar.rules = nyseRules(0)
+	return calendar
+}
+
+// CBOE is the Cboe options calendar: NYSE holidays, with index options trading
+// until 16:15 New York time
+func CBOE() *Calendar {
+	calendar, _ := New("CBOE", newYork(), nyseOpen, cboeClose, []time.Weekday{time.Saturday, time.Sunday}, nil)
+	calendar.rules = nyseRules(cboeEarlyClose - nyseEarlyClose)
+	return calendar
+}
@@ -0,0 +1,258 @@
+package option
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+const (
+	// The Barone-Adesi-Whaley critical price is iterated to this fraction of the
+	// strike
+	criticalPriceConvergence = 1e-10
+	criticalPriceIterations  = 100
+)
+
+// earlyExercise is the part of the Barone-Adesi-Whaley approximation that does
+// not depend on the asset price: the critical price beyond which the option is
+// exercised, and the early exercise premium it adds to the European price below
+// it, weight * (S / critical) ^ exponent
+type earlyExercise struct {
+	// Early exercise is never optimal and the option is worth its European price
+	never    bool
+	critical float64
+	exponent float64
+	weight   float64
+}
+
+type earlyExerciseKey struct {
+	strikePrice, yearsToExpiry, volatility, riskFreeRate float64
+}
+
+type earlyExerciseEntry struct {
+	once     sync.Once
+	exercise earlyExercise
+	err      error
+}
+
+// ApproximationPrice prices the chain option by the Barone-Adesi-Whaley or
+// Bjerksund-Stensland (2002) approximation, recording the early exercise premium
+// over the Black-Scholes value.  Greeks come from repricing, which costs little
+// next to a lattice.
+func (chain *OptionChainCalculator) ApproximationPrice(assetPrice, strikePrice, daysToExpiry float64, position *OptionPosition) error {
+	var european OptionPosition
+	err := chain.europeanPrice(assetPrice, strikePrice, daysToExpiry, &european)
+	if err != nil {
+		return err
+	}
+
+	volatility, err := chain.VolatilityAt(assetPrice, strikePrice, daysToExpiry)
+	if err != nil {
+		return err
+	}
+	err = chain.repricedPosition(assetPrice, strikePrice, daysToExpiry, volatility, position,
+		func(assetPrice, daysToExpiry, volatility, riskFreeRate float64) (float64, error) {
+			return chain.approximationValue(assetPrice, strikePrice, daysToExpiry/365, volatility, riskFreeRate)
+		})
+	if err != nil {
+		return err
+	}
+	position.EarlyExercisePremium = math.Max(position.Price-european.Price, 0.0)
+	return nil
+}
+
+// approximates reports whether the chain's American model is one of the analytic
+// approximations rather than a lattice or grid
+func (chain *OptionChainCalculator) approximates() bool {
+	return chain.latticeModel == BaroneAdesiWhaley || chain.latticeModel == BjerksundStensland
+}
+
+// approximationValue is the price of the chain's American option under its
+// approximation
+func (chain *OptionChainCalculator) approximationValue(assetPrice, strikePrice, yearsToExpiry, volatility, riskFreeRate float64) (float64, error) {
+	if assetPrice <= 0.0 || strikePrice <= 0.0 {
+		return 0.0, fmt.Errorf("asset and strike prices must be > 0, got %v/%v", assetPrice, strikePrice)
+	}
+	if yearsToExpiry <= 0.0 {
+		return 0.0, fmt.Errorf("days to expiry must be > 0, got %v", yearsToExpiry*365)
+	}
+	// A futures price costs nothing to carry
+	carry := riskFreeRate - chain.DividendYield
+	if chain.model == Black76 {
+		carry = 0.0
+	}
+	if chain.latticeModel == BjerksundStensland {
+		return bjerksundStenslandValue(chain.optionType, assetPrice, strikePrice, yearsToExpiry, volatility, riskFreeRate, carry)
+	}
+
+	key := earlyExerciseKey{strikePrice, yearsToExpiry, volatility, riskFreeRate}
+	cached, _ := chain.earlyExercises.LoadOrStore(key, &earlyExerciseEntry{})
+	entry := cached.(*earlyExerciseEntry)
+	entry.once.Do(func() {
+		entry.exercise, entry.err = baroneAdesiWhaleyExercise(chain.optionType, strikePrice, yearsToExpiry, volatility, riskFreeRate, carry)
+	})
+	if entry.err != nil {
+		return 0.0, entry.err
+	}
+	return entry.exercise.value(chain.optionType, assetPrice, strikePrice, yearsToExpiry, volatility, riskFreeRate, carry), nil
+}
+
+// value is the Barone-Adesi-Whaley price: the European price plus the early
+// exercise premium short of the critical price, and the intrinsic value beyond it
+func (exercise *earlyExercise) value(optionType int, assetPrice, strikePrice, yearsToExpiry, volatility, riskFreeRate, carry float64) float64 {
+	european := blackScholesValue(optionType, assetPrice, strikePrice, yearsToExpiry, volatility, riskFreeRate, riskFreeRate-carry)
+	switch {
+	case exercise.never:
+		return european
+	case optionType == Call && assetPrice >= exercise.critical, optionType == Put && assetPrice <= exercise.critical:
+		return intrinsicValue(optionType, assetPrice, strikePrice)
+	}
+	return european + exercise.weight*math.Pow(assetPrice/exercise.critical, exercise.exponent)
+}
+
+// baroneAdesiWhaleyExercise solves the quadratic approximation of Barone-Adesi
+// and Whaley (1987) for the critical price by Newton's method, from the seed
+// Barone-Adesi and Whaley give
+func baroneAdesiWhaleyExercise(optionType int, strikePrice, yearsToExpiry, volatility, riskFreeRate, carry float64) (earlyExercise, error) {
+	// Calls are never exercised early unless the asset yields more than the
+	// strike earns, puts unless the strike earns anything
+	if optionType == Call && carry >= riskFreeRate || optionType == Put && riskFreeRate <= 0.0 {
+		return earlyExercise{never: true}, nil
+	}
+	if riskFreeRate <= 0.0 {
+		return earlyExercise{}, fmt.Errorf("the Barone-Adesi-Whaley approximation needs a risk-free rate > 0 for calls, got %v", riskFreeRate)
+	}
+
+	variance := volatility * volatility
+	stdDev := volatility * math.Sqrt(yearsToExpiry)
+	n := 2.0 * carry / variance
+	m := 2.0 * riskFreeRate / variance
+	k := 1.0 - math.Exp(-riskFreeRate*yearsToExpiry)
+	sign := 1.0
+	if optionType == Put {
+		sign = -1.0
+	}
+	exponent := (-(n - 1.0) + sign*math.Sqrt((n-1.0)*(n-1.0)+4.0*m/k)) / 2.0
+	// The critical price of the perpetual option bounds the seed
+	perpetualExponent := (-(n - 1.0) + sign*math.Sqrt((n-1.0)*(n-1.0)+4.0*m)) / 2.0
+	perpetual := strikePrice / (1.0 - 1.0/perpetualExponent)
+	var critical float64
+	if optionType == Call {
+		h := -(carry*yearsToExpiry + 2.0*stdDev) * strikePrice / (perpetual - strikePrice)
+		critical = strikePrice + (perpetual-strikePrice)*(1.0-math.Exp(h))
+	} else {
+		h := (carry*yearsToExpiry - 2.0*stdDev) * strikePrice / (strikePrice - perpetual)
+		critical = perpetual + (strikePrice-perpetual)*math.Exp(h)
+	}
+
+	carryDiscount := math.Exp((carry - riskFreeRate) * yearsToExpiry)
+	for iteration := 0; ; iteration++ {
+		if !(critical > 0.0) || math.IsInf(critical, 0) {
+			return earlyExercise{}, fmt.Errorf("the Barone-Adesi-Whaley critical price diverged for strike %v", strikePrice)
+		}
+		d1 := (math.Log(critical/strikePrice) + (carry+variance/2.0)*yearsToExpiry) / stdDev
+		// Delta of the European option and the premium weight at the critical price
+		delta := sign * carryDiscount * normalizedCDF(sign*d1)
+		weight := sign * (1.0 - sign*delta) * critical / exponent
+		european := blackScholesValue(optionType, critical, strikePrice, yearsToExpiry, volatility, riskFreeRate, riskFreeRate-carry)
+		excess := european + weight - sign*(critical-strikePrice)
+		if math.Abs(excess) < criticalPriceConvergence*strikePrice {
+			return earlyExercise{critical: critical, exponent: exponent, weight: weight}, nil
+		}
+		if iteration == criticalPriceIterations {
+			return earlyExercise{}, fmt.Errorf("the Barone-Adesi-Whaley critical price for strike %v did not converge", strikePrice)
+		}
+		slope := delta*(1.0-1.0/exponent) + (sign-carryDiscount*normalizedPDF(d1)/stdDev)/exponent
+		critical -= excess / (slope - sign)
+	}
+}
+
+// bjerksundStenslandValue is the Bjerksund-Stensland (2002) price, the value of
+// exercising at a flat boundary over the first part of the option's life and
+// another over the rest.  Puts are priced as calls by put-call transformation.
+func bjerksundStenslandValue(optionType int, assetPrice, strikePrice, yearsToExpiry, volatility, riskFreeRate, carry float64) (float64, error) {
+	if optionType == Put {
+		return bjerksundStenslandCall(strikePrice, assetPrice, yearsToExpiry, volatility, riskFreeRate-carry, -carry)
+	}
+	return bjerksundStenslandCall(assetPrice, strikePrice, yearsToExpiry, volatility, riskFreeRate, carry)
+}
+
+func bjerksundStenslandCall(assetPrice, strikePrice, yearsToExpiry, volatility, riskFreeRate, carry float64) (float64, error) {
+	if carry >= riskFreeRate {
+		return blackScholesValue(Call, assetPrice, strikePrice, yearsToExpiry, volatility, riskFreeRate, riskFreeRate-carry), nil
+	}
+	if riskFreeRate < 0.0 {
+		return 0.0, fmt.Errorf("the Bjerksund-Stensland approximation needs a risk-free rate >= 0 for calls, got %v", riskFreeRate)
+	}
+
+	variance := volatility * volatility
+	beta := (0.5 - carry/variance) + math.Sqrt(math.Pow(carry/variance-0.5, 2.0)+2.0*riskFreeRate/variance)
+	perpetual := beta / (beta - 1.0) * strikePrice
+	floor := strikePrice
+	if riskFreeRate > 0.0 {
+		floor = math.Max(strikePrice, riskFreeRate/(riskFreeRate-carry)*strikePrice)
+	}
+	// The boundaries over the first part of the option's life, chosen by golden
+	// section, and over all of it
+	firstYears := (math.Sqrt(5.0) - 1.0) / 2.0 * yearsToExpiry
+	boundary := func(years float64) float64 {
+		h := -(carry*years + 2.0*volatility*math.Sqrt(years)) * strikePrice * strikePrice / ((perpetual - floor) * floor)
+		return floor + (perpetual-floor)*(1.0-math.Exp(h))
+	}
+	first, second := boundary(firstYears), boundary(yearsToExpiry)
+	if assetPrice >= second {
+		return assetPrice - strikePrice, nil
+	}
+	firstWeight := (first - strikePrice) * math.Pow(first, -beta)
+	secondWeight := (second - strikePrice) * math.Pow(second, -beta)
+
+	phi := func(gamma, level, barrier float64) float64 {
+		return bjerksundStenslandPhi(assetPrice, firstYears, gamma, level, barrier, volatility, riskFreeRate, carry)
+	}
+	psi := func(gamma, level float64) float64 {
+		return bjerksundStenslandPsi(assetPrice, yearsToExpiry, gamma, level, second, first, firstYears, volatility, riskFreeRate, carry)
+	}
+	return secondWeight*math.Pow(assetPrice, beta) - secondWeight*phi(beta, second, second) +
+		phi(1.0, second, second) - phi(1.0, first, second) -
+		strikePrice*phi(0.0, second, second) + strikePrice*phi(0.0, first, second) +
+		firstWeight*phi(beta, first, second) - firstWeight*psi(beta, first) +
+		psi(1.0, first) - psi(1.0, strikePrice) -
+		strikePrice*psi(0.0, first) + strikePrice*psi(0.0, strikePrice), nil
+}
+
+// bjerksundStenslandPhi values S^gamma paid at years if the asset finishes below
+// level without reaching barrier first
+func bjerksundStenslandPhi(assetPrice, years, gamma, level, barrier, volatility, riskFreeRate, carry float64) float64 {
+	variance := volatility * volatility
+	stdDev := volatility * math.Sqrt(years)
+	lambda := (-riskFreeRate + gamma*carry + 0.5*gamma*(gamma-1.0)*variance) * years
+	d := -(math.Log(assetPrice/level) + (carry+(gamma-0.5)*variance)*years) / stdDev
+	kappa := 2.0*carry/variance + 2.0*gamma - 1.0
+	return math.Exp(lambda) * math.Pow(assetPrice, gamma) *
+		(normalizedCDF(d) - math.Pow(barrier/assetPrice, kappa)*normalizedCDF(d-2.0*math.Log(barrier/assetPrice)/stdDev))
+}
+
+// bjerksundStenslandPsi values S^gamma paid at years if the asset finishes below
+// level without reaching firstBarrier by firstYears or barrier after
+func bjerksundStenslandPsi(assetPrice, years, gamma, level, barrier, firstBarrier, firstYears, volatility, riskFreeRate, carry float64) float64 {
+	variance := volatility * volatility
+	drift := carry + (gamma-0.5)*variance
+	firstStdDev := volatility * math.Sqrt(firstYears)
+	stdDev := volatility * math.Sqrt(years)
+	e1 := (math.Log(assetPrice/firstBarrier) + drift*firstYears) / firstStdDev
+	e2 := (math.Log(barrier*barrier/(assetPrice*firstBarrier)) + drift*firstYears) / firstStdDev
+	e3 := (math.Log(assetPrice/firstBarrier) - drift*firstYears) / firstStdDev
+	e4 := (math.Log(barrier*barrier/(assetPrice*firstBarrier)) - drift*firstYears) / firstStdDev
+	f1 := (math.Log(assetPrice/level) + drift*years) / stdDev
+	f2 := (math.Log(barrier*barrier/(assetPrice*level)) + drift*years) / stdDev
+	f3 := (math.Log(firstBarrier*firstBarrier/(assetPrice*level)) + drift*years) / stdDev
+	f4 := (math.Log(assetPrice*firstBarrier*firstBarrier/(level*barrier*barrier)) + drift*years) / stdDev
+	rho := math.Sqrt(firstYears / years)
+	lambda := -riskFreeRate + gamma*carry + 0.5*gamma*(gamma-1.0)*variance
+	kappa := 2.0*carry/variance + 2.0*gamma - 1.0
+	return math.Exp(lambda*years) * math.Pow(assetPrice, gamma) *
+		(bivariateNormalCDF(-e1, -f1, rho) -
+			math.Pow(barrier/assetPrice, kappa)*bivariateNormalCDF(-e2, -f2, rho) -
+			math.Pow(firstBarrier/assetPrice, kappa)*bivariateNormalCDF(-e3, -f3, -rho) +
+			math.Pow(firstBarrier/barrier, kappa)*bivariateNormalCDF(-e4, -f4, -rho))
+}
@@ -0,0 +1,133 @@
+package option
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// Leisen-Reimer steps of the binomial benchmark the approximations are
+// validated against
+const benchmarkSteps = 1001
+
+// approximationCase is one American option of the validation suite
+type approximationCase struct {
+	optionType    int
+	assetPrice    float64
+	strikePrice   float64
+	daysToExpiry  float64
+	volatility    float64
+	riskFreeRate  float64
+	dividendYield float64
+}
+
+// approximationCases is the validation suite: calls and puts struck at 100 on
+// assets at 90, 100 and 110, over a tenth and a half of a year at volatilities of
+// 15%, 25% and 35%, with the asset yielding as much as the strike earns, more,
+// and nothing at all.  It extends the table Barone-Adesi and Whaley tested on.
+func approximationCases() []approximationCase {
+	rates := [][2]float64{{0.10, 0.10}, {0.08, 0.12}, {0.08, 0.0}}
+	var cases []approximationCase
+	for _, optionType := range []int{Call, Put} {
+		for _, rate := range rates {
+			for _, days := range []float64{36.5, 182.5} {
+				for _, volatility := range []float64{0.15, 0.25, 0.35} {
+					for _, assetPrice := range []float64{90.0, 100.0, 110.0} {
+						cases = append(cases, approximationCase{optionType, assetPrice, 100.0, days, volatility, rate[0], rate[1]})
+					}
+				}
+			}
+		}
+	}
+	return cases
+}
+
+func (c *approximationCase) price(latticeModel int) (float64, error) {
+	chain, err := NewOptionChain(c.optionType, c.volatility, c.riskFreeRate, c.daysToExpiry)
+	if err != nil {
+		return 0.0, err
+	}
+	if err := chain.SetExerciseStyle(American, latticeModel, benchmarkSteps); err != nil {
+		return 0.0, err
+	}
+	if err := chain.SetDividends(c.dividendYield, nil); err != nil {
+		return 0.0, err
+	}
+	var position OptionPosition
+	err = chain.calculatePrice(c.assetPrice, c.strikePrice, c.daysToExpiry, &position)
+	return position.Price, err
+}
+
+// Both approximations against a Leisen-Reimer lattice whose error is small next
+// to theirs.  Barone-Adesi-Whaley is within about 0.067 of it and Bjerksund-Stensland,
+// the value of one exercise strategy, is a lower bound within about 0.075.
+func TestApproximationsAgainstLattice(t *testing.T) {
+	cases := approximationCases()
+	benchmarks := make([]float64, len(cases))
+	pool := OptionChainCalculator{}
+	err := pool.computeRows(context.Background(), len(cases), func(row int) (err error) {
+		benchmarks[row], err = cases[row].price(LatticeLeisenReimer)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		latticeModel int
+		maxError     float64
+		lowerBound   bool
+	}{
+		{"Barone-Adesi-Whaley", BaroneAdesiWhaley, 0.07, false},
+		{"Bjerksund-Stensland", BjerksundStensland, 0.08, true},
+	}
+	for _, test := range tests {
+		var worst float64
+		for i := range cases {
+			c := &cases[i]
+			got, err := c.price(test.latticeModel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff := got - benchmarks[i]
+			worst = math.Max(worst, math.Abs(diff))
+			if math.Abs(diff) > test.maxError {
+				t.Errorf("%s %+v: got %.4f, want %.4f within %v", test.name, *c, got, benchmarks[i], test.maxError)
+			}
+			// The lattice is good to a few thousandths here
+			if test.lowerBound && diff > 5e-3 {
+				t.Errorf("%s %+v: got %.4f above the benchmark %.4f", test.name, *c, got, benchmarks[i])
+			}
+		}
+		t.Logf("%s: largest error %.4f", test.name, worst)
+	}
+}
+
+// Barone-Adesi-Whaley calls against Haug's table, half a year out with the asset
+// yielding as much as the strike earns
+func TestBaroneAdesiWhaleyReference(t *testing.T) {
+	tests := []struct {
+		assetPrice, volatility, want float64
+	}{
+		{90, 0.15, 0.8208},
+		{100, 0.15, 4.0842},
+		{110, 0.15, 10.8087},
+		{90, 0.25, 2.7437},
+		{100, 0.25, 6.8015},
+		{110, 0.25, 13.0170},
+		{90, 0.35, 5.0063},
+		{100, 0.35, 9.5106},
+		{110, 0.35, 15.5689},
+	}
+	for _, test := range tests {
+		c := approximationCase{Call, test.assetPrice, 100, 182.5, test.volatility, 0.1, 0.1}
+		got, err := c.price(BaroneAdesiWhaley)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(got-test.want) > 5e-4 {
+			t.Errorf("S=%v vol=%v: got %.4f, want %.4f", test.assetPrice, test.volatility, got, test.want)
+		}
+	}
+}
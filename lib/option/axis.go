@@ -0,0 +1,248 @@
+package option
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// Slack allowed when deciding whether High falls on a step of a span
+	axisTolerance = 1e-9
+	// Axis values are rounded to this many parts of a unit so that repeated steps
+	// such as 0.1 do not print as 0.30000000000000004
+	axisPrecision = 1e8
+)
+
+// Limit on the length of an axis, checked before a span is listed so that a tiny
+// step cannot overflow its count or ask for an unbounded allocation
+var (
+	MaxAxisLength  = 5_000
+	ErrAxisTooLong = errors.New("option chain axis is too long")
+)
+
+// Axis is one dimension of an option chain: asset prices, strike prices or days
+// to expiry, or the relative points they are resolved from.  Values are listed in
+// ascending order.
+type Axis interface {
+	// Values lists the points of the axis in ascending order
+	Values(name string) ([]float64, error)
+	// Count is the number of points on the axis, without listing them
+	Count(name string) (int, error)
+	// Bounds are the lowest and highest points on the axis
+	Bounds(name string) (float64, float64, error)
+}
+
+// ValueList is an axis of explicit values, for example strikes that are 2.5 wide
+// near the money and 5 wide further out.  Duplicates are ignored.
+type ValueList []float64
+
+// GeometricSpan is an axis of Points values from Low to High with a constant
+// ratio between neighbours
+type GeometricSpan struct {
+	Low    float64
+	High   float64
+	Points int
+}
+
+func isNotNumber(value float64) bool {
+	return math.IsNaN(value) || math.IsInf(value, 0)
+}
+
+func roundAxisValue(value float64) float64 {
+	return math.Round(value*axisPrecision) / axisPrecision
+}
+
+func validateSpan(name string, span *ValueSpan) (float64, float64, float64, error) {
+	var err error
+	if isNotNumber(span.Low) || isNotNumber(span.High) || isNotNumber(span.Step) {
+		err = fmt.Errorf("%s: span Low, High and Step must be numbers", name)
+	} else if span.Step < 0 {
+		err = fmt.Errorf("%s: span Step cannot be negative", name)
+	} else if span.Low > span.High {
+		err = fmt.Errorf("%s: span Low > High", name)
+	} else if span.High > span.Low && span.Step == 0 {
+		err = fmt.Errorf("%s: span Step must be > 0 for Span.High > Span.Low", name)
+	}
+
+	if err != nil {
+		return 0.0, 0.0, 0.0, err
+	}
+
+	return span.Low, span.High, span.Step, nil
+}
+
+func (span *ValueSpan) Count(name string) (int, error) {
+	low, high, step, err := validateSpan(name, span)
+	if err != nil {
+		return 0, err
+	}
+	if step == 0.0 {
+		return 1, nil
+	}
+	// Check the quotient before converting it, a tiny step overflows an int
+	steps := math.Floor((high-low)/step + axisTolerance)
+	if math.IsNaN(steps) || steps >= float64(MaxAxisLength) {
+		return 0, fmt.Errorf("%w: %s span from %v to %v in steps of %v has more than %d values - increase the step or narrow the range",
+			ErrAxisTooLong, name, low, high, step, MaxAxisLength)
+	}
+	return int(steps) + 1, nil
+}
+
+// Values lists the points of a validated span from Low to High
+func (span *ValueSpan) Values(name string) ([]float64, error) {
+	count, err := span.Count(name)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, count)
+	for i := range values {
+		values[i] = roundAxisValue(span.Low + float64(i)*span.Step)
+	}
+	return values, nil
+}
+
+// Bounds are Low and the last step at or below High
+func (span *ValueSpan) Bounds(name string) (float64, float64, error) {
+	count, err := span.Count(name)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	return span.Low, roundAxisValue(span.Low + float64(count-1)*span.Step), nil
+}
+
+func (list ValueList) Values(name string) ([]float64, error) {
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%s: value list is empty", name)
+	}
+	values := append([]float64(nil), list...)
+	sort.Float64s(values)
+	unique := values[:0]
+	for i, value := range values {
+		if isNotNumber(value) {
+			return nil, fmt.Errorf("%s: value %v is not a number", name, value)
+		}
+		if i == 0 || value != values[i-1] {
+			unique = append(unique, value)
+		}
+	}
+	return unique, nil
+}
+
+func (list ValueList) Count(name string) (int, error) {
+	values, err := list.Values(name)
+	return len(values), err
+}
+
+func (list ValueList) Bounds(name string) (float64, float64, error) {
+	values, err := list.Values(name)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	return values[0], values[len(values)-1], nil
+}
+
+func (span *GeometricSpan) Count(name string) (int, error) {
+	if span.Low <= 0.0 || span.High <= 0.0 {
+		return 0, fmt.Errorf("%s: geometric span Low and High must be > 0", name)
+	} else if span.Low > span.High {
+		return 0, fmt.Errorf("%s: span Low > High", name)
+	} else if span.Points < 1 {
+		return 0, fmt.Errorf("%s: geometric span needs at least one point", name)
+	} else if span.Points == 1 && span.High > span.Low {
+		return 0, fmt.Errorf("%s: geometric span needs at least two points for High > Low", name)
+	}
+	return span.Points, nil
+}
+
+// Values lists Points values from Low to High, evenly spaced in log terms
+func (span *GeometricSpan) Values(name string) ([]float64, error) {
+	count, err := span.Count(name)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, count)
+	values[0] = span.Low
+	if count > 1 {
+		logRatio := math.Log(span.High/span.Low) / float64(count-1)
+		for i := 1; i < count-1; i++ {
+			values[i] = roundAxisValue(span.Low * math.Exp(float64(i)*logRatio))
+		}
+		values[count-1] = span.High
+	}
+	return values, nil
+}
+
+func (span *GeometricSpan) Bounds(name string) (float64, float64, error) {
+	if _, err := span.Count(name); err != nil {
+		return 0.0, 0.0, err
+	}
+	return span.Low, span.High, nil
+}
+
+// validateAxisBounds rejects axes of asset prices, strike prices or days to expiry
+// with negative values
+func validateAxisBounds(name string, axis Axis) error {
+	low, _, err := axis.Bounds(name)
+	if err != nil {
+		return err
+	}
+	if low < 0.0 {
+		return fmt.Errorf("%s: values cannot be negative, lowest is %v", name, low)
+	}
+	return nil
+}
+
+// expiryAxis anchors a span of days to expiry at High rather than Low, so the
+// longest expiry is always priced whatever the step
+func expiryAxis(axis Axis) Axis {
+	span, ok := axis.(*ValueSpan)
+	if !ok {
+		return axis
+	}
+	count, err := span.Count("")
+	if err != nil || count == 1 {
+		return axis
+	}
+	return &ValueSpan{Low: roundAxisValue(span.High - float64(count-1)*span.Step), High: span.High, Step: span.Step}
+}
+
+// expiryValues lists days to expiry from high to low.  Zero is expiry itself, which
+// has no time value to price, and is left out.
+func expiryValues(name string, axis Axis) ([]float64, error) {
+	if err := validateAxisBounds(name, axis); err != nil {
+		return nil, err
+	}
+	values, err := expiryAxis(axis).Values(name)
+	if err != nil {
+		return nil, err
+	}
+	if values[0] == 0.0 {
+		values = values[1:]
+	}
+	for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+		values[i], values[j] = values[j], values[i]
+	}
+	return values, nil
+}
+
+// expiryCount is the length of expiryValues, without listing them
+func expiryCount(name string, axis Axis) (int, error) {
+	if err := validateAxisBounds(name, axis); err != nil {
+		return 0, err
+	}
+	axis = expiryAxis(axis)
+	count, err := axis.Count(name)
+	if err != nil {
+		return 0, err
+	}
+	low, _, err := axis.Bounds(name)
+	if err != nil {
+		return 0, err
+	}
+	if low == 0.0 {
+		count--
+	}
+	return count, nil
+}
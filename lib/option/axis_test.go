@@ -0,0 +1,64 @@
+package option
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestValueSpanValues(t *testing.T) {
+	tests := []struct {
+		span ValueSpan
+		want []float64
+	}{
+		{ValueSpan{Low: 100, High: 100}, []float64{100}},
+		{ValueSpan{Low: 90, High: 110, Step: 10}, []float64{90, 100, 110}},
+		{ValueSpan{Low: 90, High: 115, Step: 10}, []float64{90, 100, 110}},
+		{ValueSpan{Low: 0.1, High: 0.3, Step: 0.1}, []float64{0.1, 0.2, 0.3}},
+	}
+	for _, test := range tests {
+		got, err := test.span.Values("span")
+		if err != nil {
+			t.Errorf("%+v: %v", test.span, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%+v: got %v, want %v", test.span, got, test.want)
+		}
+	}
+}
+
+// A step too small for the range is rejected before its count can overflow
+func TestValueSpanTooLong(t *testing.T) {
+	tests := []ValueSpan{
+		{Low: 0, High: 1e10, Step: 1e-300},
+		{Low: 0, High: 1e300, Step: 1e-300},
+		{Low: 0, High: float64(MaxAxisLength), Step: 1},
+	}
+	for _, span := range tests {
+		if count, err := span.Count("span"); !errors.Is(err, ErrAxisTooLong) {
+			t.Errorf("%+v: got count %d and error %v, want ErrAxisTooLong", span, count, err)
+		}
+		if _, err := span.Values("span"); !errors.Is(err, ErrAxisTooLong) {
+			t.Errorf("%+v: got error %v listing values, want ErrAxisTooLong", span, err)
+		}
+	}
+
+	longest := ValueSpan{Low: 1, High: float64(MaxAxisLength), Step: 1}
+	if count, err := longest.Count("span"); err != nil || count != MaxAxisLength {
+		t.Errorf("%+v: got count %d and error %v, want %d", longest, count, err, MaxAxisLength)
+	}
+}
+
+func TestValueSpanNotNumber(t *testing.T) {
+	tests := []ValueSpan{
+		{Low: math.NaN(), High: 100, Step: 1},
+		{Low: 0, High: math.Inf(1), Step: 1},
+		{Low: 0, High: 100, Step: math.NaN()},
+		{Low: 0, High: 100, Step: math.Inf(1)},
+	}
+	for _, span := range tests {
+		if count, err := span.Count("span"); err == nil {
+			t.Errorf("%+v: got count %d, want an error", span, count)
+		}
+	}
+}
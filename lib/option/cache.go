@@ -0,0 +1,59 @@
+package option
+
+import (
+	"math"
+	"sync"
+)
+
+const cacheShards = 64
+
+type d1d2CacheShard struct {
+	mutex  sync.RWMutex
+	values map[priceKey]d1d2Calculation
+}
+
+// d1d2Cache is a map of d1/d2 values sharded by key so chain workers rarely
+// contend for the same lock
+type d1d2Cache struct {
+	shards [cacheShards]d1d2CacheShard
+}
+
+func newD1D2Cache() *d1d2Cache {
+	var cache d1d2Cache
+	for i := range cache.shards {
+		cache.shards[i].values = make(map[priceKey]d1d2Calculation)
+	}
+	return &cache
+}
+
+func (cache *d1d2Cache) shard(key priceKey) *d1d2CacheShard {
+	hash := math.Float64bits(key.assetPrice)*31 + math.Float64bits(key.strikePrice)*17 + math.Float64bits(key.daysToExpiry)
+	hash ^= hash >> 29
+	return &cache.shards[hash%cacheShards]
+}
+
+func (cache *d1d2Cache) load(key priceKey) (d1d2Calculation, bool) {
+	shard := cache.shard(key)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+	value, ok := shard.values[key]
+	return value, ok
+}
+
+func (cache *d1d2Cache) store(key priceKey, value d1d2Calculation) {
+	shard := cache.shard(key)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	shard.values[key] = value
+}
+
+// resetCaches discards cached d1/d2 calculations, finite-difference grids,
+// Fourier-cosine expansions and Barone-Adesi-Whaley critical prices, which depend
+// on the chain inputs
+func (chain *OptionChainCalculator) resetCaches() {
+	chain.d1d2CalculateFuncMap = &sync.Map{}
+	chain.d1d2CalculationValueMap = newD1D2Cache()
+	chain.gridSlices = &sync.Map{}
+	chain.expansions = &sync.Map{}
+	chain.earlyExercises = &sync.Map{}
+}
@@ -1,38 +1,25 @@
 package option
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
 )
 
+// Rows of (asset price, strike price) queued per worker when streaming
+const streamRowsPerWorker = 4
+
 // Standard normal cumulative distribution function
 func normalizedCDF(x float64) float64 {
 	return 0.5 * (1.0 + math.Erf(x/math.Sqrt(2.0)))
 }
 
-func validateSpan(name string, span *ValueSpan) (float64, float64, float64, error) {
-	var err error
-	if span.Low < 0 || span.High < 0 || span.Step < 0 {
-		err = fmt.Errorf("%s: span Low, High, Step cannot be negative", name)
-	} else if span.Low > span.High {
-		err = fmt.Errorf("%s: span Low > High", name)
-	} else if span.High > span.Low && span.Step == 0 {
-		err = fmt.Errorf("%s: span Step must be > 0 for Span.High > Span.Low", name)
-	} else {
-		for _, v := range []float64{span.Low, span.High, span.Step} {
-			cVal := v * 4.0
-			if math.Ceil(cVal) != math.Floor(cVal) {
-				err = fmt.Errorf("%s: Low, High, Step values must be multiples of 0.25", name)
-				break
-			}
-		}
-	}
-
-	if err != nil {
-		return 0.0, 0.0, 0.0, err
-	}
-
-	return span.Low, span.High, span.Step, nil
+// Standard normal probability density function
+func normalizedPDF(x float64) float64 {
+	return math.Exp(-0.5*x*x) / math.Sqrt(2.0*math.Pi)
 }
 
 func NewOptionChain(optionType int, volatility, riskFreeRate, expiryInDays float64) (*OptionChainCalculator, error) {
@@ -51,29 +38,91 @@ func NewOptionChain(optionType int, volatility, riskFreeRate, expiryInDays float
 	default:
 		return nil, fmt.Errorf("unrecognized optionType %d", optionType)
 	}
+	chain.optionType = optionType
 	chain.calculatePrice = priceCalculator
-	chain.d1d2CalculateFuncMap = make(map[float64]d1d2CalculateFunc)
-	chain.d1d2CalculationValueMap = make(map[priceKey]d1d2Calculation)
+	chain.europeanPrice = priceCalculator
+	chain.resetCaches()
 
 	return &chain, nil
 }
 
-// Black-Scholes formula for call option price
+// SetVolatilitySurface prices each option of the chain at the volatility the
+// surface gives for its asset price, strike and expiry instead of the flat
+// Volatility.  A nil surface restores flat volatility.
+func (chain *OptionChainCalculator) SetVolatilitySurface(surface VolatilitySource) {
+	chain.volatilitySurface = surface
+	// d1/d2 values cached so far were computed at other volatilities
+	chain.resetCaches()
+}
 
+// VolatilityAt is the volatility one option of the chain is priced at.  Under
+// Heston it is the average volatility the model expects to expiry, and under the
+// jump models the volatility of the log price including the jumps.
+func (chain *OptionChainCalculator) VolatilityAt(assetPrice, strikePrice, daysToExpiry float64) (float64, error) {
+	switch chain.model {
+	case Heston:
+		return chain.heston.AverageVolatility(daysToExpiry / 365), nil
+	case Merton:
+		return math.Sqrt(chain.Volatility*chain.Volatility + chain.merton.Variance()), nil
+	case Kou:
+		return math.Sqrt(chain.Volatility*chain.Volatility + chain.kou.Variance()), nil
+	}
+	if chain.volatilitySurface == nil {
+		return chain.Volatility, nil
+	}
+	return chain.volatilitySurface.Volatility(assetPrice, strikePrice, daysToExpiry)
+}
+
+// SetRateCurve discounts each expiry of the chain at the curve's zero rate for it
+// instead of the flat RiskFreeRate.  A nil curve restores the flat rate.
+func (chain *OptionChainCalculator) SetRateCurve(curve RateSource) {
+	chain.rateCurve = curve
+	// d1/d2 values cached so far were computed at other rates
+	chain.resetCaches()
+}
+
+// RateAt is the continuously compounded rate options expiring in daysToExpiry are discounted at
+func (chain *OptionChainCalculator) RateAt(daysToExpiry float64) float64 {
+	if chain.rateCurve == nil {
+		return chain.RiskFreeRate
+	}
+	return chain.rateCurve.ZeroRate(daysToExpiry)
+}
+
+// discountFactor is the value at fromDays of one paid at toDays
+func (chain *OptionChainCalculator) discountFactor(fromDays, toDays float64) float64 {
+	return math.Exp(-(chain.RateAt(toDays)*toDays - chain.RateAt(fromDays)*fromDays) / 365)
+}
+
+// d1d2calculator computes the terms of d1/d2 that depend only on days to expiry.
+// Volatility is passed per option, since a surface gives each strike its own.
 func (chain *OptionChainCalculator) d1d2calculator(daysToExpiry float64) (d1d2CalculateFunc, error) {
 	yearsToExpiry := daysToExpiry / 365
 	sqrtT := math.Sqrt(yearsToExpiry)
-	maxReturn := (chain.RiskFreeRate + (chain.Volatility*chain.Volatility)/2.0) * yearsToExpiry
-	volatilityAdjustment := chain.Volatility * sqrtT
-	if volatilityAdjustment == 0.0 {
-		return nil, fmt.Errorf(
-			"volatilityAdjustment == 0.0, op = r/v/d/y = %v/%v/%v/%v",
-			chain.RiskFreeRate, chain.Volatility, daysToExpiry, yearsToExpiry,
-		)
+	if sqrtT == 0.0 {
+		return nil, fmt.Errorf("days to expiry must be > 0, got %v", daysToExpiry)
 	}
+	riskFreeRate := chain.RateAt(daysToExpiry)
+	dividendDiscount := math.Exp(-chain.DividendYield * yearsToExpiry)
+	escrowedDividends := chain.dividendsPresentValue(0.0, daysToExpiry)
 
-	return func(assetPrice float64, strikePrice float64) (*d1d2Calculation, error) {
-		d1 := (math.Log(assetPrice/strikePrice) + maxReturn) / volatilityAdjustment
+	return func(assetPrice, strikePrice, volatility float64) (*d1d2Calculation, error) {
+		maxReturn := (riskFreeRate - chain.DividendYield + (volatility*volatility)/2.0) * yearsToExpiry
+		volatilityAdjustment := volatility * sqrtT
+		if volatilityAdjustment == 0.0 {
+			return nil, fmt.Errorf(
+				"volatilityAdjustment == 0.0, op = r/v/d/y = %v/%v/%v/%v",
+				riskFreeRate, volatility, daysToExpiry, yearsToExpiry,
+			)
+		}
+		adjustedAssetPrice := assetPrice - escrowedDividends
+		if adjustedAssetPrice <= 0.0 {
+			return nil, fmt.Errorf(
+				"asset price %v does not cover dividends worth %v before expiry in %v days",
+				assetPrice, escrowedDividends, daysToExpiry,
+			)
+		}
+		d1 := (math.Log(adjustedAssetPrice/strikePrice) + maxReturn) / volatilityAdjustment
 		d2 := d1 - volatilityAdjustment
 		if math.IsNaN(d1) || math.IsNaN(d2) {
 			return nil, fmt.Errorf(
@@ -81,45 +130,48 @@ func (chain *OptionChainCalculator) d1d2calculator(daysToExpiry float64) (d1d2Ca
 				d1, d2, assetPrice, strikePrice, volatilityAdjustment,
 			)
 		}
-		return &d1d2Calculation{d1, d2, yearsToExpiry}, nil
+		return &d1d2Calculation{d1, d2, yearsToExpiry, volatility, riskFreeRate, volatilityAdjustment, adjustedAssetPrice, dividendDiscount}, nil
 	}, nil
 }
 
 func (chain *OptionChainCalculator) calculateD1D2(assetPrice, strikePrice, daysToExpiry float64) (*d1d2Calculation, error) {
-	var d1d2 d1d2Calculation
 	valueMap := chain.d1d2CalculationValueMap
 	key := priceKey{assetPrice, strikePrice, daysToExpiry}
-	d1d2, ok := valueMap[key]
+	d1d2, ok := valueMap.load(key)
 	if !ok {
-		var err error
 		funcMap := chain.d1d2CalculateFuncMap
-		calculate := funcMap[daysToExpiry]
-		if calculate == nil {
+		var calculate d1d2CalculateFunc
+		if cached, found := funcMap.Load(daysToExpiry); found {
+			calculate = cached.(d1d2CalculateFunc)
+		} else {
+			var err error
 			calculate, err = chain.d1d2calculator(daysToExpiry)
 			if err != nil {
 				return nil, err
 			}
-			funcMap[daysToExpiry] = calculate
+			funcMap.Store(daysToExpiry, calculate)
+		}
+		volatility, err := chain.VolatilityAt(assetPrice, strikePrice, daysToExpiry)
+		if err != nil {
+			return nil, err
 		}
-		calcP, err := calculate(assetPrice, strikePrice)
+		calcP, err := calculate(assetPrice, strikePrice, volatility)
 		if err != nil {
 			return nil, err
 		}
 		d1d2 = *calcP
-		valueMap[key] = d1d2
+		valueMap.store(key, d1d2)
 	}
 	return &d1d2, nil
 }
 
+// Black-Scholes formula for call option price
 func (chain *OptionChainCalculator) BlackScholesCall(assetPrice, strikePrice, daysToExpiry float64, position *OptionPosition) error {
 	d1d2, err := chain.calculateD1D2(assetPrice, strikePrice, daysToExpiry)
 	if err != nil {
 		return err
 	}
-	price := assetPrice*normalizedCDF(d1d2.d1) - strikePrice*math.Exp(-chain.RiskFreeRate*d1d2.yearsToExpiry)*normalizedCDF(d1d2.d2)
-	position.Price = price
-	position.Strike = strikePrice
-	position.DaysToExpiry = daysToExpiry
+	chain.blackScholesPosition(Call, strikePrice, daysToExpiry, d1d2, position)
 	return nil
 }
 
@@ -129,49 +181,249 @@ func (chain *OptionChainCalculator) BlackScholesPut(assetPrice, strikePrice, day
 	if err != nil {
 		return err
 	}
-	price := strikePrice*math.Exp(-chain.RiskFreeRate*d1d2.yearsToExpiry)*normalizedCDF(-d1d2.d2) - assetPrice*normalizedCDF(-d1d2.d1)
-	position.Price = price
+	chain.blackScholesPosition(Put, strikePrice, daysToExpiry, d1d2, position)
+	return nil
+}
+
+// blackScholesPosition fills in the price, and Greeks when wanted, from d1/d2
+func (chain *OptionChainCalculator) blackScholesPosition(optionType int, strikePrice, daysToExpiry float64, d1d2 *d1d2Calculation, position *OptionPosition) {
+	discountedStrike := strikePrice * math.Exp(-d1d2.riskFreeRate*d1d2.yearsToExpiry)
+	discountedAsset := d1d2.adjustedAssetPrice * d1d2.dividendDiscount
+	if optionType == Call {
+		position.Price = discountedAsset*normalizedCDF(d1d2.d1) - discountedStrike*normalizedCDF(d1d2.d2)
+	} else {
+		position.Price = discountedStrike*normalizedCDF(-d1d2.d2) - discountedAsset*normalizedCDF(-d1d2.d1)
+	}
 	position.Strike = strikePrice
 	position.DaysToExpiry = daysToExpiry
-	return nil
+	if chain.WithGreeks {
+		chain.blackScholesGreeks(optionType, discountedAsset, discountedStrike, d1d2, position)
+	}
 }
 
-func (chain *OptionChainCalculator) ComputeOptionChain(assetPriceSpan, strikePriceSpan, daysToExpirySpan *ValueSpan) (OptionChain, error) {
-	apLow, apHigh, apStep, err := validateSpan("assetPriceRange", assetPriceSpan)
+// blackScholesPrice is the Black-Scholes price alone, to compare other models with
+func (chain *OptionChainCalculator) blackScholesPrice(assetPrice, strikePrice, daysToExpiry float64) (float64, error) {
+	d1d2, err := chain.calculateD1D2(assetPrice, strikePrice, daysToExpiry)
 	if err != nil {
-		return nil, err
+		return 0.0, err
+	}
+	discountedStrike := strikePrice * math.Exp(-d1d2.riskFreeRate*d1d2.yearsToExpiry)
+	discountedAsset := d1d2.adjustedAssetPrice * d1d2.dividendDiscount
+	if chain.optionType == Call {
+		return discountedAsset*normalizedCDF(d1d2.d1) - discountedStrike*normalizedCDF(d1d2.d2), nil
+	}
+	return discountedStrike*normalizedCDF(-d1d2.d2) - discountedAsset*normalizedCDF(-d1d2.d1), nil
+}
+
+// Black-Scholes Greeks from an already computed d1/d2.  Theta is per calendar day,
+// vega and rho are per percentage point of volatility and rate respectively.  The
+// discounted asset is the escrowed asset price discounted at the dividend yield.
+func (chain *OptionChainCalculator) blackScholesGreeks(optionType int, discountedAsset, discountedStrike float64, d1d2 *d1d2Calculation, position *OptionPosition) {
+	pdfD1 := normalizedPDF(d1d2.d1)
+	sqrtT := d1d2.volatilityAdjustment / d1d2.volatility
+	decay := -discountedAsset * pdfD1 * d1d2.volatility / (2.0 * sqrtT)
+
+	var greeks Greeks
+	greeks.Gamma = d1d2.dividendDiscount * pdfD1 / (d1d2.adjustedAssetPrice * d1d2.volatilityAdjustment)
+	greeks.Vega = discountedAsset * pdfD1 * sqrtT / 100.0
+	switch optionType {
+	case Call:
+		greeks.Delta = d1d2.dividendDiscount * normalizedCDF(d1d2.d1)
+		greeks.Theta = (decay + chain.DividendYield*discountedAsset*normalizedCDF(d1d2.d1) -
+			d1d2.riskFreeRate*discountedStrike*normalizedCDF(d1d2.d2)) / 365.0
+		greeks.Rho = discountedStrike * d1d2.yearsToExpiry * normalizedCDF(d1d2.d2) / 100.0
+	case Put:
+		greeks.Delta = d1d2.dividendDiscount * (normalizedCDF(d1d2.d1) - 1.0)
+		greeks.Theta = (decay - chain.DividendYield*discountedAsset*normalizedCDF(-d1d2.d1) +
+			d1d2.riskFreeRate*discountedStrike*normalizedCDF(-d1d2.d2)) / 365.0
+		greeks.Rho = -discountedStrike * d1d2.yearsToExpiry * normalizedCDF(-d1d2.d2) / 100.0
+	}
+	position.Greeks = greeks
+}
+
+// validatePriceAxes rejects negative asset or strike prices, except under the
+// Bachelier model where prices may go negative
+func (chain *OptionChainCalculator) validatePriceAxes(assetPriceAxis, strikePriceAxis Axis) error {
+	if chain.model == Bachelier {
+		if _, _, err := assetPriceAxis.Bounds("assetPriceRange"); err != nil {
+			return err
+		}
+		_, _, err := strikePriceAxis.Bounds("strikePriceRange")
+		return err
+	}
+	if err := validateAxisBounds("assetPriceRange", assetPriceAxis); err != nil {
+		return err
+	}
+	return validateAxisBounds("strikePriceRange", strikePriceAxis)
+}
+
+// chainAxes lists the asset prices, strike prices and days to expiry of a chain,
+// the latter from high to low
+func (chain *OptionChainCalculator) chainAxes(assetPriceAxis, strikePriceAxis, daysToExpiryAxis Axis) ([]float64, []float64, []float64, error) {
+	if err := chain.validatePriceAxes(assetPriceAxis, strikePriceAxis); err != nil {
+		return nil, nil, nil, err
 	}
 
-	spLow, spHigh, spStep, err := validateSpan("strikePriceRange", strikePriceSpan)
+	assetPrices, err := assetPriceAxis.Values("assetPriceRange")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	strikePrices, err := strikePriceAxis.Values("strikePriceRange")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	daysToExpiry, err := expiryValues("daysToExpirySpan", daysToExpiryAxis)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return assetPrices, strikePrices, daysToExpiry, nil
+}
+
+func (chain *OptionChainCalculator) ComputeOptionChain(assetPriceAxis, strikePriceAxis, daysToExpiryAxis Axis) (OptionChain, error) {
+	assetPrices, strikePrices, daysToExpiry, err := chain.chainAxes(assetPriceAxis, strikePriceAxis, daysToExpiryAxis)
 	if err != nil {
 		return nil, err
 	}
 
-	dteLow, dteHigh, dteStep, err := validateSpan("daysToExpirySpan", daysToExpirySpan)
+	result := make(OptionChain, 0, len(assetPrices))
+	err = chain.computeAssetPriceRows(context.Background(), assetPrices, strikePrices, daysToExpiry, len(assetPrices),
+		func(assetPrice float64, strikePositions [][]OptionPosition) error {
+			result = append(result, strikePositions)
+			return nil
+		})
 	if err != nil {
 		return nil, err
 	}
-	if dteLow == 0.0 {
-		dteLow += dteStep
-	}
-
-	var result OptionChain
-	for assetPrice := apLow; assetPrice <= apHigh; assetPrice += apStep {
-		var strikePositionsPerAssetPrice [][]OptionPosition
-		for strikePrice := spLow; strikePrice <= spHigh; strikePrice += spStep {
-			var positionsPerStrike []OptionPosition
-			for dte := dteHigh; dte >= dteLow; dte -= dteStep {
-				var optionPosition OptionPosition
-				err := chain.calculatePrice(assetPrice, strikePrice, dte, &optionPosition)
-				if err == nil {
-					positionsPerStrike = append(positionsPerStrike, optionPosition)
-				} else {
-					return nil, err
+	return result, nil
+}
+
+// StreamOptionChain computes the chain a few asset prices at a time, passing each
+// asset price row to emit in ascending asset price order.  Only a small window of
+// rows is held in memory.  Computation stops when ctx is cancelled or emit fails.
+func (chain *OptionChainCalculator) StreamOptionChain(
+	ctx context.Context,
+	assetPriceAxis, strikePriceAxis, daysToExpiryAxis Axis,
+	emit func(assetPrice float64, strikePositions [][]OptionPosition) error,
+) error {
+	assetPrices, strikePrices, daysToExpiry, err := chain.chainAxes(assetPriceAxis, strikePriceAxis, daysToExpiryAxis)
+	if err != nil {
+		return err
+	}
+
+	// Enough asset prices per window to keep every worker busy
+	window := 1
+	if len(strikePrices) > 0 {
+		window = (chain.workers()*streamRowsPerWorker + len(strikePrices) - 1) / len(strikePrices)
+	}
+	return chain.computeAssetPriceRows(ctx, assetPrices, strikePrices, daysToExpiry, window, emit)
+}
+
+// computeAssetPriceRows prices window asset prices at a time on the worker pool and
+// emits each finished window in order
+func (chain *OptionChainCalculator) computeAssetPriceRows(
+	ctx context.Context,
+	assetPrices, strikePrices, daysToExpiry []float64,
+	window int,
+	emit func(assetPrice float64, strikePositions [][]OptionPosition) error,
+) error {
+	lastChance, err := chain.lastChanceChain()
+	if err != nil {
+		return err
+	}
+	for first := 0; first < len(assetPrices); first += window {
+		last := min(first+window, len(assetPrices))
+		rows := make([][][]OptionPosition, last-first)
+		for i := range rows {
+			rows[i] = make([][]OptionPosition, len(strikePrices))
+		}
+		err := chain.computeRows(ctx, len(rows)*len(strikePrices), func(row int) error {
+			assetIndex, strikeIndex := row/len(strikePrices), row%len(strikePrices)
+			positionsPerStrike := make([]OptionPosition, len(daysToExpiry))
+			for i, dte := range daysToExpiry {
+				err := chain.calculatePrice(assetPrices[first+assetIndex], strikePrices[strikeIndex], dte, &positionsPerStrike[i])
+				if err != nil {
+					return err
+				}
+				if chain.exerciseStyle == American {
+					if err := chain.exerciseFlag(lastChance, assetPrices[first+assetIndex], &positionsPerStrike[i]); err != nil {
+						return err
+					}
+				}
+				if chain.model == GarmanKohlhagen {
+					chain.quoteFX(assetPrices[first+assetIndex], &positionsPerStrike[i])
+				}
+				if chain.WithBlackScholes {
+					price, err := chain.blackScholesPrice(assetPrices[first+assetIndex], strikePrices[strikeIndex], dte)
+					if err != nil {
+						return err
+					}
+					positionsPerStrike[i].BlackScholesPrice = price
 				}
+				if chain.digitalSpread > 0.0 {
+					price, err := chain.spreadReplication(assetPrices[first+assetIndex], strikePrices[strikeIndex], dte)
+					if err != nil {
+						return err
+					}
+					positionsPerStrike[i].ReplicationPrice = price
+				}
+			}
+			rows[assetIndex][strikeIndex] = positionsPerStrike
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for i, strikePositions := range rows {
+			if err := emit(assetPrices[first+i], strikePositions); err != nil {
+				return err
 			}
-			strikePositionsPerAssetPrice = append(strikePositionsPerAssetPrice, positionsPerStrike)
 		}
-		result = append(result, strikePositionsPerAssetPrice)
 	}
-	return result, nil
+	return nil
+}
+
+func (chain *OptionChainCalculator) workers() int {
+	if chain.Workers > 0 {
+		return chain.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// computeRows runs compute for rows 0..rows-1 on the chain's worker pool.  Each row
+// writes only its own slot of the result, so output order does not depend on
+// scheduling.  On failure the error of the lowest failing row is returned, as a
+// sequential run would.
+func (chain *OptionChainCalculator) computeRows(ctx context.Context, rows int, compute func(row int) error) error {
+	workers := min(chain.workers(), rows)
+
+	var next atomic.Int64
+	var failed atomic.Bool
+	errs := make([]error, rows)
+	var wait sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for !failed.Load() && ctx.Err() == nil {
+				row := int(next.Add(1) - 1)
+				if row >= rows {
+					return
+				}
+				if err := compute(row); err != nil {
+					errs[row] = err
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wait.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
 }
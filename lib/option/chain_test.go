@@ -0,0 +1,224 @@
+package option
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func computeChain(tb testing.TB, workers int, american bool, assetPrices, strikePrices, daysToExpiry Axis) OptionChain {
+	tb.Helper()
+	chain, err := NewOptionChain(Put, 0.2, 0.05, 365)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	chain.Workers = workers
+	chain.WithGreeks = true
+	if american {
+		if err := chain.SetExerciseStyle(American, LatticeCRR, 50); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	result, err := chain.ComputeOptionChain(assetPrices, strikePrices, daysToExpiry)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return result
+}
+
+// The worker pool writes each row to its own slot, so a parallel chain is
+// identical to a sequential one.  Run with -race to check the shared caches.
+func TestComputeOptionChainDeterministic(t *testing.T) {
+	assetPrices := &ValueSpan{Low: 80, High: 120, Step: 1}
+	strikePrices := &ValueSpan{Low: 80, High: 120, Step: 5}
+	daysToExpiry := &ValueSpan{Low: 5, High: 60, Step: 5}
+	for _, american := range []bool{false, true} {
+		sequential := computeChain(t, 1, american, assetPrices, strikePrices, daysToExpiry)
+		for _, workers := range []int{2, 7, 4 * runtime.NumCPU()} {
+			parallel := computeChain(t, workers, american, assetPrices, strikePrices, daysToExpiry)
+			if !reflect.DeepEqual(sequential, parallel) {
+				t.Errorf("american=%v: %d workers differ from 1", american, workers)
+			}
+		}
+	}
+}
+
+// A streamed chain emits the rows of the computed chain in asset price order, and
+// stops at the first error emit returns
+func TestStreamOptionChain(t *testing.T) {
+	assetPrices := &ValueSpan{Low: 80, High: 120, Step: 1}
+	strikePrices := &ValueSpan{Low: 90, High: 110, Step: 5}
+	daysToExpiry := ValueList{30, 90}
+	want := computeChain(t, 3, true, assetPrices, strikePrices, daysToExpiry)
+
+	chain, err := NewOptionChain(Put, 0.2, 0.05, 365)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.Workers = 3
+	chain.WithGreeks = true
+	if err := chain.SetExerciseStyle(American, LatticeCRR, 50); err != nil {
+		t.Fatal(err)
+	}
+	var got OptionChain
+	var rowPrices []float64
+	err = chain.StreamOptionChain(context.Background(), assetPrices, strikePrices, daysToExpiry,
+		func(assetPrice float64, strikePositions [][]OptionPosition) error {
+			rowPrices = append(rowPrices, assetPrice)
+			got = append(got, strikePositions)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("streamed rows differ from the computed chain")
+	}
+	if wantPrices, _ := assetPrices.Values("assetPrices"); !reflect.DeepEqual(rowPrices, wantPrices) {
+		t.Errorf("rows for %v, want %v", rowPrices, wantPrices)
+	}
+
+	stop := errors.New("stop")
+	rows := 0
+	err = chain.StreamOptionChain(context.Background(), assetPrices, strikePrices, daysToExpiry,
+		func(float64, [][]OptionPosition) error {
+			rows++
+			return stop
+		})
+	if !errors.Is(err, stop) || rows != 1 {
+		t.Errorf("got %v after %d rows, want %v after 1", err, rows, stop)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := chain.StreamOptionChain(ctx, assetPrices, strikePrices, daysToExpiry,
+		func(float64, [][]OptionPosition) error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled: got %v, want %v", err, context.Canceled)
+	}
+}
+
+// Black-Scholes prices against Hull's example: a six month option struck at 40
+// on a stock at 42, at 10% and 20% volatility
+func TestBlackScholesReference(t *testing.T) {
+	tests := []struct {
+		optionType int
+		want       float64
+	}{
+		{Call, 4.7594},
+		{Put, 0.8086},
+	}
+	for _, test := range tests {
+		position, err := PriceOption(test.optionType, 42, 40, 365.0/2, 0.2, 0.1, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(position.Price-test.want) > 5e-5 {
+			t.Errorf("type %d: got %.5f, want %.4f", test.optionType, position.Price, test.want)
+		}
+	}
+}
+
+// Closed-form Greeks against central differences of the price
+func TestBlackScholesGreeks(t *testing.T) {
+	const (
+		assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate = 105.0, 100.0, 90.0, 0.3, 0.04
+		bump                                                            = 1e-4
+	)
+	price := func(optionType int, assetPrice, daysToExpiry, volatility, riskFreeRate float64) float64 {
+		position, err := PriceOption(optionType, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return position.Price
+	}
+	for _, optionType := range []int{Call, Put} {
+		position, err := PriceOption(optionType, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		up := price(optionType, assetPrice+bump, daysToExpiry, volatility, riskFreeRate)
+		down := price(optionType, assetPrice-bump, daysToExpiry, volatility, riskFreeRate)
+		want := Greeks{
+			Delta: (up - down) / (2 * bump),
+			Gamma: (up - 2*position.Price + down) / (bump * bump),
+			Theta: (price(optionType, assetPrice, daysToExpiry-bump, volatility, riskFreeRate) -
+				price(optionType, assetPrice, daysToExpiry+bump, volatility, riskFreeRate)) / (2 * bump),
+			Vega: (price(optionType, assetPrice, daysToExpiry, volatility+bump, riskFreeRate) -
+				price(optionType, assetPrice, daysToExpiry, volatility-bump, riskFreeRate)) / (2 * bump) / 100,
+			Rho: (price(optionType, assetPrice, daysToExpiry, volatility, riskFreeRate+bump) -
+				price(optionType, assetPrice, daysToExpiry, volatility, riskFreeRate-bump)) / (2 * bump) / 100,
+		}
+		got := position.Greeks
+		for _, greek := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"delta", got.Delta, want.Delta},
+			{"gamma", got.Gamma, want.Gamma},
+			{"theta", got.Theta, want.Theta},
+			{"vega", got.Vega, want.Vega},
+			{"rho", got.Rho, want.Rho},
+		} {
+			if math.Abs(greek.got-greek.want) > 1e-4 {
+				t.Errorf("type %d %s: got %v, want %v", optionType, greek.name, greek.got, greek.want)
+			}
+		}
+	}
+}
+
+// Single prices skip the chain caches but agree with a chain exactly
+func TestPriceOptionMatchesChain(t *testing.T) {
+	for _, optionType := range []int{Call, Put} {
+		chain, err := NewOptionChain(optionType, 0.3, 0.04, 90)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chain.WithGreeks = true
+		var want OptionPosition
+		if err := chain.calculatePrice(105, 100, 90, &want); err != nil {
+			t.Fatal(err)
+		}
+		got, err := PriceOption(optionType, 105, 100, 90, 0.3, 0.04, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("type %d: got %+v, want %+v", optionType, got, want)
+		}
+	}
+}
+
+// BenchmarkPriceOption prices one option with Greeks, as each Newton step of an
+// implied volatility solve and each leg of a strategy grid point does
+func BenchmarkPriceOption(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := PriceOption(Call, 105, 100, 90, 0.3, 0.04, true); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkComputeOptionChain prices a 21 x 21 x 20 chain with Greeks on
+// increasing worker counts, to show the speedup of the worker pool
+func BenchmarkComputeOptionChain(b *testing.B) {
+	assetPrices := &ValueSpan{Low: 90, High: 110, Step: 1}
+	strikePrices := &ValueSpan{Low: 90, High: 110, Step: 1}
+	daysToExpiry := &ValueSpan{Low: 5, High: 100, Step: 5}
+	workerCounts := []int{1, 2, 4, 8}
+	if cpus := runtime.NumCPU(); cpus > 8 {
+		workerCounts = append(workerCounts, cpus)
+	}
+	for _, american := range []bool{false, true} {
+		for _, workers := range workerCounts {
+			b.Run(fmt.Sprintf("american=%v/workers=%d", american, workers), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					computeChain(b, workers, american, assetPrices, strikePrices, daysToExpiry)
+				}
+			})
+		}
+	}
+}
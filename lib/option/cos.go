@@ -0,0 +1,96 @@
+package option
+
+import (
+	"math"
+	"math/cmplx"
+	"sync"
+)
+
+const (
+	// Terms of the Fourier-cosine expansion and the width of the log price range
+	// it covers, in standard deviations (Fang and Oosterlee)
+	cosTerms      = 256
+	cosTruncation = 20.0
+)
+
+// cosExpansion holds the characteristic function terms of the Fourier-cosine
+// expansion for one expiry, which serve every asset and strike price.  The log
+// price range is centred on the mean of log(S_T/K), so only its width enters
+// the terms.
+type cosExpansion struct {
+	low              float64 // lower end of the range less log(S/K)
+	width            float64
+	terms            []complex128
+	discount         float64
+	dividendDiscount float64
+}
+
+type expansionEntry struct {
+	once      sync.Once
+	expansion *cosExpansion
+	err       error
+}
+
+// newCOSExpansion prepares the terms from the characteristic function of
+// log(S_T/S) over a range around its mean, of cosTruncation times stdDev either
+// side
+func newCOSExpansion(characteristic func(u float64) complex128, mean, stdDev, yearsToExpiry, riskFreeRate, dividendYield float64) *cosExpansion {
+	spread := cosTruncation * stdDev
+	result := cosExpansion{
+		low:              mean - spread,
+		width:            2.0 * spread,
+		terms:            make([]complex128, cosTerms),
+		discount:         math.Exp(-riskFreeRate * yearsToExpiry),
+		dividendDiscount: math.Exp(-dividendYield * yearsToExpiry),
+	}
+	for k := range result.terms {
+		u := float64(k) * math.Pi / result.width
+		term := characteristic(u) * cmplx.Exp(complex(0, -u*result.low))
+		if k == 0 {
+			term /= 2.0
+		}
+		result.terms[k] = term
+	}
+	return &result
+}
+
+// expansionAt finds or prepares the expansion under a key naming the model,
+// its parameters, the expiry and the rate, so each expiry of the chain is
+// prepared once
+func (chain *OptionChainCalculator) expansionAt(key any, prepare func() (*cosExpansion, error)) (*cosExpansion, error) {
+	cached, _ := chain.expansions.LoadOrStore(key, &expansionEntry{})
+	entry := cached.(*expansionEntry)
+	entry.once.Do(func() {
+		entry.expansion, entry.err = prepare()
+	})
+	return entry.expansion, entry.err
+}
+
+// price sums the expansion for a put, whose payoff is bounded, and takes calls
+// from put-call parity
+func (expansion *cosExpansion) price(optionType int, assetPrice, strikePrice float64) float64 {
+	a := math.Log(assetPrice/strikePrice) + expansion.low
+	b := a + expansion.width
+	// The put pays over log(S_T/K) from a to 0
+	d := math.Min(0.0, b)
+	put := 0.0
+	if d > a {
+		sum := 0.0
+		expD, expA := math.Exp(d), math.Exp(a)
+		for k, term := range expansion.terms {
+			u := float64(k) * math.Pi / expansion.width
+			sin, cos := math.Sincos(u * (d - a))
+			chi := (cos*expD - expA + u*sin*expD) / (1.0 + u*u)
+			psi := d - a
+			if k > 0 {
+				psi = sin / u
+			}
+			sum += real(term) * (psi - chi)
+		}
+		put = math.Max(expansion.discount*2.0/expansion.width*strikePrice*sum, 0.0)
+	}
+	if optionType == Put {
+		return put
+	}
+	return math.Max(put+assetPrice*expansion.dividendDiscount-strikePrice*expansion.discount, 0.0)
+}
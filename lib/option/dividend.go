@@ -0,0 +1,53 @@
+package option
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SetDividends sets a continuous dividend yield and a schedule of discrete cash
+// dividends.  Discrete dividends are handled with the escrowed dividend model:
+// the present value of dividends going ex before expiry is removed from the
+// asset price before pricing.
+func (chain *OptionChainCalculator) SetDividends(dividendYield float64, dividends []Dividend) error {
+	if dividendYield < 0.0 {
+		return fmt.Errorf("dividend yield cannot be negative")
+	}
+	for _, dividend := range dividends {
+		if dividend.DaysToExDate <= 0.0 || dividend.Amount <= 0.0 {
+			return fmt.Errorf("dividend days to ex-date and amount must be > 0, got %v/%v", dividend.DaysToExDate, dividend.Amount)
+		}
+	}
+	schedule := append([]Dividend(nil), dividends...)
+	sort.Slice(schedule, func(i, j int) bool { return schedule[i].DaysToExDate < schedule[j].DaysToExDate })
+
+	chain.DividendYield = dividendYield
+	chain.Dividends = schedule
+	// d1/d2 values cached so far were computed without these dividends
+	chain.resetCaches()
+	return chain.checkModel()
+}
+
+// dividendsAfter is the dividend schedule as it will stand days from now, without
+// the dividends that have gone ex by then
+func (chain *OptionChainCalculator) dividendsAfter(days float64) []Dividend {
+	var schedule []Dividend
+	for _, dividend := range chain.Dividends {
+		if dividend.DaysToExDate > days {
+			schedule = append(schedule, Dividend{dividend.DaysToExDate - days, dividend.Amount})
+		}
+	}
+	return schedule
+}
+
+// dividendsPresentValue is the value, as of fromDays, of the discrete dividends
+// going ex after fromDays and no later than toDays
+func (chain *OptionChainCalculator) dividendsPresentValue(fromDays, toDays float64) float64 {
+	presentValue := 0.0
+	for _, dividend := range chain.Dividends {
+		if dividend.DaysToExDate > fromDays && dividend.DaysToExDate <= toDays {
+			presentValue += dividend.Amount * chain.discountFactor(fromDays, dividend.DaysToExDate)
+		}
+	}
+	return presentValue
+}
@@ -0,0 +1,65 @@
+package option
+
+import (
+	"math"
+	"testing"
+)
+
+func dividendPrice(t *testing.T, optionType int, volatility, riskFreeRate, dividendYield float64, dividends []Dividend,
+	assetPrice, strikePrice, daysToExpiry float64) float64 {
+	t.Helper()
+	chain, err := NewOptionChain(optionType, volatility, riskFreeRate, 365)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.SetDividends(dividendYield, dividends); err != nil {
+		t.Fatal(err)
+	}
+	var position OptionPosition
+	if err := chain.calculatePrice(assetPrice, strikePrice, daysToExpiry, &position); err != nil {
+		t.Fatal(err)
+	}
+	return position.Price
+}
+
+// Haug's example of the generalized Black-Scholes formula with a 5% yield, and
+// Hull's of a call on a stock paying 0.50 in two and in five months
+func TestDividendReference(t *testing.T) {
+	tests := []struct {
+		name                                    string
+		optionType                              int
+		volatility, riskFreeRate, dividendYield float64
+		dividends                               []Dividend
+		assetPrice, strikePrice, daysToExpiry   float64
+		want, tolerance                         float64
+	}{
+		{"yield", Put, 0.35, 0.1, 0.05, nil, 75, 70, 182.5, 4.0870, 5e-5},
+		{"escrowed", Call, 0.3, 0.09, 0.0, []Dividend{{365.0 * 2 / 12, 0.5}, {365.0 * 5 / 12, 0.5}}, 40, 40, 182.5, 3.67, 5e-3},
+	}
+	for _, test := range tests {
+		got := dividendPrice(t, test.optionType, test.volatility, test.riskFreeRate, test.dividendYield, test.dividends,
+			test.assetPrice, test.strikePrice, test.daysToExpiry)
+		if math.Abs(got-test.want) > test.tolerance {
+			t.Errorf("%s: got %.4f, want %.4f", test.name, got, test.want)
+		}
+	}
+}
+
+// Calls less puts are the asset less its dividends and the strike, all at
+// present value.  A dividend going ex after expiry does not count.
+func TestDividendPutCallParity(t *testing.T) {
+	const volatility, riskFreeRate, dividendYield, assetPrice, strikePrice, daysToExpiry = 0.25, 0.05, 0.02, 100.0, 95.0, 180.0
+	dividends := []Dividend{{30, 1.5}, {120, 1.5}, {210, 1.5}}
+	call := dividendPrice(t, Call, volatility, riskFreeRate, dividendYield, dividends, assetPrice, strikePrice, daysToExpiry)
+	put := dividendPrice(t, Put, volatility, riskFreeRate, dividendYield, dividends, assetPrice, strikePrice, daysToExpiry)
+
+	escrowed := 0.0
+	for _, dividend := range dividends[:2] {
+		escrowed += dividend.Amount * math.Exp(-riskFreeRate*dividend.DaysToExDate/365)
+	}
+	years := daysToExpiry / 365
+	want := (assetPrice-escrowed)*math.Exp(-dividendYield*years) - strikePrice*math.Exp(-riskFreeRate*years)
+	if math.Abs(call-put-want) > 1e-10 {
+		t.Errorf("call - put = %.10f, want %.10f", call-put, want)
+	}
+}
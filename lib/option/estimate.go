@@ -0,0 +1,155 @@
+package option
+
+import (
+	"runtime"
+	"time"
+)
+
+// Approximate single-core pricing costs, measured on a development machine.  They
+// are meant for sizing requests, not for precise prediction.
+const (
+	blackScholesCellCost = 300 * time.Nanosecond
+	cosCellCost          = 3 * time.Microsecond // Heston and Kou, by the Fourier-cosine method
+	mertonCellCost       = 800 * time.Nanosecond
+	latticeNodeCost      = 5.5   // nanoseconds per node visited
+	gridNodeCost         = 10.0  // nanoseconds per grid node and time step
+	psorNodeCost         = 270.0 // the same with early exercise
+	// One American price by approximation, on top of the Black-Scholes price the
+	// early exercise premium is taken from
+	baroneAdesiWhaleyCost  = 250 * time.Nanosecond
+	bjerksundStenslandCost = 6 * time.Microsecond
+	// Lattice Greeks reprice the tree for theta and both bumps of vega and rho
+	latticeGreeksRepricings = 5
+	// Grid Greeks re-solve for both bumps of vega and rho
+	gridGreeksRepricings = 4
+	// Heston, jump model and approximation Greeks reprice for both bumps of the
+	// asset price, vega and rho, and for theta
+	modelGreeksRepricings = 7
+	// A point of an exercise boundary takes a price or two to step away from the
+	// strike, then about twenty to bisect down to boundaryPrecision
+	boundaryPointPrices = 24
+)
+
+// ChainSize returns the number of asset prices, strike prices and days to expiry
+// ComputeOptionChain would produce for the axes, without computing anything
+func (chain *OptionChainCalculator) ChainSize(assetPriceAxis, strikePriceAxis, daysToExpiryAxis Axis) (int, int, int, error) {
+	if err := chain.validatePriceAxes(assetPriceAxis, strikePriceAxis); err != nil {
+		return 0, 0, 0, err
+	}
+	assetPrices, err := assetPriceAxis.Count("assetPriceRange")
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	strikePrices, err := strikePriceAxis.Count("strikePriceRange")
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	daysToExpiry, err := expiryCount("daysToExpirySpan", daysToExpiryAxis)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return assetPrices, strikePrices, daysToExpiry, nil
+}
+
+// EstimateComputeTime projects the wall time to price a chain of the given size
+// with the chain's current settings on its worker pool.  Finite-difference grids
+// are solved once per strike and expiry rather than per cell.
+func (chain *OptionChainCalculator) EstimateComputeTime(assetPrices, strikePrices, daysToExpiry int) time.Duration {
+	cells := assetPrices * strikePrices * daysToExpiry
+	cellCost := float64(blackScholesCellCost)
+	solveCost := 0.0
+	if chain.model == Heston || chain.model == Merton || chain.model == Kou {
+		cellCost = float64(cosCellCost)
+		if chain.model == Merton {
+			cellCost = float64(mertonCellCost)
+		}
+		if chain.WithGreeks {
+			cellCost *= 1.0 + modelGreeksRepricings
+		}
+	} else if chain.barrier != nil || chain.exerciseStyle == American && chain.latticeModel == CrankNicolson {
+		points := chain.gridPoints
+		if points == 0 {
+			points = DefaultGridPoints
+		}
+		solves := 1.0
+		if chain.WithGreeks {
+			solves += gridGreeksRepricings
+		}
+		if chain.dividendImminent() {
+			// The last chance before the dividend goes ex is solved on its own grid
+			solves++
+		}
+		nodeCost := gridNodeCost
+		switch {
+		case chain.exerciseStyle == American && chain.barrier != nil:
+			// with the European barrier price the early exercise premium is taken from
+			nodeCost = psorNodeCost + gridNodeCost/solves
+		case chain.exerciseStyle == American:
+			nodeCost = psorNodeCost
+		case !chain.barrier.knockOut():
+			// Knock-ins take the vanilla, knock-out and rebate solves
+			solves *= 3
+		}
+		solveCost = solves * nodeCost * float64(points+1) * float64(chain.gridSteps())
+	} else if chain.exerciseStyle == American && chain.approximates() {
+		// Critical prices are solved once per strike and expiry and cost little
+		repricing := float64(baroneAdesiWhaleyCost)
+		if chain.latticeModel == BjerksundStensland {
+			repricing = float64(bjerksundStenslandCost)
+		}
+		if chain.WithGreeks {
+			repricing *= 1.0 + modelGreeksRepricings
+		}
+		cellCost += repricing
+	} else if chain.exerciseStyle == American {
+		steps := float64(chain.latticeSteps)
+		lattices := 1.0
+		if chain.WithGreeks {
+			lattices += latticeGreeksRepricings
+		}
+		if chain.dividendImminent() {
+			lattices++
+		}
+		cellCost += lattices * latticeNodeCost * steps * (steps + 1.0) / 2.0
+	}
+	if chain.WithBlackScholes {
+		cellCost += float64(blackScholesCellCost)
+	}
+	if chain.digitalSpread > 0.0 {
+		// The spread's two strikes, and the vanilla option for asset-or-nothing digitals
+		cellCost += 3 * float64(blackScholesCellCost)
+	}
+	workers := min(chain.workers(), runtime.NumCPU())
+	total := cellCost*float64(cells) + solveCost*float64(strikePrices*daysToExpiry)
+	return time.Duration(total / float64(max(workers, 1)))
+}
+
+// EstimatePricingTime projects the wall time of pricing single options one after
+// another with PriceOption, as strategy grids do
+func EstimatePricingTime(prices int) time.Duration {
+	return time.Duration(prices) * blackScholesCellCost
+}
+
+// EstimateBoundaryTime projects the wall time of ExerciseBoundary over the days
+// to expiry axis, each point of which is found by pricing the option without
+// Greeks some boundaryPointPrices times
+func (chain *OptionChainCalculator) EstimateBoundaryTime(daysToExpiryAxis Axis) (time.Duration, error) {
+	points, err := expiryCount("daysToExpirySpan", daysToExpiryAxis)
+	if err != nil {
+		return 0, err
+	}
+	_, expiry, err := expiryAxis(daysToExpiryAxis).Bounds("daysToExpirySpan")
+	if err != nil {
+		return 0, err
+	}
+	for _, dividend := range chain.Dividends {
+		if dividend.DaysToExDate < expiry {
+			points++
+		}
+	}
+	search := *chain
+	search.WithGreeks = false
+	search.WithBlackScholes = false
+	search.digitalSpread = 0.0
+	return boundaryPointPrices * search.EstimateComputeTime(1, 1, points), nil
+}
@@ -0,0 +1,241 @@
+package option
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// Exercise is taken to beat holding when the option is worth no more than its
+	// intrinsic value plus this fraction of the strike, which absorbs rounding
+	exerciseTolerance = 1e-9
+	// The critical price is found to this fraction of the strike
+	boundaryPrecision = 1e-6
+	// The critical price is searched for up to this multiple of the strike for
+	// calls and down to the strike over it for puts
+	boundarySearchLimit = 64.0
+	// A dividend going ex within this many days can only be collected by
+	// exercising today
+	exDividendWindow = 1.0
+	// Days before the moment an exercise decision is made that a dividend due to
+	// go ex then is placed, inside the first time step of any lattice or grid
+	exDividendLead = 1e-6
+)
+
+// ExercisePoint is the critical asset price of an American option with
+// DaysToExpiry to go, at or below which a put and at or above which a call is
+// best exercised
+type ExercisePoint struct {
+	DaysToExpiry  float64
+	CriticalPrice float64
+	// Early exercise is not optimal at any asset price
+	Never bool
+	// The last moment to exercise before a discrete dividend goes ex
+	ExDividend bool
+}
+
+// ExerciseBoundary traces the early exercise boundary of the American option on
+// strikePrice that expires at the end of the days to expiry axis, finding the
+// critical asset price on each day on the axis that many days before its expiry.
+// Each point takes the discrete dividends that have yet to go ex by then, and the
+// moment before each dividend goes ex gets a point of its own, when calls may be
+// worth exercising to collect the dividend.  Volatilities and rates are read at
+// the days to go, from today's surface and curve.
+func (chain *OptionChainCalculator) ExerciseBoundary(strikePrice float64, daysToExpiryAxis Axis) ([]ExercisePoint, error) {
+	if chain.exerciseStyle != American {
+		return nil, fmt.Errorf("the exercise boundary is for American options")
+	}
+	if chain.barrier != nil {
+		return nil, fmt.Errorf("the exercise boundary is for options without barriers")
+	}
+	if strikePrice <= 0.0 {
+		return nil, fmt.Errorf("strike price must be > 0, got %v", strikePrice)
+	}
+	daysToExpiry, err := expiryValues("daysToExpirySpan", daysToExpiryAxis)
+	if err != nil {
+		return nil, err
+	}
+	expiry := daysToExpiry[0]
+
+	points := make([]ExercisePoint, 0, len(daysToExpiry)+len(chain.Dividends))
+	for _, days := range daysToExpiry {
+		points = append(points, ExercisePoint{DaysToExpiry: days})
+	}
+	for _, dividend := range chain.Dividends {
+		if dividend.DaysToExDate < expiry {
+			points = append(points, ExercisePoint{DaysToExpiry: expiry - dividend.DaysToExDate, ExDividend: true})
+		}
+	}
+	// From today to expiry, with the moment before a dividend goes ex ahead of the
+	// moment it has
+	sort.SliceStable(points, func(i, j int) bool {
+		if points[i].DaysToExpiry != points[j].DaysToExpiry {
+			return points[i].DaysToExpiry > points[j].DaysToExpiry
+		}
+		return points[i].ExDividend && !points[j].ExDividend
+	})
+
+	err = chain.computeRows(context.Background(), len(points), func(row int) error {
+		return chain.exerciseBoundaryAt(strikePrice, expiry, &points[row])
+	})
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// exerciseBoundaryAt finds the critical price of one point of the boundary by
+// bisection between an asset price where the option is exercised and one where
+// it is held
+func (chain *OptionChainCalculator) exerciseBoundaryAt(strikePrice, expiry float64, point *ExercisePoint) error {
+	elapsed := expiry - point.DaysToExpiry
+	var dividends []Dividend
+	for _, dividend := range chain.Dividends {
+		switch {
+		case point.ExDividend && math.Abs(dividend.DaysToExDate-elapsed) < axisTolerance:
+			dividends = append(dividends, Dividend{exDividendLead, dividend.Amount})
+		case dividend.DaysToExDate > elapsed:
+			dividends = append(dividends, Dividend{dividend.DaysToExDate - elapsed, dividend.Amount})
+		}
+	}
+	search, err := chain.exerciseChain(dividends)
+	if err != nil {
+		return err
+	}
+	exercised := func(assetPrice float64) (bool, error) {
+		var position OptionPosition
+		if err := search.calculatePrice(assetPrice, strikePrice, point.DaysToExpiry, &position); err != nil {
+			return false, err
+		}
+		return search.exercisesNow(assetPrice, strikePrice, point.DaysToExpiry, position.Price)
+	}
+
+	// Puts are exercised below the boundary and calls above it, and neither at
+	// the strike, so step away from the strike until the option is exercised
+	escrowed := search.dividendsPresentValue(0.0, point.DaysToExpiry)
+	held, exercise := strikePrice, strikePrice
+	for {
+		if chain.optionType == Put {
+			exercise /= 2.0
+		} else {
+			exercise *= 2.0
+		}
+		// A put's asset price also has to cover the dividends still to go ex
+		if exercise > boundarySearchLimit*strikePrice || exercise < strikePrice/boundarySearchLimit || exercise <= escrowed {
+			point.Never = true
+			return nil
+		}
+		found, err := exercised(exercise)
+		if err != nil {
+			return err
+		}
+		if found {
+			break
+		}
+		held = exercise
+	}
+	for math.Abs(exercise-held) > strikePrice*boundaryPrecision {
+		middle := (exercise + held) / 2.0
+		found, err := exercised(middle)
+		if err != nil {
+			return err
+		}
+		if found {
+			exercise = middle
+		} else {
+			held = middle
+		}
+	}
+	point.CriticalPrice = exercise
+	return nil
+}
+
+// exerciseChain copies the chain with its own dividend schedule, to price
+// exercise decisions without Greeks or comparisons
+func (chain *OptionChainCalculator) exerciseChain(dividends []Dividend) (*OptionChainCalculator, error) {
+	search := *chain
+	search.WithGreeks = false
+	search.WithBlackScholes = false
+	search.digitalSpread = 0.0
+	if err := search.SetDividends(chain.DividendYield, dividends); err != nil {
+		return nil, err
+	}
+	// Bind the pricers to the copy
+	if err := search.SetModel(chain.model); err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+// lastChanceChain prices the chain's options as if each discrete dividend going
+// ex within exDividendWindow days went ex straight away, since a holder who
+// wants it has to exercise today.  It is nil when there is no such dividend.
+func (chain *OptionChainCalculator) lastChanceChain() (*OptionChainCalculator, error) {
+	if !chain.dividendImminent() {
+		return nil, nil
+	}
+	dividends := append([]Dividend(nil), chain.Dividends...)
+	for i := range dividends {
+		if dividends[i].DaysToExDate <= exDividendWindow {
+			dividends[i].DaysToExDate = exDividendLead
+		}
+	}
+	return chain.exerciseChain(dividends)
+}
+
+// dividendImminent reports whether an American option's discrete dividend goes
+// ex within exDividendWindow days
+func (chain *OptionChainCalculator) dividendImminent() bool {
+	return chain.exerciseStyle == American && len(chain.Dividends) > 0 && chain.Dividends[0].DaysToExDate <= exDividendWindow
+}
+
+// exercisesNow reports whether exercising an option worth price is worth at
+// least holding it
+func (chain *OptionChainCalculator) exercisesNow(assetPrice, strikePrice, daysToExpiry, price float64) (bool, error) {
+	intrinsic := intrinsicValue(chain.optionType, assetPrice, strikePrice)
+	if intrinsic <= 0.0 {
+		return false, nil
+	}
+	slack, err := chain.exerciseSlack(assetPrice, strikePrice, daysToExpiry)
+	if err != nil {
+		return false, err
+	}
+	return price <= intrinsic+slack, nil
+}
+
+// exerciseSlack is how far above its intrinsic value an exercised option may be
+// priced: rounding on a lattice, and on the grid the error of reading the payoff
+// between its nodes, which grows with the asset price and the cube of the spacing
+// of the grid it is read from
+func (chain *OptionChainCalculator) exerciseSlack(assetPrice, strikePrice, daysToExpiry float64) (float64, error) {
+	if chain.barrier == nil && chain.latticeModel != CrankNicolson {
+		return exerciseTolerance * strikePrice, nil
+	}
+	volatility, err := chain.VolatilityAt(assetPrice, strikePrice, daysToExpiry)
+	if err != nil {
+		return 0.0, err
+	}
+	// Grids are widened to take in asset prices far from the strike
+	treeAssetPrice := math.Max(assetPrice-chain.dividendsPresentValue(0.0, daysToExpiry), assetPrice/boundarySearchLimit)
+	low, high := chain.gridBounds(math.Log(treeAssetPrice), strikePrice, daysToExpiry/365, volatility, chain.barrier != nil)
+	spacing := (high - low) / float64(chain.gridIntervals())
+	return math.Max(exerciseTolerance*strikePrice, spacing*spacing*spacing*math.Max(assetPrice, strikePrice)), nil
+}
+
+// exerciseFlag sets ExerciseNow on an American position, repricing it on the
+// last chance chain when a dividend is about to go ex
+func (chain *OptionChainCalculator) exerciseFlag(lastChance *OptionChainCalculator, assetPrice float64, position *OptionPosition) error {
+	held := position.Price
+	if lastChance != nil {
+		var exDividend OptionPosition
+		if err := lastChance.calculatePrice(assetPrice, position.Strike, position.DaysToExpiry, &exDividend); err != nil {
+			return err
+		}
+		held = exDividend.Price
+	}
+	exercise, err := chain.exercisesNow(assetPrice, position.Strike, position.DaysToExpiry, held)
+	position.ExerciseNow = exercise
+	return err
+}
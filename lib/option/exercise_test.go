@@ -0,0 +1,102 @@
+package option
+
+import (
+	"math"
+	"testing"
+)
+
+// A put's critical price rises towards the strike as expiry nears and a call's
+// on an asset with a yield falls towards it.  The grid finds them to about the
+// spacing of its nodes.
+func TestExerciseBoundaryShape(t *testing.T) {
+	axis := &ValueSpan{Low: 30, High: 360, Step: 30}
+	tests := []struct {
+		name          string
+		optionType    int
+		dividendYield float64
+	}{
+		{"put", Put, 0.0},
+		{"call", Call, 0.04},
+	}
+	for _, test := range tests {
+		lattice, err := testChain(t, test.optionType, 0.3, 0.08, withAmerican(LatticeCRR, 300), withDividends(test.dividendYield, nil)).ExerciseBoundary(100, axis)
+		if err != nil {
+			t.Fatal(err)
+		}
+		grid, err := testChain(t, test.optionType, 0.3, 0.08, withAmerican(CrankNicolson, 300), withDividends(test.dividendYield, nil)).ExerciseBoundary(100, axis)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lattice) != 12 || len(grid) != 12 {
+			t.Fatalf("%s: got %d and %d points, want 12", test.name, len(lattice), len(grid))
+		}
+		for i, point := range lattice {
+			if point.Never || (test.optionType == Put) != (point.CriticalPrice < 100) {
+				t.Errorf("%s: %+v is on the wrong side of the strike", test.name, point)
+			}
+			if i > 0 && (test.optionType == Put) != (point.CriticalPrice > lattice[i-1].CriticalPrice) {
+				t.Errorf("%s: %v days to go at %v, %v at %v", test.name,
+					point.DaysToExpiry, point.CriticalPrice, lattice[i-1].DaysToExpiry, lattice[i-1].CriticalPrice)
+			}
+			spacing := 2.0 * gridDeviations * 0.3 * math.Sqrt(point.DaysToExpiry/365) / DefaultGridPoints
+			if math.Abs(math.Log(grid[i].CriticalPrice/point.CriticalPrice)) > spacing {
+				t.Errorf("%s: %v days to go at %v on the grid, %v on the lattice", test.name,
+					point.DaysToExpiry, grid[i].CriticalPrice, point.CriticalPrice)
+			}
+		}
+	}
+}
+
+// Without dividends a call is never exercised early, and with discrete ones only
+// just before one goes ex, when the dividend is worth more than the interest on
+// the strike until the next chance
+func TestExerciseBoundaryDividends(t *testing.T) {
+	axis := &ValueSpan{Low: 30, High: 360, Step: 30}
+	points, err := testChain(t, Call, 0.3, 0.08, withAmerican(LatticeCRR, 300)).ExerciseBoundary(100, axis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, point := range points {
+		if !point.Never {
+			t.Errorf("without dividends: %+v, want never", point)
+		}
+	}
+
+	dividends := []Dividend{{100, 3}, {250, 3}}
+	for _, latticeModel := range []int{LatticeCRR, CrankNicolson} {
+		points, err := testChain(t, Call, 0.3, 0.08, withAmerican(latticeModel, 300), withDividends(0.0, dividends)).ExerciseBoundary(100, axis)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(points) != 14 {
+			t.Fatalf("model %d: got %d points, want 14", latticeModel, len(points))
+		}
+		for _, point := range points {
+			// Only the last dividend beats the interest on the strike to expiry
+			exercised := point.ExDividend && point.DaysToExpiry == 110
+			if point.Never == exercised {
+				t.Errorf("model %d: %+v", latticeModel, point)
+			}
+			if exercised && (point.CriticalPrice < 120 || point.CriticalPrice > 135) {
+				t.Errorf("model %d: %v days to go at %v, want about 127", latticeModel, point.DaysToExpiry, point.CriticalPrice)
+			}
+		}
+	}
+}
+
+// A call is flagged for exercise the day before a dividend worth more than the
+// time value it gives up
+func TestExerciseNowBeforeDividend(t *testing.T) {
+	chain := testChain(t, Call, 0.3, 0.08, withAmerican(LatticeCRR, 200), withDividends(0.0, []Dividend{{1, 3}}))
+	optionChain, err := chain.ComputeOptionChain(ValueList{100, 120, 140}, ValueList{100}, ValueList{10, 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, row := range optionChain {
+		for _, position := range row[0] {
+			if want := i > 0; position.ExerciseNow != want {
+				t.Errorf("S=%v, %v days: exercise now %v, want %v", 100+20*i, position.DaysToExpiry, position.ExerciseNow, want)
+			}
+		}
+	}
+}
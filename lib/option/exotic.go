@@ -0,0 +1,272 @@
+package option
+
+import (
+	"fmt"
+	"math"
+)
+
+// Path-dependent options priced by Monte Carlo
+const (
+	AsianOption = iota
+	BarrierOption
+	LookbackOption
+)
+
+// Averages of Asian options, taken over the asset prices at each step after today
+const (
+	ArithmeticAverage = iota
+	GeometricAverage
+)
+
+// Barrier types.  An out option dies when the asset price reaches the barrier and
+// an in option only comes alive then.
+const (
+	UpAndOut = iota
+	DownAndOut
+	UpAndIn
+	DownAndIn
+)
+
+// Lookback strikes: the best asset price over the life of the option, or a fixed
+// strike paid against the best price
+const (
+	FloatingStrike = iota
+	FixedStrike
+)
+
+// Shift of a discretely monitored barrier that matches continuous monitoring,
+// in standard deviations of one step (Broadie, Glasserman and Kou)
+const barrierCorrection = 0.5826
+
+// ExoticOption describes a path-dependent option.  Prices are observed at each
+// step of the simulation, which sets the Asian fixings and the barrier and
+// lookback monitoring.
+type ExoticOption struct {
+	Kind         int
+	OptionType   int
+	Strike       float64
+	DaysToExpiry float64
+	// Asian options
+	Average int
+	// Barrier options: the rebate is paid when an out option knocks out, or at
+	// expiry when an in option never knocks in
+	BarrierType          int
+	Barrier              float64
+	Rebate               float64
+	ContinuousMonitoring bool
+	// Lookback options
+	LookbackStrike int
+}
+
+// exoticModel holds what pricing one option needs besides its path
+type exoticModel struct {
+	option        *ExoticOption
+	barrier       float64
+	discounts     []float64
+	controlMean   float64
+	control       func(path []float64) float64
+	assetPrice    float64
+	volatility    float64
+	riskFreeRate  float64
+	dividendYield float64
+}
+
+func (exotic *ExoticOption) validate(assetPrice, volatility float64, steps int) error {
+	if exotic.OptionType != Call && exotic.OptionType != Put {
+		return fmt.Errorf("unrecognized optionType %d", exotic.OptionType)
+	}
+	if assetPrice <= 0.0 || volatility <= 0.0 || exotic.DaysToExpiry <= 0.0 {
+		return fmt.Errorf("asset price, volatility and days to expiry must be > 0, got %v/%v/%v", assetPrice, volatility, exotic.DaysToExpiry)
+	}
+	if steps < 1 {
+		return fmt.Errorf("steps must be > 0, got %d", steps)
+	}
+	strikeNeeded := true
+	switch exotic.Kind {
+	case AsianOption:
+		if exotic.Average != ArithmeticAverage && exotic.Average != GeometricAverage {
+			return fmt.Errorf("unrecognized average %d", exotic.Average)
+		}
+	case BarrierOption:
+		if exotic.BarrierType < UpAndOut || exotic.BarrierType > DownAndIn {
+			return fmt.Errorf("unrecognized barrier type %d", exotic.BarrierType)
+		}
+		if exotic.Barrier <= 0.0 || exotic.Rebate < 0.0 {
+			return fmt.Errorf("barrier must be > 0 and rebate >= 0, got %v/%v", exotic.Barrier, exotic.Rebate)
+		}
+		if (exotic.up() && assetPrice >= exotic.Barrier) || (!exotic.up() && assetPrice <= exotic.Barrier) {
+			return fmt.Errorf("asset price %v is already through the barrier at %v", assetPrice, exotic.Barrier)
+		}
+	case LookbackOption:
+		switch exotic.LookbackStrike {
+		case FloatingStrike:
+			strikeNeeded = false
+		case FixedStrike:
+		default:
+			return fmt.Errorf("unrecognized lookback strike %d", exotic.LookbackStrike)
+		}
+	default:
+		return fmt.Errorf("unrecognized exotic option kind %d", exotic.Kind)
+	}
+	if strikeNeeded && exotic.Strike <= 0.0 {
+		return fmt.Errorf("strike price must be > 0, got %v", exotic.Strike)
+	}
+	return nil
+}
+
+func (exotic *ExoticOption) up() bool {
+	return exotic.BarrierType == UpAndOut || exotic.BarrierType == UpAndIn
+}
+
+func (exotic *ExoticOption) knockOut() bool {
+	return exotic.BarrierType == UpAndOut || exotic.BarrierType == DownAndOut
+}
+
+// vanillaPayoff is what a European option on the exotic's strike pays at a final price
+func (exotic *ExoticOption) vanillaPayoff(price float64) float64 {
+	return intrinsicValue(exotic.OptionType, price, exotic.Strike)
+}
+
+// newExoticModel prepares the discount factors of each step, the barrier with
+// any continuity correction and the control variate: the geometric average
+// option for arithmetic Asians, the asset itself for floating strike lookbacks,
+// and a European option on the same strike for the rest
+func newExoticModel(exotic *ExoticOption, assetPrice, volatility, riskFreeRate, dividendYield float64, steps int) (*exoticModel, error) {
+	if err := exotic.validate(assetPrice, volatility, steps); err != nil {
+		return nil, err
+	}
+	yearsToExpiry := exotic.DaysToExpiry / 365
+	dt := yearsToExpiry / float64(steps)
+	model := exoticModel{
+		option:        exotic,
+		barrier:       exotic.Barrier,
+		discounts:     make([]float64, steps+1),
+		assetPrice:    assetPrice,
+		volatility:    volatility,
+		riskFreeRate:  riskFreeRate,
+		dividendYield: dividendYield,
+	}
+	for step := range model.discounts {
+		model.discounts[step] = math.Exp(-riskFreeRate * dt * float64(step))
+	}
+	if exotic.Kind == BarrierOption && exotic.ContinuousMonitoring {
+		// Discrete monitoring misses crossings between steps, so the barrier moves
+		// towards the asset price to make up for them
+		shift := math.Exp(barrierCorrection * volatility * math.Sqrt(dt))
+		if exotic.up() {
+			model.barrier /= shift
+		} else {
+			model.barrier *= shift
+		}
+	}
+
+	finalDiscount := model.discounts[steps]
+	switch {
+	case exotic.Kind == AsianOption && exotic.Average == ArithmeticAverage:
+		geometric := *exotic
+		geometric.Average = GeometricAverage
+		model.controlMean = GeometricAsianPrice(exotic.OptionType, assetPrice, exotic.Strike, exotic.DaysToExpiry, volatility, riskFreeRate, dividendYield, steps)
+		model.control = func(path []float64) float64 {
+			return geometric.vanillaPayoff(average(GeometricAverage, path[1:])) * finalDiscount
+		}
+	case exotic.Kind == LookbackOption && exotic.LookbackStrike == FloatingStrike:
+		model.controlMean = assetPrice * math.Exp(-dividendYield*yearsToExpiry)
+		model.control = func(path []float64) float64 {
+			return path[len(path)-1] * finalDiscount
+		}
+	default:
+		chain, err := NewOptionChain(exotic.OptionType, volatility, riskFreeRate, exotic.DaysToExpiry)
+		if err != nil {
+			return nil, err
+		}
+		if err := chain.SetDividends(dividendYield, nil); err != nil {
+			return nil, err
+		}
+		var european OptionPosition
+		if err := chain.calculatePrice(assetPrice, exotic.Strike, exotic.DaysToExpiry, &european); err != nil {
+			return nil, err
+		}
+		model.controlMean = european.Price
+		model.control = func(path []float64) float64 {
+			return exotic.vanillaPayoff(path[len(path)-1]) * finalDiscount
+		}
+	}
+	return &model, nil
+}
+
+// average of the prices, arithmetic or geometric
+func average(kind int, prices []float64) float64 {
+	sum := 0.0
+	if kind == GeometricAverage {
+		for _, price := range prices {
+			sum += math.Log(price)
+		}
+		return math.Exp(sum / float64(len(prices)))
+	}
+	for _, price := range prices {
+		sum += price
+	}
+	return sum / float64(len(prices))
+}
+
+// presentValue is the discounted payoff of one path of asset prices, today's first
+func (model *exoticModel) presentValue(path []float64) float64 {
+	exotic := model.option
+	steps := len(path) - 1
+	switch exotic.Kind {
+	case AsianOption:
+		return exotic.vanillaPayoff(average(exotic.Average, path[1:])) * model.discounts[steps]
+	case BarrierOption:
+		up := exotic.up()
+		for step := 1; step <= steps; step++ {
+			if (up && path[step] >= model.barrier) || (!up && path[step] <= model.barrier) {
+				if exotic.knockOut() {
+					return exotic.Rebate * model.discounts[step]
+				}
+				return exotic.vanillaPayoff(path[steps]) * model.discounts[steps]
+			}
+		}
+		if exotic.knockOut() {
+			return exotic.vanillaPayoff(path[steps]) * model.discounts[steps]
+		}
+		return exotic.Rebate * model.discounts[steps]
+	case LookbackOption:
+		low, high := path[0], path[0]
+		for _, price := range path[1:] {
+			low, high = math.Min(low, price), math.Max(high, price)
+		}
+		final := path[steps]
+		var payoff float64
+		switch {
+		case exotic.LookbackStrike == FloatingStrike && exotic.OptionType == Call:
+			payoff = final - low
+		case exotic.LookbackStrike == FloatingStrike:
+			payoff = high - final
+		case exotic.OptionType == Call:
+			payoff = math.Max(high-exotic.Strike, 0.0)
+		default:
+			payoff = math.Max(exotic.Strike-low, 0.0)
+		}
+		return payoff * model.discounts[steps]
+	}
+	return 0.0
+}
+
+// GeometricAsianPrice prices an option on the geometric average of the asset price
+// at fixings evenly spaced over the life of the option, the last at expiry.  The
+// average is lognormal, so Black-Scholes applies to it (Kemna and Vorst).
+func GeometricAsianPrice(optionType int, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, dividendYield float64, fixings int) float64 {
+	yearsToExpiry := daysToExpiry / 365
+	dt := yearsToExpiry / float64(fixings)
+	n := float64(fixings)
+	mean := math.Log(assetPrice) + (riskFreeRate-dividendYield-volatility*volatility/2.0)*dt*(n+1.0)/2.0
+	deviation := volatility * math.Sqrt(dt*(n+1.0)*(2.0*n+1.0)/(6.0*n))
+	d1 := (mean - math.Log(strikePrice) + deviation*deviation) / deviation
+	d2 := d1 - deviation
+	forward := math.Exp(mean + deviation*deviation/2.0)
+	discount := math.Exp(-riskFreeRate * yearsToExpiry)
+	if optionType == Call {
+		return discount * (forward*normalizedCDF(d1) - strikePrice*normalizedCDF(d2))
+	}
+	return discount * (strikePrice*normalizedCDF(-d2) - forward*normalizedCDF(-d1))
+}
@@ -0,0 +1,305 @@
+package option
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// Delta conventions for currency options.  Spot delta is the hedge in the spot
+// market per unit of foreign notional and forward delta the hedge in a forward
+// contract.  Premium-adjusted deltas net off the premium, for pairs whose premium
+// is paid in the foreign currency.
+const (
+	SpotDelta = iota
+	ForwardDelta
+	PremiumAdjustedSpotDelta
+	PremiumAdjustedForwardDelta
+)
+
+// Premium conventions for currency options
+const (
+	// Domestic currency per unit of foreign notional, as Garman-Kohlhagen prices
+	DomesticPips = iota
+	// Percent of the foreign notional
+	ForeignPercent
+	// Foreign currency per unit of domestic notional
+	ForeignPips
+	// Percent of the domestic notional
+	DomesticPercent
+)
+
+// At-the-money conventions for currency smiles
+const (
+	// The strike is the forward rate
+	AtmForward = iota
+	// The strike at which a straddle has zero delta
+	AtmDeltaNeutral
+)
+
+// Halvings of the strike tried when bracketing a premium-adjusted put strike
+const fxBracketSteps = 100
+
+// SetForeignRate sets the interest rate of the foreign currency for the
+// Garman-Kohlhagen model, which the currency earns as a stock earns a continuous
+// dividend yield.  Unlike a dividend yield it may be negative.
+func (chain *OptionChainCalculator) SetForeignRate(foreignRate float64) {
+	chain.DividendYield = foreignRate
+	// d1/d2 values cached so far were computed at another foreign rate
+	chain.resetCaches()
+}
+
+// SetFXConventions selects how a Garman-Kohlhagen chain quotes deltas and
+// premiums.  Other Greeks stay in domestic currency per unit of foreign notional.
+func (chain *OptionChainCalculator) SetFXConventions(deltaConvention, premiumConvention int) error {
+	if deltaConvention < SpotDelta || deltaConvention > PremiumAdjustedForwardDelta {
+		return fmt.Errorf("unrecognized delta convention %d", deltaConvention)
+	}
+	if premiumConvention < DomesticPips || premiumConvention > DomesticPercent {
+		return fmt.Errorf("unrecognized premium convention %d", premiumConvention)
+	}
+	chain.deltaConvention = deltaConvention
+	chain.premiumConvention = premiumConvention
+	return chain.checkModel()
+}
+
+// quoteFX restates a position priced in domestic pips in the chain's delta and
+// premium conventions
+func (chain *OptionChainCalculator) quoteFX(assetPrice float64, position *OptionPosition) {
+	if chain.WithGreeks {
+		foreignDiscount := math.Exp(-chain.DividendYield * position.DaysToExpiry / 365)
+		switch chain.deltaConvention {
+		case ForwardDelta:
+			position.Greeks.Delta /= foreignDiscount
+		case PremiumAdjustedSpotDelta:
+			position.Greeks.Delta -= position.Price / assetPrice
+		case PremiumAdjustedForwardDelta:
+			position.Greeks.Delta = (position.Greeks.Delta - position.Price/assetPrice) / foreignDiscount
+		}
+	}
+	scale := 1.0
+	switch chain.premiumConvention {
+	case ForeignPercent:
+		scale = 100.0 / assetPrice
+	case ForeignPips:
+		scale = 1.0 / (assetPrice * position.Strike)
+	case DomesticPercent:
+		scale = 100.0 / position.Strike
+	}
+	position.Price *= scale
+	position.EarlyExercisePremium *= scale
+}
+
+// FXForward is the forward rate for daysToExpiry by covered interest parity
+func FXForward(spot, daysToExpiry, domesticRate, foreignRate float64) float64 {
+	return spot * math.Exp((domesticRate-foreignRate)*daysToExpiry/365)
+}
+
+// fxTerms are the forward, the standard deviation of the log rate and the
+// foreign discount factor of an expiry
+func fxTerms(spot, daysToExpiry, domesticRate, foreignRate, volatility float64) (float64, float64, float64, error) {
+	if spot <= 0.0 || daysToExpiry <= 0.0 || volatility <= 0.0 {
+		return 0.0, 0.0, 0.0, fmt.Errorf("spot, days to expiry and volatility must be > 0, got %v/%v/%v", spot, daysToExpiry, volatility)
+	}
+	yearsToExpiry := daysToExpiry / 365
+	return FXForward(spot, daysToExpiry, domesticRate, foreignRate), volatility * math.Sqrt(yearsToExpiry), math.Exp(-foreignRate * yearsToExpiry), nil
+}
+
+// FXDelta is the delta of a Garman-Kohlhagen option in a delta convention, signed
+// negative for puts
+func FXDelta(optionType, deltaConvention int, spot, strikePrice, daysToExpiry, domesticRate, foreignRate, volatility float64) (float64, error) {
+	forward, stdDev, foreignDiscount, err := fxTerms(spot, daysToExpiry, domesticRate, foreignRate, volatility)
+	if err != nil {
+		return 0.0, err
+	}
+	if strikePrice <= 0.0 {
+		return 0.0, fmt.Errorf("strike price must be > 0, got %v", strikePrice)
+	}
+	sign := 1.0
+	if optionType == Put {
+		sign = -1.0
+	}
+	d1 := (math.Log(forward/strikePrice) + stdDev*stdDev/2.0) / stdDev
+	d2 := d1 - stdDev
+	switch deltaConvention {
+	case SpotDelta:
+		return sign * foreignDiscount * normalizedCDF(sign*d1), nil
+	case ForwardDelta:
+		return sign * normalizedCDF(sign*d1), nil
+	case PremiumAdjustedSpotDelta:
+		return sign * foreignDiscount * strikePrice / forward * normalizedCDF(sign*d2), nil
+	case PremiumAdjustedForwardDelta:
+		return sign * strikePrice / forward * normalizedCDF(sign*d2), nil
+	}
+	return 0.0, fmt.Errorf("unrecognized delta convention %d", deltaConvention)
+}
+
+// FXStrikeFromDelta finds the strike of a Garman-Kohlhagen option with an
+// unsigned delta in a delta convention, so 0.25 is the 25 delta call or put.
+// Without premium adjustment the strike follows from d1 directly.  With it the
+// call delta rises and falls again with the strike, and the strike above the
+// delta's peak is taken, as the market does.
+func FXStrikeFromDelta(optionType, deltaConvention int, spot, daysToExpiry, domesticRate, foreignRate, volatility, delta float64) (float64, error) {
+	forward, stdDev, foreignDiscount, err := fxTerms(spot, daysToExpiry, domesticRate, foreignRate, volatility)
+	if err != nil {
+		return 0.0, err
+	}
+	if optionType != Call && optionType != Put {
+		return 0.0, fmt.Errorf("unrecognized optionType %d", optionType)
+	}
+	// Spot deltas approach the foreign discount factor deep in the money
+	deltaLimit := 1.0
+	if deltaConvention == SpotDelta || deltaConvention == PremiumAdjustedSpotDelta {
+		deltaLimit = foreignDiscount
+	}
+	if delta <= 0.0 || delta >= deltaLimit {
+		return 0.0, fmt.Errorf("delta must be > 0 and < %v, got %v", deltaLimit, delta)
+	}
+	forwardDelta := delta / deltaLimit
+	sign := 1.0
+	if optionType == Put {
+		sign = -1.0
+	}
+	d1 := sign * inverseNormalCDF(forwardDelta)
+	strike := forward * math.Exp(-d1*stdDev+stdDev*stdDev/2.0)
+	if deltaConvention == SpotDelta || deltaConvention == ForwardDelta {
+		return strike, nil
+	}
+
+	// Premium-adjusted forward delta: the strike over the forward times N(d2)
+	excess := func(strikePrice float64) float64 {
+		d2 := (math.Log(forward/strikePrice) - stdDev*stdDev/2.0) / stdDev
+		return strikePrice/forward*normalizedCDF(sign*d2) - forwardDelta
+	}
+	// Netting off the premium lowers a call's delta and raises a put's, so the
+	// adjusted strike is below the unadjusted one for both
+	high := strike
+	var low float64
+	if optionType == Call {
+		// The call delta peaks where stdDev N(d2) = n(d2)
+		peak, err := util.Brent(func(d2 float64) float64 {
+			return stdDev*normalizedCDF(d2) - normalizedPDF(d2)
+		}, -8.0, 8.0, 1e-12, brentIterations)
+		if err != nil {
+			return 0.0, err
+		}
+		low = forward * math.Exp(-peak*stdDev-stdDev*stdDev/2.0)
+		if excess(low) < 0.0 {
+			return 0.0, fmt.Errorf("no call has a premium-adjusted delta of %v at %v days", delta, daysToExpiry)
+		}
+	} else {
+		low = strike
+		for i := 0; excess(low) >= 0.0; i++ {
+			if i == fxBracketSteps {
+				return 0.0, fmt.Errorf("no put strike found for premium-adjusted delta %v at %v days", delta, daysToExpiry)
+			}
+			low /= 2.0
+		}
+	}
+	return util.Brent(excess, low, high, deltaStrikeTolerance*forward, brentIterations)
+}
+
+// FXAtmStrike is the at-the-money strike of an expiry in an at-the-money and a
+// delta convention.  The delta-neutral straddle strike is above the forward
+// without premium adjustment and below it with.
+func FXAtmStrike(atmConvention, deltaConvention int, spot, daysToExpiry, domesticRate, foreignRate, volatility float64) (float64, error) {
+	forward, stdDev, _, err := fxTerms(spot, daysToExpiry, domesticRate, foreignRate, volatility)
+	if err != nil {
+		return 0.0, err
+	}
+	switch atmConvention {
+	case AtmForward:
+		return forward, nil
+	case AtmDeltaNeutral:
+		if deltaConvention == PremiumAdjustedSpotDelta || deltaConvention == PremiumAdjustedForwardDelta {
+			return forward * math.Exp(-stdDev*stdDev/2.0), nil
+		}
+		return forward * math.Exp(stdDev*stdDev/2.0), nil
+	}
+	return 0.0, fmt.Errorf("unrecognized at-the-money convention %d", atmConvention)
+}
+
+// FXSmileWing quotes the smile at one delta: the call volatility less the put
+// volatility, and the average of the two over the at-the-money volatility
+type FXSmileWing struct {
+	Delta        float64
+	RiskReversal float64
+	Butterfly    float64
+}
+
+// FXSmileQuote is how the market quotes a currency smile at one expiry
+type FXSmileQuote struct {
+	DaysToExpiry  float64
+	AtmVolatility float64
+	Wings         []FXSmileWing
+}
+
+// FXSmilePoint is one strike of a currency smile, with its signed delta
+type FXSmilePoint struct {
+	OptionType int
+	Delta      float64
+	Strike     float64
+	Volatility float64
+}
+
+// FXSmileStrikes converts a smile quote to strikes and volatilities, in strike
+// order: a put and a call per wing and the at-the-money strike, quoted as a call.
+// Wing volatilities follow the smile strangle convention, so the call is at
+// atm + butterfly + riskReversal/2 and the put at atm + butterfly - riskReversal/2.
+func FXSmileStrikes(quote FXSmileQuote, spot, domesticRate, foreignRate float64, deltaConvention, atmConvention int) ([]FXSmilePoint, error) {
+	atmStrike, err := FXAtmStrike(atmConvention, deltaConvention, spot, quote.DaysToExpiry, domesticRate, foreignRate, quote.AtmVolatility)
+	if err != nil {
+		return nil, err
+	}
+	atmDelta, err := FXDelta(Call, deltaConvention, spot, atmStrike, quote.DaysToExpiry, domesticRate, foreignRate, quote.AtmVolatility)
+	if err != nil {
+		return nil, err
+	}
+	points := []FXSmilePoint{{Call, atmDelta, atmStrike, quote.AtmVolatility}}
+	for _, wing := range quote.Wings {
+		for _, optionType := range []int{Call, Put} {
+			volatility := quote.AtmVolatility + wing.Butterfly + wing.RiskReversal/2.0
+			sign := 1.0
+			if optionType == Put {
+				volatility -= wing.RiskReversal
+				sign = -1.0
+			}
+			strike, err := FXStrikeFromDelta(optionType, deltaConvention, spot, quote.DaysToExpiry, domesticRate, foreignRate, volatility, wing.Delta)
+			if err != nil {
+				return nil, fmt.Errorf("%v delta wing at %v days: %w", wing.Delta, quote.DaysToExpiry, err)
+			}
+			points = append(points, FXSmilePoint{optionType, sign * wing.Delta, strike, volatility})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Strike < points[j].Strike })
+	return points, nil
+}
+
+// fxDeltaStrikeAxis resolves unsigned deltas to strikes in the chain's delta
+// convention.  With a volatility surface the strike and its volatility are found
+// together by iterating from the volatility at the forward.
+func (chain *OptionChainCalculator) fxDeltaStrikeAxis(spot, daysToExpiry float64, deltas Axis) *RelativeAxis {
+	domesticRate := chain.RateAt(daysToExpiry)
+	foreignRate := chain.DividendYield
+
+	return &RelativeAxis{Points: deltas, Resolve: func(delta float64) (float64, error) {
+		strike := FXForward(spot, daysToExpiry, domesticRate, foreignRate)
+		for i := 0; i < deltaStrikeIterations; i++ {
+			volatility, err := chain.VolatilityAt(spot, strike, daysToExpiry)
+			if err != nil {
+				return 0.0, err
+			}
+			next, err := FXStrikeFromDelta(chain.optionType, chain.deltaConvention, spot, daysToExpiry, domesticRate, foreignRate, volatility, delta)
+			if err != nil {
+				return 0.0, err
+			}
+			if math.Abs(next-strike) <= deltaStrikeTolerance*strike {
+				return next, nil
+			}
+			strike = next
+		}
+		return 0.0, fmt.Errorf("no strike found for delta %v at %v days on the volatility surface", delta, daysToExpiry)
+	}}
+}
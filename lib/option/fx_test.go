@@ -0,0 +1,103 @@
+package option
+
+import (
+	"math"
+	"testing"
+)
+
+// fxQuote prices a position as a chain row does, in the chain's conventions
+func fxQuote(t *testing.T, chain *OptionChainCalculator, spot, strikePrice, daysToExpiry float64) OptionPosition {
+	t.Helper()
+	var position OptionPosition
+	if err := chain.calculatePrice(spot, strikePrice, daysToExpiry, &position); err != nil {
+		t.Fatal(err)
+	}
+	chain.quoteFX(spot, &position)
+	return position
+}
+
+// Haug's example of a six month call on a currency at 1.56 struck at 1.60, and
+// the put by parity with the forward
+func TestGarmanKohlhagenReference(t *testing.T) {
+	const spot, strikePrice, daysToExpiry, domesticRate, foreignRate, volatility = 1.56, 1.60, 182.5, 0.06, 0.08, 0.12
+	call := fxQuote(t, testChain(t, Call, volatility, domesticRate, withFX(foreignRate, SpotDelta, DomesticPips), withGreeks), spot, strikePrice, daysToExpiry)
+	if math.Abs(call.Price-0.0291) > 5e-5 {
+		t.Errorf("call: got %.4f, want 0.0291", call.Price)
+	}
+	put := fxQuote(t, testChain(t, Put, volatility, domesticRate, withFX(foreignRate, SpotDelta, DomesticPips), withGreeks), spot, strikePrice, daysToExpiry)
+	discount := math.Exp(-domesticRate * daysToExpiry / 365)
+	if want := discount * (FXForward(spot, daysToExpiry, domesticRate, foreignRate) - strikePrice); math.Abs(call.Price-put.Price-want) > 1e-12 {
+		t.Errorf("call - put = %.12f, want %.12f", call.Price-put.Price, want)
+	}
+}
+
+// A chain quotes the deltas FXDelta does and restates domestic pips in each
+// premium convention
+func TestFXConventions(t *testing.T) {
+	const spot, strikePrice, daysToExpiry, domesticRate, foreignRate, volatility = 1.10, 1.15, 91.0, 0.05, 0.03, 0.10
+	for _, optionType := range []int{Call, Put} {
+		pips := fxQuote(t, testChain(t, optionType, volatility, domesticRate, withFX(foreignRate, SpotDelta, DomesticPips), withGreeks), spot, strikePrice, daysToExpiry)
+		for deltaConvention := SpotDelta; deltaConvention <= PremiumAdjustedForwardDelta; deltaConvention++ {
+			position := fxQuote(t, testChain(t, optionType, volatility, domesticRate, withFX(foreignRate, deltaConvention, DomesticPips), withGreeks), spot, strikePrice, daysToExpiry)
+			want, err := FXDelta(optionType, deltaConvention, spot, strikePrice, daysToExpiry, domesticRate, foreignRate, volatility)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(position.Greeks.Delta-want) > 1e-9 {
+				t.Errorf("type %d delta convention %d: got %v, want %v", optionType, deltaConvention, position.Greeks.Delta, want)
+			}
+		}
+		for _, premium := range []struct {
+			convention int
+			want       float64
+		}{
+			{ForeignPercent, pips.Price * 100 / spot},
+			{ForeignPips, pips.Price / (spot * strikePrice)},
+			{DomesticPercent, pips.Price * 100 / strikePrice},
+		} {
+			position := fxQuote(t, testChain(t, optionType, volatility, domesticRate, withFX(foreignRate, SpotDelta, premium.convention), withGreeks), spot, strikePrice, daysToExpiry)
+			if math.Abs(position.Price-premium.want) > 1e-12 {
+				t.Errorf("type %d premium convention %d: got %v, want %v", optionType, premium.convention, position.Price, premium.want)
+			}
+		}
+	}
+}
+
+// Strikes found by delta are priced back to that delta in every convention, and
+// a straddle at the delta-neutral strike has no delta
+func TestFXStrikeFromDelta(t *testing.T) {
+	const spot, daysToExpiry, domesticRate, foreignRate, volatility = 1.10, 91.0, 0.05, 0.03, 0.10
+	for deltaConvention := SpotDelta; deltaConvention <= PremiumAdjustedForwardDelta; deltaConvention++ {
+		for _, optionType := range []int{Call, Put} {
+			for _, delta := range []float64{0.1, 0.25, 0.5} {
+				strike, err := FXStrikeFromDelta(optionType, deltaConvention, spot, daysToExpiry, domesticRate, foreignRate, volatility, delta)
+				if err != nil {
+					t.Fatalf("convention %d type %d delta %v: %v", deltaConvention, optionType, delta, err)
+				}
+				got, err := FXDelta(optionType, deltaConvention, spot, strike, daysToExpiry, domesticRate, foreignRate, volatility)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if math.Abs(math.Abs(got)-delta) > 1e-9 {
+					t.Errorf("convention %d type %d: strike %v has delta %v, want %v", deltaConvention, optionType, strike, got, delta)
+				}
+			}
+		}
+
+		atmStrike, err := FXAtmStrike(AtmDeltaNeutral, deltaConvention, spot, daysToExpiry, domesticRate, foreignRate, volatility)
+		if err != nil {
+			t.Fatal(err)
+		}
+		call, err := FXDelta(Call, deltaConvention, spot, atmStrike, daysToExpiry, domesticRate, foreignRate, volatility)
+		if err != nil {
+			t.Fatal(err)
+		}
+		put, err := FXDelta(Put, deltaConvention, spot, atmStrike, daysToExpiry, domesticRate, foreignRate, volatility)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(call+put) > 1e-12 {
+			t.Errorf("convention %d: straddle at %v has delta %v", deltaConvention, atmStrike, call+put)
+		}
+	}
+}
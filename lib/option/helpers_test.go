@@ -0,0 +1,74 @@
+package option
+
+import "testing"
+
+// chainSetup configures a test chain after NewOptionChain
+type chainSetup func(chain *OptionChainCalculator) error
+
+// testChain builds a chain expiring within a year with the setups applied in
+// order, failing the test if any of them is rejected
+func testChain(t *testing.T, optionType int, volatility, riskFreeRate float64, setups ...chainSetup) *OptionChainCalculator {
+	t.Helper()
+	chain, err := NewOptionChain(optionType, volatility, riskFreeRate, 365)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, setup := range setups {
+		if err := setup(chain); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return chain
+}
+
+func withGreeks(chain *OptionChainCalculator) error {
+	chain.WithGreeks = true
+	return nil
+}
+
+func withAmerican(latticeModel, steps int) chainSetup {
+	return func(chain *OptionChainCalculator) error {
+		return chain.SetExerciseStyle(American, latticeModel, steps)
+	}
+}
+
+func withDividends(dividendYield float64, dividends []Dividend) chainSetup {
+	return func(chain *OptionChainCalculator) error {
+		return chain.SetDividends(dividendYield, dividends)
+	}
+}
+
+func withModel(model int) chainSetup {
+	return func(chain *OptionChainCalculator) error {
+		return chain.SetModel(model)
+	}
+}
+
+// withFX prices Garman-Kohlhagen currency options quoted under the conventions
+func withFX(foreignRate float64, deltaConvention, premiumConvention int) chainSetup {
+	return func(chain *OptionChainCalculator) error {
+		if err := chain.SetModel(GarmanKohlhagen); err != nil {
+			return err
+		}
+		chain.SetForeignRate(foreignRate)
+		return chain.SetFXConventions(deltaConvention, premiumConvention)
+	}
+}
+
+func withBarrier(barrierType int, level, rebate float64) chainSetup {
+	return func(chain *OptionChainCalculator) error {
+		return chain.SetBarrier(barrierType, level, rebate)
+	}
+}
+
+func withGridPoints(points int) chainSetup {
+	return func(chain *OptionChainCalculator) error {
+		return chain.SetGridPoints(points)
+	}
+}
+
+func withPayoff(payoff int, terms PayoffTerms) chainSetup {
+	return func(chain *OptionChainCalculator) error {
+		return chain.SetPayoff(payoff, terms)
+	}
+}
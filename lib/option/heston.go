@@ -0,0 +1,351 @@
+package option
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+const (
+	hestonSpotBump      = 0.001 // relative, for delta and gamma
+	hestonMaxRho        = 0.999
+	hestonMaxStartRho   = 0.99 * hestonMaxRho // fits start off the bound, where atanh diverges
+	hestonFitTolerance  = 1e-10
+	hestonFitIterations = 3000
+	// Objective value of parameters that cannot price the quotes
+	infeasibleFit = 1e12
+)
+
+// HestonParameters holds the parameters of the Heston stochastic volatility model: the
+// variance reverts at speed Kappa to its long-run level Theta, with volatility
+// of variance Sigma, correlation Rho with the asset price and initial level V0
+type HestonParameters struct {
+	Kappa float64
+	Theta float64
+	Sigma float64
+	Rho   float64
+	V0    float64
+}
+
+// HestonQuote is an implied volatility quote to calibrate the Heston model to
+type HestonQuote struct {
+	Strike       float64
+	DaysToExpiry float64
+	Volatility   float64
+}
+
+type hestonKey struct {
+	parameters   HestonParameters
+	daysToExpiry float64
+	riskFreeRate float64
+}
+
+func (heston *HestonParameters) Validate() error {
+	if heston.Kappa <= 0.0 || heston.Theta <= 0.0 || heston.Sigma <= 0.0 || heston.V0 <= 0.0 || math.Abs(heston.Rho) >= 1.0 {
+		return fmt.Errorf("Heston needs Kappa, Theta, Sigma and V0 > 0 and |Rho| < 1, got %v/%v/%v/%v/%v",
+			heston.Kappa, heston.Theta, heston.Sigma, heston.V0, heston.Rho)
+	}
+	return nil
+}
+
+// Feller reports whether 2 Kappa Theta >= Sigma^2, so the variance never reaches zero
+func (heston *HestonParameters) Feller() bool {
+	return 2.0*heston.Kappa*heston.Theta >= heston.Sigma*heston.Sigma
+}
+
+// AverageVolatility is the square root of the variance expected on average over
+// the next yearsToExpiry
+func (heston *HestonParameters) AverageVolatility(yearsToExpiry float64) float64 {
+	if yearsToExpiry <= 0.0 {
+		return math.Sqrt(heston.V0)
+	}
+	decay := (1.0 - math.Exp(-heston.Kappa*yearsToExpiry)) / (heston.Kappa * yearsToExpiry)
+	return math.Sqrt(heston.Theta + (heston.V0-heston.Theta)*decay)
+}
+
+// characteristic is the characteristic function of log(S_T/S) at u, in the form
+// that avoids branch cuts of the complex logarithm (Albrecher et al.)
+func (heston *HestonParameters) characteristic(u complex128, yearsToExpiry, carry float64) complex128 {
+	kappa, sigma := complex(heston.Kappa, 0), complex(heston.Sigma, 0)
+	iu := complex(0, 1) * u
+	beta := kappa - complex(heston.Rho*heston.Sigma, 0)*iu
+	d := cmplx.Sqrt(beta*beta + sigma*sigma*(iu+u*u))
+	g := (beta - d) / (beta + d)
+	decay := cmplx.Exp(-d * complex(yearsToExpiry, 0))
+	sigma2 := sigma * sigma
+	c := complex(carry*yearsToExpiry, 0)*iu +
+		complex(heston.Kappa*heston.Theta, 0)/sigma2*((beta-d)*complex(yearsToExpiry, 0)-2.0*cmplx.Log((1.0-g*decay)/(1.0-g)))
+	dTerm := (beta - d) / sigma2 * (1.0 - decay) / (1.0 - g*decay)
+	return cmplx.Exp(c + dTerm*complex(heston.V0, 0))
+}
+
+// expansion prepares the Fourier-cosine terms for one expiry, over a range of
+// log(S_T/S) set by its first two cumulants
+func (heston *HestonParameters) expansion(yearsToExpiry, riskFreeRate, dividendYield float64) (*cosExpansion, error) {
+	if yearsToExpiry <= 0.0 {
+		return nil, fmt.Errorf("days to expiry must be > 0, got %v", yearsToExpiry*365)
+	}
+	kappa, theta, sigma, rho, v0 := heston.Kappa, heston.Theta, heston.Sigma, heston.Rho, heston.V0
+	t := yearsToExpiry
+	carry := riskFreeRate - dividendYield
+	decay := math.Exp(-kappa * t)
+	c1 := carry*t + (1.0-decay)*(theta-v0)/(2.0*kappa) - theta*t/2.0
+	c2 := (sigma*t*kappa*decay*(v0-theta)*(8.0*kappa*rho-4.0*sigma) +
+		kappa*rho*sigma*(1.0-decay)*(16.0*theta-8.0*v0) +
+		2.0*theta*kappa*t*(-4.0*kappa*rho*sigma+sigma*sigma+4.0*kappa*kappa) +
+		sigma*sigma*((theta-2.0*v0)*decay*decay+theta*(6.0*decay-7.0)+2.0*v0) +
+		8.0*kappa*kappa*(v0-theta)*(1.0-decay)) / (8.0 * kappa * kappa * kappa)
+	stdDev := math.Sqrt(math.Abs(c2))
+	if !(stdDev > 0.0) {
+		return nil, fmt.Errorf("Heston variance of the log price is not positive for %v days", yearsToExpiry*365)
+	}
+	characteristic := func(u float64) complex128 {
+		return heston.characteristic(complex(u, 0), t, carry)
+	}
+	return newCOSExpansion(characteristic, c1, stdDev, t, riskFreeRate, dividendYield), nil
+}
+
+// Price prices a European option under the Heston model by the Fourier-cosine method
+func (heston *HestonParameters) Price(optionType int, assetPrice, strikePrice, daysToExpiry, riskFreeRate, dividendYield float64) (float64, error) {
+	if err := heston.Validate(); err != nil {
+		return 0.0, err
+	}
+	if assetPrice <= 0.0 || strikePrice <= 0.0 {
+		return 0.0, fmt.Errorf("asset and strike prices must be > 0, got %v/%v", assetPrice, strikePrice)
+	}
+	expansion, err := heston.expansion(daysToExpiry/365, riskFreeRate, dividendYield)
+	if err != nil {
+		return 0.0, err
+	}
+	return expansion.price(optionType, assetPrice, strikePrice), nil
+}
+
+// ImpliedVolatility is the Black-Scholes volatility of the model's price for the
+// out-of-the-money option at a strike
+func (heston *HestonParameters) ImpliedVolatility(assetPrice, strikePrice, daysToExpiry, riskFreeRate, dividendYield float64) (float64, error) {
+	years := daysToExpiry / 365
+	optionType := Put
+	if strikePrice >= assetPrice*math.Exp((riskFreeRate-dividendYield)*years) {
+		optionType = Call
+	}
+	price, err := heston.Price(optionType, assetPrice, strikePrice, daysToExpiry, riskFreeRate, dividendYield)
+	if err != nil {
+		return 0.0, err
+	}
+	// A dividend yield prices as Black-Scholes on the asset price less the dividends
+	return ImpliedVolatility(optionType, price, assetPrice*math.Exp(-dividendYield*years), strikePrice, daysToExpiry, riskFreeRate)
+}
+
+// SetHeston prices the chain's options under the Heston model with the given
+// parameters.  The volatility of each option, used for relative asset price and
+// strike axes, is the average the model expects to its expiry.
+func (chain *OptionChainCalculator) SetHeston(parameters HestonParameters) error {
+	if err := parameters.Validate(); err != nil {
+		return err
+	}
+	chain.heston = &parameters
+	return chain.SetModel(Heston)
+}
+
+// HestonPrice prices the chain option under the Heston model.  Delta and gamma
+// come from bumping the asset price; theta, rho and vega from repricing a day
+// closer to expiry, at bumped rates and at a bumped initial volatility sqrt(V0).
+func (chain *OptionChainCalculator) HestonPrice(assetPrice, strikePrice, daysToExpiry float64, position *OptionPosition) error {
+	if chain.heston == nil {
+		return fmt.Errorf("Heston parameters are not set")
+	}
+	if assetPrice <= 0.0 || strikePrice <= 0.0 {
+		return fmt.Errorf("asset and strike prices must be > 0, got %v/%v", assetPrice, strikePrice)
+	}
+	parameters := *chain.heston
+	riskFreeRate := chain.RateAt(daysToExpiry)
+	reprice := func(parameters HestonParameters, assetPrice, daysToExpiry, riskFreeRate float64) (float64, error) {
+		expansion, err := chain.expansionAt(hestonKey{parameters, daysToExpiry, riskFreeRate}, func() (*cosExpansion, error) {
+			return parameters.expansion(daysToExpiry/365, riskFreeRate, chain.DividendYield)
+		})
+		if err != nil {
+			return 0.0, err
+		}
+		return expansion.price(chain.optionType, assetPrice, strikePrice), nil
+	}
+	price, err := reprice(parameters, assetPrice, daysToExpiry, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	position.Price = price
+	position.Strike = strikePrice
+	position.DaysToExpiry = daysToExpiry
+	if !chain.WithGreeks {
+		return nil
+	}
+
+	var greeks Greeks
+	bump := assetPrice * hestonSpotBump
+	up, err := reprice(parameters, assetPrice+bump, daysToExpiry, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	down, err := reprice(parameters, assetPrice-bump, daysToExpiry, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	greeks.Delta = (up - down) / (2.0 * bump)
+	greeks.Gamma = (up - 2.0*price + down) / (bump * bump)
+
+	dayBump := math.Min(latticeDayBump, daysToExpiry/2.0)
+	earlier, err := reprice(parameters, assetPrice, daysToExpiry-dayBump, chain.RateAt(daysToExpiry-dayBump))
+	if err != nil {
+		return err
+	}
+	greeks.Theta = (earlier - price) / dayBump
+
+	volatility := math.Sqrt(parameters.V0)
+	volatilityBump := math.Min(latticeVolatilityBump, volatility/2.0)
+	bumped := parameters
+	bumped.V0 = (volatility + volatilityBump) * (volatility + volatilityBump)
+	volatilityUp, err := reprice(bumped, assetPrice, daysToExpiry, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	bumped.V0 = (volatility - volatilityBump) * (volatility - volatilityBump)
+	volatilityDown, err := reprice(bumped, assetPrice, daysToExpiry, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	greeks.Vega = (volatilityUp - volatilityDown) / (2.0 * volatilityBump) / 100.0
+
+	rateUp, err := reprice(parameters, assetPrice, daysToExpiry, riskFreeRate+latticeRateBump)
+	if err != nil {
+		return err
+	}
+	rateDown, err := reprice(parameters, assetPrice, daysToExpiry, riskFreeRate-latticeRateBump)
+	if err != nil {
+		return err
+	}
+	greeks.Rho = (rateUp - rateDown) / (2.0 * latticeRateBump) / 100.0
+	position.Greeks = greeks
+	return nil
+}
+
+// blackTerms are the out-of-the-money Black-Scholes price and vega of a quote,
+// from its forward
+func blackTerms(forward, strike, yearsToExpiry, volatility, discount float64) (int, float64, float64) {
+	stdDev := volatility * math.Sqrt(yearsToExpiry)
+	d1 := (math.Log(forward/strike) + stdDev*stdDev/2.0) / stdDev
+	d2 := d1 - stdDev
+	vega := discount * forward * normalizedPDF(d1) * math.Sqrt(yearsToExpiry)
+	if strike >= forward {
+		return Call, discount * (forward*normalizedCDF(d1) - strike*normalizedCDF(d2)), vega
+	}
+	return Put, discount * (strike*normalizedCDF(-d2) - forward*normalizedCDF(-d1)), vega
+}
+
+// CalibrateHeston fits the Heston parameters to implied volatility quotes across
+// strikes and expiries by Nelder-Mead, from start when given and otherwise from
+// a few starting points around the quotes.  Errors are in out-of-the-money
+// option prices divided by their Black-Scholes vega, which approximates
+// volatility errors without inverting each model price.
+func CalibrateHeston(quotes []HestonQuote, assetPrice, riskFreeRate, dividendYield float64, start *HestonParameters) (*HestonParameters, error) {
+	if len(quotes) < 5 {
+		return nil, fmt.Errorf("at least 5 quotes are needed to calibrate Heston, got %d", len(quotes))
+	}
+	if assetPrice <= 0.0 {
+		return nil, fmt.Errorf("asset price must be > 0, got %v", assetPrice)
+	}
+
+	// Quotes are grouped by expiry, so each expiry's expansion serves its strikes
+	type target struct {
+		optionType int
+		strike     float64
+		price      float64
+		vega       float64
+	}
+	byExpiry := make(map[float64][]target)
+	atmVariance, atmDistance := 0.0, math.Inf(1)
+	for _, quote := range quotes {
+		if quote.Strike <= 0.0 || quote.DaysToExpiry <= 0.0 || quote.Volatility <= 0.0 {
+			return nil, fmt.Errorf("quote strike, days to expiry and volatility must be > 0, got %v/%v/%v",
+				quote.Strike, quote.DaysToExpiry, quote.Volatility)
+		}
+		years := quote.DaysToExpiry / 365
+		forward := assetPrice * math.Exp((riskFreeRate-dividendYield)*years)
+		optionType, price, vega := blackTerms(forward, quote.Strike, years, quote.Volatility, math.Exp(-riskFreeRate*years))
+		// Floor the vega so far out-of-the-money quotes do not dominate the fit
+		vega = math.Max(vega, 1e-4*assetPrice*math.Sqrt(years))
+		byExpiry[quote.DaysToExpiry] = append(byExpiry[quote.DaysToExpiry], target{optionType, quote.Strike, price, vega})
+		if distance := math.Abs(math.Log(quote.Strike / forward)); distance < atmDistance {
+			atmVariance, atmDistance = quote.Volatility*quote.Volatility, distance
+		}
+	}
+	expiries := make([]float64, 0, len(byExpiry))
+	for days := range byExpiry {
+		expiries = append(expiries, days)
+	}
+	sort.Float64s(expiries)
+
+	// Search in unconstrained coordinates: logs of Kappa, Theta, Sigma and V0, and atanh Rho
+	decode := func(point []float64) HestonParameters {
+		return HestonParameters{
+			Kappa: math.Exp(point[0]),
+			Theta: math.Exp(point[1]),
+			Sigma: math.Exp(point[2]),
+			Rho:   hestonMaxRho * math.Tanh(point[3]),
+			V0:    math.Exp(point[4]),
+		}
+	}
+	objective := func(point []float64) float64 {
+		heston := decode(point)
+		errors := 0.0
+		for _, days := range expiries {
+			expansion, err := heston.expansion(days/365, riskFreeRate, dividendYield)
+			if err != nil {
+				return infeasibleFit
+			}
+			for _, quote := range byExpiry[days] {
+				difference := (expansion.price(quote.optionType, assetPrice, quote.strike) - quote.price) / quote.vega
+				errors += difference * difference
+			}
+		}
+		if math.IsNaN(errors) {
+			return infeasibleFit
+		}
+		return errors
+	}
+
+	encode := func(heston HestonParameters) []float64 {
+		return []float64{
+			math.Log(heston.Kappa), math.Log(heston.Theta), math.Log(heston.Sigma),
+			math.Atanh(math.Max(-hestonMaxStartRho, math.Min(heston.Rho, hestonMaxStartRho)) / hestonMaxRho), math.Log(heston.V0),
+		}
+	}
+	var starts []HestonParameters
+	if start != nil {
+		if err := start.Validate(); err != nil {
+			return nil, err
+		}
+		starts = append(starts, *start)
+	} else {
+		for _, rho := range []float64{-0.7, 0.0} {
+			for _, sigma := range []float64{0.3, 1.0} {
+				starts = append(starts, HestonParameters{Kappa: 2.0, Theta: atmVariance, Sigma: sigma, Rho: rho, V0: atmVariance})
+			}
+		}
+	}
+	step := []float64{0.5, 0.5, 0.5, 0.5, 0.5}
+	var best []float64
+	bestErrors := math.Inf(1)
+	for _, heston := range starts {
+		point, errors := util.NelderMead(objective, encode(heston), step, hestonFitTolerance, hestonFitIterations)
+		if errors < bestErrors {
+			best, bestErrors = point, errors
+		}
+	}
+	if bestErrors >= infeasibleFit {
+		return nil, fmt.Errorf("no Heston fit found for the quotes")
+	}
+	heston := decode(best)
+	return &heston, nil
+}
@@ -0,0 +1,88 @@
+package option
+
+import (
+	"math"
+	"testing"
+)
+
+// The parameters of Fang and Oosterlee's (2008) Heston example
+var testHeston = HestonParameters{Kappa: 1.5768, Theta: 0.0398, Sigma: 0.5751, Rho: -0.5711, V0: 0.0175}
+
+// Fang and Oosterlee's reference value for their at-the-money one year call
+// without rates, 5.785155450
+func TestHestonReference(t *testing.T) {
+	got, err := testHeston.Price(Call, 100, 100, 365, 0.0, 0.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got-5.785155450) > 1e-6 {
+		t.Errorf("got %.9f, want 5.785155450", got)
+	}
+}
+
+// Calls less puts are the discounted forward less the strike, and without
+// volatility of variance at the long run variance Heston is Black-Scholes
+func TestHestonLimits(t *testing.T) {
+	const riskFreeRate, dividendYield, daysToExpiry = 0.03, 0.01, 365.0
+	for _, strikePrice := range []float64{50, 80, 120, 200} {
+		call, err := testHeston.Price(Call, 100, strikePrice, daysToExpiry, riskFreeRate, dividendYield)
+		if err != nil {
+			t.Fatal(err)
+		}
+		put, err := testHeston.Price(Put, 100, strikePrice, daysToExpiry, riskFreeRate, dividendYield)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 100*math.Exp(-dividendYield) - strikePrice*math.Exp(-riskFreeRate); math.Abs(call-put-want) > 1e-8 {
+			t.Errorf("K=%v: call - put = %v, want %v", strikePrice, call-put, want)
+		}
+	}
+
+	flat := HestonParameters{Kappa: 1, Theta: 0.04, Sigma: 0.001, Rho: 0, V0: 0.04}
+	for _, optionType := range []int{Call, Put} {
+		got, err := flat.Price(optionType, 100, 110, 180, 0.05, 0.02)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := blackScholesValue(optionType, 100, 110, 180.0/365, 0.2, 0.05, 0.02); math.Abs(got-want) > 1e-5 {
+			t.Errorf("type %d: got %v, want the Black-Scholes price %v", optionType, got, want)
+		}
+	}
+}
+
+// Calibrating to the model's own implied volatilities recovers its parameters
+func TestCalibrateHestonRoundTrip(t *testing.T) {
+	const assetPrice, riskFreeRate, dividendYield = 100.0, 0.03, 0.01
+	var quotes []HestonQuote
+	for _, daysToExpiry := range []float64{30, 91, 182, 365} {
+		for _, strikePrice := range []float64{80, 90, 100, 110, 120} {
+			volatility, err := testHeston.ImpliedVolatility(assetPrice, strikePrice, daysToExpiry, riskFreeRate, dividendYield)
+			if err != nil {
+				t.Fatal(err)
+			}
+			quotes = append(quotes, HestonQuote{Strike: strikePrice, DaysToExpiry: daysToExpiry, Volatility: volatility})
+		}
+	}
+	// A start on the correlation bound is moved inside it rather than fitting from infinity
+	boundary := HestonParameters{Kappa: 2.0, Theta: 0.04, Sigma: 0.5, Rho: -hestonMaxRho, V0: 0.04}
+	for _, start := range []*HestonParameters{nil, &boundary} {
+		fit, err := CalibrateHeston(quotes, assetPrice, riskFreeRate, dividendYield, start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, parameter := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"kappa", fit.Kappa, testHeston.Kappa},
+			{"theta", fit.Theta, testHeston.Theta},
+			{"sigma", fit.Sigma, testHeston.Sigma},
+			{"rho", fit.Rho, testHeston.Rho},
+			{"v0", fit.V0, testHeston.V0},
+		} {
+			if math.Abs(parameter.got-parameter.want) > 1e-3*math.Abs(parameter.want) {
+				t.Errorf("start %+v: %s: got %v, want %v", start, parameter.name, parameter.got, parameter.want)
+			}
+		}
+	}
+}
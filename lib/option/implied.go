@@ -0,0 +1,144 @@
+package option
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+const (
+	impliedVolatilityLow       = 1e-6
+	impliedVolatilityHigh      = 5.0
+	impliedVolatilityMax       = 100.0
+	impliedVolatilityTolerance = 1e-10
+	impliedPriceTolerance      = 1e-10
+	newtonIterations           = 20
+	brentIterations            = 200
+)
+
+// PriceOption prices a single European option with Black-Scholes.  Implied
+// volatility solves and strategy grids call it in loops, so it works out d1/d2
+// directly rather than setting up the caches of a chain calculator.
+func PriceOption(optionType int, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate float64, withGreeks bool) (OptionPosition, error) {
+	var position OptionPosition
+	if optionType != Call && optionType != Put {
+		return position, fmt.Errorf("unrecognized optionType %d", optionType)
+	}
+	chain := OptionChainCalculator{Volatility: volatility, RiskFreeRate: riskFreeRate, ExpiryInDays: daysToExpiry, WithGreeks: withGreeks}
+	calculate, err := chain.d1d2calculator(daysToExpiry)
+	if err != nil {
+		return position, err
+	}
+	d1d2, err := calculate(assetPrice, strikePrice, volatility)
+	if err != nil {
+		return position, err
+	}
+	chain.blackScholesPosition(optionType, strikePrice, daysToExpiry, d1d2, &position)
+	return position, nil
+}
+
+// arbitrageBounds returns the open interval a European option price must lie in
+// for an implied volatility to exist
+func arbitrageBounds(optionType int, assetPrice, strikePrice, daysToExpiry, riskFreeRate float64) (float64, float64, error) {
+	discountedStrike := strikePrice * math.Exp(-riskFreeRate*daysToExpiry/365)
+	switch optionType {
+	case Call:
+		return math.Max(0.0, assetPrice-discountedStrike), assetPrice, nil
+	case Put:
+		return math.Max(0.0, discountedStrike-assetPrice), discountedStrike, nil
+	}
+	return 0.0, 0.0, fmt.Errorf("unrecognized optionType %d", optionType)
+}
+
+// ImpliedVolatility finds the Black-Scholes volatility that reproduces price.
+// Newton-Raphson steps on vega are tried first, and the search falls back to
+// Brent's method on a bracketing interval when Newton leaves the bracket or
+// stalls on a small vega.
+func ImpliedVolatility(optionType int, price, assetPrice, strikePrice, daysToExpiry, riskFreeRate float64) (float64, error) {
+	if assetPrice <= 0 || strikePrice <= 0 || daysToExpiry <= 0 {
+		return 0.0, fmt.Errorf("asset price, strike price and days to expiry must be > 0")
+	}
+	lower, upper, err := arbitrageBounds(optionType, assetPrice, strikePrice, daysToExpiry, riskFreeRate)
+	if err != nil {
+		return 0.0, err
+	}
+	if price <= lower || price >= upper {
+		return 0.0, fmt.Errorf(
+			"no implied volatility: price %v is outside the arbitrage bounds (%v, %v)",
+			price, lower, upper,
+		)
+	}
+
+	var evalErr error
+	priceError := func(volatility float64) (float64, float64) {
+		position, err := PriceOption(optionType, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, true)
+		if err != nil {
+			evalErr = err
+			return math.NaN(), 0.0
+		}
+		return position.Price - price, position.Greeks.Vega * 100.0
+	}
+
+	// Bracket the root; the price is increasing in volatility
+	low, high := impliedVolatilityLow, impliedVolatilityHigh
+	fLow, _ := priceError(low)
+	fHigh, _ := priceError(high)
+	for fHigh < 0 && high < impliedVolatilityMax {
+		low, fLow = high, fHigh
+		high *= 2.0
+		fHigh, _ = priceError(high)
+	}
+	if evalErr != nil {
+		return 0.0, evalErr
+	}
+	if fLow > 0 || fHigh < 0 {
+		return 0.0, fmt.Errorf(
+			"no implied volatility: price %v is not reached for volatility in [%v, %v]",
+			price, impliedVolatilityLow, high,
+		)
+	}
+
+	// Newton from the Brenner-Subrahmanyam approximation, narrowing the bracket as we go
+	volatility := math.Sqrt(2.0*math.Pi*365/daysToExpiry) * price / assetPrice
+	if volatility <= low || volatility >= high {
+		volatility = 0.5 * (low + high)
+	}
+	for i := 0; i < newtonIterations; i++ {
+		diff, vega := priceError(volatility)
+		if evalErr != nil {
+			return 0.0, evalErr
+		}
+		if math.Abs(diff) < impliedPriceTolerance {
+			return volatility, nil
+		}
+		if diff < 0 {
+			low = volatility
+		} else {
+			high = volatility
+		}
+		if vega < impliedPriceTolerance {
+			break
+		}
+		next := volatility - diff/vega
+		if next <= low || next >= high {
+			break
+		}
+		if math.Abs(next-volatility) < impliedVolatilityTolerance {
+			return next, nil
+		}
+		volatility = next
+	}
+
+	volatility, err = util.Brent(func(v float64) float64 {
+		diff, _ := priceError(v)
+		return diff
+	}, low, high, impliedVolatilityTolerance, brentIterations)
+	if evalErr != nil {
+		return 0.0, evalErr
+	}
+	if err != nil {
+		return 0.0, fmt.Errorf("no implied volatility: %w", err)
+	}
+	return volatility, nil
+}
@@ -0,0 +1,67 @@
+package option
+
+import (
+	"math"
+	"testing"
+)
+
+// Implied volatilities recover the volatility a price was made at, from deep in
+// the money to far out of it, where Newton gives way to Brent
+func TestImpliedVolatilityRoundTrip(t *testing.T) {
+	for _, optionType := range []int{Call, Put} {
+		for _, strikePrice := range []float64{50, 80, 100, 125, 200} {
+			for _, daysToExpiry := range []float64{7, 90, 730} {
+				for _, volatility := range []float64{0.05, 0.2, 0.6, 1.5} {
+					position, err := PriceOption(optionType, 100, strikePrice, daysToExpiry, volatility, 0.03, false)
+					if err != nil {
+						t.Fatal(err)
+					}
+					lower, upper, err := arbitrageBounds(optionType, 100, strikePrice, daysToExpiry, 0.03)
+					if err != nil {
+						t.Fatal(err)
+					}
+					// Too close to a bound for the price to pin down the volatility
+					if position.Price-lower < 1e-6 || upper-position.Price < 1e-6 {
+						continue
+					}
+					got, err := ImpliedVolatility(optionType, position.Price, 100, strikePrice, daysToExpiry, 0.03)
+					if err != nil {
+						t.Errorf("type %d K=%v days=%v vol=%v: %v", optionType, strikePrice, daysToExpiry, volatility, err)
+					} else if math.Abs(got-volatility) > 1e-6 {
+						t.Errorf("type %d K=%v days=%v vol=%v: got %v", optionType, strikePrice, daysToExpiry, volatility, got)
+					}
+				}
+			}
+		}
+	}
+}
+
+// Hull's example: a six month call struck at 40 on a stock at 42 is worth 4.7594
+// at 20% volatility and a 10% rate
+func TestImpliedVolatilityReference(t *testing.T) {
+	got, err := ImpliedVolatility(Call, 4.7594, 42, 40, 365.0/2, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got-0.2) > 1e-4 {
+		t.Errorf("got %.6f, want 0.2", got)
+	}
+}
+
+func TestImpliedVolatilityOutsideBounds(t *testing.T) {
+	tests := []struct {
+		optionType int
+		price      float64
+	}{
+		{Call, 0.0},
+		{Call, 1.5}, // below the discounted intrinsic value
+		{Call, 101},
+		{Put, 0.0},
+		{Put, 120},
+	}
+	for _, test := range tests {
+		if got, err := ImpliedVolatility(test.optionType, test.price, 100, 98, 30, 0.05); err == nil {
+			t.Errorf("type %d price %v: got %v, want an error", test.optionType, test.price, got)
+		}
+	}
+}
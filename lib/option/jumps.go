@@ -0,0 +1,270 @@
+package option
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+const (
+	// The Merton series stops once the Poisson weights summed cover all but this
+	// much probability, or after mertonMaxTerms jumps
+	mertonTailWeight = 1e-12
+	mertonMaxTerms   = 200
+	jumpSpotBump     = 0.001 // relative, for delta and gamma
+)
+
+// MertonJumps are the jumps of the Merton jump-diffusion model: they arrive at
+// Intensity per year, and each multiplies the asset price by a lognormal factor
+// whose log has mean Mean and standard deviation Volatility
+type MertonJumps struct {
+	Intensity  float64
+	Mean       float64
+	Volatility float64
+}
+
+// KouJumps are the jumps of the Kou double-exponential model: they arrive at
+// Intensity per year and are up moves with probability UpProbability.  The log
+// of each jump factor is exponentially distributed, with mean UpMean for up
+// moves and DownMean (a positive size) for down moves.
+type KouJumps struct {
+	Intensity     float64
+	UpProbability float64
+	UpMean        float64
+	DownMean      float64
+}
+
+type kouKey struct {
+	jumps        KouJumps
+	volatility   float64
+	daysToExpiry float64
+	riskFreeRate float64
+}
+
+func (jumps *MertonJumps) Validate() error {
+	if jumps.Intensity < 0.0 || jumps.Volatility < 0.0 {
+		return fmt.Errorf("Merton jumps need Intensity and Volatility >= 0, got %v/%v", jumps.Intensity, jumps.Volatility)
+	}
+	return nil
+}
+
+// Variance is the yearly variance the jumps add to the log asset price
+func (jumps *MertonJumps) Variance() float64 {
+	return jumps.Intensity * (jumps.Mean*jumps.Mean + jumps.Volatility*jumps.Volatility)
+}
+
+// Price prices a European option under the Merton model as the Black-Scholes
+// prices conditional on each number of jumps, weighted by its Poisson probability
+func (jumps *MertonJumps) Price(optionType int, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, dividendYield float64) (float64, error) {
+	if err := jumps.Validate(); err != nil {
+		return 0.0, err
+	}
+	years := daysToExpiry / 365
+	if years <= 0.0 {
+		return 0.0, fmt.Errorf("days to expiry must be > 0, got %v", daysToExpiry)
+	}
+	if assetPrice <= 0.0 || strikePrice <= 0.0 || volatility <= 0.0 {
+		return 0.0, fmt.Errorf("asset and strike prices and volatility must be > 0, got %v/%v/%v", assetPrice, strikePrice, volatility)
+	}
+	// The drift is lowered by the expected jump so the discounted price stays a martingale
+	logJump := jumps.Mean + jumps.Volatility*jumps.Volatility/2.0
+	meanJump := math.Exp(logJump) - 1.0
+	rate := jumps.Intensity * (1.0 + meanJump) * years
+	dividendDiscount := math.Exp(-dividendYield * years)
+
+	price, covered := 0.0, 0.0
+	weight := math.Exp(-rate)
+	for n := 0; n < mertonMaxTerms; n++ {
+		if n > 0 {
+			weight *= rate / float64(n)
+		}
+		jumpCount := float64(n)
+		stdDev := math.Sqrt(volatility*volatility*years + jumpCount*jumps.Volatility*jumps.Volatility)
+		discount := math.Exp(-(riskFreeRate-jumps.Intensity*meanJump)*years - jumpCount*logJump)
+		// Black-Scholes on the forward, given n jumps
+		forward := assetPrice * dividendDiscount / discount
+		d1 := (math.Log(forward/strikePrice) + stdDev*stdDev/2.0) / stdDev
+		d2 := d1 - stdDev
+		conditional := discount * (forward*normalizedCDF(d1) - strikePrice*normalizedCDF(d2))
+		if optionType == Put {
+			conditional = discount * (strikePrice*normalizedCDF(-d2) - forward*normalizedCDF(-d1))
+		}
+		price += weight * conditional
+		if covered += weight; covered >= 1.0-mertonTailWeight {
+			break
+		}
+	}
+	return price, nil
+}
+
+func (jumps *KouJumps) Validate() error {
+	if jumps.Intensity < 0.0 || jumps.UpProbability < 0.0 || jumps.UpProbability > 1.0 ||
+		jumps.UpMean <= 0.0 || jumps.UpMean >= 1.0 || jumps.DownMean <= 0.0 {
+		return fmt.Errorf("Kou jumps need Intensity >= 0, UpProbability in [0, 1], UpMean in (0, 1) and DownMean > 0, got %v/%v/%v/%v",
+			jumps.Intensity, jumps.UpProbability, jumps.UpMean, jumps.DownMean)
+	}
+	return nil
+}
+
+// Variance is the yearly variance the jumps add to the log asset price
+func (jumps *KouJumps) Variance() float64 {
+	p := jumps.UpProbability
+	return 2.0 * jumps.Intensity * (p*jumps.UpMean*jumps.UpMean + (1.0-p)*jumps.DownMean*jumps.DownMean)
+}
+
+// expansion prepares the Fourier-cosine terms for one expiry.  An up mean of one
+// or more would give the asset price an infinite expectation.
+func (jumps *KouJumps) expansion(yearsToExpiry, volatility, riskFreeRate, dividendYield float64) (*cosExpansion, error) {
+	if yearsToExpiry <= 0.0 {
+		return nil, fmt.Errorf("days to expiry must be > 0, got %v", yearsToExpiry*365)
+	}
+	if volatility <= 0.0 {
+		return nil, fmt.Errorf("volatility must be > 0, got %v", volatility)
+	}
+	t, lambda, p := yearsToExpiry, jumps.Intensity, jumps.UpProbability
+	up, down := 1.0/jumps.UpMean, 1.0/jumps.DownMean
+	meanJump := p*up/(up-1.0) + (1.0-p)*down/(down+1.0) - 1.0
+	drift := riskFreeRate - dividendYield - volatility*volatility/2.0 - lambda*meanJump
+
+	mean := t * (drift + lambda*(p*jumps.UpMean-(1.0-p)*jumps.DownMean))
+	variance := t * (volatility*volatility + jumps.Variance())
+	fourth := 24.0 * t * lambda * (p*math.Pow(jumps.UpMean, 4) + (1.0-p)*math.Pow(jumps.DownMean, 4))
+	characteristic := func(u float64) complex128 {
+		iu := complex(0, u)
+		exponent := iu*complex(drift, 0) - complex(volatility*volatility*u*u/2.0, 0) +
+			complex(lambda, 0)*(complex(p*up, 0)/(complex(up, 0)-iu)+complex((1.0-p)*down, 0)/(complex(down, 0)+iu)-1.0)
+		return cmplx.Exp(exponent * complex(t, 0))
+	}
+	// The fourth cumulant widens the range for the heavier tails of the jumps
+	return newCOSExpansion(characteristic, mean, math.Sqrt(variance+math.Sqrt(fourth)), t, riskFreeRate, dividendYield), nil
+}
+
+// Price prices a European option under the Kou model by the Fourier-cosine method
+func (jumps *KouJumps) Price(optionType int, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, dividendYield float64) (float64, error) {
+	if err := jumps.Validate(); err != nil {
+		return 0.0, err
+	}
+	if assetPrice <= 0.0 || strikePrice <= 0.0 {
+		return 0.0, fmt.Errorf("asset and strike prices must be > 0, got %v/%v", assetPrice, strikePrice)
+	}
+	expansion, err := jumps.expansion(daysToExpiry/365, volatility, riskFreeRate, dividendYield)
+	if err != nil {
+		return 0.0, err
+	}
+	return expansion.price(optionType, assetPrice, strikePrice), nil
+}
+
+// SetMertonJumps prices the chain's options under the Merton jump-diffusion
+// model, with Volatility as the volatility between jumps
+func (chain *OptionChainCalculator) SetMertonJumps(jumps MertonJumps) error {
+	if err := jumps.Validate(); err != nil {
+		return err
+	}
+	chain.merton = &jumps
+	return chain.SetModel(Merton)
+}
+
+// SetKouJumps prices the chain's options under the Kou double-exponential jump
+// model, with Volatility as the volatility between jumps
+func (chain *OptionChainCalculator) SetKouJumps(jumps KouJumps) error {
+	if err := jumps.Validate(); err != nil {
+		return err
+	}
+	chain.kou = &jumps
+	return chain.SetModel(Kou)
+}
+
+// MertonPrice prices the chain option under the Merton jump-diffusion model
+func (chain *OptionChainCalculator) MertonPrice(assetPrice, strikePrice, daysToExpiry float64, position *OptionPosition) error {
+	if chain.merton == nil {
+		return fmt.Errorf("Merton jumps are not set")
+	}
+	jumps := *chain.merton
+	return chain.repricedPosition(assetPrice, strikePrice, daysToExpiry, chain.Volatility, position,
+		func(assetPrice, daysToExpiry, volatility, riskFreeRate float64) (float64, error) {
+			return jumps.Price(chain.optionType, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, chain.DividendYield)
+		})
+}
+
+// KouPrice prices the chain option under the Kou double-exponential jump model
+func (chain *OptionChainCalculator) KouPrice(assetPrice, strikePrice, daysToExpiry float64, position *OptionPosition) error {
+	if chain.kou == nil {
+		return fmt.Errorf("Kou jumps are not set")
+	}
+	if assetPrice <= 0.0 || strikePrice <= 0.0 {
+		return fmt.Errorf("asset and strike prices must be > 0, got %v/%v", assetPrice, strikePrice)
+	}
+	jumps := *chain.kou
+	return chain.repricedPosition(assetPrice, strikePrice, daysToExpiry, chain.Volatility, position,
+		func(assetPrice, daysToExpiry, volatility, riskFreeRate float64) (float64, error) {
+			expansion, err := chain.expansionAt(kouKey{jumps, volatility, daysToExpiry, riskFreeRate}, func() (*cosExpansion, error) {
+				return jumps.expansion(daysToExpiry/365, volatility, riskFreeRate, chain.DividendYield)
+			})
+			if err != nil {
+				return 0.0, err
+			}
+			return expansion.price(chain.optionType, assetPrice, strikePrice), nil
+		})
+}
+
+// repricedPosition prices an option with a model that has no closed-form Greeks.
+// Delta and gamma come from bumping the asset price; theta, vega and rho from
+// repricing a day closer to expiry, at a bumped volatility and at bumped rates.
+func (chain *OptionChainCalculator) repricedPosition(assetPrice, strikePrice, daysToExpiry, volatility float64, position *OptionPosition,
+	reprice func(assetPrice, daysToExpiry, volatility, riskFreeRate float64) (float64, error),
+) error {
+	riskFreeRate := chain.RateAt(daysToExpiry)
+	price, err := reprice(assetPrice, daysToExpiry, volatility, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	position.Price = price
+	position.Strike = strikePrice
+	position.DaysToExpiry = daysToExpiry
+	if !chain.WithGreeks {
+		return nil
+	}
+
+	var greeks Greeks
+	bump := assetPrice * jumpSpotBump
+	up, err := reprice(assetPrice+bump, daysToExpiry, volatility, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	down, err := reprice(assetPrice-bump, daysToExpiry, volatility, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	greeks.Delta = (up - down) / (2.0 * bump)
+	greeks.Gamma = (up - 2.0*price + down) / (bump * bump)
+
+	dayBump := math.Min(latticeDayBump, daysToExpiry/2.0)
+	earlier, err := reprice(assetPrice, daysToExpiry-dayBump, volatility, chain.RateAt(daysToExpiry-dayBump))
+	if err != nil {
+		return err
+	}
+	greeks.Theta = (earlier - price) / dayBump
+
+	volatilityBump := math.Min(latticeVolatilityBump, volatility/2.0)
+	volatilityUp, err := reprice(assetPrice, daysToExpiry, volatility+volatilityBump, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	volatilityDown, err := reprice(assetPrice, daysToExpiry, volatility-volatilityBump, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	greeks.Vega = (volatilityUp - volatilityDown) / (2.0 * volatilityBump) / 100.0
+
+	rateUp, err := reprice(assetPrice, daysToExpiry, volatility, riskFreeRate+latticeRateBump)
+	if err != nil {
+		return err
+	}
+	rateDown, err := reprice(assetPrice, daysToExpiry, volatility, riskFreeRate-latticeRateBump)
+	if err != nil {
+		return err
+	}
+	greeks.Rho = (rateUp - rateDown) / (2.0 * latticeRateBump) / 100.0
+	position.Greeks = greeks
+	return nil
+}
@@ -0,0 +1,93 @@
+package option
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// Kou's (2002) example of a six month call struck at 98 on an asset at 100
+func TestKouReference(t *testing.T) {
+	jumps := KouJumps{Intensity: 1, UpProbability: 0.4, UpMean: 0.1, DownMean: 0.2}
+	got, err := jumps.Price(Call, 100, 98, 182.5, 0.16, 0.05, 0.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got-9.14732) > 5e-6 {
+		t.Errorf("got %.6f, want 9.14732", got)
+	}
+}
+
+// Without jumps both models are Black-Scholes
+func TestJumpsWithoutJumps(t *testing.T) {
+	for _, optionType := range []int{Call, Put} {
+		for _, strikePrice := range []float64{80, 100, 120} {
+			want := blackScholesValue(optionType, 100, strikePrice, 180.0/365, 0.2, 0.05, 0.02)
+			merton, err := (&MertonJumps{Mean: -0.1, Volatility: 0.15}).Price(optionType, 100, strikePrice, 180, 0.2, 0.05, 0.02)
+			if err != nil {
+				t.Fatal(err)
+			}
+			kou, err := (&KouJumps{UpProbability: 0.5, UpMean: 0.1, DownMean: 0.1}).Price(optionType, 100, strikePrice, 180, 0.2, 0.05, 0.02)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(merton-want) > 1e-10 || math.Abs(kou-want) > 1e-8 {
+				t.Errorf("type %d K=%v: got Merton %v and Kou %v, want %v", optionType, strikePrice, merton, kou, want)
+			}
+		}
+	}
+}
+
+// Both models against a simulation of their jumps.  Each path draws the normal
+// and the jumps of the log asset price at expiry directly.
+func TestJumpsSimulated(t *testing.T) {
+	const assetPrice, strikePrice, volatility, riskFreeRate, dividendYield, years, paths = 100.0, 95.0, 0.2, 0.05, 0.02, 0.5, 1_000_000
+	merton := MertonJumps{Intensity: 2, Mean: -0.1, Volatility: 0.15}
+	kou := KouJumps{Intensity: 1, UpProbability: 0.4, UpMean: 0.1, DownMean: 0.2}
+	up, down := 1.0/kou.UpMean, 1.0/kou.DownMean
+	tests := []struct {
+		name      string
+		price     func() (float64, error)
+		meanJump  float64
+		intensity float64
+		jump      func(rng *rand.Rand) float64
+	}{
+		{"Merton", func() (float64, error) {
+			return merton.Price(Put, assetPrice, strikePrice, years*365, volatility, riskFreeRate, dividendYield)
+		}, math.Exp(merton.Mean+merton.Volatility*merton.Volatility/2.0) - 1.0, merton.Intensity, func(rng *rand.Rand) float64 {
+			return merton.Mean + merton.Volatility*rng.NormFloat64()
+		}},
+		{"Kou", func() (float64, error) {
+			return kou.Price(Put, assetPrice, strikePrice, years*365, volatility, riskFreeRate, dividendYield)
+		}, kou.UpProbability*up/(up-1.0) + (1.0-kou.UpProbability)*down/(down+1.0) - 1.0, kou.Intensity, func(rng *rand.Rand) float64 {
+			if rng.Float64() < kou.UpProbability {
+				return kou.UpMean * rng.ExpFloat64()
+			}
+			return -kou.DownMean * rng.ExpFloat64()
+		}},
+	}
+	for _, test := range tests {
+		want, err := test.price()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rng := rand.New(rand.NewPCG(1, 2))
+		drift := (riskFreeRate - dividendYield - volatility*volatility/2.0 - test.intensity*test.meanJump) * years
+		sum, sumSq := 0.0, 0.0
+		for range paths {
+			x := drift + volatility*math.Sqrt(years)*rng.NormFloat64()
+			// Jumps arrive at exponentially distributed intervals
+			for arrival := rng.ExpFloat64() / test.intensity; arrival < years; arrival += rng.ExpFloat64() / test.intensity {
+				x += test.jump(rng)
+			}
+			payoff := math.Exp(-riskFreeRate*years) * math.Max(strikePrice-assetPrice*math.Exp(x), 0.0)
+			sum += payoff
+			sumSq += payoff * payoff
+		}
+		mean := sum / paths
+		standardError := math.Sqrt((sumSq/paths - mean*mean) / paths)
+		if math.Abs(mean-want) > monteCarloErrors*standardError {
+			t.Errorf("%s: simulated %v ± %v, priced %v", test.name, mean, standardError, want)
+		}
+	}
+}
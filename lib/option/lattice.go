@@ -0,0 +1,231 @@
+package option
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	DefaultLatticeSteps = 100
+	MaxLatticeSteps     = 5000
+	// Bump sizes for lattice Greeks that cannot be read off the tree
+	latticeVolatilityBump = 0.01
+	latticeRateBump       = 0.0001
+	latticeDayBump        = 1.0
+)
+
+// latticeValue carries the root price with the first two steps of the tree,
+// from which delta and gamma are read
+type latticeValue struct {
+	price  float64
+	step1  [2]float64
+	step2  [3]float64
+	spots1 [2]float64
+	spots2 [3]float64
+}
+
+func intrinsicValue(optionType int, assetPrice, strikePrice float64) float64 {
+	if optionType == Call {
+		return math.Max(assetPrice-strikePrice, 0.0)
+	}
+	return math.Max(strikePrice-assetPrice, 0.0)
+}
+
+// Peizer-Pratt method 2 inversion used by Leisen-Reimer to map a normal
+// quantile onto a binomial probability
+func peizerPratt(z float64, steps int) float64 {
+	n := float64(steps)
+	x := z / (n + 1.0/3.0 + 0.1/(n+1.0))
+	root := 0.5 * math.Sqrt(1.0-math.Exp(-x*x*(n+1.0/6.0)))
+	if z < 0 {
+		return 0.5 - root
+	}
+	return 0.5 + root
+}
+
+// SetExerciseStyle selects European (Black-Scholes) or American (lattice,
+// finite-difference or approximation) pricing for the chain.  Steps are the time
+// steps of the lattice or grid, which the approximations do without.
+// Leisen-Reimer requires an odd step count, so even counts are rounded up.
+func (chain *OptionChainCalculator) SetExerciseStyle(exerciseStyle, latticeModel, steps int) error {
+	if exerciseStyle != European && exerciseStyle != American {
+		return fmt.Errorf("unrecognized exerciseStyle %d", exerciseStyle)
+	}
+	if latticeModel < LatticeCRR || latticeModel > BjerksundStensland {
+		return fmt.Errorf("unrecognized latticeModel %d", latticeModel)
+	}
+	if steps < 2 || steps > MaxLatticeSteps {
+		return fmt.Errorf("lattice steps must be between 2 and %d", MaxLatticeSteps)
+	}
+	if latticeModel == LatticeLeisenReimer && steps%2 == 0 {
+		steps++
+	}
+	chain.exerciseStyle = exerciseStyle
+	chain.latticeModel = latticeModel
+	chain.latticeSteps = steps
+	chain.selectPricer()
+	chain.resetCaches()
+	return chain.checkModel()
+}
+
+// binomialLattice builds the tree on the escrowed asset price, adding back the
+// value of dividends still to go ex at each step to find the exercisable spot
+func (chain *OptionChainCalculator) binomialLattice(assetPrice, strikePrice, yearsToExpiry, volatility, riskFreeRate float64) (*latticeValue, error) {
+	steps := chain.latticeSteps
+	dt := yearsToExpiry / float64(steps)
+	// A futures price costs nothing to carry
+	carry := riskFreeRate - chain.DividendYield
+	if chain.model == Black76 {
+		carry = 0.0
+	}
+	growth := math.Exp(carry * dt)
+	discount := math.Exp(-riskFreeRate * dt)
+
+	daysToExpiry := yearsToExpiry * 365
+	escrowed := make([]float64, steps+1)
+	for step := range escrowed {
+		escrowed[step] = chain.dividendsPresentValue(float64(step)*dt*365, daysToExpiry)
+	}
+	treeAssetPrice := assetPrice - escrowed[0]
+	if treeAssetPrice <= 0.0 {
+		return nil, fmt.Errorf(
+			"asset price %v does not cover dividends worth %v before expiry in %v days",
+			assetPrice, escrowed[0], daysToExpiry,
+		)
+	}
+
+	var up, down, p float64
+	switch chain.latticeModel {
+	case LatticeCRR:
+		up = math.Exp(volatility * math.Sqrt(dt))
+		down = 1.0 / up
+		p = (growth - down) / (up - down)
+	case LatticeLeisenReimer:
+		volatilityAdjustment := volatility * math.Sqrt(yearsToExpiry)
+		d1 := (math.Log(treeAssetPrice/strikePrice) +
+			(carry+volatility*volatility/2.0)*yearsToExpiry) / volatilityAdjustment
+		d2 := d1 - volatilityAdjustment
+		p = peizerPratt(d2, steps)
+		up = growth * peizerPratt(d1, steps) / p
+		down = (growth - p*up) / (1.0 - p)
+	}
+	if !(p > 0.0 && p < 1.0) || down <= 0.0 {
+		return nil, fmt.Errorf(
+			"lattice probability out of range, op = p/u/d/steps = %v/%v/%v/%v - increase lattice steps",
+			p, up, down, steps,
+		)
+	}
+
+	ratio := up / down
+	values := make([]float64, steps+1)
+	spot := treeAssetPrice * math.Pow(down, float64(steps))
+	for i := 0; i <= steps; i++ {
+		values[i] = intrinsicValue(chain.optionType, spot, strikePrice)
+		spot *= ratio
+	}
+
+	var result latticeValue
+	american := chain.exerciseStyle == American
+	for step := steps - 1; step >= 0; step-- {
+		spot = treeAssetPrice * math.Pow(down, float64(step))
+		for i := 0; i <= step; i++ {
+			exerciseSpot := spot + escrowed[step]
+			value := discount * (p*values[i+1] + (1.0-p)*values[i])
+			if american {
+				value = math.Max(value, intrinsicValue(chain.optionType, exerciseSpot, strikePrice))
+			}
+			values[i] = value
+			switch step {
+			case 2:
+				result.step2[i], result.spots2[i] = value, exerciseSpot
+			case 1:
+				result.step1[i], result.spots1[i] = value, exerciseSpot
+			}
+			spot *= ratio
+		}
+	}
+	result.price = values[0]
+	return &result, nil
+}
+
+// LatticePrice prices the chain option on a binomial lattice, recording the early
+// exercise premium over the Black-Scholes value
+func (chain *OptionChainCalculator) LatticePrice(assetPrice, strikePrice, daysToExpiry float64, position *OptionPosition) error {
+	var european OptionPosition
+	err := chain.europeanPrice(assetPrice, strikePrice, daysToExpiry, &european)
+	if err != nil {
+		return err
+	}
+
+	volatility, err := chain.VolatilityAt(assetPrice, strikePrice, daysToExpiry)
+	if err != nil {
+		return err
+	}
+	yearsToExpiry := daysToExpiry / 365
+	lattice, err := chain.binomialLattice(assetPrice, strikePrice, yearsToExpiry, volatility, chain.RateAt(daysToExpiry))
+	if err != nil {
+		return err
+	}
+
+	position.Price = lattice.price
+	position.Strike = strikePrice
+	position.DaysToExpiry = daysToExpiry
+	position.EarlyExercisePremium = math.Max(lattice.price-european.Price, 0.0)
+	if chain.WithGreeks {
+		return chain.latticeGreeks(assetPrice, strikePrice, daysToExpiry, volatility, lattice, position)
+	}
+	return nil
+}
+
+// latticeGreeks reads delta and gamma off the tree and bumps the remaining inputs
+// from the volatility the option was priced at
+func (chain *OptionChainCalculator) latticeGreeks(assetPrice, strikePrice, daysToExpiry, volatility float64, lattice *latticeValue, position *OptionPosition) error {
+	reprice := func(days, volatility, riskFreeRate float64) (float64, error) {
+		bumped, err := chain.binomialLattice(assetPrice, strikePrice, days/365, volatility, riskFreeRate)
+		if err != nil {
+			return 0.0, err
+		}
+		return bumped.price, nil
+	}
+
+	var greeks Greeks
+	greeks.Delta = (lattice.step1[1] - lattice.step1[0]) / (lattice.spots1[1] - lattice.spots1[0])
+	upperDelta := (lattice.step2[2] - lattice.step2[1]) / (lattice.spots2[2] - lattice.spots2[1])
+	lowerDelta := (lattice.step2[1] - lattice.step2[0]) / (lattice.spots2[1] - lattice.spots2[0])
+	greeks.Gamma = (upperDelta - lowerDelta) / (0.5 * (lattice.spots2[2] - lattice.spots2[0]))
+
+	riskFreeRate := chain.RateAt(daysToExpiry)
+	dayBump := math.Min(latticeDayBump, daysToExpiry/2.0)
+	// A day on, the dividends still to go ex are a day closer too
+	later := *chain
+	later.Dividends = chain.dividendsAfter(dayBump)
+	earlier, err := later.binomialLattice(assetPrice, strikePrice, (daysToExpiry-dayBump)/365, volatility, chain.RateAt(daysToExpiry-dayBump))
+	if err != nil {
+		return err
+	}
+	greeks.Theta = (earlier.price - lattice.price) / dayBump
+
+	volatilityBump := math.Min(latticeVolatilityBump, volatility/2.0)
+	volatilityUp, err := reprice(daysToExpiry, volatility+volatilityBump, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	volatilityDown, err := reprice(daysToExpiry, volatility-volatilityBump, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	greeks.Vega = (volatilityUp - volatilityDown) / (2.0 * volatilityBump) / 100.0
+
+	rateUp, err := reprice(daysToExpiry, volatility, riskFreeRate+latticeRateBump)
+	if err != nil {
+		return err
+	}
+	rateDown, err := reprice(daysToExpiry, volatility, riskFreeRate-latticeRateBump)
+	if err != nil {
+		return err
+	}
+	greeks.Rho = (rateUp - rateDown) / (2.0 * latticeRateBump) / 100.0
+
+	position.Greeks = greeks
+	return nil
+}
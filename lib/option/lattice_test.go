@@ -0,0 +1,74 @@
+package option
+
+import (
+	"math"
+	"testing"
+)
+
+// American puts against the finite-difference values Longstaff and Schwartz
+// (2001) report in their Table 1, which are good to about a cent
+func TestLatticeAmericanPut(t *testing.T) {
+	tests := []struct {
+		assetPrice, volatility, daysToExpiry, want float64
+	}{
+		{36, 0.2, 365, 4.478},
+		{36, 0.4, 365, 7.101},
+		{40, 0.2, 365, 2.314},
+		{40, 0.2, 730, 2.885},
+		{44, 0.4, 730, 5.647},
+	}
+	for _, latticeModel := range []int{LatticeCRR, LatticeLeisenReimer} {
+		for _, test := range tests {
+			chain := testChain(t, Put, test.volatility, 0.06, withAmerican(latticeModel, 2000))
+			var position OptionPosition
+			if err := chain.calculatePrice(test.assetPrice, 40, test.daysToExpiry, &position); err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(position.Price-test.want) > 0.01 {
+				t.Errorf("model %d S=%v vol=%v days=%v: got %.4f, want %.3f",
+					latticeModel, test.assetPrice, test.volatility, test.daysToExpiry, position.Price, test.want)
+			}
+			if position.EarlyExercisePremium <= 0.0 {
+				t.Errorf("model %d S=%v: early exercise premium %v, want > 0", latticeModel, test.assetPrice, position.EarlyExercisePremium)
+			}
+		}
+	}
+}
+
+// Without dividends an American call is worth its European price
+func TestLatticeAmericanCallWithoutDividends(t *testing.T) {
+	chain := testChain(t, Call, 0.25, 0.05, withAmerican(LatticeLeisenReimer, 501))
+	var position OptionPosition
+	if err := chain.calculatePrice(100, 100, 180, &position); err != nil {
+		t.Fatal(err)
+	}
+	want := blackScholesValue(Call, 100, 100, 180.0/365, 0.25, 0.05, 0.0)
+	if math.Abs(position.Price-want) > 1e-4 {
+		t.Errorf("got %.6f, want the Black-Scholes price %.6f", position.Price, want)
+	}
+}
+
+// Theta reprices a day on, when the dividends still to go ex are a day closer
+func TestLatticeThetaShiftsDividends(t *testing.T) {
+	chain := testChain(t, Call, 0.25, 0.05, withAmerican(LatticeCRR, 200))
+	chain.WithGreeks = true
+	if err := chain.SetDividends(0.0, []Dividend{{10, 2}, {100, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	var position OptionPosition
+	if err := chain.calculatePrice(100, 95, 60, &position); err != nil {
+		t.Fatal(err)
+	}
+
+	later := testChain(t, Call, 0.25, 0.05, withAmerican(LatticeCRR, 200))
+	if err := later.SetDividends(0.0, []Dividend{{9, 2}, {99, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	var tomorrow OptionPosition
+	if err := later.calculatePrice(100, 95, 59, &tomorrow); err != nil {
+		t.Fatal(err)
+	}
+	if want := tomorrow.Price - position.Price; math.Abs(position.Greeks.Theta-want) > 1e-12 {
+		t.Errorf("theta %v, want %v", position.Greeks.Theta, want)
+	}
+}
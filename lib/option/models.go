@@ -0,0 +1,232 @@
+package option
+
+import (
+	"fmt"
+	"math"
+)
+
+// Pricing models for European exercise.  Black-76 and Bachelier price options on
+// a futures or forward price, so the asset price axis is the futures price.
+// Garman-Kohlhagen prices currency options on the spot rate, with the foreign
+// interest rate in place of a dividend yield.  Heston gives the variance of the
+// asset price its own mean-reverting random walk.  Merton and Kou add jumps to
+// the asset price, lognormal and double-exponential in size respectively.
+const (
+	BlackScholes = iota
+	Black76
+	Bachelier
+	GarmanKohlhagen
+	Heston
+	Merton
+	Kou
+)
+
+var modelNames = map[int]string{
+	BlackScholes:    "Black-Scholes",
+	Black76:         "Black-76",
+	Bachelier:       "Bachelier",
+	GarmanKohlhagen: "Garman-Kohlhagen",
+	Heston:          "Heston",
+	Merton:          "Merton",
+	Kou:             "Kou",
+}
+
+// SetModel selects the pricing model.  Under Bachelier volatility is the normal
+// (absolute) volatility of the price per square root of a year, and asset and
+// strike prices may be zero or negative.  Futures carry no dividends, and
+// Bachelier has no lattice for American exercise.
+func (chain *OptionChainCalculator) SetModel(model int) error {
+	switch model {
+	case BlackScholes, GarmanKohlhagen:
+		if chain.payoff != Vanilla {
+			chain.europeanPrice = chain.PayoffPrice
+		} else if chain.optionType == Call {
+			chain.europeanPrice = chain.BlackScholesCall
+		} else {
+			chain.europeanPrice = chain.BlackScholesPut
+		}
+	case Black76:
+		chain.europeanPrice = chain.Black76Price
+	case Bachelier:
+		chain.europeanPrice = chain.BachelierPrice
+	case Heston:
+		if chain.heston == nil {
+			return fmt.Errorf("the Heston model needs parameters - use SetHeston")
+		}
+		chain.europeanPrice = chain.HestonPrice
+	case Merton:
+		if chain.merton == nil {
+			return fmt.Errorf("the Merton model needs jumps - use SetMertonJumps")
+		}
+		chain.europeanPrice = chain.MertonPrice
+	case Kou:
+		if chain.kou == nil {
+			return fmt.Errorf("the Kou model needs jumps - use SetKouJumps")
+		}
+		chain.europeanPrice = chain.KouPrice
+	default:
+		return fmt.Errorf("unrecognized model %d", model)
+	}
+	chain.model = model
+	chain.selectPricer()
+	chain.resetCaches()
+	return chain.checkModel()
+}
+
+// checkModel rejects settings the chain's model cannot price
+func (chain *OptionChainCalculator) checkModel() error {
+	if chain.model != GarmanKohlhagen && (chain.deltaConvention != SpotDelta || chain.premiumConvention != DomesticPips) {
+		return fmt.Errorf("delta and premium conventions apply to the Garman-Kohlhagen model")
+	}
+	if chain.payoff != Vanilla {
+		switch {
+		case chain.model != BlackScholes:
+			return fmt.Errorf("digital, gap and power options are priced under the Black-Scholes model only")
+		case chain.exerciseStyle == American, chain.barrier != nil:
+			return fmt.Errorf("digital, gap and power options are European without barriers")
+		}
+	}
+	if chain.barrier != nil {
+		switch {
+		case chain.model == Bachelier:
+			return fmt.Errorf("barrier options are not available under the Bachelier model")
+		case chain.exerciseStyle == American && !chain.barrier.knockOut():
+			return fmt.Errorf("american exercise is not available for knock-in options")
+		case len(chain.Dividends) > 0:
+			return fmt.Errorf("barrier options do not take discrete dividends - use a dividend yield")
+		}
+	}
+	if chain.exerciseStyle == American && chain.approximates() {
+		switch {
+		case chain.barrier != nil:
+			return fmt.Errorf("the Barone-Adesi-Whaley and Bjerksund-Stensland approximations price options without barriers - use a lattice or grid")
+		case len(chain.Dividends) > 0:
+			return fmt.Errorf("the Barone-Adesi-Whaley and Bjerksund-Stensland approximations take a dividend yield but not discrete dividends")
+		}
+	}
+	switch chain.model {
+	case BlackScholes:
+		return nil
+	case GarmanKohlhagen:
+		if len(chain.Dividends) > 0 {
+			return fmt.Errorf("discrete dividends do not apply to currency options")
+		}
+		return nil
+	case Heston, Merton, Kou:
+		name := modelNames[chain.model]
+		switch {
+		case len(chain.Dividends) > 0:
+			return fmt.Errorf("the %s model takes a dividend yield but not discrete dividends", name)
+		case chain.exerciseStyle == American, chain.barrier != nil:
+			return fmt.Errorf("the %s model prices European options without barriers only", name)
+		}
+		return nil
+	}
+	if chain.DividendYield != 0.0 || len(chain.Dividends) > 0 {
+		return fmt.Errorf("dividends do not apply to options on futures")
+	}
+	if chain.model == Bachelier && chain.exerciseStyle == American {
+		return fmt.Errorf("american exercise is not available under the Bachelier model")
+	}
+	return nil
+}
+
+// onFutures reports whether the chain's model prices options on a futures price
+func (chain *OptionChainCalculator) onFutures() bool {
+	return chain.model == Black76 || chain.model == Bachelier
+}
+
+// futuresTerms are the inputs shared by the futures option models for one option
+func (chain *OptionChainCalculator) futuresTerms(futuresPrice, strikePrice, daysToExpiry float64) (float64, float64, float64, float64, error) {
+	yearsToExpiry := daysToExpiry / 365
+	if yearsToExpiry <= 0.0 {
+		return 0.0, 0.0, 0.0, 0.0, fmt.Errorf("days to expiry must be > 0, got %v", daysToExpiry)
+	}
+	volatility, err := chain.VolatilityAt(futuresPrice, strikePrice, daysToExpiry)
+	if err != nil {
+		return 0.0, 0.0, 0.0, 0.0, err
+	}
+	if !(volatility > 0.0) {
+		return 0.0, 0.0, 0.0, 0.0, fmt.Errorf("volatility must be > 0, got %v", volatility)
+	}
+	riskFreeRate := chain.RateAt(daysToExpiry)
+	return yearsToExpiry, volatility, riskFreeRate, math.Exp(-riskFreeRate * yearsToExpiry), nil
+}
+
+// Black76Price prices an option on a futures price: Black-Scholes on the forward
+// with no drift, with the premium discounted to today
+func (chain *OptionChainCalculator) Black76Price(futuresPrice, strikePrice, daysToExpiry float64, position *OptionPosition) error {
+	yearsToExpiry, volatility, riskFreeRate, discount, err := chain.futuresTerms(futuresPrice, strikePrice, daysToExpiry)
+	if err != nil {
+		return err
+	}
+	if futuresPrice <= 0.0 || strikePrice <= 0.0 {
+		return fmt.Errorf("Black-76 needs futures and strike prices > 0, got %v/%v", futuresPrice, strikePrice)
+	}
+	sqrtT := math.Sqrt(yearsToExpiry)
+	volatilityAdjustment := volatility * sqrtT
+	d1 := (math.Log(futuresPrice/strikePrice) + volatilityAdjustment*volatilityAdjustment/2.0) / volatilityAdjustment
+	d2 := d1 - volatilityAdjustment
+
+	sign := 1.0
+	if chain.optionType == Put {
+		sign = -1.0
+	}
+	price := sign * discount * (futuresPrice*normalizedCDF(sign*d1) - strikePrice*normalizedCDF(sign*d2))
+	position.Price = price
+	position.Strike = strikePrice
+	position.DaysToExpiry = daysToExpiry
+	if chain.WithGreeks {
+		pdfD1 := normalizedPDF(d1)
+		position.Greeks = Greeks{
+			Delta: sign * discount * normalizedCDF(sign*d1),
+			Gamma: discount * pdfD1 / (futuresPrice * volatilityAdjustment),
+			Theta: (-discount*futuresPrice*pdfD1*volatility/(2.0*sqrtT) + riskFreeRate*price) / 365.0,
+			Vega:  discount * futuresPrice * pdfD1 * sqrtT / 100.0,
+			Rho:   -yearsToExpiry * price / 100.0,
+		}
+	}
+	return nil
+}
+
+// BachelierPrice prices an option on a futures price that moves normally rather
+// than lognormally, so that it can go negative.  Vega is per hundredth of a unit
+// of normal volatility, as the other models' is per volatility point.
+func (chain *OptionChainCalculator) BachelierPrice(futuresPrice, strikePrice, daysToExpiry float64, position *OptionPosition) error {
+	yearsToExpiry, volatility, riskFreeRate, discount, err := chain.futuresTerms(futuresPrice, strikePrice, daysToExpiry)
+	if err != nil {
+		return err
+	}
+	sqrtT := math.Sqrt(yearsToExpiry)
+	deviation := volatility * sqrtT
+	d := (futuresPrice - strikePrice) / deviation
+
+	sign := 1.0
+	if chain.optionType == Put {
+		sign = -1.0
+	}
+	pdfD := normalizedPDF(d)
+	price := discount * (sign*(futuresPrice-strikePrice)*normalizedCDF(sign*d) + deviation*pdfD)
+	position.Price = price
+	position.Strike = strikePrice
+	position.DaysToExpiry = daysToExpiry
+	if chain.WithGreeks {
+		position.Greeks = Greeks{
+			Delta: sign * discount * normalizedCDF(sign*d),
+			Gamma: discount * pdfD / deviation,
+			Theta: (-discount*volatility*pdfD/(2.0*sqrtT) + riskFreeRate*price) / 365.0,
+			Vega:  discount * sqrtT * pdfD / 100.0,
+			Rho:   -yearsToExpiry * price / 100.0,
+		}
+	}
+	return nil
+}
+
+// NormalDeviationAxis resolves a number of standard deviations of the price over
+// daysToExpiry, at the given normal volatility, to asset prices around spot
+func NormalDeviationAxis(spot, volatility, daysToExpiry float64, deviations Axis) *RelativeAxis {
+	deviation := volatility * math.Sqrt(daysToExpiry/365)
+	return &RelativeAxis{Points: deviations, Resolve: func(deviations float64) (float64, error) {
+		return spot + deviations*deviation, nil
+	}}
+}
@@ -0,0 +1,108 @@
+package option
+
+import (
+	"math"
+	"testing"
+)
+
+// Black-76 against Haug's example of an at-the-money option on a future at 19
+func TestBlack76Reference(t *testing.T) {
+	for _, optionType := range []int{Call, Put} {
+		chain := testChain(t, optionType, 0.28, 0.1, withModel(Black76), withGreeks)
+		var position OptionPosition
+		if err := chain.Black76Price(19, 19, 0.75*365, &position); err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(position.Price-1.7011) > 5e-5 {
+			t.Errorf("type %d: got %.4f, want 1.7011", optionType, position.Price)
+		}
+	}
+}
+
+// An at-the-money Bachelier option is worth the discounted deviation over the
+// square root of 2 pi, and calls less puts are the discounted forward less the
+// strike, at negative prices too
+func TestBachelierReference(t *testing.T) {
+	const volatility, riskFreeRate, daysToExpiry = 10.0, 0.05, 182.5
+	discount := math.Exp(-riskFreeRate * daysToExpiry / 365)
+	deviation := volatility * math.Sqrt(daysToExpiry/365)
+	for _, optionType := range []int{Call, Put} {
+		chain := testChain(t, optionType, volatility, riskFreeRate, withModel(Bachelier), withGreeks)
+		var position OptionPosition
+		if err := chain.BachelierPrice(-3, -3, daysToExpiry, &position); err != nil {
+			t.Fatal(err)
+		}
+		if want := discount * deviation / math.Sqrt(2.0*math.Pi); math.Abs(position.Price-want) > 1e-12 {
+			t.Errorf("type %d: got %.12f, want %.12f", optionType, position.Price, want)
+		}
+	}
+
+	for _, point := range [][2]float64{{-5, 2}, {0, -4}, {3, 3.5}} {
+		futuresPrice, strikePrice := point[0], point[1]
+		var call, put OptionPosition
+		if err := testChain(t, Call, volatility, riskFreeRate, withModel(Bachelier), withGreeks).BachelierPrice(futuresPrice, strikePrice, daysToExpiry, &call); err != nil {
+			t.Fatal(err)
+		}
+		if err := testChain(t, Put, volatility, riskFreeRate, withModel(Bachelier), withGreeks).BachelierPrice(futuresPrice, strikePrice, daysToExpiry, &put); err != nil {
+			t.Fatal(err)
+		}
+		if want := discount * (futuresPrice - strikePrice); math.Abs(call.Price-put.Price-want) > 1e-12 {
+			t.Errorf("F=%v K=%v: call - put = %.12f, want %.12f", futuresPrice, strikePrice, call.Price-put.Price, want)
+		}
+	}
+}
+
+// Futures model Greeks against central differences, with vega per volatility
+// point in both
+func TestFuturesModelGreeks(t *testing.T) {
+	tests := []struct {
+		name                                             string
+		model                                            int
+		futuresPrice, strikePrice, volatility, priceBump float64
+	}{
+		{"Black76", Black76, 80, 75, 0.3, 1e-3},
+		{"Bachelier", Bachelier, 2, 3, 12, 1e-3},
+	}
+	const riskFreeRate, daysToExpiry, bump = 0.04, 90.0, 1e-4
+	for _, test := range tests {
+		for _, optionType := range []int{Call, Put} {
+			price := func(futuresPrice, volatility, daysToExpiry float64) float64 {
+				chain := testChain(t, optionType, volatility, riskFreeRate, withModel(test.model), withGreeks)
+				var position OptionPosition
+				if err := chain.europeanPrice(futuresPrice, test.strikePrice, daysToExpiry, &position); err != nil {
+					t.Fatal(err)
+				}
+				return position.Price
+			}
+			chain := testChain(t, optionType, test.volatility, riskFreeRate, withModel(test.model), withGreeks)
+			var position OptionPosition
+			if err := chain.europeanPrice(test.futuresPrice, test.strikePrice, daysToExpiry, &position); err != nil {
+				t.Fatal(err)
+			}
+			up := price(test.futuresPrice+test.priceBump, test.volatility, daysToExpiry)
+			down := price(test.futuresPrice-test.priceBump, test.volatility, daysToExpiry)
+			want := Greeks{
+				Delta: (up - down) / (2 * test.priceBump),
+				Gamma: (up - 2*position.Price + down) / (test.priceBump * test.priceBump),
+				Theta: (price(test.futuresPrice, test.volatility, daysToExpiry-bump) -
+					price(test.futuresPrice, test.volatility, daysToExpiry+bump)) / (2 * bump),
+				Vega: (price(test.futuresPrice, test.volatility+bump, daysToExpiry) -
+					price(test.futuresPrice, test.volatility-bump, daysToExpiry)) / (2 * bump) / 100,
+			}
+			got := position.Greeks
+			for _, greek := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"delta", got.Delta, want.Delta},
+				{"gamma", got.Gamma, want.Gamma},
+				{"theta", got.Theta, want.Theta},
+				{"vega", got.Vega, want.Vega},
+			} {
+				if math.Abs(greek.got-greek.want) > 1e-5 {
+					t.Errorf("%s type %d %s: got %v, want %v", test.name, optionType, greek.name, greek.got, greek.want)
+				}
+			}
+		}
+	}
+}
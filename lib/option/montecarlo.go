@@ -0,0 +1,179 @@
+package option
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Samples simulated per batch.  Each batch draws from its own generator seeded
+// from the run's seed and the batch number, so results do not depend on how
+// batches are spread over workers.
+const monteCarloBatch = 1024
+
+// MonteCarlo simulates lognormal asset price paths to price path-dependent
+// options.  With Antithetic each path is paired with its mirror image, drawn
+// from the negated normals; with ControlVariate the estimate is corrected by a
+// related payoff of the same paths whose price is known in closed form.
+type MonteCarlo struct {
+	Paths          int
+	Steps          int
+	Seed           uint64
+	Antithetic     bool
+	ControlVariate bool
+	// Number of goroutines simulating paths; GOMAXPROCS when zero
+	Workers int
+}
+
+// MonteCarloResult is a simulated price with its standard error.  Paths counts
+// both paths of an antithetic pair.
+type MonteCarloResult struct {
+	Price         float64
+	StandardError float64
+	Paths         int
+}
+
+// moments accumulates the means and co-moments of payoffs and control payoffs
+type moments struct {
+	count    float64
+	mean     float64
+	meanC    float64
+	sumSq    float64
+	sumSqC   float64
+	sumCross float64
+}
+
+// add one sample, by Welford's method
+func (m *moments) add(value, control float64) {
+	m.count++
+	delta, deltaC := value-m.mean, control-m.meanC
+	m.mean += delta / m.count
+	m.meanC += deltaC / m.count
+	m.sumSq += delta * (value - m.mean)
+	m.sumSqC += deltaC * (control - m.meanC)
+	m.sumCross += delta * (control - m.meanC)
+}
+
+// merge in the moments of another set of samples (Chan, Golub and LeVeque)
+func (m *moments) merge(other *moments) {
+	if other.count == 0 {
+		return
+	}
+	count := m.count + other.count
+	delta, deltaC := other.mean-m.mean, other.meanC-m.meanC
+	weight := m.count * other.count / count
+	m.sumSq += other.sumSq + delta*delta*weight
+	m.sumSqC += other.sumSqC + deltaC*deltaC*weight
+	m.sumCross += other.sumCross + delta*deltaC*weight
+	m.mean += delta * other.count / count
+	m.meanC += deltaC * other.count / count
+	m.count = count
+}
+
+func (mc *MonteCarlo) workers() int {
+	if mc.Workers > 0 {
+		return mc.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// samples is the number of independent samples: pairs of paths when antithetic
+func (mc *MonteCarlo) samples() int {
+	if mc.Antithetic {
+		return (mc.Paths + 1) / 2
+	}
+	return mc.Paths
+}
+
+// Price simulates the option under Black-Scholes dynamics with a continuous
+// dividend yield.  Computation stops early when ctx is cancelled.
+func (mc *MonteCarlo) Price(ctx context.Context, exotic *ExoticOption, assetPrice, volatility, riskFreeRate, dividendYield float64) (MonteCarloResult, error) {
+	if mc.Paths < 2 {
+		return MonteCarloResult{}, fmt.Errorf("paths must be at least 2, got %d", mc.Paths)
+	}
+	model, err := newExoticModel(exotic, assetPrice, volatility, riskFreeRate, dividendYield, mc.Steps)
+	if err != nil {
+		return MonteCarloResult{}, err
+	}
+
+	samples := mc.samples()
+	batches := (samples + monteCarloBatch - 1) / monteCarloBatch
+	results := make([]moments, batches)
+	var next atomic.Int64
+	var wait sync.WaitGroup
+	for w := 0; w < min(mc.workers(), batches); w++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for ctx.Err() == nil {
+				batch := int(next.Add(1) - 1)
+				if batch >= batches {
+					return
+				}
+				count := min(monteCarloBatch, samples-batch*monteCarloBatch)
+				mc.simulate(model, rand.New(rand.NewPCG(mc.Seed, uint64(batch))), count, &results[batch])
+			}
+		}()
+	}
+	wait.Wait()
+	if err := ctx.Err(); err != nil {
+		return MonteCarloResult{}, err
+	}
+
+	// Batches are merged in order so the sums are rounded the same way every run
+	var total moments
+	for i := range results {
+		total.merge(&results[i])
+	}
+	price, variance := total.mean, total.sumSq/(total.count-1)
+	if mc.ControlVariate && total.sumSqC > 0.0 {
+		beta := total.sumCross / total.sumSqC
+		price -= beta * (total.meanC - model.controlMean)
+		variance = (total.sumSq - beta*total.sumCross) / (total.count - 1)
+	}
+	paths := samples
+	if mc.Antithetic {
+		paths *= 2
+	}
+	return MonteCarloResult{
+		Price:         price,
+		StandardError: math.Sqrt(math.Max(variance, 0.0) / total.count),
+		Paths:         paths,
+	}, nil
+}
+
+// simulate adds count samples drawn from rng to result
+func (mc *MonteCarlo) simulate(model *exoticModel, rng *rand.Rand, count int, result *moments) {
+	steps := mc.Steps
+	dt := model.option.DaysToExpiry / 365 / float64(steps)
+	drift := (model.riskFreeRate - model.dividendYield - model.volatility*model.volatility/2.0) * dt
+	diffusion := model.volatility * math.Sqrt(dt)
+
+	normals := make([]float64, steps)
+	path := make([]float64, steps+1)
+	walk := func(sign float64) (float64, float64) {
+		path[0] = model.assetPrice
+		logPrice := math.Log(model.assetPrice)
+		for step, normal := range normals {
+			logPrice += drift + sign*diffusion*normal
+			path[step+1] = math.Exp(logPrice)
+		}
+		return model.presentValue(path), model.control(path)
+	}
+
+	for i := 0; i < count; i++ {
+		for step := range normals {
+			normals[step] = rng.NormFloat64()
+		}
+		value, control := walk(1.0)
+		if mc.Antithetic {
+			mirror, mirrorControl := walk(-1.0)
+			value, control = (value+mirror)/2.0, (control+mirrorControl)/2.0
+		}
+		result.add(value, control)
+	}
+}
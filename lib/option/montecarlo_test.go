@@ -0,0 +1,94 @@
+package option
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// Standard errors a simulated price may stray from its closed form
+const monteCarloErrors = 4.0
+
+// A geometric average of one fixing is the price at expiry
+func TestGeometricAsianPrice(t *testing.T) {
+	for _, optionType := range []int{Call, Put} {
+		got := GeometricAsianPrice(optionType, 100, 95, 180, 0.3, 0.05, 0.02, 1)
+		if want := blackScholesValue(optionType, 100, 95, 180.0/365, 0.3, 0.05, 0.02); math.Abs(got-want) > 1e-12 {
+			t.Errorf("type %d: got %v, want %v", optionType, got, want)
+		}
+	}
+}
+
+// Simulated prices against closed forms: a geometric Asian, and a down-and-out
+// and a down-and-in call, which add up to the European call
+func TestMonteCarloReference(t *testing.T) {
+	const assetPrice, strikePrice, volatility, riskFreeRate, dividendYield = 100.0, 100.0, 0.2, 0.05, 0.01
+	mc := MonteCarlo{Paths: 100_000, Steps: 12, Seed: 1, Antithetic: true}
+	asian := ExoticOption{Kind: AsianOption, OptionType: Call, Strike: strikePrice, DaysToExpiry: 365, Average: GeometricAverage}
+	result, err := mc.Price(context.Background(), &asian, assetPrice, volatility, riskFreeRate, dividendYield)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := GeometricAsianPrice(Call, assetPrice, strikePrice, 365, volatility, riskFreeRate, dividendYield, 12); math.Abs(result.Price-want) > monteCarloErrors*result.StandardError {
+		t.Errorf("geometric Asian: got %v ± %v, want %v", result.Price, result.StandardError, want)
+	}
+
+	// Reiner and Rubinstein's down-and-in call for a barrier below the strike
+	const barrier, years = 90.0, 1.0
+	deviation := volatility * math.Sqrt(years)
+	lambda := (riskFreeRate - dividendYield + volatility*volatility/2.0) / (volatility * volatility)
+	y := math.Log(barrier*barrier/(assetPrice*strikePrice))/deviation + lambda*deviation
+	downAndIn := assetPrice*math.Exp(-dividendYield*years)*math.Pow(barrier/assetPrice, 2.0*lambda)*normalizedCDF(y) -
+		strikePrice*math.Exp(-riskFreeRate*years)*math.Pow(barrier/assetPrice, 2.0*lambda-2.0)*normalizedCDF(y-deviation)
+	european := blackScholesValue(Call, assetPrice, strikePrice, years, volatility, riskFreeRate, dividendYield)
+
+	mc = MonteCarlo{Paths: 100_000, Steps: 250, Seed: 7, Antithetic: true, ControlVariate: true}
+	prices := make(map[int]float64)
+	for _, test := range []struct {
+		name        string
+		barrierType int
+		want        float64
+	}{
+		{"down-and-out", DownAndOut, european - downAndIn},
+		{"down-and-in", DownAndIn, downAndIn},
+	} {
+		exotic := ExoticOption{Kind: BarrierOption, OptionType: Call, Strike: strikePrice, DaysToExpiry: 365,
+			BarrierType: test.barrierType, Barrier: barrier, ContinuousMonitoring: true}
+		result, err := mc.Price(context.Background(), &exotic, assetPrice, volatility, riskFreeRate, dividendYield)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(result.Price-test.want) > monteCarloErrors*result.StandardError {
+			t.Errorf("%s: got %v ± %v, want %v", test.name, result.Price, result.StandardError, test.want)
+		}
+		prices[test.barrierType] = result.Price
+	}
+	if sum := prices[DownAndOut] + prices[DownAndIn]; math.Abs(sum-european) > 1e-3 {
+		t.Errorf("out + in = %v, want %v", sum, european)
+	}
+}
+
+// The control variate narrows the error, and results do not depend on the
+// number of workers
+func TestMonteCarloVariance(t *testing.T) {
+	asian := ExoticOption{Kind: AsianOption, OptionType: Put, Strike: 105, DaysToExpiry: 180, Average: ArithmeticAverage}
+	price := func(mc MonteCarlo) MonteCarloResult {
+		t.Helper()
+		result, err := mc.Price(context.Background(), &asian, 100, 0.25, 0.04, 0.0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	plain := price(MonteCarlo{Paths: 20_000, Steps: 26, Seed: 3})
+	controlled := price(MonteCarlo{Paths: 20_000, Steps: 26, Seed: 3, Antithetic: true, ControlVariate: true})
+	if controlled.StandardError > plain.StandardError/10 {
+		t.Errorf("standard error %v with the control variate, %v without", controlled.StandardError, plain.StandardError)
+	}
+	if math.Abs(controlled.Price-plain.Price) > monteCarloErrors*plain.StandardError {
+		t.Errorf("got %v with the control variate, %v ± %v without", controlled.Price, plain.Price, plain.StandardError)
+	}
+	if single := price(MonteCarlo{Paths: 20_000, Steps: 26, Seed: 3, Antithetic: true, ControlVariate: true, Workers: 1}); single != controlled {
+		t.Errorf("got %+v on one worker, %+v on several", single, controlled)
+	}
+}
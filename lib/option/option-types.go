@@ -1,5 +1,7 @@
 package option
 
+import "sync"
+
 // DTO objects
 
 const (
@@ -7,6 +9,32 @@ const (
 	Put
 )
 
+// Payoffs at expiry on the call or put side: vanilla, digitals paying cash or
+// the asset, gap options and power options
+const (
+	Vanilla = iota
+	CashOrNothing
+	AssetOrNothing
+	Gap
+	Power
+)
+
+// Exercise styles
+const (
+	European = iota
+	American
+)
+
+// Models for American exercise: binomial lattices, a finite-difference grid, or
+// the Barone-Adesi-Whaley and Bjerksund-Stensland analytic approximations
+const (
+	LatticeCRR = iota
+	LatticeLeisenReimer
+	CrankNicolson
+	BaroneAdesiWhaley
+	BjerksundStensland
+)
+
 type Asset struct {
 	Name       string
 	Volatility float64
@@ -20,13 +48,38 @@ type Option struct {
 	Expiry float64
 }
 
+// Sensitivities of an option price.  Theta is per calendar day, Vega is per
+// volatility point and Rho is per percentage point of the risk-free rate.
+type Greeks struct {
+	Delta float64
+	Gamma float64
+	Theta float64
+	Vega  float64
+	Rho   float64
+}
+
 type OptionPosition struct {
 	Price        float64
 	Strike       float64
 	DaysToExpiry float64
+	Greeks       Greeks
+	// Value of the right to exercise early over the Black-Scholes price; zero for European exercise
+	EarlyExercisePremium float64
+	// Exercising now is worth at least holding, for American exercise
+	ExerciseNow bool
+	// Black-Scholes price at the same volatility, when the chain compares models
+	BlackScholesPrice float64
+	// Price of the vanilla spread replicating a digital, when the chain replicates digitals
+	ReplicationPrice float64
+}
+
+// Discrete cash dividend, going ex after DaysToExDate days
+type Dividend struct {
+	DaysToExDate float64
+	Amount       float64
 }
 
-// For Option Chain calculatins
+// Evenly stepped axis for Option Chain calculations
 type ValueSpan struct {
 	Low  float64
 	High float64
@@ -34,9 +87,14 @@ type ValueSpan struct {
 }
 
 type d1d2Calculation struct {
-	d1            float64
-	d2            float64
-	yearsToExpiry float64
+	d1                   float64
+	d2                   float64
+	yearsToExpiry        float64
+	volatility           float64
+	riskFreeRate         float64
+	volatilityAdjustment float64
+	adjustedAssetPrice   float64
+	dividendDiscount     float64
 }
 
 type priceKey struct {
@@ -45,16 +103,44 @@ type priceKey struct {
 	daysToExpiry float64
 }
 
-type d1d2CalculateFunc func(assetPrice, strikePrice float64) (*d1d2Calculation, error)
+type d1d2CalculateFunc func(assetPrice, strikePrice, volatility float64) (*d1d2Calculation, error)
 type priceCalculatorFunc func(assetPrice, strikePrice, daysToExpiry float64, position *OptionPosition) error
 
 type OptionChainCalculator struct {
-	Volatility              float64
-	RiskFreeRate            float64
-	ExpiryInDays            float64
+	Volatility    float64
+	RiskFreeRate  float64
+	ExpiryInDays  float64
+	DividendYield float64
+	Dividends     []Dividend
+	WithGreeks    bool
+	// Also price each option by Black-Scholes at the volatility VolatilityAt gives
+	WithBlackScholes bool
+	// Number of goroutines computing the chain; GOMAXPROCS when zero
+	Workers                 int
+	optionType              int
+	model                   int
+	deltaConvention         int
+	premiumConvention       int
+	exerciseStyle           int
+	latticeModel            int
+	latticeSteps            int
+	gridPoints              int
+	barrier                 *chainBarrier
+	payoff                  int
+	payoffTerms             PayoffTerms
+	digitalSpread           float64
+	heston                  *HestonParameters
+	merton                  *MertonJumps
+	kou                     *KouJumps
 	calculatePrice          priceCalculatorFunc
-	d1d2CalculateFuncMap    map[float64]d1d2CalculateFunc
-	d1d2CalculationValueMap map[priceKey]d1d2Calculation
+	europeanPrice           priceCalculatorFunc
+	volatilitySurface       VolatilitySource
+	rateCurve               RateSource
+	d1d2CalculateFuncMap    *sync.Map
+	d1d2CalculationValueMap *d1d2Cache
+	gridSlices              *sync.Map
+	expansions              *sync.Map
+	earlyExercises          *sync.Map
 }
 
 type OptionChain [][][]OptionPosition
@@ -0,0 +1,250 @@
+package option
+
+import (
+	"fmt"
+	"math"
+)
+
+// PayoffTerms are the inputs of payoffs other than the vanilla one
+type PayoffTerms struct {
+	Cash     float64 // Paid by cash-or-nothing digitals in the money at expiry
+	Trigger  float64 // Asset price beyond which a gap option pays the asset less its strike
+	Exponent float64 // Power of the asset price in power options
+}
+
+// payoffSensitivities are the price and Greeks of a payoff per unit of the
+// chain's inputs, before scaling to the conventions of Greeks
+type payoffSensitivities struct {
+	price, delta, gamma, dT, dSigma, dRate float64
+}
+
+func (sensitivities payoffSensitivities) plus(scale float64, other payoffSensitivities) payoffSensitivities {
+	return payoffSensitivities{
+		price:  sensitivities.price + scale*other.price,
+		delta:  sensitivities.delta + scale*other.delta,
+		gamma:  sensitivities.gamma + scale*other.gamma,
+		dT:     sensitivities.dT + scale*other.dT,
+		dSigma: sensitivities.dSigma + scale*other.dSigma,
+		dRate:  sensitivities.dRate + scale*other.dRate,
+	}
+}
+
+// SetPayoff prices the chain's options with a payoff other than the vanilla
+// one, on the chain's call or put side: cash-or-nothing and asset-or-nothing
+// digitals pay terms.Cash or the asset when they finish in the money, gap options
+// pay the asset less the strike once it is beyond terms.Trigger, and power
+// options pay max(S^n - K, 0) or max(K - S^n, 0) for terms.Exponent n, so their
+// strikes are on the scale of the powered asset price.  They are priced in
+// closed form under Black-Scholes, with European exercise and no barrier.
+func (chain *OptionChainCalculator) SetPayoff(payoff int, terms PayoffTerms) error {
+	switch payoff {
+	case Vanilla, AssetOrNothing:
+	case CashOrNothing:
+		if terms.Cash <= 0.0 {
+			return fmt.Errorf("cash-or-nothing options need a cash payout > 0, got %v", terms.Cash)
+		}
+	case Gap:
+		if terms.Trigger <= 0.0 {
+			return fmt.Errorf("gap options need a trigger price > 0, got %v", terms.Trigger)
+		}
+	case Power:
+		if terms.Exponent <= 0.0 {
+			return fmt.Errorf("power options need an exponent > 0, got %v", terms.Exponent)
+		}
+	default:
+		return fmt.Errorf("unrecognized payoff %d", payoff)
+	}
+	if payoff != CashOrNothing && payoff != AssetOrNothing {
+		chain.digitalSpread = 0.0
+	}
+	chain.payoff = payoff
+	chain.payoffTerms = terms
+	return chain.SetModel(chain.model)
+}
+
+// SetDigitalSpread also prices each digital of the chain by replicating it with
+// a spread of vanilla options width apart around its strike: a cash-or-nothing
+// digital is cash/width spreads, and an asset-or-nothing one is the vanilla
+// option plus strike/width spreads.  The spread prices each of its strikes at
+// its own volatility, so against a volatility surface it takes in the skew the
+// closed form at one volatility misses.  Zero stops the replication.
+func (chain *OptionChainCalculator) SetDigitalSpread(width float64) error {
+	if width < 0.0 {
+		return fmt.Errorf("digital spread width cannot be negative, got %v", width)
+	}
+	if width > 0.0 && chain.payoff != CashOrNothing && chain.payoff != AssetOrNothing {
+		return fmt.Errorf("the digital spread replicates cash-or-nothing and asset-or-nothing options")
+	}
+	chain.digitalSpread = width
+	return nil
+}
+
+// PayoffPrice prices the chain option with its payoff in closed form
+func (chain *OptionChainCalculator) PayoffPrice(assetPrice, strikePrice, daysToExpiry float64, position *OptionPosition) error {
+	var sensitivities payoffSensitivities
+	var err error
+	switch chain.payoff {
+	case CashOrNothing:
+		sensitivities, err = chain.digital(CashOrNothing, assetPrice, strikePrice, daysToExpiry)
+		sensitivities = payoffSensitivities{}.plus(chain.payoffTerms.Cash, sensitivities)
+	case AssetOrNothing:
+		sensitivities, err = chain.digital(AssetOrNothing, assetPrice, strikePrice, daysToExpiry)
+	case Gap:
+		sensitivities, err = chain.gap(assetPrice, strikePrice, daysToExpiry)
+	case Power:
+		sensitivities, err = chain.power(assetPrice, strikePrice, daysToExpiry)
+	default:
+		return fmt.Errorf("unrecognized payoff %d", chain.payoff)
+	}
+	if err != nil {
+		return err
+	}
+	position.Price = sensitivities.price
+	position.Strike = strikePrice
+	position.DaysToExpiry = daysToExpiry
+	if chain.WithGreeks {
+		position.Greeks = Greeks{
+			Delta: sensitivities.delta,
+			Gamma: sensitivities.gamma,
+			Theta: -sensitivities.dT / 365.0,
+			Vega:  sensitivities.dSigma / 100.0,
+			Rho:   sensitivities.dRate / 100.0,
+		}
+	}
+	return nil
+}
+
+// digital prices a unit cash-or-nothing or an asset-or-nothing digital paying
+// beyond trigger
+func (chain *OptionChainCalculator) digital(payoff int, assetPrice, trigger, daysToExpiry float64) (payoffSensitivities, error) {
+	d1d2, err := chain.calculateD1D2(assetPrice, trigger, daysToExpiry)
+	if err != nil {
+		return payoffSensitivities{}, err
+	}
+	sign := 1.0
+	if chain.optionType == Put {
+		sign = -1.0
+	}
+	years, volatility, rate := d1d2.yearsToExpiry, d1d2.volatility, d1d2.riskFreeRate
+	sqrtT := math.Sqrt(years)
+	asset := d1d2.adjustedAssetPrice
+	carry := rate - chain.DividendYield
+
+	if payoff == CashOrNothing {
+		discount := math.Exp(-rate * years)
+		density := sign * discount * normalizedPDF(d1d2.d2)
+		price := discount * normalizedCDF(sign*d1d2.d2)
+		return payoffSensitivities{
+			price:  price,
+			delta:  density / (asset * d1d2.volatilityAdjustment),
+			gamma:  -density * d1d2.d1 / (asset * asset * volatility * volatility * years),
+			dT:     -rate*price + density*((carry-volatility*volatility/2.0)/(volatility*sqrtT)-d1d2.d2/(2.0*years)),
+			dSigma: -density * d1d2.d1 / volatility,
+			dRate:  -years*price + density*sqrtT/volatility,
+		}, nil
+	}
+	discountedAsset := asset * d1d2.dividendDiscount
+	density := sign * discountedAsset * normalizedPDF(d1d2.d1)
+	price := discountedAsset * normalizedCDF(sign*d1d2.d1)
+	return payoffSensitivities{
+		price:  price,
+		delta:  d1d2.dividendDiscount*normalizedCDF(sign*d1d2.d1) + density/(asset*d1d2.volatilityAdjustment),
+		gamma:  -density * d1d2.d2 / (asset * asset * volatility * volatility * years),
+		dT:     -chain.DividendYield*price + density*((carry+volatility*volatility/2.0)/(volatility*sqrtT)-d1d2.d1/(2.0*years)),
+		dSigma: -density * d1d2.d2 / volatility,
+		dRate:  density * sqrtT / volatility,
+	}, nil
+}
+
+// gap prices a gap option as an asset-or-nothing digital less strike units of
+// the cash-or-nothing one, both paying beyond the trigger, for calls, and the
+// reverse for puts
+func (chain *OptionChainCalculator) gap(assetPrice, strikePrice, daysToExpiry float64) (payoffSensitivities, error) {
+	assetDigital, err := chain.digital(AssetOrNothing, assetPrice, chain.payoffTerms.Trigger, daysToExpiry)
+	if err != nil {
+		return payoffSensitivities{}, err
+	}
+	cashDigital, err := chain.digital(CashOrNothing, assetPrice, chain.payoffTerms.Trigger, daysToExpiry)
+	if err != nil {
+		return payoffSensitivities{}, err
+	}
+	if chain.optionType == Put {
+		return payoffSensitivities{}.plus(strikePrice, cashDigital).plus(-1.0, assetDigital), nil
+	}
+	return assetDigital.plus(-strikePrice, cashDigital), nil
+}
+
+// power prices a power option as Black-Scholes on S^n, which is lognormal with
+// volatility n sigma and forward S^n exp(n(r-q)T + n(n-1)sigma^2 T/2).  The
+// volatility is the one for the equivalent strike K^(1/n) on the asset price.
+func (chain *OptionChainCalculator) power(assetPrice, strikePrice, daysToExpiry float64) (payoffSensitivities, error) {
+	if strikePrice <= 0.0 {
+		return payoffSensitivities{}, fmt.Errorf("power option strike must be > 0, got %v", strikePrice)
+	}
+	n := chain.payoffTerms.Exponent
+	d1d2, err := chain.calculateD1D2(assetPrice, math.Pow(strikePrice, 1.0/n), daysToExpiry)
+	if err != nil {
+		return payoffSensitivities{}, err
+	}
+	sign := 1.0
+	if chain.optionType == Put {
+		sign = -1.0
+	}
+	years, volatility, rate := d1d2.yearsToExpiry, d1d2.volatility, d1d2.riskFreeRate
+	asset := d1d2.adjustedAssetPrice
+	discount := math.Exp(-rate * years)
+	growth := n*(rate-chain.DividendYield) + n*(n-1.0)*volatility*volatility/2.0
+	forward := math.Pow(asset, n) * math.Exp(growth*years)
+	stdDev := n * volatility * math.Sqrt(years)
+	d1 := (math.Log(forward/strikePrice) + stdDev*stdDev/2.0) / stdDev
+	d2 := d1 - stdDev
+	price := sign * discount * (forward*normalizedCDF(sign*d1) - strikePrice*normalizedCDF(sign*d2))
+
+	// Sensitivities of the Black price to the forward and to the standard deviation
+	byForward := sign * discount * normalizedCDF(sign*d1)
+	byForward2 := discount * normalizedPDF(d1) / (forward * stdDev)
+	byStdDev := discount * forward * normalizedPDF(d1)
+	forwardByAsset := n * forward / asset
+	return payoffSensitivities{
+		price:  price,
+		delta:  byForward * forwardByAsset,
+		gamma:  byForward*forwardByAsset*(n-1.0)/asset + byForward2*forwardByAsset*forwardByAsset,
+		dT:     -rate*price + byForward*forward*growth + byStdDev*stdDev/(2.0*years),
+		dSigma: byForward*forward*n*(n-1.0)*volatility*years + byStdDev*n*math.Sqrt(years),
+		dRate:  -years*price + byForward*forward*n*years,
+	}, nil
+}
+
+// spreadReplication prices the chain's digital by the vanilla spread of
+// SetDigitalSpread
+func (chain *OptionChainCalculator) spreadReplication(assetPrice, strikePrice, daysToExpiry float64) (float64, error) {
+	width := chain.digitalSpread
+	if strikePrice-width/2.0 <= 0.0 {
+		return 0.0, fmt.Errorf("digital spread of width %v needs a strike above %v, got %v", width, width/2.0, strikePrice)
+	}
+	low, err := chain.blackScholesPrice(assetPrice, strikePrice-width/2.0, daysToExpiry)
+	if err != nil {
+		return 0.0, err
+	}
+	high, err := chain.blackScholesPrice(assetPrice, strikePrice+width/2.0, daysToExpiry)
+	if err != nil {
+		return 0.0, err
+	}
+	// Calls pay more at the lower strike and puts at the higher one
+	spread := (low - high) / width
+	if chain.optionType == Put {
+		spread = -spread
+	}
+	if chain.payoff == CashOrNothing {
+		return chain.payoffTerms.Cash * spread, nil
+	}
+	vanilla, err := chain.blackScholesPrice(assetPrice, strikePrice, daysToExpiry)
+	if err != nil {
+		return 0.0, err
+	}
+	if chain.optionType == Put {
+		// An asset-or-nothing put is K cash-or-nothing puts less the vanilla put
+		return strikePrice*spread - vanilla, nil
+	}
+	return vanilla + strikePrice*spread, nil
+}
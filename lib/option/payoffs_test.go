@@ -0,0 +1,143 @@
+package option
+
+import (
+	"math"
+	"testing"
+)
+
+func payoffPosition(t *testing.T, optionType, payoff int, terms PayoffTerms, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, dividendYield float64) OptionPosition {
+	t.Helper()
+	var position OptionPosition
+	chain := testChain(t, optionType, volatility, riskFreeRate, withDividends(dividendYield, nil), withPayoff(payoff, terms), withGreeks)
+	if err := chain.calculatePrice(assetPrice, strikePrice, daysToExpiry, &position); err != nil {
+		t.Fatal(err)
+	}
+	return position
+}
+
+// Haug's examples of a gap call, a cash-or-nothing put and an asset-or-nothing
+// put, with his cost of carry b as the rate less the dividend yield
+func TestPayoffReference(t *testing.T) {
+	tests := []struct {
+		name                                    string
+		optionType, payoff                      int
+		terms                                   PayoffTerms
+		assetPrice, strikePrice, daysToExpiry   float64
+		volatility, riskFreeRate, dividendYield float64
+		want                                    float64
+	}{
+		{"gap", Call, Gap, PayoffTerms{Trigger: 50}, 50, 57, 182.5, 0.2, 0.09, 0.0, -0.0053},
+		{"cash-or-nothing", Put, CashOrNothing, PayoffTerms{Cash: 10}, 100, 80, 273.75, 0.35, 0.06, 0.06, 2.6710},
+		{"asset-or-nothing", Put, AssetOrNothing, PayoffTerms{}, 70, 65, 182.5, 0.27, 0.07, 0.05, 20.2069},
+	}
+	for _, test := range tests {
+		position := payoffPosition(t, test.optionType, test.payoff, test.terms,
+			test.assetPrice, test.strikePrice, test.daysToExpiry, test.volatility, test.riskFreeRate, test.dividendYield)
+		if math.Abs(position.Price-test.want) > 5e-5 {
+			t.Errorf("%s: got %.4f, want %.4f", test.name, position.Price, test.want)
+		}
+	}
+}
+
+// A vanilla option is an asset-or-nothing option less strike cash-or-nothing
+// ones, a gap option triggered at its strike and a power option to the first
+// power.  Calls and puts of a digital add up to its payout at present value.
+func TestPayoffParity(t *testing.T) {
+	const assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, dividendYield = 100.0, 95.0, 90.0, 0.25, 0.05, 0.02
+	years := daysToExpiry / 365
+	price := func(optionType, payoff int, terms PayoffTerms) float64 {
+		t.Helper()
+		return payoffPosition(t, optionType, payoff, terms, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, dividendYield).Price
+	}
+	for _, optionType := range []int{Call, Put} {
+		vanilla := blackScholesValue(optionType, assetPrice, strikePrice, years, volatility, riskFreeRate, dividendYield)
+		replicated := price(optionType, AssetOrNothing, PayoffTerms{}) - strikePrice*price(optionType, CashOrNothing, PayoffTerms{Cash: 1})
+		if optionType == Put {
+			replicated = -replicated
+		}
+		for _, test := range []struct {
+			name string
+			got  float64
+		}{
+			{"digitals", replicated},
+			{"gap", price(optionType, Gap, PayoffTerms{Trigger: strikePrice})},
+			{"power", price(optionType, Power, PayoffTerms{Exponent: 1})},
+		} {
+			if math.Abs(test.got-vanilla) > 1e-10 {
+				t.Errorf("type %d %s: got %v, want the vanilla price %v", optionType, test.name, test.got, vanilla)
+			}
+		}
+	}
+	if got, want := price(Call, CashOrNothing, PayoffTerms{Cash: 3})+price(Put, CashOrNothing, PayoffTerms{Cash: 3}), 3*math.Exp(-riskFreeRate*years); math.Abs(got-want) > 1e-12 {
+		t.Errorf("cash-or-nothing call + put = %v, want %v", got, want)
+	}
+	if got, want := price(Call, AssetOrNothing, PayoffTerms{})+price(Put, AssetOrNothing, PayoffTerms{}), assetPrice*math.Exp(-dividendYield*years); math.Abs(got-want) > 1e-10 {
+		t.Errorf("asset-or-nothing call + put = %v, want %v", got, want)
+	}
+}
+
+// Payoff Greeks against central differences
+func TestPayoffGreeks(t *testing.T) {
+	const assetPrice, daysToExpiry, volatility, riskFreeRate, dividendYield = 100.0, 120.0, 0.3, 0.04, 0.015
+	const priceBump, bump = 0.01, 1e-5
+	terms := PayoffTerms{Cash: 5, Trigger: 103, Exponent: 1.7}
+	for _, payoff := range []int{CashOrNothing, AssetOrNothing, Gap, Power} {
+		strikePrice := 97.0
+		if payoff == Power {
+			strikePrice = 2500
+		}
+		for _, optionType := range []int{Call, Put} {
+			price := func(assetPrice, daysToExpiry, volatility, riskFreeRate float64) float64 {
+				t.Helper()
+				return payoffPosition(t, optionType, payoff, terms, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, dividendYield).Price
+			}
+			position := payoffPosition(t, optionType, payoff, terms, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, dividendYield)
+			up, down := price(assetPrice+priceBump, daysToExpiry, volatility, riskFreeRate), price(assetPrice-priceBump, daysToExpiry, volatility, riskFreeRate)
+			want := Greeks{
+				Delta: (up - down) / (2 * priceBump),
+				Gamma: (up - 2*position.Price + down) / (priceBump * priceBump),
+				Theta: (price(assetPrice, daysToExpiry-bump, volatility, riskFreeRate) - price(assetPrice, daysToExpiry+bump, volatility, riskFreeRate)) / (2 * bump),
+				Vega:  (price(assetPrice, daysToExpiry, volatility+bump, riskFreeRate) - price(assetPrice, daysToExpiry, volatility-bump, riskFreeRate)) / (2 * bump) / 100,
+				Rho:   (price(assetPrice, daysToExpiry, volatility, riskFreeRate+bump) - price(assetPrice, daysToExpiry, volatility, riskFreeRate-bump)) / (2 * bump) / 100,
+			}
+			got := position.Greeks
+			for _, greek := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"delta", got.Delta, want.Delta},
+				{"gamma", got.Gamma, want.Gamma},
+				{"theta", got.Theta, want.Theta},
+				{"vega", got.Vega, want.Vega},
+				{"rho", got.Rho, want.Rho},
+			} {
+				if math.Abs(greek.got-greek.want) > 1e-6*math.Max(1.0, math.Abs(greek.want)) {
+					t.Errorf("payoff %d type %d %s: got %v, want %v", payoff, optionType, greek.name, greek.got, greek.want)
+				}
+			}
+		}
+	}
+}
+
+// A narrow spread of vanilla options replicates the digitals
+func TestDigitalSpreadReplication(t *testing.T) {
+	for _, payoff := range []int{CashOrNothing, AssetOrNothing} {
+		for _, optionType := range []int{Call, Put} {
+			chain := testChain(t, optionType, 0.3, 0.04, withDividends(0.01, nil), withPayoff(payoff, PayoffTerms{Cash: 1}), withGreeks)
+			if err := chain.SetDigitalSpread(0.01); err != nil {
+				t.Fatal(err)
+			}
+			var position OptionPosition
+			if err := chain.calculatePrice(100, 105, 60, &position); err != nil {
+				t.Fatal(err)
+			}
+			replicated, err := chain.spreadReplication(100, 105, 60)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(replicated-position.Price) > 1e-5 {
+				t.Errorf("payoff %d type %d: replicated %v, closed form %v", payoff, optionType, replicated, position.Price)
+			}
+		}
+	}
+}
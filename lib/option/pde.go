@@ -0,0 +1,536 @@
+package option
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+const (
+	DefaultGridPoints = 200
+	MaxGridPoints     = 5000
+	// Half width of the grid around the strike, in standard deviations of the log
+	// price at expiry
+	gridDeviations = 6.0
+	// The first time steps are each taken as two fully implicit half steps, which
+	// damps the oscillations Crank-Nicolson leaves from the kink in the payoff
+	// (Rannacher)
+	rannacherSteps = 2
+	// Projected successive over-relaxation for the early exercise constraint
+	psorRelaxation = 1.2
+	psorTolerance  = 1e-10
+	psorIterations = 1000
+)
+
+// Payoffs solved on the grid: the chain's option, or a rebate paid at expiry
+// unless the barrier is reached first, which prices the rebate of a knock-in
+const (
+	gridOption = iota
+	gridRebate
+)
+
+// chainBarrier is the barrier of a chain's options
+type chainBarrier struct {
+	barrierType int
+	level       float64
+	rebate      float64
+}
+
+// gridProblem identifies one finite-difference solve.  The grid is in the log of
+// the escrowed asset price, so one solve prices every asset price inside it.
+type gridProblem struct {
+	payoff       int
+	strike       float64
+	daysToExpiry float64
+	volatility   float64
+	riskFreeRate float64
+	low          float64
+	high         float64
+	american     bool
+	barrier      bool
+}
+
+// gridSlice holds option values across the grid today and one time step later
+type gridSlice struct {
+	low    float64
+	dx     float64
+	values []float64
+	later  []float64
+}
+
+// gridEntry solves its problem once, however many workers ask for it
+type gridEntry struct {
+	once  sync.Once
+	slice *gridSlice
+	err   error
+}
+
+// gridReading is a value read off a slice at one asset price
+type gridReading struct {
+	price float64
+	delta float64
+	gamma float64
+	later float64
+}
+
+// SetGridPoints sets the number of asset price intervals of the finite-difference grid
+func (chain *OptionChainCalculator) SetGridPoints(points int) error {
+	if points < 10 || points > MaxGridPoints {
+		return fmt.Errorf("grid points must be between 10 and %d", MaxGridPoints)
+	}
+	chain.gridPoints = points
+	chain.resetCaches()
+	return nil
+}
+
+// SetBarrier makes the chain's options barrier options, priced on the
+// finite-difference grid.  Out options pay the rebate when they knock out and
+// in options pay it at expiry if they never knock in.
+func (chain *OptionChainCalculator) SetBarrier(barrierType int, level, rebate float64) error {
+	if barrierType < UpAndOut || barrierType > DownAndIn {
+		return fmt.Errorf("unrecognized barrier type %d", barrierType)
+	}
+	if level <= 0.0 || rebate < 0.0 {
+		return fmt.Errorf("barrier must be > 0 and rebate >= 0, got %v/%v", level, rebate)
+	}
+	chain.barrier = &chainBarrier{barrierType, level, rebate}
+	chain.selectPricer()
+	chain.resetCaches()
+	return chain.checkModel()
+}
+
+// selectPricer picks the pricing method for the chain's exercise style, model
+// and barrier
+func (chain *OptionChainCalculator) selectPricer() {
+	switch {
+	case chain.barrier != nil, chain.exerciseStyle == American && chain.latticeModel == CrankNicolson:
+		chain.calculatePrice = chain.FiniteDifferencePrice
+	case chain.exerciseStyle == American && chain.approximates():
+		chain.calculatePrice = chain.ApproximationPrice
+	case chain.exerciseStyle == American:
+		chain.calculatePrice = chain.LatticePrice
+	default:
+		chain.calculatePrice = chain.europeanPrice
+	}
+}
+
+// gridIntervals is the number of intervals between the grid's asset prices
+func (chain *OptionChainCalculator) gridIntervals() int {
+	if chain.gridPoints == 0 {
+		return DefaultGridPoints
+	}
+	return chain.gridPoints
+}
+
+// gridSteps is the number of time steps of a solve: the lattice steps, which
+// European barrier chains may not have set
+func (chain *OptionChainCalculator) gridSteps() int {
+	if chain.latticeSteps == 0 {
+		return DefaultLatticeSteps
+	}
+	return chain.latticeSteps
+}
+
+func (barrier *chainBarrier) up() bool {
+	return barrier.barrierType == UpAndOut || barrier.barrierType == UpAndIn
+}
+
+func (barrier *chainBarrier) knockOut() bool {
+	return barrier.barrierType == UpAndOut || barrier.barrierType == DownAndOut
+}
+
+// breached reports whether an asset price is at or through the barrier
+func (barrier *chainBarrier) breached(assetPrice float64) bool {
+	if barrier.up() {
+		return assetPrice >= barrier.level
+	}
+	return assetPrice <= barrier.level
+}
+
+// FiniteDifferencePrice prices the chain option by solving the Black-Scholes PDE
+// with Crank-Nicolson on a grid of asset prices, with early exercise enforced by
+// PSOR.  Each solve is kept, so the other asset prices of the chain on the same
+// strike and expiry are read off the same grid.  Knock-in options are valued as
+// the vanilla option less the knock-out, plus the rebate when not knocked in.
+func (chain *OptionChainCalculator) FiniteDifferencePrice(assetPrice, strikePrice, daysToExpiry float64, position *OptionPosition) error {
+	volatility, err := chain.VolatilityAt(assetPrice, strikePrice, daysToExpiry)
+	if err != nil {
+		return err
+	}
+	riskFreeRate := chain.RateAt(daysToExpiry)
+	american := chain.exerciseStyle == American
+	reading, err := chain.gridPrice(assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, american)
+	if err != nil {
+		return err
+	}
+
+	position.Price = reading.price
+	position.Strike = strikePrice
+	position.DaysToExpiry = daysToExpiry
+	if american {
+		var european OptionPosition
+		if chain.barrier == nil {
+			err = chain.europeanPrice(assetPrice, strikePrice, daysToExpiry, &european)
+		} else {
+			var europeanReading gridReading
+			europeanReading, err = chain.gridPrice(assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, false)
+			european.Price = europeanReading.price
+		}
+		if err != nil {
+			return err
+		}
+		position.EarlyExercisePremium = math.Max(reading.price-european.Price, 0.0)
+	}
+	if !chain.WithGreeks {
+		return nil
+	}
+
+	reprice := func(volatility, riskFreeRate float64) (float64, error) {
+		bumped, err := chain.gridPrice(assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, american)
+		return bumped.price, err
+	}
+	greeks := Greeks{
+		Delta: reading.delta,
+		Gamma: reading.gamma,
+		Theta: (reading.later - reading.price) / (daysToExpiry / float64(chain.gridSteps())),
+	}
+	volatilityBump := math.Min(latticeVolatilityBump, volatility/2.0)
+	volatilityUp, err := reprice(volatility+volatilityBump, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	volatilityDown, err := reprice(volatility-volatilityBump, riskFreeRate)
+	if err != nil {
+		return err
+	}
+	greeks.Vega = (volatilityUp - volatilityDown) / (2.0 * volatilityBump) / 100.0
+	rateUp, err := reprice(volatility, riskFreeRate+latticeRateBump)
+	if err != nil {
+		return err
+	}
+	rateDown, err := reprice(volatility, riskFreeRate-latticeRateBump)
+	if err != nil {
+		return err
+	}
+	greeks.Rho = (rateUp - rateDown) / (2.0 * latticeRateBump) / 100.0
+	position.Greeks = greeks
+	return nil
+}
+
+// gridPrice combines the solves that value the chain option at one asset price
+func (chain *OptionChainCalculator) gridPrice(assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate float64, american bool) (gridReading, error) {
+	read := func(payoff int, barrier bool) (gridReading, error) {
+		return chain.readGrid(payoff, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate, american, barrier)
+	}
+	barrier := chain.barrier
+	if barrier == nil {
+		return read(gridOption, false)
+	}
+	if barrier.breached(assetPrice) {
+		if barrier.knockOut() {
+			return gridReading{price: barrier.rebate, later: barrier.rebate}, nil
+		}
+		return read(gridOption, false)
+	}
+	if barrier.knockOut() {
+		return read(gridOption, true)
+	}
+
+	vanilla, err := read(gridOption, false)
+	if err != nil {
+		return gridReading{}, err
+	}
+	knockOut, err := read(gridOption, true)
+	if err != nil {
+		return gridReading{}, err
+	}
+	result := gridReading{
+		price: vanilla.price - knockOut.price,
+		delta: vanilla.delta - knockOut.delta,
+		gamma: vanilla.gamma - knockOut.gamma,
+		later: vanilla.later - knockOut.later,
+	}
+	if barrier.rebate > 0.0 {
+		rebate, err := read(gridRebate, true)
+		if err != nil {
+			return gridReading{}, err
+		}
+		result.price += rebate.price
+		result.delta += rebate.delta
+		result.gamma += rebate.gamma
+		result.later += rebate.later
+	}
+	return result, nil
+}
+
+// readGrid reads the value of a payoff at an asset price off its grid, solving the
+// grid first if no other asset price has
+func (chain *OptionChainCalculator) readGrid(payoff int, assetPrice, strikePrice, daysToExpiry, volatility, riskFreeRate float64, american, barrier bool) (gridReading, error) {
+	yearsToExpiry := daysToExpiry / 365
+	if yearsToExpiry <= 0.0 || volatility <= 0.0 {
+		return gridReading{}, fmt.Errorf("days to expiry and volatility must be > 0, got %v/%v", daysToExpiry, volatility)
+	}
+	treeAssetPrice := assetPrice - chain.dividendsPresentValue(0.0, daysToExpiry)
+	if treeAssetPrice <= 0.0 {
+		return gridReading{}, fmt.Errorf(
+			"asset price %v does not cover dividends worth %v before expiry in %v days",
+			assetPrice, assetPrice-treeAssetPrice, daysToExpiry,
+		)
+	}
+
+	x := math.Log(treeAssetPrice)
+	low, high := chain.gridBounds(x, strikePrice, yearsToExpiry, volatility, barrier)
+	problem := gridProblem{payoff, strikePrice, daysToExpiry, volatility, riskFreeRate, low, high, american, barrier}
+	cached, _ := chain.gridSlices.LoadOrStore(problem, &gridEntry{})
+	entry := cached.(*gridEntry)
+	entry.once.Do(func() {
+		entry.slice, entry.err = chain.solveGrid(&problem)
+	})
+	if entry.err != nil {
+		return gridReading{}, entry.err
+	}
+	return entry.slice.read(x, treeAssetPrice), nil
+}
+
+// gridBounds are the log asset prices at the ends of the grid that values the
+// log asset price x.  The grid spans a fixed number of standard deviations around
+// the strike, so it is shared by the asset prices within it; one further out gets
+// its own.
+func (chain *OptionChainCalculator) gridBounds(x, strikePrice, yearsToExpiry, volatility float64, barrier bool) (float64, float64) {
+	width := gridDeviations * volatility * math.Sqrt(yearsToExpiry)
+	low, high := math.Log(strikePrice)-width, math.Log(strikePrice)+width
+	if x <= low || x >= high {
+		low, high = math.Min(low, x-width/4.0), math.Max(high, x+width/4.0)
+	}
+	if barrier {
+		// The barrier bounds the grid on its side, however far it is from the strike
+		if chain.barrier.up() {
+			high = math.Log(chain.barrier.level)
+			low = math.Min(low, high-width)
+		} else {
+			low = math.Log(chain.barrier.level)
+			high = math.Max(high, low+width)
+		}
+		// and the other end moves out to put the strike on a node, as it is at the
+		// centre of a grid without a barrier.  Between nodes the kink in the payoff
+		// leaves an error that comes and goes with the number of grid points.
+		points := float64(chain.gridIntervals())
+		strike := math.Log(strikePrice)
+		if chain.barrier.up() {
+			if intervals := math.Floor(points * (high - strike) / (high - low)); intervals >= 1.0 && intervals < points {
+				low = high - (high-strike)*points/intervals
+			}
+		} else if intervals := math.Floor(points * (strike - low) / (high - low)); intervals >= 1.0 && intervals < points {
+			high = low + (strike-low)*points/intervals
+		}
+	}
+	return low, high
+}
+
+// read interpolates quadratically through the three nodes nearest x, which gives
+// delta and gamma as well as the price
+func (slice *gridSlice) read(x, treeAssetPrice float64) gridReading {
+	last := len(slice.values) - 1
+	j := int(math.Round((x - slice.low) / slice.dx))
+	j = max(1, min(j, last-1))
+	u := (x - slice.low - float64(j)*slice.dx) / slice.dx
+
+	quadratic := func(values []float64) (float64, float64, float64) {
+		slope := (values[j+1] - values[j-1]) / 2.0
+		curve := values[j+1] - 2.0*values[j] + values[j-1]
+		return values[j] + u*slope + u*u*curve/2.0, (slope + u*curve) / slice.dx, curve / (slice.dx * slice.dx)
+	}
+	price, dx, dxx := quadratic(slice.values)
+	later, _, _ := quadratic(slice.later)
+	return gridReading{
+		price: price,
+		delta: dx / treeAssetPrice,
+		gamma: (dxx - dx) / (treeAssetPrice * treeAssetPrice),
+		later: later,
+	}
+}
+
+// solveGrid steps the payoff back from expiry to today in log asset price
+func (chain *OptionChainCalculator) solveGrid(problem *gridProblem) (*gridSlice, error) {
+	points := chain.gridIntervals()
+	steps := chain.gridSteps()
+	yearsToExpiry := problem.daysToExpiry / 365
+	dt := yearsToExpiry / float64(steps)
+	dx := (problem.high - problem.low) / float64(points)
+	if !(dx > 0.0) {
+		return nil, fmt.Errorf("finite-difference grid from %v to %v is empty", math.Exp(problem.low), math.Exp(problem.high))
+	}
+
+	volatility, riskFreeRate := problem.volatility, problem.riskFreeRate
+	// A futures price costs nothing to carry
+	carry := riskFreeRate - chain.DividendYield
+	if chain.model == Black76 {
+		carry = 0.0
+	}
+	variance := volatility * volatility
+	drift := carry - variance/2.0
+	below := variance/(2.0*dx*dx) - drift/(2.0*dx)
+	above := variance/(2.0*dx*dx) + drift/(2.0*dx)
+	centre := -variance/(dx*dx) - riskFreeRate
+
+	spots := make([]float64, points+1)
+	values := make([]float64, points+1)
+	for j := range spots {
+		spots[j] = math.Exp(problem.low + float64(j)*dx)
+		if problem.payoff == gridRebate {
+			values[j] = chain.barrier.rebate
+		} else {
+			values[j] = intrinsicValue(chain.optionType, spots[j], problem.strike)
+		}
+	}
+
+	// exercise is the value of exercising at each node with tau years to expiry,
+	// with dividends still to go ex added back to the escrowed price
+	exercise := make([]float64, points+1)
+	setExercise := func(tau float64) {
+		escrowed := chain.dividendsPresentValue((yearsToExpiry-tau)*365, problem.daysToExpiry)
+		for j, spot := range spots {
+			exercise[j] = intrinsicValue(chain.optionType, spot+escrowed, problem.strike)
+		}
+	}
+	// boundary values at the ends of the grid: the rebate at a knock-out barrier,
+	// otherwise the discounted payoff deep in or out of the money, exercised when
+	// it is worth most if American
+	boundary := func(j int, tau float64) float64 {
+		atBarrier := problem.barrier && (j == 0) != chain.barrier.up()
+		if atBarrier {
+			if problem.payoff == gridRebate {
+				return 0.0
+			}
+			return chain.barrier.rebate
+		}
+		if problem.payoff == gridRebate {
+			return chain.barrier.rebate * math.Exp(-riskFreeRate*tau)
+		}
+		forward := spots[j] * math.Exp((carry-riskFreeRate)*tau)
+		value := math.Max(forward-problem.strike*math.Exp(-riskFreeRate*tau), 0.0)
+		if chain.optionType == Put {
+			value = math.Max(problem.strike*math.Exp(-riskFreeRate*tau)-forward, 0.0)
+		}
+		if problem.american {
+			elapsed := (yearsToExpiry - tau) * 365
+			value = math.Max(value, math.Max(exercise[j],
+				chain.exDividendExercise(spots[j], problem.strike, elapsed, problem.daysToExpiry, carry, riskFreeRate)))
+		}
+		return value
+	}
+
+	rhs := make([]float64, points+1)
+	scratch := make([]float64, points+1)
+	// step advances values by h years to tau years to expiry, weighting the new
+	// time level by theta: one half for Crank-Nicolson and one for implicit Euler
+	step := func(theta, h, tau float64) error {
+		if problem.american {
+			setExercise(tau)
+		}
+		for j := 1; j < points; j++ {
+			rhs[j] = values[j] + (1.0-theta)*h*(below*values[j-1]+centre*values[j]+above*values[j+1])
+		}
+		lower, diagonal, upper := -theta*h*below, 1.0-theta*h*centre, -theta*h*above
+		values[0], values[points] = boundary(0, tau), boundary(points, tau)
+		if problem.american {
+			// PSOR reads the boundary values from values, like any neighbour
+			return projectedSOR(values, rhs, exercise, lower, diagonal, upper)
+		}
+		rhs[1] -= lower * values[0]
+		rhs[points-1] -= upper * values[points]
+		solveTridiagonal(values, rhs, scratch, lower, diagonal, upper)
+		return nil
+	}
+
+	later := make([]float64, points+1)
+	for n := 1; n <= steps; n++ {
+		if n == steps {
+			copy(later, values)
+		}
+		tau := float64(n) * dt
+		if n <= rannacherSteps {
+			if err := step(1.0, dt/2.0, tau-dt/2.0); err != nil {
+				return nil, err
+			}
+			if err := step(1.0, dt/2.0, tau); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := step(0.5, dt, tau); err != nil {
+			return nil, err
+		}
+	}
+	return &gridSlice{low: problem.low, dx: dx, values: values, later: later}, nil
+}
+
+// exDividendExercise is the most an option sure to be exercised is worth when
+// exercised just before or just after one of the dividends going ex between
+// elapsed days and expiry, on an escrowed asset price.  A call deep in the money
+// may be worth more exercised to collect a later dividend than now or at expiry,
+// and a put worth more once a dividend has gone ex.
+func (chain *OptionChainCalculator) exDividendExercise(spot, strikePrice, elapsed, daysToExpiry, carry, riskFreeRate float64) float64 {
+	sign := 1.0
+	if chain.optionType == Put {
+		sign = -1.0
+	}
+	best := 0.0
+	for _, moment := range chain.Dividends {
+		if moment.DaysToExDate <= elapsed || moment.DaysToExDate > daysToExpiry {
+			continue
+		}
+		// The asset holds the dividends going ex from the moment on just before it,
+		// and those after it just after
+		before, after := 0.0, 0.0
+		for _, dividend := range chain.Dividends {
+			if dividend.DaysToExDate >= moment.DaysToExDate && dividend.DaysToExDate <= daysToExpiry {
+				presentValue := dividend.Amount * chain.discountFactor(elapsed, dividend.DaysToExDate)
+				before += presentValue
+				if dividend.DaysToExDate > moment.DaysToExDate {
+					after += presentValue
+				}
+			}
+		}
+		years := (moment.DaysToExDate - elapsed) / 365
+		forward := spot*math.Exp((carry-riskFreeRate)*years) - strikePrice*math.Exp(-riskFreeRate*years)
+		best = math.Max(best, math.Max(sign*(forward+before), sign*(forward+after)))
+	}
+	return best
+}
+
+// solveTridiagonal solves the interior of a constant tridiagonal system in place
+// by the Thomas algorithm; values holds the boundary values at both ends
+func solveTridiagonal(values, rhs, scratch []float64, lower, diagonal, upper float64) {
+	last := len(values) - 1
+	// Forward sweep, keeping the modified upper coefficients in scratch
+	scratch[1] = upper / diagonal
+	values[1] = rhs[1] / diagonal
+	for j := 2; j < last; j++ {
+		pivot := diagonal - lower*scratch[j-1]
+		scratch[j] = upper / pivot
+		values[j] = (rhs[j] - lower*values[j-1]) / pivot
+	}
+	for j := last - 2; j >= 1; j-- {
+		values[j] -= scratch[j] * values[j+1]
+	}
+}
+
+// projectedSOR solves the interior of a tridiagonal system subject to the values
+// staying at or above exercise, starting from the values already held
+func projectedSOR(values, rhs, exercise []float64, lower, diagonal, upper float64) error {
+	last := len(values) - 1
+	for iteration := 0; iteration < psorIterations; iteration++ {
+		change := 0.0
+		for j := 1; j < last; j++ {
+			solved := (rhs[j] - lower*values[j-1] - upper*values[j+1]) / diagonal
+			next := math.Max(values[j]+psorRelaxation*(solved-values[j]), exercise[j])
+			change = math.Max(change, math.Abs(next-values[j]))
+			values[j] = next
+		}
+		if change <= psorTolerance {
+			return nil
+		}
+	}
+	return fmt.Errorf("early exercise did not converge in %d iterations - increase time steps", psorIterations)
+}
@@ -0,0 +1,103 @@
+package option
+
+import (
+	"math"
+	"testing"
+)
+
+// Crank-Nicolson against the American puts of Longstaff and Schwartz's Table 1,
+// as the lattices are
+func TestCrankNicolsonAmericanPut(t *testing.T) {
+	tests := []struct {
+		assetPrice, volatility, daysToExpiry, want float64
+	}{
+		{36, 0.2, 365, 4.478},
+		{40, 0.4, 365, 5.311},
+		{44, 0.2, 730, 1.690},
+	}
+	for _, test := range tests {
+		chain := testChain(t, Put, test.volatility, 0.06, withAmerican(CrankNicolson, 200))
+		var position OptionPosition
+		if err := chain.calculatePrice(test.assetPrice, 40, test.daysToExpiry, &position); err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(position.Price-test.want) > 0.01 {
+			t.Errorf("S=%v vol=%v days=%v: got %.4f, want %.3f", test.assetPrice, test.volatility, test.daysToExpiry, position.Price, test.want)
+		}
+	}
+}
+
+// Barriers on the grid against the closed forms: eight deviations away they
+// leave the European price, and a down-and-out call on a barrier below the strike is the
+// call less its reflection in the barrier
+func TestFiniteDifferenceBarrier(t *testing.T) {
+	const assetPrice, strikePrice, daysToExpiry, barrier = 100.0, 100.0, 180.0, 90.0
+	years := daysToExpiry / 365
+	for _, optionType := range []int{Call, Put} {
+		var position OptionPosition
+		chain := testChain(t, optionType, 0.25, 0.05, withDividends(0.02, nil), withBarrier(UpAndOut, 400, 0), withGridPoints(400))
+		if err := chain.calculatePrice(assetPrice, strikePrice, daysToExpiry, &position); err != nil {
+			t.Fatal(err)
+		}
+		if want := blackScholesValue(optionType, assetPrice, strikePrice, years, 0.25, 0.05, 0.02); math.Abs(position.Price-want) > 2e-3 {
+			t.Errorf("type %d far barrier: got %v, want %v", optionType, position.Price, want)
+		}
+	}
+
+	drift := 0.05 - 0.02 - 0.25*0.25/2.0
+	call := blackScholesValue(Call, assetPrice, strikePrice, years, 0.25, 0.05, 0.02)
+	reflected := blackScholesValue(Call, barrier*barrier/assetPrice, strikePrice, years, 0.25, 0.05, 0.02)
+	downAndOut := call - math.Pow(barrier/assetPrice, 2.0*drift/(0.25*0.25))*reflected
+	for _, test := range []struct {
+		name        string
+		barrierType int
+		want        float64
+	}{
+		{"down-and-out", DownAndOut, downAndOut},
+		{"down-and-in", DownAndIn, call - downAndOut},
+	} {
+		var position OptionPosition
+		chain := testChain(t, Call, 0.25, 0.05, withDividends(0.02, nil), withBarrier(test.barrierType, barrier, 0), withGridPoints(400))
+		if err := chain.calculatePrice(assetPrice, strikePrice, daysToExpiry, &position); err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(position.Price-test.want) > 1e-3 {
+			t.Errorf("%s: got %v, want %v", test.name, position.Price, test.want)
+		}
+	}
+
+	// Below the barrier the option is already out and worth its rebate
+	var position OptionPosition
+	chain := testChain(t, Put, 0.25, 0.05, withDividends(0.02, nil), withBarrier(DownAndOut, barrier, 1), withGridPoints(400))
+	if err := chain.calculatePrice(85, strikePrice, daysToExpiry, &position); err != nil {
+		t.Fatal(err)
+	}
+	if position.Price != 1 {
+		t.Errorf("knocked out: got %v, want the rebate 1", position.Price)
+	}
+}
+
+// Deep in the money, where the grid ends, an American call on an asset with two
+// dividends to come is worth more exercised for the second dividend than for the
+// first or at expiry, as it is on the lattice
+func TestCrankNicolsonDividendEdge(t *testing.T) {
+	dividends := []Dividend{{1, 3}, {151, 3}}
+	price := func(latticeModel, steps int, assetPrice float64) float64 {
+		t.Helper()
+		chain := testChain(t, Call, 0.3, 0.08, withAmerican(latticeModel, steps))
+		if err := chain.SetDividends(0.0, dividends); err != nil {
+			t.Fatal(err)
+		}
+		var position OptionPosition
+		if err := chain.calculatePrice(assetPrice, 100, 260, &position); err != nil {
+			t.Fatal(err)
+		}
+		return position.Price
+	}
+	for _, assetPrice := range []float64{100, 250, 400} {
+		got, want := price(CrankNicolson, 300, assetPrice), price(LatticeCRR, 2000, assetPrice)
+		if math.Abs(got-want) > 0.01 {
+			t.Errorf("S=%v: got %.4f, want the lattice price %.4f", assetPrice, got, want)
+		}
+	}
+}
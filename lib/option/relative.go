@@ -0,0 +1,145 @@
+package option
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	deltaStrikeIterations = 50
+	deltaStrikeTolerance  = 1e-10
+)
+
+// RelativeAxis resolves the points of an axis given relative to a spot price, such
+// as percentage moves or moneyness, to absolute values.  Resolve must be monotonic
+// so the bounds of the points resolve to the bounds of the axis.
+type RelativeAxis struct {
+	Points  Axis
+	Resolve func(point float64) (float64, error)
+}
+
+func (axis *RelativeAxis) Count(name string) (int, error) {
+	return axis.Points.Count(name)
+}
+
+func (axis *RelativeAxis) Values(name string) ([]float64, error) {
+	points, err := axis.Points.Values(name)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, len(points))
+	for i, point := range points {
+		value, err := axis.Resolve(point)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		values[i] = roundAxisValue(value)
+	}
+	sort.Float64s(values)
+	return values, nil
+}
+
+func (axis *RelativeAxis) Bounds(name string) (float64, float64, error) {
+	low, high, err := axis.Points.Bounds(name)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	if low, err = axis.Resolve(low); err != nil {
+		return 0.0, 0.0, fmt.Errorf("%s: %w", name, err)
+	}
+	if high, err = axis.Resolve(high); err != nil {
+		return 0.0, 0.0, fmt.Errorf("%s: %w", name, err)
+	}
+	return roundAxisValue(math.Min(low, high)), roundAxisValue(math.Max(low, high)), nil
+}
+
+// inverseNormalCDF is the quantile function of the standard normal distribution
+func inverseNormalCDF(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2.0*p-1.0)
+}
+
+// PercentMoveAxis resolves percentage moves such as -10 or 5 to asset prices around spot
+func PercentMoveAxis(spot float64, moves Axis) *RelativeAxis {
+	return &RelativeAxis{Points: moves, Resolve: func(move float64) (float64, error) {
+		assetPrice := spot * (1.0 + move/100.0)
+		if assetPrice <= 0.0 {
+			return 0.0, fmt.Errorf("a move of %v%% takes spot %v to %v", move, spot, assetPrice)
+		}
+		return assetPrice, nil
+	}}
+}
+
+// StandardDeviationAxis resolves a number of standard deviations of the log asset
+// price over daysToExpiry, at the given volatility, to asset prices around spot
+func StandardDeviationAxis(spot, volatility, daysToExpiry float64, deviations Axis) *RelativeAxis {
+	deviation := volatility * math.Sqrt(daysToExpiry/365)
+	return &RelativeAxis{Points: deviations, Resolve: func(deviations float64) (float64, error) {
+		return spot * math.Exp(deviations*deviation), nil
+	}}
+}
+
+// MoneynessAxis resolves moneyness (strike over spot) to strike prices
+func MoneynessAxis(spot float64, moneyness Axis) *RelativeAxis {
+	return &RelativeAxis{Points: moneyness, Resolve: func(moneyness float64) (float64, error) {
+		if moneyness <= 0.0 {
+			return 0.0, fmt.Errorf("moneyness must be > 0, got %v", moneyness)
+		}
+		return spot * moneyness, nil
+	}}
+}
+
+// DeltaStrikeAxis resolves spot deltas of the chain's option type to strike prices
+// for the given expiry, under the chain's model, volatility, rate and dividends.
+// Deltas are given without sign, so 0.25 is the 25 delta call or put.  With a
+// volatility surface the strike and its volatility are found together by
+// iterating from the at-the-money volatility.
+func (chain *OptionChainCalculator) DeltaStrikeAxis(spot, daysToExpiry float64, deltas Axis) *RelativeAxis {
+	if chain.model == GarmanKohlhagen {
+		return chain.fxDeltaStrikeAxis(spot, daysToExpiry, deltas)
+	}
+	yearsToExpiry := daysToExpiry / 365
+	sqrtT := math.Sqrt(yearsToExpiry)
+	riskFreeRate := chain.RateAt(daysToExpiry)
+	// Delta approaches this deep in the money: the discounted value of what the option delivers
+	carry := riskFreeRate - chain.DividendYield
+	deltaLimit := math.Exp(-chain.DividendYield * yearsToExpiry)
+	adjustedSpot := spot - chain.dividendsPresentValue(0.0, daysToExpiry)
+	if chain.onFutures() {
+		carry = 0.0
+		deltaLimit = math.Exp(-riskFreeRate * yearsToExpiry)
+	}
+
+	return &RelativeAxis{Points: deltas, Resolve: func(delta float64) (float64, error) {
+		if delta <= 0.0 || delta >= deltaLimit {
+			return 0.0, fmt.Errorf("delta must be > 0 and < %v, got %v", deltaLimit, delta)
+		}
+		if sqrtT == 0.0 || (chain.model != Bachelier && adjustedSpot <= 0.0) {
+			return 0.0, fmt.Errorf("cannot find strikes by delta for %v days to expiry", daysToExpiry)
+		}
+		d1 := inverseNormalCDF(delta / deltaLimit)
+		if chain.optionType == Put {
+			d1 = -d1
+		}
+
+		strike := spot
+		for i := 0; i < deltaStrikeIterations; i++ {
+			volatility, err := chain.VolatilityAt(spot, strike, daysToExpiry)
+			if err != nil {
+				return 0.0, err
+			}
+			var next float64
+			if chain.model == Bachelier {
+				next = spot - d1*volatility*sqrtT
+			} else {
+				drift := (carry + volatility*volatility/2.0) * yearsToExpiry
+				next = adjustedSpot * math.Exp(drift-d1*volatility*sqrtT)
+			}
+			if math.Abs(next-strike) <= deltaStrikeTolerance*math.Abs(strike) {
+				return next, nil
+			}
+			strike = next
+		}
+		return 0.0, fmt.Errorf("no strike found for delta %v at %v days on the volatility surface", delta, daysToExpiry)
+	}}
+}
@@ -0,0 +1,83 @@
+package option
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRelativeAxes(t *testing.T) {
+	tests := []struct {
+		name string
+		axis *RelativeAxis
+		want []float64
+	}{
+		{"percent", PercentMoveAxis(80, ValueList{-10, 0, 25}), []float64{72, 80, 100}},
+		{"moneyness", MoneynessAxis(80, ValueList{1.1, 0.9}), []float64{72, 88}},
+		// A year at 50% volatility is a standard deviation of 0.5 in the log price
+		{"standard deviation", StandardDeviationAxis(100, 0.5, 365, ValueList{-2, 0, 2}),
+			[]float64{roundAxisValue(100 / math.E), 100, roundAxisValue(100 * math.E)}},
+		{"normal deviation", NormalDeviationAxis(-2, 8, 91.25, ValueList{-1, 1}), []float64{-6, 2}},
+	}
+	for _, test := range tests {
+		got, err := test.axis.Values(test.name)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	if _, err := PercentMoveAxis(80, ValueList{-100}).Values("percent"); err == nil {
+		t.Errorf("a move of -100%% resolved to a price")
+	}
+}
+
+// Strikes found by delta are priced back to that delta
+func TestDeltaStrikeAxis(t *testing.T) {
+	deltas := ValueList{0.1, 0.25, 0.5, 0.75}
+	tests := []struct {
+		name          string
+		model         int
+		dividendYield float64
+		dividends     []Dividend
+	}{
+		{"BlackScholes", BlackScholes, 0.0, nil},
+		{"yield", BlackScholes, 0.03, nil},
+		{"dividends", BlackScholes, 0.0, []Dividend{{45, 2}}},
+		{"Black76", Black76, 0.0, nil},
+	}
+	for _, test := range tests {
+		for _, optionType := range []int{Call, Put} {
+			chain, err := NewOptionChain(optionType, 0.3, 0.05, 365)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := chain.SetDividends(test.dividendYield, test.dividends); err != nil {
+				t.Fatal(err)
+			}
+			if err := chain.SetModel(test.model); err != nil {
+				t.Fatal(err)
+			}
+			chain.WithGreeks = true
+			points, err := deltas.Values("deltas")
+			if err != nil {
+				t.Fatal(err)
+			}
+			axis := chain.DeltaStrikeAxis(100, 90, deltas)
+			for _, delta := range points {
+				strike, err := axis.Resolve(delta)
+				if err != nil {
+					t.Fatalf("%s type %d delta %v: %v", test.name, optionType, delta, err)
+				}
+				var position OptionPosition
+				if err := chain.calculatePrice(100, strike, 90, &position); err != nil {
+					t.Fatal(err)
+				}
+				if got := math.Abs(position.Greeks.Delta); math.Abs(got-delta) > 1e-8 {
+					t.Errorf("%s type %d: strike %v has delta %v, want %v", test.name, optionType, strike, got, delta)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package option
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// Tolerance of the asset price at which a compound option or complex chooser is
+// exercised at indifference, relative to the strike
+const criticalPriceTolerance = 1e-12
+
+// Gauss-Legendre abscissae and weights on [-1, 0) for the bivariate normal, with
+// more points for stronger correlations (Genz)
+var (
+	bivariateAbscissae = [3][]float64{
+		{-0.9324695142031522, -0.6612093864662647, -0.2386191860831970},
+		{-0.9815606342467191, -0.9041172563704750, -0.7699026741943050, -0.5873179542866171, -0.3678314989981802, -0.1252334085114692},
+		{-0.9931285991850949, -0.9639719272779138, -0.9122344282513259, -0.8391169718222188, -0.7463319064601508,
+			-0.6360536807265150, -0.5108670019508271, -0.3737060887154196, -0.2277858511416451, -0.07652652113349733},
+	}
+	bivariateWeights = [3][]float64{
+		{0.1713244923791705, 0.3607615730481384, 0.4679139345726904},
+		{0.04717533638651177, 0.1069393259953183, 0.1600783285433464, 0.2031674267230659, 0.2334925365383547, 0.2491470458134029},
+		{0.01761400713915212, 0.04060142980038694, 0.06267204833410906, 0.08327674157670475, 0.1019301198172404,
+			0.1181945319615184, 0.1316886384491766, 0.1420961093183821, 0.1491729864726037, 0.1527533871307259},
+	}
+)
+
+// bivariateNormalCDF is the probability that two standard normals with
+// correlation rho are below a and b, to double precision by Genz's method
+// (Drezner and Wesolowsky's integral over the correlation)
+func bivariateNormalCDF(a, b, rho float64) float64 {
+	// Genz works with the upper tail, P(X > h, Y > k)
+	h, k := -a, -b
+	points := 2
+	switch {
+	case math.Abs(rho) < 0.3:
+		points = 0
+	case math.Abs(rho) < 0.75:
+		points = 1
+	}
+	abscissae, weights := bivariateAbscissae[points], bivariateWeights[points]
+	hk := h * k
+
+	if math.Abs(rho) < 0.925 {
+		hs := (h*h + k*k) / 2.0
+		asr := math.Asin(rho)
+		sum := 0.0
+		for i, x := range abscissae {
+			for _, sign := range []float64{-1.0, 1.0} {
+				sn := math.Sin(asr * (sign*x + 1.0) / 2.0)
+				sum += weights[i] * math.Exp((sn*hk-hs)/(1.0-sn*sn))
+			}
+		}
+		return sum*asr/(4.0*math.Pi) + normalizedCDF(-h)*normalizedCDF(-k)
+	}
+
+	if rho < 0.0 {
+		k, hk = -k, -hk
+	}
+	sum := 0.0
+	if math.Abs(rho) < 1.0 {
+		as := (1.0 - rho) * (1.0 + rho)
+		a := math.Sqrt(as)
+		bs := (h - k) * (h - k)
+		c := (4.0 - hk) / 8.0
+		d := (12.0 - hk) / 16.0
+		sum = a * math.Exp(-(bs/as+hk)/2.0) * (1.0 - c*(bs-as)*(1.0-d*bs/5.0)/3.0 + c*d*as*as/5.0)
+		if hk > -160.0 {
+			b := math.Sqrt(bs)
+			sum -= math.Exp(-hk/2.0) * math.Sqrt(2.0*math.Pi) * normalizedCDF(-b/a) * b * (1.0 - c*bs*(1.0-d*bs/5.0)/3.0)
+		}
+		a /= 2.0
+		for i, x := range abscissae {
+			for _, sign := range []float64{-1.0, 1.0} {
+				xs := (a * (sign*x + 1.0)) * (a * (sign*x + 1.0))
+				rs := math.Sqrt(1.0 - xs)
+				sum += a * weights[i] * (math.Exp(-bs/(2.0*xs)-hk/(1.0+rs))/rs - math.Exp(-(bs/xs+hk)/2.0)*(1.0+c*xs*(1.0+d*xs)))
+			}
+		}
+		sum = -sum / (2.0 * math.Pi)
+	}
+	if rho > 0.0 {
+		return sum + normalizedCDF(-math.Max(h, k))
+	}
+	return -sum + math.Max(0.0, normalizedCDF(-h)-normalizedCDF(-k))
+}
+
+// blackScholesValue is the Black-Scholes price of a European option on an asset
+// with a continuous dividend yield
+func blackScholesValue(optionType int, assetPrice, strikePrice, yearsToExpiry, volatility, riskFreeRate, dividendYield float64) float64 {
+	stdDev := volatility * math.Sqrt(yearsToExpiry)
+	d1 := (math.Log(assetPrice/strikePrice) + (riskFreeRate-dividendYield)*yearsToExpiry + stdDev*stdDev/2.0) / stdDev
+	d2 := d1 - stdDev
+	discountedAsset := assetPrice * math.Exp(-dividendYield*yearsToExpiry)
+	discountedStrike := strikePrice * math.Exp(-riskFreeRate*yearsToExpiry)
+	if optionType == Call {
+		return discountedAsset*normalizedCDF(d1) - discountedStrike*normalizedCDF(d2)
+	}
+	return discountedStrike*normalizedCDF(-d2) - discountedAsset*normalizedCDF(-d1)
+}
+
+// criticalPrice finds the asset price at which excess, increasing in the asset
+// price, is zero, searching outwards from strike
+func criticalPrice(excess func(assetPrice float64) float64, strike float64) (float64, error) {
+	low, high := strike, strike
+	for excess(low) > 0.0 {
+		if low /= 2.0; low < strike*1e-12 {
+			return 0.0, fmt.Errorf("no critical asset price above %v", low)
+		}
+	}
+	for excess(high) < 0.0 {
+		if high *= 2.0; high > strike*1e12 {
+			return 0.0, fmt.Errorf("no critical asset price below %v", high)
+		}
+	}
+	return util.Brent(excess, low, high, criticalPriceTolerance*strike, brentIterations)
+}
+
+// CompoundOption is an option on an option: at DaysToExpiry the holder may buy
+// (Call) or sell (Put) for Strike an Underlying call or put with its own strike
+// and expiry, which is later
+type CompoundOption struct {
+	OptionType             int
+	Strike                 float64
+	DaysToExpiry           float64
+	Underlying             int
+	UnderlyingStrike       float64
+	UnderlyingDaysToExpiry float64
+}
+
+func (compound *CompoundOption) Validate() error {
+	for _, optionType := range []int{compound.OptionType, compound.Underlying} {
+		if optionType != Call && optionType != Put {
+			return fmt.Errorf("unrecognized optionType %d", optionType)
+		}
+	}
+	if compound.Strike <= 0.0 || compound.UnderlyingStrike <= 0.0 {
+		return fmt.Errorf("compound and underlying strikes must be > 0, got %v/%v", compound.Strike, compound.UnderlyingStrike)
+	}
+	if compound.DaysToExpiry <= 0.0 || compound.UnderlyingDaysToExpiry <= compound.DaysToExpiry {
+		return fmt.Errorf("the compound option must expire in > 0 days and before its underlying, got %v/%v",
+			compound.DaysToExpiry, compound.UnderlyingDaysToExpiry)
+	}
+	return nil
+}
+
+// Price prices the compound option in closed form (Geske), returning also the
+// asset price at its expiry above which an underlying call, or below which an
+// underlying put, is worth more than the compound strike
+func (compound *CompoundOption) Price(assetPrice, volatility, riskFreeRate, dividendYield float64) (float64, float64, error) {
+	if err := compound.Validate(); err != nil {
+		return 0.0, 0.0, err
+	}
+	if assetPrice <= 0.0 || volatility <= 0.0 {
+		return 0.0, 0.0, fmt.Errorf("asset price and volatility must be > 0, got %v/%v", assetPrice, volatility)
+	}
+	t1, t2 := compound.DaysToExpiry/365, compound.UnderlyingDaysToExpiry/365
+	remaining := t2 - t1
+	underlyingStrike := compound.UnderlyingStrike
+	if compound.Underlying == Put && compound.Strike >= underlyingStrike*math.Exp(-riskFreeRate*remaining) {
+		return 0.0, 0.0, fmt.Errorf("compound strike %v is above any value of the underlying put", compound.Strike)
+	}
+	sign := 1.0
+	if compound.Underlying == Put {
+		sign = -1.0
+	}
+	critical, err := criticalPrice(func(price float64) float64 {
+		return sign * (blackScholesValue(compound.Underlying, price, underlyingStrike, remaining, volatility, riskFreeRate, dividendYield) - compound.Strike)
+	}, underlyingStrike)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+
+	carry := riskFreeRate - dividendYield
+	y1 := (math.Log(assetPrice/critical) + (carry+volatility*volatility/2.0)*t1) / (volatility * math.Sqrt(t1))
+	y2 := y1 - volatility*math.Sqrt(t1)
+	z1 := (math.Log(assetPrice/underlyingStrike) + (carry+volatility*volatility/2.0)*t2) / (volatility * math.Sqrt(t2))
+	z2 := z1 - volatility*math.Sqrt(t2)
+	rho := math.Sqrt(t1 / t2)
+	asset := assetPrice * math.Exp(-dividendYield*t2)
+	strike := underlyingStrike * math.Exp(-riskFreeRate*t2)
+	compoundStrike := compound.Strike * math.Exp(-riskFreeRate*t1)
+
+	var price float64
+	switch {
+	case compound.OptionType == Call && compound.Underlying == Call:
+		price = asset*bivariateNormalCDF(z1, y1, rho) - strike*bivariateNormalCDF(z2, y2, rho) - compoundStrike*normalizedCDF(y2)
+	case compound.OptionType == Put && compound.Underlying == Call:
+		price = strike*bivariateNormalCDF(z2, -y2, -rho) - asset*bivariateNormalCDF(z1, -y1, -rho) + compoundStrike*normalizedCDF(-y2)
+	case compound.OptionType == Call && compound.Underlying == Put:
+		price = strike*bivariateNormalCDF(-z2, -y2, rho) - asset*bivariateNormalCDF(-z1, -y1, rho) - compoundStrike*normalizedCDF(-y2)
+	default:
+		price = asset*bivariateNormalCDF(-z1, y1, -rho) - strike*bivariateNormalCDF(-z2, y2, -rho) + compoundStrike*normalizedCDF(y2)
+	}
+	return math.Max(price, 0.0), critical, nil
+}
+
+// ChooserOption lets the holder choose after DaysToChoice whether it is a call or
+// a put.  A simple chooser's call and put share a strike and expiry; a complex
+// one's differ.
+type ChooserOption struct {
+	DaysToChoice     float64
+	CallStrike       float64
+	CallDaysToExpiry float64
+	PutStrike        float64
+	PutDaysToExpiry  float64
+}
+
+func (chooser *ChooserOption) Validate() error {
+	if chooser.CallStrike <= 0.0 || chooser.PutStrike <= 0.0 {
+		return fmt.Errorf("chooser call and put strikes must be > 0, got %v/%v", chooser.CallStrike, chooser.PutStrike)
+	}
+	if chooser.DaysToChoice <= 0.0 || chooser.CallDaysToExpiry < chooser.DaysToChoice || chooser.PutDaysToExpiry < chooser.DaysToChoice {
+		return fmt.Errorf("the choice must come in > 0 days and no later than the call and put expire, got %v/%v/%v",
+			chooser.DaysToChoice, chooser.CallDaysToExpiry, chooser.PutDaysToExpiry)
+	}
+	return nil
+}
+
+// Simple reports whether the call and put share their strike and expiry
+func (chooser *ChooserOption) Simple() bool {
+	return chooser.CallStrike == chooser.PutStrike && chooser.CallDaysToExpiry == chooser.PutDaysToExpiry
+}
+
+// Price prices the chooser in closed form: a simple chooser as a call plus a put
+// expiring at the choice (Rubinstein), and a complex one with the asset price at
+// the choice that makes the call and put worth the same, which it also returns
+func (chooser *ChooserOption) Price(assetPrice, volatility, riskFreeRate, dividendYield float64) (float64, float64, error) {
+	if err := chooser.Validate(); err != nil {
+		return 0.0, 0.0, err
+	}
+	if assetPrice <= 0.0 || volatility <= 0.0 {
+		return 0.0, 0.0, fmt.Errorf("asset price and volatility must be > 0, got %v/%v", assetPrice, volatility)
+	}
+	t := chooser.DaysToChoice / 365
+	carry := riskFreeRate - dividendYield
+	if chooser.Simple() {
+		T := chooser.CallDaysToExpiry / 365
+		strike := chooser.CallStrike
+		// By parity the put is a put on the strike discounted from expiry to the choice
+		call := blackScholesValue(Call, assetPrice, strike, T, volatility, riskFreeRate, dividendYield)
+		adjustedStrike := strike * math.Exp(-carry*(T-t))
+		put := blackScholesValue(Put, assetPrice, adjustedStrike, t, volatility, riskFreeRate, dividendYield) * math.Exp(-dividendYield*(T-t))
+		return call + put, 0.0, nil
+	}
+
+	tc, tp := chooser.CallDaysToExpiry/365, chooser.PutDaysToExpiry/365
+	critical, err := criticalPrice(func(price float64) float64 {
+		return blackScholesValue(Call, price, chooser.CallStrike, tc-t, volatility, riskFreeRate, dividendYield) -
+			blackScholesValue(Put, price, chooser.PutStrike, tp-t, volatility, riskFreeRate, dividendYield)
+	}, chooser.CallStrike)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	d1 := (math.Log(assetPrice/critical) + (carry+volatility*volatility/2.0)*t) / (volatility * math.Sqrt(t))
+	d2 := d1 - volatility*math.Sqrt(t)
+	y1 := (math.Log(assetPrice/chooser.CallStrike) + (carry+volatility*volatility/2.0)*tc) / (volatility * math.Sqrt(tc))
+	y2 := (math.Log(assetPrice/chooser.PutStrike) + (carry+volatility*volatility/2.0)*tp) / (volatility * math.Sqrt(tp))
+	rho1, rho2 := math.Sqrt(t/tc), math.Sqrt(t/tp)
+	price := assetPrice*math.Exp(-dividendYield*tc)*bivariateNormalCDF(d1, y1, rho1) -
+		chooser.CallStrike*math.Exp(-riskFreeRate*tc)*bivariateNormalCDF(d2, y1-volatility*math.Sqrt(tc), rho1) -
+		assetPrice*math.Exp(-dividendYield*tp)*bivariateNormalCDF(-d1, -y2, rho2) +
+		chooser.PutStrike*math.Exp(-riskFreeRate*tp)*bivariateNormalCDF(-d2, -y2+volatility*math.Sqrt(tp), rho2)
+	return math.Max(price, 0.0), critical, nil
+}
+
+// ExchangeOption is the right to give Quantity2 units of a second asset for
+// Quantity1 units of the first at expiry
+type ExchangeOption struct {
+	Quantity1    float64
+	Quantity2    float64
+	DaysToExpiry float64
+}
+
+// ExchangeAsset is one asset of an exchange option
+type ExchangeAsset struct {
+	Price         float64
+	Volatility    float64
+	DividendYield float64
+}
+
+// Price prices the exchange option in closed form (Margrabe): Black-Scholes on
+// the ratio of the two assets, with the second as the numeraire, so the
+// risk-free rate drops out.  It also returns the volatility of the ratio.
+func (exchange *ExchangeOption) Price(first, second ExchangeAsset, correlation float64) (float64, float64, error) {
+	if exchange.Quantity1 <= 0.0 || exchange.Quantity2 <= 0.0 || exchange.DaysToExpiry <= 0.0 {
+		return 0.0, 0.0, fmt.Errorf("exchange quantities and days to expiry must be > 0, got %v/%v/%v",
+			exchange.Quantity1, exchange.Quantity2, exchange.DaysToExpiry)
+	}
+	for _, asset := range []ExchangeAsset{first, second} {
+		if asset.Price <= 0.0 || asset.Volatility <= 0.0 {
+			return 0.0, 0.0, fmt.Errorf("asset prices and volatilities must be > 0, got %v/%v", asset.Price, asset.Volatility)
+		}
+	}
+	if correlation < -1.0 || correlation > 1.0 {
+		return 0.0, 0.0, fmt.Errorf("correlation must be between -1 and 1, got %v", correlation)
+	}
+	volatility := math.Sqrt(first.Volatility*first.Volatility + second.Volatility*second.Volatility -
+		2.0*correlation*first.Volatility*second.Volatility)
+	years := exchange.DaysToExpiry / 365
+	received := exchange.Quantity1 * first.Price
+	given := exchange.Quantity2 * second.Price
+	if volatility < 1e-12 {
+		// Perfectly correlated assets of equal volatility keep their ratio
+		return math.Max(received*math.Exp(-first.DividendYield*years)-given*math.Exp(-second.DividendYield*years), 0.0), volatility, nil
+	}
+	return blackScholesValue(Call, received, given, years, volatility, second.DividendYield, first.DividendYield), volatility, nil
+}
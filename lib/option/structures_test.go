@@ -0,0 +1,160 @@
+package option
+
+import (
+	"math"
+	"testing"
+)
+
+// simpson integrates f over [low, high] with Simpson's rule on intervals
+// intervals, an even number
+func simpson(f func(float64) float64, low, high float64, intervals int) float64 {
+	width := (high - low) / float64(intervals)
+	sum := f(low) + f(high)
+	for i := 1; i < intervals; i++ {
+		weight := 2.0
+		if i%2 == 1 {
+			weight = 4.0
+		}
+		sum += weight * f(low+float64(i)*width)
+	}
+	return sum * width / 3.0
+}
+
+// bivariateByQuadrature integrates the density of the first normal times the
+// probability that the second is below b given it
+func bivariateByQuadrature(a, b, rho float64) float64 {
+	scale := math.Sqrt(1.0 - rho*rho)
+	return simpson(func(x float64) float64 {
+		return normalizedPDF(x) * normalizedCDF((b-rho*x)/scale)
+	}, -12.0, a, 4000)
+}
+
+func TestBivariateNormalCDF(t *testing.T) {
+	tests := []struct {
+		a, b, rho, want float64
+	}{
+		{0, 0, 0, 0.25},
+		{0, 0, 0.5, 1.0 / 3.0},
+		{0, 0, -0.5, 1.0 / 6.0},
+		{0, 0, 0.95, 0.25 + math.Asin(0.95)/(2.0*math.Pi)},
+		{1, -0.5, 0, normalizedCDF(1) * normalizedCDF(-0.5)},
+		// Perfect correlation leaves one normal
+		{0.3, 1.2, 1, normalizedCDF(0.3)},
+		{0.3, 1.2, 0.9999999999, normalizedCDF(0.3)},
+		{0.3, 1.2, -1, normalizedCDF(0.3) + normalizedCDF(1.2) - 1.0},
+		{0.3, 1.2, -0.9999999999, normalizedCDF(0.3) + normalizedCDF(1.2) - 1.0},
+		{-0.3, -1.2, -0.9999999999, 0.0},
+	}
+	for _, test := range tests {
+		if got := bivariateNormalCDF(test.a, test.b, test.rho); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("M(%v, %v, %v): got %.12f, want %.12f", test.a, test.b, test.rho, got, test.want)
+		}
+	}
+}
+
+// Each branch of Genz's method against quadrature, and symmetric in a and b
+func TestBivariateNormalCDFQuadrature(t *testing.T) {
+	for _, rho := range []float64{-0.99, -0.93, -0.8, -0.5, -0.2, 0.1, 0.4, 0.74, 0.76, 0.92, 0.93, 0.99} {
+		for _, point := range [][2]float64{{-2, -1.5}, {-1, 0.5}, {0, 0}, {0.5, 2}, {1.5, -0.25}, {2.5, 3}} {
+			a, b := point[0], point[1]
+			got := bivariateNormalCDF(a, b, rho)
+			if want := bivariateByQuadrature(a, b, rho); math.Abs(got-want) > 1e-9 {
+				t.Errorf("M(%v, %v, %v): got %.12f, want %.12f", a, b, rho, got, want)
+			}
+			if swapped := bivariateNormalCDF(b, a, rho); math.Abs(got-swapped) > 1e-14 {
+				t.Errorf("M(%v, %v, %v) = %.15f but M(%v, %v, %v) = %.15f", a, b, rho, got, b, a, rho, swapped)
+			}
+		}
+	}
+}
+
+// A put on a call against Haug's example of Geske's formula, which he rounds
+// from a less exact bivariate normal
+func TestCompoundOptionReference(t *testing.T) {
+	compound := CompoundOption{
+		OptionType: Put, Strike: 50, DaysToExpiry: 0.25 * 365,
+		Underlying: Call, UnderlyingStrike: 520, UnderlyingDaysToExpiry: 0.5 * 365,
+	}
+	price, _, err := compound.Price(500, 0.35, 0.08, 0.03)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(price-21.1965) > 2e-4 {
+		t.Errorf("got %.4f, want 21.1965", price)
+	}
+}
+
+// A call on an option less a put on it is the option less the strike today
+func TestCompoundOptionParity(t *testing.T) {
+	for _, underlying := range []int{Call, Put} {
+		call := CompoundOption{OptionType: Call, Strike: 5, DaysToExpiry: 60, Underlying: underlying, UnderlyingStrike: 100, UnderlyingDaysToExpiry: 180}
+		put := call
+		put.OptionType = Put
+		callPrice, _, err := call.Price(100, 0.3, 0.05, 0.02)
+		if err != nil {
+			t.Fatal(err)
+		}
+		putPrice, _, err := put.Price(100, 0.3, 0.05, 0.02)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := blackScholesValue(underlying, 100, 100, 180.0/365, 0.3, 0.05, 0.02) - 5*math.Exp(-0.05*60/365)
+		if math.Abs(callPrice-putPrice-want) > 1e-9 {
+			t.Errorf("underlying %d: call - put = %.9f, want %.9f", underlying, callPrice-putPrice, want)
+		}
+	}
+}
+
+// Simple and complex choosers against Haug's examples
+func TestChooserOptionReference(t *testing.T) {
+	tests := []struct {
+		name    string
+		chooser ChooserOption
+
+		assetPrice, volatility, riskFreeRate, dividendYield, want float64
+	}{
+		{"simple", ChooserOption{DaysToChoice: 0.25 * 365, CallStrike: 50, CallDaysToExpiry: 0.5 * 365, PutStrike: 50, PutDaysToExpiry: 0.5 * 365},
+			50, 0.25, 0.08, 0.0, 6.1071},
+		{"complex", ChooserOption{DaysToChoice: 0.25 * 365, CallStrike: 55, CallDaysToExpiry: 0.5 * 365, PutStrike: 48, PutDaysToExpiry: 0.5833 * 365},
+			50, 0.35, 0.1, 0.05, 6.0508},
+	}
+	for _, test := range tests {
+		price, _, err := test.chooser.Price(test.assetPrice, test.volatility, test.riskFreeRate, test.dividendYield)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(price-test.want) > 1e-4 {
+			t.Errorf("%s: got %.4f, want %.4f", test.name, price, test.want)
+		}
+	}
+}
+
+// Margrabe's formula against quadrature over the second asset, where the first
+// is lognormal given it and the payoff is a call struck at the second
+func TestExchangeOptionQuadrature(t *testing.T) {
+	const (
+		riskFreeRate = 0.1
+		correlation  = -0.5
+	)
+	exchange := ExchangeOption{Quantity1: 1, Quantity2: 1, DaysToExpiry: 36.5}
+	first := ExchangeAsset{Price: 22, Volatility: 0.2, DividendYield: 0.06}
+	second := ExchangeAsset{Price: 20, Volatility: 0.25, DividendYield: 0.04}
+	got, _, err := exchange.Price(first, second, correlation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	years := exchange.DaysToExpiry / 365
+	stdDev1, stdDev2 := first.Volatility*math.Sqrt(years), second.Volatility*math.Sqrt(years)
+	want := math.Exp(-riskFreeRate*years) * simpson(func(z float64) float64 {
+		strike := second.Price * math.Exp((riskFreeRate-second.DividendYield)*years-stdDev2*stdDev2/2.0+stdDev2*z)
+		mean := math.Log(first.Price) + (riskFreeRate-first.DividendYield)*years - stdDev1*stdDev1/2.0 + correlation*stdDev1*z
+		stdDev := stdDev1 * math.Sqrt(1.0-correlation*correlation)
+		forward := math.Exp(mean + stdDev*stdDev/2.0)
+		d1 := (math.Log(forward/strike) + stdDev*stdDev/2.0) / stdDev
+		return normalizedPDF(z) * (forward*normalizedCDF(d1) - strike*normalizedCDF(d1-stdDev))
+	}, -12.0, 12.0, 4000)
+	if math.Abs(got-want) > 1e-8 {
+		t.Errorf("got %.9f, want %.9f", got, want)
+	}
+}
@@ -0,0 +1,193 @@
+package option
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// Interpolation of a volatility surface across strikes
+const (
+	StrikeLinear = iota
+	StrikeSpline
+)
+
+// Interpolation of a volatility surface across expiries
+const (
+	ExpiryTotalVariance = iota
+	ExpiryVolatility
+)
+
+// Extrapolation beyond the strikes and expiries of a volatility surface
+const (
+	ExtrapolateFlat = iota
+	ExtrapolateLinear
+)
+
+// VolatilitySource gives the volatility to price one option of a chain with
+type VolatilitySource interface {
+	Volatility(assetPrice, strikePrice, daysToExpiry float64) (float64, error)
+}
+
+// RateSource gives the continuously compounded zero rate to discount an expiry at
+type RateSource interface {
+	ZeroRate(daysToExpiry float64) float64
+}
+
+// VolatilitySurface interpolates a grid of implied volatilities by strike and days
+// to expiry.  With Moneyness set, strikes are strike over asset price and the
+// smile moves with the asset price; otherwise strikes are prices and the smile
+// stays put as the asset price moves.
+type VolatilitySurface struct {
+	Moneyness    bool
+	Strikes      []float64
+	DaysToExpiry []float64
+	// One row of volatilities per days to expiry, one column per strike
+	Volatilities        [][]float64
+	StrikeInterpolation int
+	ExpiryInterpolation int
+	Extrapolation       int
+	splines             []*util.Spline
+}
+
+// NewVolatilitySurface validates the grid and sorts it by strike and expiry
+func NewVolatilitySurface(moneyness bool, strikes, daysToExpiry []float64, volatilities [][]float64,
+	strikeInterpolation, expiryInterpolation, extrapolation int) (*VolatilitySurface, error) {
+	if len(strikes) == 0 || len(daysToExpiry) == 0 {
+		return nil, fmt.Errorf("volatility surface needs at least one strike and one expiry")
+	}
+	if len(volatilities) != len(daysToExpiry) {
+		return nil, fmt.Errorf("volatility surface has %d rows for %d expiries", len(volatilities), len(daysToExpiry))
+	}
+	if strikeInterpolation != StrikeLinear && strikeInterpolation != StrikeSpline {
+		return nil, fmt.Errorf("unrecognized strike interpolation %d", strikeInterpolation)
+	}
+	if expiryInterpolation != ExpiryTotalVariance && expiryInterpolation != ExpiryVolatility {
+		return nil, fmt.Errorf("unrecognized expiry interpolation %d", expiryInterpolation)
+	}
+	if extrapolation != ExtrapolateFlat && extrapolation != ExtrapolateLinear {
+		return nil, fmt.Errorf("unrecognized extrapolation %d", extrapolation)
+	}
+
+	strikeOrder := sortedOrder(strikes)
+	expiryOrder := sortedOrder(daysToExpiry)
+	surface := VolatilitySurface{
+		Moneyness:           moneyness,
+		StrikeInterpolation: strikeInterpolation,
+		ExpiryInterpolation: expiryInterpolation,
+		Extrapolation:       extrapolation,
+	}
+	for _, i := range strikeOrder {
+		if strikes[i] <= 0.0 {
+			return nil, fmt.Errorf("volatility surface strikes must be > 0, got %v", strikes[i])
+		}
+		surface.Strikes = append(surface.Strikes, strikes[i])
+	}
+	for _, row := range expiryOrder {
+		if daysToExpiry[row] <= 0.0 {
+			return nil, fmt.Errorf("volatility surface days to expiry must be > 0, got %v", daysToExpiry[row])
+		}
+		if len(volatilities[row]) != len(strikes) {
+			return nil, fmt.Errorf("volatility surface row for %v days has %d volatilities for %d strikes",
+				daysToExpiry[row], len(volatilities[row]), len(strikes))
+		}
+		smile := make([]float64, len(strikes))
+		for column, i := range strikeOrder {
+			if !(volatilities[row][i] > 0.0) {
+				return nil, fmt.Errorf("volatility at strike %v and %v days must be > 0, got %v",
+					strikes[i], daysToExpiry[row], volatilities[row][i])
+			}
+			smile[column] = volatilities[row][i]
+		}
+		surface.DaysToExpiry = append(surface.DaysToExpiry, daysToExpiry[row])
+		surface.Volatilities = append(surface.Volatilities, smile)
+	}
+	for i := 1; i < len(surface.Strikes); i++ {
+		if surface.Strikes[i] == surface.Strikes[i-1] {
+			return nil, fmt.Errorf("volatility surface strike %v is repeated", surface.Strikes[i])
+		}
+	}
+	for i := 1; i < len(surface.DaysToExpiry); i++ {
+		if surface.DaysToExpiry[i] == surface.DaysToExpiry[i-1] {
+			return nil, fmt.Errorf("volatility surface expiry %v is repeated", surface.DaysToExpiry[i])
+		}
+	}
+
+	if strikeInterpolation == StrikeSpline {
+		for _, smile := range surface.Volatilities {
+			spline, err := util.NewSpline(surface.Strikes, smile)
+			if err != nil {
+				return nil, err
+			}
+			surface.splines = append(surface.splines, spline)
+		}
+	}
+	return &surface, nil
+}
+
+// sortedOrder lists the indexes of values in ascending order of value
+func sortedOrder(values []float64) []int {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return values[order[i]] < values[order[j]] })
+	return order
+}
+
+// smileVolatility interpolates the volatility at a strike for one expiry of the grid
+func (surface *VolatilitySurface) smileVolatility(row int, strike float64) float64 {
+	if surface.Extrapolation == ExtrapolateFlat {
+		strike = math.Max(surface.Strikes[0], math.Min(strike, surface.Strikes[len(surface.Strikes)-1]))
+	}
+	if surface.StrikeInterpolation == StrikeSpline {
+		return surface.splines[row].At(strike)
+	}
+	return util.LinearInterpolate(surface.Strikes, surface.Volatilities[row], strike)
+}
+
+// Volatility interpolates across strikes on each expiry of the grid, then across
+// expiries linearly in total variance (volatility squared times time) or in
+// volatility.  Outside the grid volatility is held flat or extrapolated linearly
+// from the outermost strikes and expiries.
+func (surface *VolatilitySurface) Volatility(assetPrice, strikePrice, daysToExpiry float64) (float64, error) {
+	strike := strikePrice
+	if surface.Moneyness {
+		if assetPrice <= 0.0 {
+			return 0.0, fmt.Errorf("moneyness needs an asset price > 0, got %v", assetPrice)
+		}
+		strike = strikePrice / assetPrice
+	}
+
+	expiries := surface.DaysToExpiry
+	last := len(expiries) - 1
+	var volatility float64
+	switch {
+	case last == 0 || (surface.Extrapolation == ExtrapolateFlat && daysToExpiry <= expiries[0]):
+		volatility = surface.smileVolatility(0, strike)
+	case surface.Extrapolation == ExtrapolateFlat && daysToExpiry >= expiries[last]:
+		volatility = surface.smileVolatility(last, strike)
+	default:
+		i := max(0, min(sort.SearchFloat64s(expiries, daysToExpiry)-1, last-1))
+		lower, upper := surface.smileVolatility(i, strike), surface.smileVolatility(i+1, strike)
+		weight := (daysToExpiry - expiries[i]) / (expiries[i+1] - expiries[i])
+		if surface.ExpiryInterpolation == ExpiryVolatility {
+			volatility = lower + weight*(upper-lower)
+		} else {
+			lowerVariance := lower * lower * expiries[i]
+			upperVariance := upper * upper * expiries[i+1]
+			variance := lowerVariance + weight*(upperVariance-lowerVariance)
+			if variance <= 0.0 || daysToExpiry <= 0.0 {
+				return 0.0, fmt.Errorf("total variance at strike %v and %v days is not positive", strikePrice, daysToExpiry)
+			}
+			volatility = math.Sqrt(variance / daysToExpiry)
+		}
+	}
+
+	if !(volatility > 0.0) {
+		return 0.0, fmt.Errorf("volatility surface gives volatility %v at strike %v and %v days", volatility, strikePrice, daysToExpiry)
+	}
+	return volatility, nil
+}
@@ -0,0 +1,75 @@
+package option
+
+import (
+	"math"
+	"testing"
+)
+
+// A surface over strikes 90, 100 and 110 and expiries of 30 and 90 days, given
+// out of order
+func testSurface(t *testing.T, moneyness bool, strikeInterpolation, expiryInterpolation, extrapolation int) *VolatilitySurface {
+	t.Helper()
+	strikes := []float64{110, 90, 100}
+	if moneyness {
+		strikes = []float64{1.1, 0.9, 1.0}
+	}
+	surface, err := NewVolatilitySurface(moneyness, strikes, []float64{90, 30},
+		[][]float64{{0.22, 0.28, 0.24}, {0.26, 0.34, 0.30}}, strikeInterpolation, expiryInterpolation, extrapolation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return surface
+}
+
+func TestVolatilitySurface(t *testing.T) {
+	tests := []struct {
+		name                                                    string
+		moneyness                                               bool
+		strikeInterpolation, expiryInterpolation, extrapolation int
+		assetPrice, strikePrice, daysToExpiry, want             float64
+	}{
+		{"grid point", false, StrikeLinear, ExpiryTotalVariance, ExtrapolateFlat, 100, 90, 30, 0.34},
+		{"between strikes", false, StrikeLinear, ExpiryTotalVariance, ExtrapolateFlat, 100, 95, 90, 0.26},
+		{"total variance", false, StrikeLinear, ExpiryTotalVariance, ExtrapolateFlat, 100, 100, 60,
+			math.Sqrt((0.30*0.30*30 + 0.24*0.24*90) / 2 / 60)},
+		{"volatility", false, StrikeLinear, ExpiryVolatility, ExtrapolateFlat, 100, 100, 60, 0.27},
+		{"flat below strikes", false, StrikeLinear, ExpiryTotalVariance, ExtrapolateFlat, 100, 50, 30, 0.34},
+		{"flat beyond expiries", false, StrikeLinear, ExpiryTotalVariance, ExtrapolateFlat, 100, 110, 365, 0.22},
+		{"linear below strikes", false, StrikeLinear, ExpiryVolatility, ExtrapolateLinear, 100, 80, 30, 0.38},
+		{"linear before expiries", false, StrikeLinear, ExpiryVolatility, ExtrapolateLinear, 100, 100, 15, 0.315},
+		{"spline at grid point", false, StrikeSpline, ExpiryTotalVariance, ExtrapolateFlat, 100, 110, 90, 0.22},
+		{"moneyness", true, StrikeLinear, ExpiryTotalVariance, ExtrapolateFlat, 200, 190, 90, 0.26},
+	}
+	for _, test := range tests {
+		surface := testSurface(t, test.moneyness, test.strikeInterpolation, test.expiryInterpolation, test.extrapolation)
+		got, err := surface.Volatility(test.assetPrice, test.strikePrice, test.daysToExpiry)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// A flat surface prices a chain exactly as the flat volatility does
+func TestFlatSurfacePrices(t *testing.T) {
+	surface, err := NewVolatilitySurface(false, []float64{80, 120}, []float64{30, 365},
+		[][]float64{{0.25, 0.25}, {0.25, 0.25}}, StrikeSpline, ExpiryTotalVariance, ExtrapolateLinear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := NewOptionChain(Call, 0.25, 0.05, 365)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.SetVolatilitySurface(surface)
+	for _, strikePrice := range []float64{70, 100, 130} {
+		var position OptionPosition
+		if err := chain.calculatePrice(100, strikePrice, 90, &position); err != nil {
+			t.Fatal(err)
+		}
+		if want := blackScholesValue(Call, 100, strikePrice, 90.0/365, 0.25, 0.05, 0.0); math.Abs(position.Price-want) > 1e-12 {
+			t.Errorf("K=%v: got %v, want %v", strikePrice, position.Price, want)
+		}
+	}
+}
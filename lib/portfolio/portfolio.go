@@ -0,0 +1,191 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/strategy"
+)
+
+const dayDuration = 24 * time.Hour
+
+// Holding is a position in one instrument on an underlying asset.  Instrument and
+// Side use the strategy package constants; Expiry and Strike apply to options only.
+type Holding struct {
+	ID         string    `json:"id"`
+	AssetName  string    `json:"assetName"`
+	Instrument int       `json:"instrument"`
+	Strike     float64   `json:"strike"`
+	Expiry     time.Time `json:"expiry"`
+	Quantity   float64   `json:"quantity"`
+	Side       int       `json:"side"`
+	EntryPrice float64   `json:"entryPrice"`
+	Multiplier float64   `json:"multiplier"`
+}
+
+// Market holds the pricing inputs for one underlying
+type Market struct {
+	SpotPrice    float64
+	Volatility   float64
+	RiskFreeRate float64
+}
+
+// Report values holdings per underlying and in total
+type Report struct {
+	Underlyings map[string]strategy.Valuation
+	Total       strategy.Valuation
+}
+
+// WhatIf compares a portfolio with and without a proposed trade
+type WhatIf struct {
+	Before *Report
+	After  *Report
+	Impact strategy.Valuation
+}
+
+func validateHolding(holding *Holding) error {
+	if holding.AssetName == "" {
+		return fmt.Errorf("holding asset name is required")
+	}
+	if holding.Quantity <= 0.0 {
+		return fmt.Errorf("holding quantity must be > 0")
+	}
+	if holding.Side != strategy.Long && holding.Side != strategy.Short {
+		return fmt.Errorf("unrecognized holding side %d", holding.Side)
+	}
+	if holding.Multiplier < 0.0 {
+		return fmt.Errorf("holding multiplier cannot be negative")
+	}
+	switch holding.Instrument {
+	case option.Call, option.Put:
+		if holding.Strike <= 0.0 || holding.Expiry.IsZero() {
+			return fmt.Errorf("option holding needs a strike > 0 and an expiry")
+		}
+	case strategy.Stock:
+	default:
+		return fmt.Errorf("unrecognized holding instrument %d", holding.Instrument)
+	}
+	return nil
+}
+
+// leg converts a holding into a strategy leg as of valuationTime.  The multiplier
+// is folded into the quantity so holdings with different multipliers can share
+// one strategy.
+func (holding *Holding) leg(valuationTime time.Time) strategy.Leg {
+	multiplier := holding.Multiplier
+	if multiplier == 0.0 {
+		multiplier = 1.0
+	}
+	leg := strategy.Leg{
+		Instrument: holding.Instrument,
+		Quantity:   holding.Quantity * multiplier,
+		Side:       holding.Side,
+		EntryPrice: holding.EntryPrice,
+	}
+	if holding.Instrument != strategy.Stock {
+		daysToExpiry := float64(holding.Expiry.Sub(valuationTime)) / float64(dayDuration)
+		if daysToExpiry < 0.0 {
+			daysToExpiry = 0.0
+		}
+		leg.Option = option.Option{Type: holding.Instrument, Strike: holding.Strike, Expiry: daysToExpiry}
+	}
+	return leg
+}
+
+func addValuation(total *strategy.Valuation, valuation *strategy.Valuation) {
+	total.Value += valuation.Value
+	total.ProfitLoss += valuation.ProfitLoss
+	total.Greeks.Delta += valuation.Greeks.Delta
+	total.Greeks.Gamma += valuation.Greeks.Gamma
+	total.Greeks.Theta += valuation.Greeks.Theta
+	total.Greeks.Vega += valuation.Greeks.Vega
+	total.Greeks.Rho += valuation.Greeks.Rho
+}
+
+func subtractValuation(after, before *strategy.Valuation) strategy.Valuation {
+	return strategy.Valuation{
+		Value:      after.Value - before.Value,
+		ProfitLoss: after.ProfitLoss - before.ProfitLoss,
+		Greeks: option.Greeks{
+			Delta: after.Greeks.Delta - before.Greeks.Delta,
+			Gamma: after.Greeks.Gamma - before.Greeks.Gamma,
+			Theta: after.Greeks.Theta - before.Greeks.Theta,
+			Vega:  after.Greeks.Vega - before.Greeks.Vega,
+			Rho:   after.Greeks.Rho - before.Greeks.Rho,
+		},
+	}
+}
+
+// Value prices holdings at valuationTime, grouping them by underlying.  Every
+// underlying held needs an entry in markets.
+func Value(holdings []Holding, markets map[string]Market, valuationTime time.Time) (*Report, error) {
+	legsByAsset := make(map[string][]strategy.Leg)
+	for i := range holdings {
+		holding := &holdings[i]
+		legsByAsset[holding.AssetName] = append(legsByAsset[holding.AssetName], holding.leg(valuationTime))
+	}
+
+	assetNames := make([]string, 0, len(legsByAsset))
+	for assetName := range legsByAsset {
+		assetNames = append(assetNames, assetName)
+	}
+	sort.Strings(assetNames)
+
+	report := Report{Underlyings: make(map[string]strategy.Valuation)}
+	for _, assetName := range assetNames {
+		market, ok := markets[assetName]
+		if !ok {
+			return nil, fmt.Errorf("no market data for %s", assetName)
+		}
+		asset := option.Asset{Name: assetName, Volatility: market.Volatility, RfReturn: market.RiskFreeRate}
+		legs, err := strategy.NewStrategy(asset, market.SpotPrice, 1.0, legsByAsset[assetName])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", assetName, err)
+		}
+		valuation, err := legs.Valuation()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", assetName, err)
+		}
+		report.Underlyings[assetName] = *valuation
+		addValuation(&report.Total, valuation)
+	}
+	return &report, nil
+}
+
+// TheoreticalPrice is the current Black-Scholes value of one unit of a holding,
+// the price a trade is entered at when it has none
+func TheoreticalPrice(holding *Holding, markets map[string]Market, valuationTime time.Time) (float64, error) {
+	if err := validateHolding(holding); err != nil {
+		return 0.0, err
+	}
+	market, ok := markets[holding.AssetName]
+	if !ok {
+		return 0.0, fmt.Errorf("no market data for %s", holding.AssetName)
+	}
+	asset := option.Asset{Name: holding.AssetName, Volatility: market.Volatility, RfReturn: market.RiskFreeRate}
+	legs, err := strategy.NewStrategy(asset, market.SpotPrice, 1.0, []strategy.Leg{holding.leg(valuationTime)})
+	if err != nil {
+		return 0.0, err
+	}
+	return legs.TheoreticalPrice(&legs.Legs[0])
+}
+
+// Impact values the portfolio before and after adding the trade holdings
+func Impact(holdings, trade []Holding, markets map[string]Market, valuationTime time.Time) (*WhatIf, error) {
+	for i := range trade {
+		if err := validateHolding(&trade[i]); err != nil {
+			return nil, fmt.Errorf("trade leg %d: %w", i, err)
+		}
+	}
+	before, err := Value(holdings, markets, valuationTime)
+	if err != nil {
+		return nil, err
+	}
+	after, err := Value(append(append([]Holding(nil), holdings...), trade...), markets, valuationTime)
+	if err != nil {
+		return nil, err
+	}
+	return &WhatIf{Before: before, After: after, Impact: subtractValuation(&after.Total, &before.Total)}, nil
+}
@@ -0,0 +1,53 @@
+package portfolio
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/strategy"
+)
+
+// A trade entered at its theoretical price adds value and Greeks but no profit
+func TestImpactAtTheoreticalPrice(t *testing.T) {
+	valuationTime := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	markets := map[string]Market{"ACME": {SpotPrice: 100, Volatility: 0.25, RiskFreeRate: 0.04}}
+	holdings := []Holding{{AssetName: "ACME", Instrument: strategy.Stock, Quantity: 100, Side: strategy.Long, EntryPrice: 90}}
+	for _, side := range []int{strategy.Long, strategy.Short} {
+		trade := Holding{
+			AssetName: "ACME", Instrument: option.Put, Strike: 95, Expiry: valuationTime.AddDate(0, 0, 73),
+			Quantity: 1, Side: side, Multiplier: 100,
+		}
+		price, err := TheoreticalPrice(&trade, markets, valuationTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := option.PriceOption(option.Put, 100, 95, 73, 0.25, 0.04, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(price-want.Price) > 1e-12 {
+			t.Errorf("theoretical price %v, want %v", price, want.Price)
+		}
+		trade.EntryPrice = price
+
+		whatIf, err := Impact(holdings, []Holding{trade}, markets, valuationTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(whatIf.Impact.ProfitLoss) > 1e-9 {
+			t.Errorf("side %d: trade profit %v, want 0", side, whatIf.Impact.ProfitLoss)
+		}
+		if math.Abs(math.Abs(whatIf.Impact.Value)-100*price) > 1e-9 {
+			t.Errorf("side %d: trade value %v, want %v", side, whatIf.Impact.Value, 100*price)
+		}
+	}
+}
+
+func TestTheoreticalPriceNeedsMarket(t *testing.T) {
+	holding := Holding{AssetName: "OTHER", Instrument: strategy.Stock, Quantity: 1, Side: strategy.Long}
+	if _, err := TheoreticalPrice(&holding, map[string]Market{}, time.Now()); err == nil {
+		t.Error("expected an error without market data")
+	}
+}
@@ -0,0 +1,114 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// ErrHoldingNotFound is returned when removing a holding that is not in the store
+var ErrHoldingNotFound = errors.New("holding not found")
+
+// Store keeps holdings in a local JSON file, rewritten atomically on every change
+type Store struct {
+	path     string
+	mutex    sync.Mutex
+	holdings []Holding
+	nextID   int
+}
+
+type storeFile struct {
+	NextID   int       `json:"nextId"`
+	Holdings []Holding `json:"holdings"`
+}
+
+// OpenStore loads the store at path, starting empty if the file does not exist yet
+func OpenStore(path string) (*Store, error) {
+	store := Store{path: path, nextID: 1}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var contents storeFile
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return nil, fmt.Errorf("portfolio store %s: %w", path, err)
+	}
+	store.holdings = contents.Holdings
+	if contents.NextID > store.nextID {
+		store.nextID = contents.NextID
+	}
+	return &store, nil
+}
+
+func (store *Store) save() error {
+	data, err := json.MarshalIndent(storeFile{NextID: store.nextID, Holdings: store.holdings}, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(store.path), filepath.Base(store.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), store.path)
+}
+
+// Holdings returns a copy of the stored holdings
+func (store *Store) Holdings() []Holding {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return append([]Holding{}, store.holdings...)
+}
+
+// Add validates and stores a holding, assigning its ID
+func (store *Store) Add(holding Holding) (Holding, error) {
+	if err := validateHolding(&holding); err != nil {
+		return Holding{}, err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	holding.ID = strconv.Itoa(store.nextID)
+	store.nextID++
+	store.holdings = append(store.holdings, holding)
+	if err := store.save(); err != nil {
+		store.holdings = store.holdings[:len(store.holdings)-1]
+		store.nextID--
+		return Holding{}, err
+	}
+	return holding, nil
+}
+
+// Remove deletes the holding with the given ID
+func (store *Store) Remove(id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	for i, holding := range store.holdings {
+		if holding.ID == id {
+			previous := store.holdings
+			store.holdings = append(append([]Holding{}, previous[:i]...), previous[i+1:]...)
+			if err := store.save(); err != nil {
+				store.holdings = previous
+				return err
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: id %s", ErrHoldingNotFound, id)
+}
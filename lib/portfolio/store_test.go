@@ -0,0 +1,94 @@
+package portfolio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/strategy"
+)
+
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// Holdings and the next ID survive reopening the store, and every rewrite
+// replaces the file without leaving temporary files behind
+func TestStoreReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "portfolio.json")
+	store := openStore(t, path)
+	if holdings := store.Holdings(); len(holdings) != 0 {
+		t.Fatalf("new store: got %d holdings, want 0", len(holdings))
+	}
+
+	stock := Holding{AssetName: "ACME", Instrument: strategy.Stock, Quantity: 100, Side: strategy.Long, EntryPrice: 90}
+	put := Holding{
+		AssetName: "ACME", Instrument: option.Put, Strike: 95, Expiry: time.Date(2026, 12, 18, 0, 0, 0, 0, time.UTC),
+		Quantity: 1, Side: strategy.Long, EntryPrice: 2.5, Multiplier: 100,
+	}
+	for _, holding := range []Holding{stock, put} {
+		if _, err := store.Add(holding); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Remove("1"); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := openStore(t, path)
+	holdings := reopened.Holdings()
+	if len(holdings) != 1 || holdings[0].ID != "2" || !holdings[0].Expiry.Equal(put.Expiry) || holdings[0].Strike != put.Strike {
+		t.Errorf("reopened: got %+v, want the put as holding 2", holdings)
+	}
+	added, err := reopened.Add(stock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.ID != "3" {
+		t.Errorf("after reopening: got ID %s, want 3", added.ID)
+	}
+	if err := reopened.Remove("1"); !errors.Is(err, ErrHoldingNotFound) {
+		t.Errorf("removing holding 1 twice: got %v, want %v", err, ErrHoldingNotFound)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "portfolio.json" {
+		t.Errorf("store directory holds %v, want only portfolio.json", entries)
+	}
+}
+
+// A holding that cannot be saved is not kept, and does not use up an ID
+func TestStoreAddRollback(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	store := openStore(t, filepath.Join(dir, "portfolio.json"))
+	stock := Holding{AssetName: "ACME", Instrument: strategy.Stock, Quantity: 100, Side: strategy.Long, EntryPrice: 90}
+	if _, err := store.Add(stock); err == nil {
+		t.Fatal("saving into a missing directory: got no error")
+	}
+	if holdings := store.Holdings(); len(holdings) != 0 {
+		t.Errorf("after a failed save: got %d holdings, want 0", len(holdings))
+	}
+
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	added, err := store.Add(stock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.ID != "1" {
+		t.Errorf("after a failed save: got ID %s, want 1", added.ID)
+	}
+}
@@ -0,0 +1,165 @@
+package rates
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// Instruments a curve can be bootstrapped from
+const (
+	// Money market deposit paying simple interest on an ACT/360 basis at maturity
+	Deposit = iota
+	// Par swap whose fixed leg pays the quoted rate Frequency times a year
+	Swap
+	// Treasury par yield, with coupons paid Frequency times a year
+	Treasury
+)
+
+const (
+	// Zero rates searched for each pillar
+	bootstrapRateLow    = -0.5
+	bootstrapRateHigh   = 1.0
+	bootstrapTolerance  = 1e-12
+	bootstrapIterations = 100
+	// Spline pillars all move together, so the bootstrap is repeated until they settle
+	bootstrapPasses = 20
+)
+
+// Quote is the market rate of one instrument maturing days ahead
+type Quote struct {
+	Instrument     int
+	DaysToMaturity float64
+	Rate           float64
+	// Coupon or fixed leg payments per year; 1 for swaps and 2 for Treasuries when zero
+	Frequency int
+}
+
+// paymentDays lists the coupon days of a par instrument, stepping back from
+// maturity a period at a time, so that any short period comes first
+func (quote *Quote) paymentDays() []float64 {
+	frequency := quote.Frequency
+	if frequency == 0 {
+		frequency = 1
+		if quote.Instrument == Treasury {
+			frequency = 2
+		}
+	}
+	period := 365.0 / float64(frequency)
+	var days []float64
+	for day := quote.DaysToMaturity; day > 1e-9; day -= period {
+		days = append([]float64{day}, days...)
+	}
+	return days
+}
+
+// parValue is the value of a par instrument paying the quoted rate, less its par
+// value of one, on a curve
+func (quote *Quote) parValue(curve *Curve) float64 {
+	value, previous := 0.0, 0.0
+	for _, day := range quote.paymentDays() {
+		value += quote.Rate * (day - previous) / 365 * curve.DiscountFactor(day)
+		previous = day
+	}
+	return value + curve.DiscountFactor(quote.DaysToMaturity) - 1.0
+}
+
+// Bootstrap builds a curve with a pillar at the maturity of each quote, so that
+// every instrument reprices at par.  Deposits give their pillar directly; each
+// swap or Treasury pillar is solved for with the pillars before it in place.
+func Bootstrap(quotes []Quote, interpolation int) (*Curve, error) {
+	if len(quotes) == 0 {
+		return nil, fmt.Errorf("rate curve needs at least one quote")
+	}
+	sorted := append([]Quote(nil), quotes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].DaysToMaturity < sorted[j].DaysToMaturity })
+
+	days := make([]float64, len(sorted))
+	for i, quote := range sorted {
+		if quote.DaysToMaturity <= 0.0 {
+			return nil, fmt.Errorf("quote days to maturity must be > 0, got %v", quote.DaysToMaturity)
+		}
+		if i > 0 && quote.DaysToMaturity == sorted[i-1].DaysToMaturity {
+			return nil, fmt.Errorf("more than one quote matures in %v days", quote.DaysToMaturity)
+		}
+		if quote.Frequency < 0 || quote.Frequency > 12 {
+			return nil, fmt.Errorf("quote payment frequency must be between 1 and 12, or 0 for the default, got %d", quote.Frequency)
+		}
+		switch quote.Instrument {
+		case Deposit:
+			if growth := 1.0 + quote.Rate*quote.DaysToMaturity/360; growth <= 0.0 {
+				return nil, fmt.Errorf("deposit rate %v for %v days is below -100%%", quote.Rate, quote.DaysToMaturity)
+			}
+		case Swap, Treasury:
+		default:
+			return nil, fmt.Errorf("unrecognized instrument %d", quote.Instrument)
+		}
+		days[i] = quote.DaysToMaturity
+	}
+
+	zeroRates := make([]float64, len(sorted))
+	var curve *Curve
+	for pass := 0; pass < bootstrapPasses; pass++ {
+		moved := 0.0
+		for i, quote := range sorted {
+			// The first pass sees only the pillars solved so far
+			pillars := len(sorted)
+			if pass == 0 {
+				pillars = i + 1
+			}
+
+			previous := zeroRates[i]
+			var zeroRate float64
+			if quote.Instrument == Deposit {
+				zeroRate = math.Log(1.0+quote.Rate*quote.DaysToMaturity/360) * 365 / quote.DaysToMaturity
+			} else {
+				var failure error
+				solved, err := util.Brent(func(rate float64) float64 {
+					zeroRates[i] = rate
+					trial, err := NewCurve(days[:pillars], zeroRates[:pillars], interpolation)
+					if err != nil {
+						failure = err
+						return 0.0
+					}
+					return quote.parValue(trial)
+				}, bootstrapRateLow, bootstrapRateHigh, bootstrapTolerance, bootstrapIterations)
+				if failure != nil {
+					return nil, failure
+				}
+				if err != nil {
+					return nil, fmt.Errorf("no zero rate reprices the %v day quote of %v: %w", quote.DaysToMaturity, quote.Rate, err)
+				}
+				zeroRate = solved
+			}
+			if pass > 0 {
+				moved = math.Max(moved, math.Abs(zeroRate-previous))
+			}
+			zeroRates[i] = zeroRate
+		}
+
+		var err error
+		if curve, err = NewCurve(days, zeroRates, interpolation); err != nil {
+			return nil, err
+		}
+		// Linear and log-linear pillars only affect the curve around them
+		if interpolation != SplineZero || (pass > 0 && moved <= bootstrapTolerance) {
+			break
+		}
+	}
+	return curve, nil
+}
+
+// ParRate is the rate at which a quote's instrument would trade at par on the curve
+func (curve *Curve) ParRate(quote Quote) float64 {
+	if quote.Instrument == Deposit {
+		return (1.0/curve.DiscountFactor(quote.DaysToMaturity) - 1.0) * 360 / quote.DaysToMaturity
+	}
+	annuity, previous := 0.0, 0.0
+	for _, day := range quote.paymentDays() {
+		annuity += (day - previous) / 365 * curve.DiscountFactor(day)
+		previous = day
+	}
+	return (1.0 - curve.DiscountFactor(quote.DaysToMaturity)) / annuity
+}
@@ -0,0 +1,89 @@
+package rates
+
+import (
+	"math"
+	"testing"
+)
+
+var testQuotes = []Quote{
+	{Instrument: Deposit, DaysToMaturity: 30, Rate: 0.050},
+	{Instrument: Deposit, DaysToMaturity: 91, Rate: 0.051},
+	{Instrument: Deposit, DaysToMaturity: 182, Rate: 0.052},
+	{Instrument: Swap, DaysToMaturity: 365, Rate: 0.049},
+	{Instrument: Swap, DaysToMaturity: 730, Rate: 0.045},
+	{Instrument: Treasury, DaysToMaturity: 1825, Rate: 0.042},
+	{Instrument: Swap, DaysToMaturity: 3650, Rate: 0.043, Frequency: 2},
+}
+
+// Every quote reprices at par on the curve bootstrapped from it
+func TestBootstrapReprices(t *testing.T) {
+	for _, interpolation := range []int{LinearZero, LogLinearDiscount, SplineZero} {
+		curve, err := Bootstrap(testQuotes, interpolation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, quote := range testQuotes {
+			if got := curve.ParRate(quote); math.Abs(got-quote.Rate) > 1e-10 {
+				t.Errorf("interpolation %d, %v days: par rate %v, want %v", interpolation, quote.DaysToMaturity, got, quote.Rate)
+			}
+		}
+	}
+}
+
+// Pillars worked by hand: a deposit's simple ACT/360 interest, a one year annual
+// swap paying once, and a two year swap discounting its first coupon on the first
+func TestBootstrapReference(t *testing.T) {
+	curve, err := Bootstrap([]Quote{
+		{Instrument: Deposit, DaysToMaturity: 90, Rate: 0.05},
+		{Instrument: Swap, DaysToMaturity: 365, Rate: 0.05},
+		{Instrument: Swap, DaysToMaturity: 730, Rate: 0.06},
+	}, LogLinearDiscount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oneYear := 1.0 / 1.05
+	tests := []struct {
+		days, want float64
+	}{
+		{90, math.Log(1.0+0.05*90/360) * 365 / 90},
+		{365, math.Log(1.05)},
+		{730, -math.Log((1.0-0.06*oneYear)/1.06) / 2},
+	}
+	for _, test := range tests {
+		if got := curve.ZeroRate(test.days); math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%v days: zero rate %v, want %v", test.days, got, test.want)
+		}
+	}
+}
+
+// Log-linear discount factors give flat forwards between pillars, and zero
+// rates are flat outside them
+func TestCurveInterpolation(t *testing.T) {
+	curve, err := NewCurve([]float64{100, 300}, []float64{0.03, 0.05}, LogLinearDiscount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := (0.05*300 - 0.03*100) / 200
+	for _, span := range [][2]float64{{100, 150}, {180, 260}, {290, 300}} {
+		if got := curve.ForwardRate(span[0], span[1]); math.Abs(got-want) > 1e-12 {
+			t.Errorf("forward from %v to %v: got %v, want %v", span[0], span[1], got, want)
+		}
+	}
+	if got := curve.ZeroRate(10); got != 0.03 {
+		t.Errorf("before the first pillar: got %v, want 0.03", got)
+	}
+	if got := curve.ZeroRate(1000); got != 0.05 {
+		t.Errorf("beyond the last pillar: got %v, want 0.05", got)
+	}
+
+	linear, err := NewCurve([]float64{100, 300}, []float64{0.03, 0.05}, LinearZero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := linear.ZeroRate(200); math.Abs(got-0.04) > 1e-15 {
+		t.Errorf("linear zero at 200 days: got %v, want 0.04", got)
+	}
+	if got, want := linear.DiscountFactor(200), math.Exp(-0.04*200/365); math.Abs(got-want) > 1e-15 {
+		t.Errorf("discount factor at 200 days: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+package rates
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// Interpolation of a curve between its pillars
+const (
+	// Linear in zero rates
+	LinearZero = iota
+	// Linear in log discount factors, giving flat forward rates between pillars
+	LogLinearDiscount
+	// Natural cubic spline through zero rates
+	SplineZero
+)
+
+// Curve is a term structure of continuously compounded zero rates by days ahead,
+// with time measured as days over 365.  Before the first pillar and beyond the
+// last the zero rate is held flat.
+type Curve struct {
+	Days          []float64
+	ZeroRates     []float64
+	Interpolation int
+	spline        *util.Spline
+	logDiscounts  []float64
+}
+
+// NewCurve validates the pillars and prepares the interpolation.  Days must be
+// strictly increasing and > 0.
+func NewCurve(days, zeroRates []float64, interpolation int) (*Curve, error) {
+	if len(days) == 0 || len(days) != len(zeroRates) {
+		return nil, fmt.Errorf("rate curve needs matching days and zero rates, got %d and %d", len(days), len(zeroRates))
+	}
+	for i, day := range days {
+		if day <= 0.0 {
+			return nil, fmt.Errorf("rate curve days must be > 0, got %v", day)
+		}
+		if i > 0 && day <= days[i-1] {
+			return nil, fmt.Errorf("rate curve days must be strictly increasing, got %v after %v", day, days[i-1])
+		}
+		if math.IsNaN(zeroRates[i]) || math.IsInf(zeroRates[i], 0) {
+			return nil, fmt.Errorf("rate curve zero rate at %v days must be finite, got %v", day, zeroRates[i])
+		}
+	}
+
+	curve := Curve{
+		Days:          append([]float64(nil), days...),
+		ZeroRates:     append([]float64(nil), zeroRates...),
+		Interpolation: interpolation,
+	}
+	switch interpolation {
+	case LinearZero:
+	case LogLinearDiscount:
+		curve.logDiscounts = make([]float64, len(days))
+		for i, day := range days {
+			curve.logDiscounts[i] = -zeroRates[i] * day / 365
+		}
+	case SplineZero:
+		spline, err := util.NewSpline(curve.Days, curve.ZeroRates)
+		if err != nil {
+			return nil, err
+		}
+		curve.spline = spline
+	default:
+		return nil, fmt.Errorf("unrecognized rate curve interpolation %d", interpolation)
+	}
+	return &curve, nil
+}
+
+// ZeroRate is the continuously compounded rate from today to days ahead
+func (curve *Curve) ZeroRate(days float64) float64 {
+	last := len(curve.Days) - 1
+	switch {
+	case days <= curve.Days[0]:
+		return curve.ZeroRates[0]
+	case days >= curve.Days[last]:
+		return curve.ZeroRates[last]
+	}
+	switch curve.Interpolation {
+	case LogLinearDiscount:
+		return -util.LinearInterpolate(curve.Days, curve.logDiscounts, days) * 365 / days
+	case SplineZero:
+		return curve.spline.At(days)
+	}
+	return util.LinearInterpolate(curve.Days, curve.ZeroRates, days)
+}
+
+// DiscountFactor is the value today of one paid days ahead
+func (curve *Curve) DiscountFactor(days float64) float64 {
+	return math.Exp(-curve.ZeroRate(days) * days / 365)
+}
+
+// ForwardRate is the continuously compounded rate between two days ahead
+func (curve *Curve) ForwardRate(fromDays, toDays float64) float64 {
+	if toDays == fromDays {
+		return curve.ZeroRate(toDays)
+	}
+	return (curve.ZeroRate(toDays)*toDays - curve.ZeroRate(fromDays)*fromDays) / (toDays - fromDays)
+}
@@ -0,0 +1,293 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// Leg instruments; calls and puts share the option package constants
+const Stock = option.Put + 1
+
+// Leg sides
+const (
+	Long = iota
+	Short
+)
+
+const (
+	breakevenSamples   = 2000
+	breakevenTolerance = 1e-6
+	// Asset prices this many times the highest strike stand in for "unbounded"
+	unboundedMultiple = 10.0
+	unboundedSlope    = 1e-6
+)
+
+// Leg is one instrument in a strategy.  For stock legs only Quantity, Side and
+// EntryPrice are used; option legs carry strike and expiry in Option.
+type Leg struct {
+	Instrument int
+	Option     option.Option
+	Quantity   float64
+	Side       int
+	EntryPrice float64
+}
+
+// Strategy is a set of legs on one asset, valued with Black-Scholes at the
+// asset volatility and risk-free rate
+type Strategy struct {
+	Asset      option.Asset
+	Spot       float64
+	Multiplier float64
+	Legs       []Leg
+}
+
+// StrategyValue is the combined value and profit of the legs at one grid point
+type StrategyValue struct {
+	AssetPrice   float64
+	DaysToExpiry float64
+	Value        float64
+	ProfitLoss   float64
+}
+
+// StrategyGrid holds values per asset price, then per days to expiry of the front leg
+type StrategyGrid [][]StrategyValue
+
+// Valuation is the current value, profit and net Greeks of a set of legs
+type Valuation struct {
+	Value      float64
+	ProfitLoss float64
+	Greeks     option.Greeks
+}
+
+// Analysis summarises the strategy at the front leg expiry and its Greeks at spot
+type Analysis struct {
+	NetEntryCost       float64
+	Breakevens         []float64
+	MaxProfit          float64
+	MaxProfitUnbounded bool
+	MaxLoss            float64
+	MaxLossUnbounded   bool
+	Greeks             option.Greeks
+}
+
+func NewStrategy(asset option.Asset, spot, multiplier float64, legs []Leg) (*Strategy, error) {
+	if len(legs) == 0 {
+		return nil, fmt.Errorf("strategy needs at least one leg")
+	}
+	if spot <= 0.0 {
+		return nil, fmt.Errorf("spot price must be > 0")
+	}
+	if multiplier <= 0.0 {
+		return nil, fmt.Errorf("multiplier must be > 0")
+	}
+	for i, leg := range legs {
+		if leg.Quantity <= 0.0 {
+			return nil, fmt.Errorf("leg %d: quantity must be > 0", i)
+		}
+		if leg.Side != Long && leg.Side != Short {
+			return nil, fmt.Errorf("leg %d: unrecognized side %d", i, leg.Side)
+		}
+		switch leg.Instrument {
+		case option.Call, option.Put:
+			if leg.Option.Strike <= 0.0 || leg.Option.Expiry < 0.0 {
+				return nil, fmt.Errorf("leg %d: option strike must be > 0 and expiry cannot be negative", i)
+			}
+		case Stock:
+		default:
+			return nil, fmt.Errorf("leg %d: unrecognized instrument %d", i, leg.Instrument)
+		}
+	}
+	return &Strategy{Asset: asset, Spot: spot, Multiplier: multiplier, Legs: legs}, nil
+}
+
+// FrontExpiry is the days to expiry of the nearest option leg, or zero for stock only
+func (strategy *Strategy) FrontExpiry() float64 {
+	front := 0.0
+	for _, leg := range strategy.Legs {
+		if leg.Instrument != Stock && (front == 0.0 || leg.Option.Expiry < front) {
+			front = leg.Option.Expiry
+		}
+	}
+	return front
+}
+
+func (leg *Leg) sign() float64 {
+	if leg.Side == Short {
+		return -1.0
+	}
+	return 1.0
+}
+
+// legValue prices one unit of a leg after daysElapsed; expired options are worth intrinsic value
+func (strategy *Strategy) legValue(leg *Leg, assetPrice, daysElapsed float64, withGreeks bool) (option.OptionPosition, error) {
+	if leg.Instrument == Stock {
+		return option.OptionPosition{Price: assetPrice, Greeks: option.Greeks{Delta: 1.0}}, nil
+	}
+	remaining := leg.Option.Expiry - daysElapsed
+	if remaining <= 0.0 {
+		intrinsic := math.Max(assetPrice-leg.Option.Strike, 0.0)
+		if leg.Instrument == option.Put {
+			intrinsic = math.Max(leg.Option.Strike-assetPrice, 0.0)
+		}
+		return option.OptionPosition{Price: intrinsic, Strike: leg.Option.Strike}, nil
+	}
+	return option.PriceOption(
+		leg.Instrument, assetPrice, leg.Option.Strike, remaining,
+		strategy.Asset.Volatility, strategy.Asset.RfReturn, withGreeks,
+	)
+}
+
+// TheoreticalPrice is the current Black-Scholes value of one unit of a leg at spot
+func (strategy *Strategy) TheoreticalPrice(leg *Leg) (float64, error) {
+	position, err := strategy.legValue(leg, strategy.Spot, 0.0, false)
+	return position.Price, err
+}
+
+// valueAt returns the combined value and profit of all legs after daysElapsed
+func (strategy *Strategy) valueAt(assetPrice, daysElapsed float64) (float64, float64, error) {
+	value, profitLoss := 0.0, 0.0
+	for i := range strategy.Legs {
+		leg := &strategy.Legs[i]
+		position, err := strategy.legValue(leg, assetPrice, daysElapsed, false)
+		if err != nil {
+			return 0.0, 0.0, err
+		}
+		units := leg.sign() * leg.Quantity * strategy.Multiplier
+		value += units * position.Price
+		profitLoss += units * (position.Price - leg.EntryPrice)
+	}
+	return value, profitLoss, nil
+}
+
+// Evaluate values the strategy over asset prices and days to expiry of the front
+// leg.  Days to expiry run from high to low as in the option chain; zero is the
+// front expiry itself.
+func (strategy *Strategy) Evaluate(assetPriceAxis, daysToExpiryAxis option.Axis) (StrategyGrid, error) {
+	assetPrices, err := assetPriceAxis.Values("assetPriceRange")
+	if err != nil {
+		return nil, err
+	}
+	daysToExpiry, err := daysToExpiryAxis.Values("daysToExpirySpan")
+	if err != nil {
+		return nil, err
+	}
+	front := strategy.FrontExpiry()
+	if highest := daysToExpiry[len(daysToExpiry)-1]; highest > front {
+		return nil, fmt.Errorf("daysToExpirySpan: High %v is beyond the front leg expiry %v", highest, front)
+	}
+
+	var result StrategyGrid
+	for _, assetPrice := range assetPrices {
+		var row []StrategyValue
+		for i := len(daysToExpiry) - 1; i >= 0; i-- {
+			dte := daysToExpiry[i]
+			value, profitLoss, err := strategy.valueAt(assetPrice, front-dte)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, StrategyValue{assetPrice, dte, value, profitLoss})
+		}
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+// Valuation is the combined value, profit and net Greeks of the legs at spot today
+func (strategy *Strategy) Valuation() (*Valuation, error) {
+	var valuation Valuation
+	for i := range strategy.Legs {
+		leg := &strategy.Legs[i]
+		position, err := strategy.legValue(leg, strategy.Spot, 0.0, true)
+		if err != nil {
+			return nil, err
+		}
+		units := leg.sign() * leg.Quantity * strategy.Multiplier
+		valuation.Value += units * position.Price
+		valuation.ProfitLoss += units * (position.Price - leg.EntryPrice)
+		valuation.Greeks.Delta += units * position.Greeks.Delta
+		valuation.Greeks.Gamma += units * position.Greeks.Gamma
+		valuation.Greeks.Theta += units * position.Greeks.Theta
+		valuation.Greeks.Vega += units * position.Greeks.Vega
+		valuation.Greeks.Rho += units * position.Greeks.Rho
+	}
+	return &valuation, nil
+}
+
+// Analyze finds breakevens and profit extremes at the front leg expiry, and the
+// net Greeks of the strategy at spot today
+func (strategy *Strategy) Analyze() (*Analysis, error) {
+	var analysis Analysis
+	for i := range strategy.Legs {
+		leg := &strategy.Legs[i]
+		analysis.NetEntryCost += leg.sign() * leg.Quantity * strategy.Multiplier * leg.EntryPrice
+	}
+	valuation, err := strategy.Valuation()
+	if err != nil {
+		return nil, err
+	}
+	analysis.Greeks = valuation.Greeks
+
+	front := strategy.FrontExpiry()
+	var evalErr error
+	profitAtExpiry := func(assetPrice float64) float64 {
+		_, profitLoss, err := strategy.valueAt(assetPrice, front)
+		if err != nil {
+			evalErr = err
+		}
+		return profitLoss
+	}
+
+	// Sample from zero, where short puts and long stock lose most, to well past the
+	// highest strike, always including the strikes themselves where expiry payoffs
+	// kink
+	highest := strategy.Spot
+	samples := []float64{0.0}
+	for _, leg := range strategy.Legs {
+		if leg.Instrument != Stock {
+			highest = math.Max(highest, leg.Option.Strike)
+			samples = append(samples, leg.Option.Strike)
+		}
+	}
+	upper := highest * unboundedMultiple
+	for i := 1; i <= breakevenSamples; i++ {
+		samples = append(samples, upper*float64(i)/breakevenSamples)
+	}
+	sort.Float64s(samples)
+
+	profits := make([]float64, len(samples))
+	for i, assetPrice := range samples {
+		profits[i] = profitAtExpiry(assetPrice)
+	}
+	if evalErr != nil {
+		return nil, evalErr
+	}
+
+	analysis.MaxProfit, analysis.MaxLoss = profits[0], profits[0]
+	for i, profit := range profits {
+		analysis.MaxProfit = math.Max(analysis.MaxProfit, profit)
+		analysis.MaxLoss = math.Min(analysis.MaxLoss, profit)
+		if i == 0 {
+			continue
+		}
+		previous := profits[i-1]
+		if profit == 0.0 && previous != 0.0 {
+			analysis.Breakevens = append(analysis.Breakevens, samples[i])
+		} else if profit != 0.0 && previous != 0.0 && math.Signbit(previous) != math.Signbit(profit) {
+			breakeven, err := util.Brent(profitAtExpiry, samples[i-1], samples[i], breakevenTolerance, 100)
+			if err != nil {
+				return nil, err
+			}
+			analysis.Breakevens = append(analysis.Breakevens, breakeven)
+		}
+	}
+
+	last := len(samples) - 1
+	slope := (profits[last] - profits[last-1]) / (samples[last] - samples[last-1])
+	analysis.MaxProfitUnbounded = slope > unboundedSlope
+	analysis.MaxLossUnbounded = slope < -unboundedSlope
+	return &analysis, nil
+}
@@ -0,0 +1,93 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+)
+
+var testAsset = option.Asset{Name: "ACME", Volatility: 0.25, RfReturn: 0.05}
+
+func analyze(t *testing.T, legs []Leg) *Analysis {
+	t.Helper()
+	strategy, err := NewStrategy(testAsset, 100, 1, legs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	analysis, err := strategy.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return analysis
+}
+
+// The worst case of a short put and of long stock is the asset going to zero
+func TestAnalyzeLossAtZero(t *testing.T) {
+	tests := []struct {
+		name     string
+		legs     []Leg
+		wantLoss float64
+	}{
+		{"short put", []Leg{{Instrument: option.Put, Option: option.Option{Type: option.Put, Strike: 95, Expiry: 30},
+			Quantity: 1, Side: Short, EntryPrice: 2}}, -93},
+		{"covered call", []Leg{
+			{Instrument: Stock, Quantity: 1, Side: Long, EntryPrice: 100},
+			{Instrument: option.Call, Option: option.Option{Type: option.Call, Strike: 105, Expiry: 30},
+				Quantity: 1, Side: Short, EntryPrice: 3},
+		}, -97},
+	}
+	for _, test := range tests {
+		analysis := analyze(t, test.legs)
+		if math.Abs(analysis.MaxLoss-test.wantLoss) > 1e-9 {
+			t.Errorf("%s: max loss %v, want %v", test.name, analysis.MaxLoss, test.wantLoss)
+		}
+		if analysis.MaxLossUnbounded {
+			t.Errorf("%s: max loss reported unbounded", test.name)
+		}
+	}
+}
+
+func TestStraddleTemplate(t *testing.T) {
+	legs, err := TemplateLegs(Straddle, &TemplateParams{Strikes: []float64{100}, Expiries: []float64{30}, Quantity: 1, Side: Long})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(legs) != 2 || legs[0].Instrument != option.Put || legs[1].Instrument != option.Call ||
+		legs[0].Option.Strike != 100 || legs[1].Option.Strike != 100 {
+		t.Fatalf("unexpected straddle legs %+v", legs)
+	}
+	legs[0].EntryPrice, legs[1].EntryPrice = 3, 4
+	analysis := analyze(t, legs)
+	if len(analysis.Breakevens) != 2 || math.Abs(analysis.Breakevens[0]-93) > 1e-4 || math.Abs(analysis.Breakevens[1]-107) > 1e-4 {
+		t.Errorf("breakevens %v, want [93 107]", analysis.Breakevens)
+	}
+	if analysis.MaxLoss != -7 || !analysis.MaxProfitUnbounded {
+		t.Errorf("max loss %v unbounded profit %v, want -7 and true", analysis.MaxLoss, analysis.MaxProfitUnbounded)
+	}
+}
+
+// A put and the call above it may share a strike, other strikes must increase
+func TestTemplateStrikes(t *testing.T) {
+	tests := []struct {
+		template int
+		strikes  []float64
+		valid    bool
+	}{
+		{Strangle, []float64{100, 100}, true},
+		{Strangle, []float64{105, 100}, false},
+		{IronCondor, []float64{90, 100, 100, 110}, true},
+		{IronCondor, []float64{100, 100, 105, 110}, false},
+		{Collar, []float64{100, 100}, true},
+		{Vertical, []float64{100, 100}, false},
+		{Butterfly, []float64{95, 100, 100}, false},
+	}
+	for _, test := range tests {
+		_, err := TemplateLegs(test.template, &TemplateParams{
+			OptionType: option.Call, Strikes: test.strikes, Expiries: []float64{30}, Quantity: 1, Side: Long,
+		})
+		if (err == nil) != test.valid {
+			t.Errorf("template %d strikes %v: error %v, want valid %v", test.template, test.strikes, err, test.valid)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package strategy
+
+import (
+	"fmt"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+)
+
+// Built-in strategy templates
+const (
+	Vertical = iota
+	IronCondor
+	Calendar
+	Butterfly
+	Strangle
+	Collar
+	Straddle
+)
+
+// TemplateParams describes a templated strategy.  Strikes are given low to high,
+// and a put may share its strike with the call above it;
+// Expiries holds one expiry, or near and far expiries for a calendar.  Side is the
+// direction of the whole structure, e.g. a Long vertical buys the lower strike call.
+type TemplateParams struct {
+	OptionType int
+	Strikes    []float64
+	Expiries   []float64
+	Quantity   float64
+	Side       int
+}
+
+func templateLeg(instrument int, strike, expiry, quantity float64, side int) Leg {
+	return Leg{
+		Instrument: instrument,
+		Option:     option.Option{Type: instrument, Strike: strike, Expiry: expiry},
+		Quantity:   quantity,
+		Side:       side,
+	}
+}
+
+func opposite(side int) int {
+	if side == Long {
+		return Short
+	}
+	return Long
+}
+
+// checkTemplate checks the counts of strikes and expiries and that strikes
+// increase.  When pair is not zero the put at strike pair-1 and the call at strike
+// pair may share a strike.
+func checkTemplate(name string, params *TemplateParams, strikes, expiries, pair int) error {
+	if len(params.Strikes) != strikes {
+		return fmt.Errorf("%s needs %d strikes, got %d", name, strikes, len(params.Strikes))
+	}
+	if len(params.Expiries) != expiries {
+		return fmt.Errorf("%s needs %d expiries, got %d", name, expiries, len(params.Expiries))
+	}
+	for i := 1; i < len(params.Strikes); i++ {
+		if params.Strikes[i] < params.Strikes[i-1] || params.Strikes[i] == params.Strikes[i-1] && i != pair {
+			return fmt.Errorf("%s strikes must be increasing", name)
+		}
+	}
+	if params.Quantity <= 0.0 {
+		return fmt.Errorf("%s quantity must be > 0", name)
+	}
+	if params.Side != Long && params.Side != Short {
+		return fmt.Errorf("%s: unrecognized side %d", name, params.Side)
+	}
+	return nil
+}
+
+// TemplateLegs expands a built-in template into legs without entry prices
+func TemplateLegs(template int, params *TemplateParams) ([]Leg, error) {
+	side, quantity := params.Side, params.Quantity
+	switch template {
+	case Vertical:
+		// Long buys the lower strike for calls and the higher strike for puts
+		if err := checkTemplate("vertical", params, 2, 1, 0); err != nil {
+			return nil, err
+		}
+		low, high, expiry := params.Strikes[0], params.Strikes[1], params.Expiries[0]
+		if params.OptionType == option.Put {
+			return []Leg{
+				templateLeg(option.Put, high, expiry, quantity, side),
+				templateLeg(option.Put, low, expiry, quantity, opposite(side)),
+			}, nil
+		}
+		return []Leg{
+			templateLeg(option.Call, low, expiry, quantity, side),
+			templateLeg(option.Call, high, expiry, quantity, opposite(side)),
+		}, nil
+
+	case IronCondor:
+		// Short sells the inner strangle and buys the outer wings
+		if err := checkTemplate("iron condor", params, 4, 1, 2); err != nil {
+			return nil, err
+		}
+		expiry := params.Expiries[0]
+		return []Leg{
+			templateLeg(option.Put, params.Strikes[0], expiry, quantity, opposite(side)),
+			templateLeg(option.Put, params.Strikes[1], expiry, quantity, side),
+			templateLeg(option.Call, params.Strikes[2], expiry, quantity, side),
+			templateLeg(option.Call, params.Strikes[3], expiry, quantity, opposite(side)),
+		}, nil
+
+	case Calendar:
+		// Long sells the near expiry and buys the far expiry
+		if err := checkTemplate("calendar", params, 1, 2, 0); err != nil {
+			return nil, err
+		}
+		near, far := params.Expiries[0], params.Expiries[1]
+		if near >= far {
+			return nil, fmt.Errorf("calendar near expiry must be before far expiry")
+		}
+		return []Leg{
+			templateLeg(params.OptionType, params.Strikes[0], near, quantity, opposite(side)),
+			templateLeg(params.OptionType, params.Strikes[0], far, quantity, side),
+		}, nil
+
+	case Butterfly:
+		// Long buys the wings and sells twice the body
+		if err := checkTemplate("butterfly", params, 3, 1, 0); err != nil {
+			return nil, err
+		}
+		expiry := params.Expiries[0]
+		return []Leg{
+			templateLeg(params.OptionType, params.Strikes[0], expiry, quantity, side),
+			templateLeg(params.OptionType, params.Strikes[1], expiry, 2.0*quantity, opposite(side)),
+			templateLeg(params.OptionType, params.Strikes[2], expiry, quantity, side),
+		}, nil
+
+	case Strangle:
+		if err := checkTemplate("strangle", params, 2, 1, 1); err != nil {
+			return nil, err
+		}
+		expiry := params.Expiries[0]
+		return []Leg{
+			templateLeg(option.Put, params.Strikes[0], expiry, quantity, side),
+			templateLeg(option.Call, params.Strikes[1], expiry, quantity, side),
+		}, nil
+
+	case Straddle:
+		if err := checkTemplate("straddle", params, 1, 1, 0); err != nil {
+			return nil, err
+		}
+		expiry := params.Expiries[0]
+		return []Leg{
+			templateLeg(option.Put, params.Strikes[0], expiry, quantity, side),
+			templateLeg(option.Call, params.Strikes[0], expiry, quantity, side),
+		}, nil
+
+	case Collar:
+		// Long holds the stock, buys the lower strike put and sells the higher strike call
+		if err := checkTemplate("collar", params, 2, 1, 1); err != nil {
+			return nil, err
+		}
+		expiry := params.Expiries[0]
+		return []Leg{
+			{Instrument: Stock, Quantity: quantity, Side: side},
+			templateLeg(option.Put, params.Strikes[0], expiry, quantity, side),
+			templateLeg(option.Call, params.Strikes[1], expiry, quantity, opposite(side)),
+		}, nil
+	}
+	return nil, fmt.Errorf("unrecognized template %d", template)
+}
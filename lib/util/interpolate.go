@@ -0,0 +1,96 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+)
+
+// validateKnots checks that knots have matching lengths and strictly increasing x
+func validateKnots(x, y []float64) error {
+	if len(x) == 0 || len(x) != len(y) {
+		return fmt.Errorf("interpolation needs matching x and y values, got %d and %d", len(x), len(y))
+	}
+	for i := 1; i < len(x); i++ {
+		if x[i] <= x[i-1] {
+			return fmt.Errorf("interpolation x values must be strictly increasing, got %v after %v", x[i], x[i-1])
+		}
+	}
+	return nil
+}
+
+// segment is the index i such that x[i] <= at < x[i+1], clamped to the first and
+// last segments so that points outside the knots extrapolate from the end segments
+func segment(x []float64, at float64) int {
+	i := sort.SearchFloat64s(x, at) - 1
+	return max(0, min(i, len(x)-2))
+}
+
+// LinearInterpolate interpolates linearly between knots, extrapolating from the
+// end segments.  x must be strictly increasing.
+func LinearInterpolate(x, y []float64, at float64) float64 {
+	if len(x) == 1 {
+		return y[0]
+	}
+	i := segment(x, at)
+	return y[i] + (y[i+1]-y[i])*(at-x[i])/(x[i+1]-x[i])
+}
+
+// Spline is a natural cubic spline through a set of knots.  Beyond the end knots
+// it continues as a straight line with the end slope.
+type Spline struct {
+	x, y              []float64
+	secondDerivatives []float64
+}
+
+func NewSpline(x, y []float64) (*Spline, error) {
+	if err := validateKnots(x, y); err != nil {
+		return nil, err
+	}
+	n := len(x)
+	spline := Spline{x: append([]float64(nil), x...), y: append([]float64(nil), y...), secondDerivatives: make([]float64, n)}
+	if n < 3 {
+		return &spline, nil
+	}
+
+	// Tridiagonal system for the second derivatives, zero at both ends
+	upper := make([]float64, n)
+	rhs := make([]float64, n)
+	for i := 1; i < n-1; i++ {
+		h0, h1 := x[i]-x[i-1], x[i+1]-x[i]
+		diagonal := 2.0*(h0+h1) - h0*upper[i-1]
+		upper[i] = h1 / diagonal
+		rhs[i] = (6.0*((y[i+1]-y[i])/h1-(y[i]-y[i-1])/h0) - h0*rhs[i-1]) / diagonal
+	}
+	for i := n - 2; i > 0; i-- {
+		spline.secondDerivatives[i] = rhs[i] - upper[i]*spline.secondDerivatives[i+1]
+	}
+	return &spline, nil
+}
+
+// slope is the first derivative of the spline at knot i
+func (spline *Spline) slope(i int) float64 {
+	x, y, m := spline.x, spline.y, spline.secondDerivatives
+	if i == len(x)-1 {
+		h := x[i] - x[i-1]
+		return (y[i]-y[i-1])/h + h*(m[i-1]+2.0*m[i])/6.0
+	}
+	h := x[i+1] - x[i]
+	return (y[i+1]-y[i])/h - h*(2.0*m[i]+m[i+1])/6.0
+}
+
+func (spline *Spline) At(at float64) float64 {
+	x, y, m := spline.x, spline.y, spline.secondDerivatives
+	n := len(x)
+	switch {
+	case n == 1:
+		return y[0]
+	case at < x[0]:
+		return y[0] + spline.slope(0)*(at-x[0])
+	case at > x[n-1]:
+		return y[n-1] + spline.slope(n-1)*(at-x[n-1])
+	}
+	i := segment(x, at)
+	h := x[i+1] - x[i]
+	a, b := (x[i+1]-at)/h, (at-x[i])/h
+	return a*y[i] + b*y[i+1] + ((a*a*a-a)*m[i]+(b*b*b-b)*m[i+1])*h*h/6.0
+}
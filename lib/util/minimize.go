@@ -0,0 +1,93 @@
+package util
+
+import (
+	"math"
+	"sort"
+)
+
+// Nelder-Mead reflection, expansion, contraction and shrink coefficients
+const (
+	nelderMeadReflect  = 1.0
+	nelderMeadExpand   = 2.0
+	nelderMeadContract = 0.5
+	nelderMeadShrink   = 0.5
+)
+
+// NelderMead minimizes f from start with the downhill simplex method.  The initial
+// simplex steps each coordinate by step.  It stops when the function values of
+// the simplex agree within tolerance or after maxIterations, returning the best
+// point found and its value.
+func NelderMead(f func([]float64) float64, start, step []float64, tolerance float64, maxIterations int) ([]float64, float64) {
+	n := len(start)
+	simplex := make([][]float64, n+1)
+	values := make([]float64, n+1)
+	for i := range simplex {
+		simplex[i] = append([]float64(nil), start...)
+		if i > 0 {
+			simplex[i][i-1] += step[i-1]
+		}
+		values[i] = f(simplex[i])
+	}
+
+	order := make([]int, n+1)
+	point := func(from []float64, toward []float64, scale float64) []float64 {
+		result := make([]float64, n)
+		for i := range result {
+			result[i] = from[i] + scale*(toward[i]-from[i])
+		}
+		return result
+	}
+
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		for i := range order {
+			order[i] = i
+		}
+		sort.Slice(order, func(i, j int) bool { return values[order[i]] < values[order[j]] })
+		best, worst, secondWorst := order[0], order[n], order[n-1]
+		if math.Abs(values[worst]-values[best]) <= tolerance*(math.Abs(values[best])+tolerance) {
+			break
+		}
+
+		centroid := make([]float64, n)
+		for _, i := range order[:n] {
+			for j := range centroid {
+				centroid[j] += simplex[i][j] / float64(n)
+			}
+		}
+
+		reflected := point(centroid, simplex[worst], -nelderMeadReflect)
+		reflectedValue := f(reflected)
+		switch {
+		case reflectedValue < values[best]:
+			expanded := point(centroid, simplex[worst], -nelderMeadExpand)
+			if expandedValue := f(expanded); expandedValue < reflectedValue {
+				simplex[worst], values[worst] = expanded, expandedValue
+			} else {
+				simplex[worst], values[worst] = reflected, reflectedValue
+			}
+		case reflectedValue < values[secondWorst]:
+			simplex[worst], values[worst] = reflected, reflectedValue
+		default:
+			contracted := point(centroid, simplex[worst], nelderMeadContract)
+			if reflectedValue < values[worst] {
+				contracted = point(centroid, reflected, nelderMeadContract)
+			}
+			if contractedValue := f(contracted); contractedValue < math.Min(values[worst], reflectedValue) {
+				simplex[worst], values[worst] = contracted, contractedValue
+			} else {
+				for _, i := range order[1:] {
+					simplex[i] = point(simplex[best], simplex[i], nelderMeadShrink)
+					values[i] = f(simplex[i])
+				}
+			}
+		}
+	}
+
+	best := 0
+	for i := range values {
+		if values[i] < values[best] {
+			best = i
+		}
+	}
+	return simplex[best], values[best]
+}
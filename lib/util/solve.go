@@ -0,0 +1,87 @@
+package util
+
+import (
+	"fmt"
+	"math"
+)
+
+const machineEpsilon = 2.220446049250313e-16
+
+// Brent finds a root of f within [low, high] using Brent's method.  f(low) and
+// f(high) must have opposite signs.
+func Brent(f func(float64) float64, low, high, tolerance float64, maxIterations int) (float64, error) {
+	a, b := low, high
+	fa, fb := f(a), f(b)
+	if fa == 0.0 {
+		return a, nil
+	}
+	if fb == 0.0 {
+		return b, nil
+	}
+	if math.Signbit(fa) == math.Signbit(fb) {
+		return 0.0, fmt.Errorf("root is not bracketed by [%v, %v]: f = %v/%v", low, high, fa, fb)
+	}
+
+	c, fc := a, fa
+	d := b - a
+	e := d
+	for i := 0; i < maxIterations; i++ {
+		if math.Signbit(fb) == math.Signbit(fc) {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+
+		tol := 2.0*machineEpsilon*math.Abs(b) + 0.5*tolerance
+		m := 0.5 * (c - b)
+		if math.Abs(m) <= tol || fb == 0.0 {
+			return b, nil
+		}
+
+		if math.Abs(e) >= tol && math.Abs(fa) > math.Abs(fb) {
+			// Attempt inverse quadratic interpolation, or secant when only two points differ
+			var p, q float64
+			s := fb / fa
+			if a == c {
+				p = 2.0 * m * s
+				q = 1.0 - s
+			} else {
+				qa := fa / fc
+				r := fb / fc
+				p = s * (2.0*m*qa*(qa-r) - (b-a)*(r-1.0))
+				q = (qa - 1.0) * (r - 1.0) * (s - 1.0)
+			}
+			if p > 0.0 {
+				q = -q
+			} else {
+				p = -p
+			}
+			if 2.0*p < math.Min(3.0*m*q-math.Abs(tol*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d = m
+				e = d
+			}
+		} else {
+			d = m
+			e = d
+		}
+
+		a, fa = b, fb
+		if math.Abs(d) > tol {
+			b += d
+		} else if m > 0.0 {
+			b += tol
+		} else {
+			b -= tol
+		}
+		fb = f(b)
+	}
+
+	return 0.0, fmt.Errorf("no convergence after %d iterations in [%v, %v]", maxIterations, low, high)
+}
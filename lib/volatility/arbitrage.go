@@ -0,0 +1,108 @@
+package volatility
+
+import (
+	"math"
+)
+
+const (
+	// Step for the finite differences of total variance in log strike over forward
+	arbitrageBump = 1e-4
+	// Conditions this close to zero are taken as met, allowing for rounding
+	arbitrageTolerance = 1e-9
+)
+
+// Violation is a run of log strike over forward where a no-arbitrage condition
+// fails for an expiry, with the most negative value of the condition
+type Violation struct {
+	DaysToExpiry     float64
+	LogMoneynessLow  float64
+	LogMoneynessHigh float64
+	Worst            float64
+}
+
+// collectViolations groups grid points where condition is negative into runs
+func collectViolations(daysToExpiry float64, logMoneyness, condition []float64) []Violation {
+	var violations []Violation
+	var current *Violation
+	for i, value := range condition {
+		if value >= -arbitrageTolerance {
+			current = nil
+			continue
+		}
+		if current == nil {
+			violations = append(violations, Violation{DaysToExpiry: daysToExpiry, LogMoneynessLow: logMoneyness[i], Worst: value})
+			current = &violations[len(violations)-1]
+		}
+		current.LogMoneynessHigh = logMoneyness[i]
+		current.Worst = math.Min(current.Worst, value)
+	}
+	return violations
+}
+
+// totalVarianceAt is the total variance of a smile at log strike over forward k
+func totalVarianceAt(expiry *ExpirySmile, k float64) (float64, error) {
+	years := expiry.DaysToExpiry / 365
+	volatility, err := expiry.Smile.Volatility(expiry.Forward, expiry.Forward*math.Exp(k), years)
+	if err != nil {
+		return 0.0, err
+	}
+	return volatility * volatility * years, nil
+}
+
+// ButterflyArbitrage checks Gatheral's density condition on each smile
+//
+//	g(k) = (1 - k w'/(2w))^2 - w'^2/4 (1/w + 1/4) + w''/2 >= 0
+//
+// over the log strikes given.  A negative g means a negative risk-neutral density,
+// so a butterfly spread there would have a negative price.
+func (surface *SmileSurface) ButterflyArbitrage(logMoneyness []float64) ([]Violation, error) {
+	var violations []Violation
+	for i := range surface.Expiries {
+		expiry := &surface.Expiries[i]
+		condition := make([]float64, len(logMoneyness))
+		for j, k := range logMoneyness {
+			down, err := totalVarianceAt(expiry, k-arbitrageBump)
+			if err != nil {
+				return nil, err
+			}
+			w, err := totalVarianceAt(expiry, k)
+			if err != nil {
+				return nil, err
+			}
+			up, err := totalVarianceAt(expiry, k+arbitrageBump)
+			if err != nil {
+				return nil, err
+			}
+			slope := (up - down) / (2.0 * arbitrageBump)
+			curvature := (up - 2.0*w + down) / (arbitrageBump * arbitrageBump)
+			term := 1.0 - k*slope/(2.0*w)
+			condition[j] = term*term - slope*slope/4.0*(1.0/w+0.25) + curvature/2.0
+		}
+		violations = append(violations, collectViolations(expiry.DaysToExpiry, logMoneyness, condition)...)
+	}
+	return violations, nil
+}
+
+// CalendarArbitrage checks that total variance does not fall from one expiry to
+// the next at the same log strike over forward.  Violations are reported against
+// the later expiry, with the fall in total variance as the condition.
+func (surface *SmileSurface) CalendarArbitrage(logMoneyness []float64) ([]Violation, error) {
+	var violations []Violation
+	for i := 1; i < len(surface.Expiries); i++ {
+		earlier, later := &surface.Expiries[i-1], &surface.Expiries[i]
+		condition := make([]float64, len(logMoneyness))
+		for j, k := range logMoneyness {
+			before, err := totalVarianceAt(earlier, k)
+			if err != nil {
+				return nil, err
+			}
+			after, err := totalVarianceAt(later, k)
+			if err != nil {
+				return nil, err
+			}
+			condition[j] = after - before
+		}
+		violations = append(violations, collectViolations(later.DaysToExpiry, logMoneyness, condition)...)
+	}
+	return violations, nil
+}
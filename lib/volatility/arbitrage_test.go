@@ -0,0 +1,55 @@
+package volatility
+
+import (
+	"math"
+	"testing"
+)
+
+func logMoneynessGrid() []float64 {
+	var grid []float64
+	for k := -1.0; k <= 1.0+1e-9; k += 0.05 {
+		grid = append(grid, math.Round(k*100)/100)
+	}
+	return grid
+}
+
+func TestArbitrage(t *testing.T) {
+	// Steep and sharply curved, with little variance at the money
+	bad := SVI{A: 0.001, B: 0.6, Rho: -0.9, M: 0.0, Sigma: 0.02}
+	tests := []struct {
+		name                string
+		near, far           SVI
+		butterfly, calendar bool
+	}{
+		{"clean", testSVI, testSVI, false, false},
+		{"butterfly and calendar", bad, testSVI, true, true},
+	}
+	for _, test := range tests {
+		surface, err := NewSmileSurface(100, []ExpirySmile{
+			{DaysToExpiry: 30, Forward: 100, Smile: &test.near},
+			{DaysToExpiry: 60, Forward: 100, Smile: &test.far},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		butterfly, err := surface.ButterflyArbitrage(logMoneynessGrid())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (len(butterfly) > 0) != test.butterfly {
+			t.Errorf("%s: butterfly violations %+v", test.name, butterfly)
+		}
+		calendar, err := surface.CalendarArbitrage(logMoneynessGrid())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (len(calendar) > 0) != test.calendar {
+			t.Errorf("%s: calendar violations %+v", test.name, calendar)
+		}
+		for _, violation := range append(butterfly, calendar...) {
+			if !(violation.Worst < 0) || violation.LogMoneynessLow > violation.LogMoneynessHigh {
+				t.Errorf("%s: malformed violation %+v", test.name, violation)
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package volatility
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+const (
+	sabrMinimumQuotes = 3
+	// The fit keeps the correlation inside (-1, 1), where x(z) stays finite
+	sabrMaxRho = 0.999
+	// Below this |z| the ratio z / x(z) is taken as its limit of one
+	sabrSmallZ = 1e-7
+)
+
+// SABR holds the parameters of the SABR stochastic volatility model: initial
+// volatility Alpha, elasticity Beta in [0, 1], forward-volatility correlation Rho
+// and volatility of volatility Nu
+type SABR struct {
+	Alpha float64
+	Beta  float64
+	Rho   float64
+	Nu    float64
+}
+
+// Volatility is Hagan's lognormal implied volatility approximation
+func (sabr *SABR) Volatility(forward, strike, yearsToExpiry float64) (float64, error) {
+	if forward <= 0.0 || strike <= 0.0 {
+		return 0.0, fmt.Errorf("SABR needs forward and strike > 0, got %v/%v", forward, strike)
+	}
+	oneMinusBeta := 1.0 - sabr.Beta
+	logRatio := math.Log(forward / strike)
+	scale := math.Pow(forward*strike, oneMinusBeta/2.0)
+
+	z := sabr.Nu / sabr.Alpha * scale * logRatio
+	zOverX := 1.0
+	if math.Abs(z) > sabrSmallZ {
+		x := math.Log((math.Sqrt(1.0-2.0*sabr.Rho*z+z*z) + z - sabr.Rho) / (1.0 - sabr.Rho))
+		zOverX = z / x
+	}
+	denominator := scale * (1.0 + oneMinusBeta*oneMinusBeta/24.0*logRatio*logRatio +
+		math.Pow(oneMinusBeta, 4)/1920.0*math.Pow(logRatio, 4))
+	correction := 1.0 + (oneMinusBeta*oneMinusBeta*sabr.Alpha*sabr.Alpha/(24.0*scale*scale)+
+		sabr.Rho*sabr.Beta*sabr.Nu*sabr.Alpha/(4.0*scale)+
+		(2.0-3.0*sabr.Rho*sabr.Rho)*sabr.Nu*sabr.Nu/24.0)*yearsToExpiry
+
+	volatility := sabr.Alpha / denominator * zOverX * correction
+	if !(volatility > 0.0) || math.IsInf(volatility, 0) {
+		return 0.0, fmt.Errorf("SABR volatility %v at strike %v is not positive", volatility, strike)
+	}
+	return volatility, nil
+}
+
+func (sabr *SABR) Validate() error {
+	if sabr.Alpha <= 0.0 || sabr.Beta < 0.0 || sabr.Beta > 1.0 || math.Abs(sabr.Rho) >= 1.0 || sabr.Nu < 0.0 {
+		return fmt.Errorf("SABR needs Alpha > 0, Beta in [0, 1], |Rho| < 1 and Nu >= 0, got %v/%v/%v/%v",
+			sabr.Alpha, sabr.Beta, sabr.Rho, sabr.Nu)
+	}
+	return nil
+}
+
+// FitSABR calibrates SABR to implied volatility quotes of one expiry by
+// Nelder-Mead on squared volatility errors.  With fitBeta false Beta is held at
+// the value given; otherwise it is fitted too, starting from that value.
+func FitSABR(forward, yearsToExpiry float64, strikes, volatilities []float64, beta float64, fitBeta bool) (*SABR, error) {
+	minimum := sabrMinimumQuotes
+	if fitBeta {
+		minimum++
+	}
+	if err := validateQuotes(forward, yearsToExpiry, strikes, volatilities, minimum); err != nil {
+		return nil, err
+	}
+	if beta < 0.0 || beta > 1.0 {
+		return nil, fmt.Errorf("SABR beta must be in [0, 1], got %v", beta)
+	}
+
+	// The quote nearest the money sets the starting alpha
+	atm := 0
+	for i, strike := range strikes {
+		if math.Abs(math.Log(strike/forward)) < math.Abs(math.Log(strikes[atm]/forward)) {
+			atm = i
+		}
+	}
+
+	// Search in unconstrained coordinates: log alpha, atanh rho, log nu and logit beta
+	decode := func(point []float64) SABR {
+		sabr := SABR{Alpha: math.Exp(point[0]), Beta: beta, Rho: sabrMaxRho * math.Tanh(point[1]), Nu: math.Exp(point[2])}
+		if fitBeta {
+			sabr.Beta = 1.0 / (1.0 + math.Exp(-point[3]))
+		}
+		return sabr
+	}
+	objective := func(point []float64) float64 {
+		sabr := decode(point)
+		errors := 0.0
+		for i, strike := range strikes {
+			volatility, err := sabr.Volatility(forward, strike, yearsToExpiry)
+			if err != nil {
+				return infeasiblePenalty
+			}
+			errors += (volatility - volatilities[i]) * (volatility - volatilities[i])
+		}
+		return errors
+	}
+
+	start := []float64{math.Log(volatilities[atm] * math.Pow(forward, 1.0-beta)), 0.0, 0.0}
+	step := []float64{0.5, 0.5, 0.5}
+	if fitBeta {
+		clamped := math.Max(0.01, math.Min(beta, 0.99))
+		start = append(start, math.Log(clamped/(1.0-clamped)))
+		step = append(step, 1.0)
+	}
+	var best []float64
+	bestErrors := math.Inf(1)
+	for _, nu := range []float64{0.2, 0.5, 1.5} {
+		start[2] = math.Log(nu)
+		point, errors := util.NelderMead(objective, start, step, fitTolerance, fitIterations)
+		if errors < bestErrors {
+			best, bestErrors = point, errors
+		}
+	}
+	if bestErrors >= infeasiblePenalty {
+		return nil, fmt.Errorf("no SABR fit found for the quotes")
+	}
+	sabr := decode(best)
+	return &sabr, nil
+}
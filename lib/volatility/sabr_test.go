@@ -0,0 +1,63 @@
+package volatility
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSABRVolatility(t *testing.T) {
+	// Without volatility of volatility and with Beta of one, SABR is Black-Scholes
+	flat := SABR{Alpha: 0.25, Beta: 1, Rho: -0.5, Nu: 0}
+	for _, strike := range []float64{50, 100, 200} {
+		if got, err := flat.Volatility(100, strike, 2); err != nil || math.Abs(got-0.25) > 1e-15 {
+			t.Errorf("flat at K=%v: got %v (%v), want 0.25", strike, got, err)
+		}
+	}
+
+	// Hagan's at-the-money volatility
+	sabr := SABR{Alpha: 0.3, Beta: 0.7, Rho: -0.3, Nu: 0.6}
+	const forward, years = 100.0, 0.5
+	scale := math.Pow(forward, 1-sabr.Beta)
+	want := sabr.Alpha / scale * (1 + ((1-sabr.Beta)*(1-sabr.Beta)*sabr.Alpha*sabr.Alpha/(24*scale*scale)+
+		sabr.Rho*sabr.Beta*sabr.Nu*sabr.Alpha/(4*scale)+(2-3*sabr.Rho*sabr.Rho)*sabr.Nu*sabr.Nu/24)*years)
+	if got, err := sabr.Volatility(forward, forward, years); err != nil || math.Abs(got-want) > 1e-15 {
+		t.Errorf("at the money: got %v (%v), want %v", got, err, want)
+	}
+	// Negative correlation skews the smile down to the right
+	low, _ := sabr.Volatility(forward, 80, years)
+	high, _ := sabr.Volatility(forward, 120, years)
+	if !(low > high) {
+		t.Errorf("volatility %v at 80 not above %v at 120", low, high)
+	}
+}
+
+// A fit to quotes from known parameters recovers them, with Beta held or fitted
+func TestFitSABR(t *testing.T) {
+	truth := SABR{Alpha: 0.3, Beta: 0.7, Rho: -0.3, Nu: 0.6}
+	strikes, volatilities := quotes(t, &truth, 0.5)
+	for _, fit := range []struct {
+		beta    float64
+		fitBeta bool
+	}{
+		{0.7, false},
+		{0.5, true},
+	} {
+		fitted, err := FitSABR(100, 0.5, strikes, volatilities, fit.beta, fit.fitBeta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, parameter := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"Alpha", fitted.Alpha, truth.Alpha},
+			{"Beta", fitted.Beta, truth.Beta},
+			{"Rho", fitted.Rho, truth.Rho},
+			{"Nu", fitted.Nu, truth.Nu},
+		} {
+			if math.Abs(parameter.got-parameter.want) > 1e-6 {
+				t.Errorf("fitBeta=%v %s: got %v, want %v", fit.fitBeta, parameter.name, parameter.got, parameter.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package volatility
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Smile is the implied volatility of one expiry as a function of strike, given
+// the forward price of the asset to that expiry
+type Smile interface {
+	Volatility(forward, strike, yearsToExpiry float64) (float64, error)
+}
+
+// ExpirySmile is a smile fitted to one expiry, with the forward it was fitted at
+type ExpirySmile struct {
+	DaysToExpiry float64
+	Forward      float64
+	Smile        Smile
+}
+
+// SmileSurface prices options from smiles fitted per expiry.  Smiles are in
+// strike over forward, and forwards move in proportion with the asset price, so
+// the surface moves with the asset price across a chain.  Between expiries total
+// variance is interpolated linearly at the same strike over forward; outside them
+// the nearest smile is used.
+type SmileSurface struct {
+	SpotPrice float64
+	Expiries  []ExpirySmile
+}
+
+func NewSmileSurface(spotPrice float64, expiries []ExpirySmile) (*SmileSurface, error) {
+	if spotPrice <= 0.0 {
+		return nil, fmt.Errorf("smile surface spot price must be > 0, got %v", spotPrice)
+	}
+	if len(expiries) == 0 {
+		return nil, fmt.Errorf("smile surface needs at least one expiry")
+	}
+	sorted := append([]ExpirySmile(nil), expiries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].DaysToExpiry < sorted[j].DaysToExpiry })
+	for i, expiry := range sorted {
+		if expiry.DaysToExpiry <= 0.0 || expiry.Forward <= 0.0 || expiry.Smile == nil {
+			return nil, fmt.Errorf("smile for %v days needs days to expiry and forward > 0", expiry.DaysToExpiry)
+		}
+		if i > 0 && expiry.DaysToExpiry == sorted[i-1].DaysToExpiry {
+			return nil, fmt.Errorf("smile surface expiry %v is repeated", expiry.DaysToExpiry)
+		}
+	}
+	return &SmileSurface{SpotPrice: spotPrice, Expiries: sorted}, nil
+}
+
+// totalVariance is the total variance of expiry i at a strike over forward, with
+// the forward scaled to the asset price
+func (surface *SmileSurface) totalVariance(i int, assetPrice, moneyness float64) (float64, error) {
+	expiry := &surface.Expiries[i]
+	forward := expiry.Forward * assetPrice / surface.SpotPrice
+	years := expiry.DaysToExpiry / 365
+	volatility, err := expiry.Smile.Volatility(forward, forward*moneyness, years)
+	if err != nil {
+		return 0.0, err
+	}
+	return volatility * volatility * years, nil
+}
+
+// Volatility implements option.VolatilitySource
+func (surface *SmileSurface) Volatility(assetPrice, strikePrice, daysToExpiry float64) (float64, error) {
+	if assetPrice <= 0.0 || strikePrice <= 0.0 || daysToExpiry <= 0.0 {
+		return 0.0, fmt.Errorf("smile surface needs asset price, strike and days to expiry > 0")
+	}
+	expiries := surface.Expiries
+	last := len(expiries) - 1
+
+	// Forward to the requested expiry, interpolated in log terms between the fitted ones
+	i := max(0, min(sort.Search(len(expiries), func(i int) bool { return expiries[i].DaysToExpiry >= daysToExpiry })-1, last-1))
+	forwardRatio := func(j int) float64 { return math.Log(expiries[j].Forward / surface.SpotPrice) }
+	logForward := forwardRatio(0) * daysToExpiry / expiries[0].DaysToExpiry
+	if last > 0 {
+		weight := (daysToExpiry - expiries[i].DaysToExpiry) / (expiries[i+1].DaysToExpiry - expiries[i].DaysToExpiry)
+		logForward = forwardRatio(i) + weight*(forwardRatio(i+1)-forwardRatio(i))
+	}
+	moneyness := strikePrice / (assetPrice * math.Exp(logForward))
+	years := daysToExpiry / 365
+
+	var variance float64
+	switch {
+	case daysToExpiry <= expiries[0].DaysToExpiry || last == 0:
+		nearest := 0
+		if daysToExpiry > expiries[0].DaysToExpiry {
+			nearest = last
+		}
+		nearestVariance, err := surface.totalVariance(nearest, assetPrice, moneyness)
+		if err != nil {
+			return 0.0, err
+		}
+		variance = nearestVariance / (expiries[nearest].DaysToExpiry / 365) * years
+	case daysToExpiry >= expiries[last].DaysToExpiry:
+		lastVariance, err := surface.totalVariance(last, assetPrice, moneyness)
+		if err != nil {
+			return 0.0, err
+		}
+		variance = lastVariance / (expiries[last].DaysToExpiry / 365) * years
+	default:
+		lower, err := surface.totalVariance(i, assetPrice, moneyness)
+		if err != nil {
+			return 0.0, err
+		}
+		upper, err := surface.totalVariance(i+1, assetPrice, moneyness)
+		if err != nil {
+			return 0.0, err
+		}
+		weight := (daysToExpiry - expiries[i].DaysToExpiry) / (expiries[i+1].DaysToExpiry - expiries[i].DaysToExpiry)
+		variance = lower + weight*(upper-lower)
+	}
+	if !(variance > 0.0) {
+		return 0.0, fmt.Errorf("smile surface total variance at strike %v and %v days is not positive", strikePrice, daysToExpiry)
+	}
+	return math.Sqrt(variance / years), nil
+}
+
+// validateQuotes checks a set of implied volatility quotes for one expiry
+func validateQuotes(forward, yearsToExpiry float64, strikes, volatilities []float64, minimum int) error {
+	if forward <= 0.0 || yearsToExpiry <= 0.0 {
+		return fmt.Errorf("forward and time to expiry must be > 0, got %v/%v", forward, yearsToExpiry)
+	}
+	if len(strikes) != len(volatilities) {
+		return fmt.Errorf("%d strikes for %d volatilities", len(strikes), len(volatilities))
+	}
+	if len(strikes) < minimum {
+		return fmt.Errorf("at least %d quotes are needed, got %d", minimum, len(strikes))
+	}
+	for i := range strikes {
+		if strikes[i] <= 0.0 || !(volatilities[i] > 0.0) {
+			return fmt.Errorf("quote strike and volatility must be > 0, got %v/%v", strikes[i], volatilities[i])
+		}
+	}
+	return nil
+}
+
+// Residuals are the smile volatilities at the quoted strikes and their root mean
+// square difference from the quotes
+func Residuals(smile Smile, forward, yearsToExpiry float64, strikes, volatilities []float64) ([]float64, float64, error) {
+	fitted := make([]float64, len(strikes))
+	squares := 0.0
+	for i, strike := range strikes {
+		volatility, err := smile.Volatility(forward, strike, yearsToExpiry)
+		if err != nil {
+			return nil, 0.0, err
+		}
+		fitted[i] = volatility
+		squares += (volatility - volatilities[i]) * (volatility - volatilities[i])
+	}
+	return fitted, math.Sqrt(squares / float64(len(strikes))), nil
+}
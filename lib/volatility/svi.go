@@ -0,0 +1,171 @@
+package volatility
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+const (
+	sviMinimumQuotes = 5
+	// Largest |Rho| a fit may reach, keeping the smile wings finite
+	sviMaxRho         = 0.999
+	sviMinSigma       = 1e-4
+	fitTolerance      = 1e-12
+	fitIterations     = 2000
+	infeasiblePenalty = 1e6
+)
+
+// SVI is Gatheral's raw stochastic volatility inspired parameterisation of total
+// implied variance in log strike over forward k:
+//
+//	w(k) = A + B (Rho (k - M) + sqrt((k - M)^2 + Sigma^2))
+type SVI struct {
+	A     float64
+	B     float64
+	Rho   float64
+	M     float64
+	Sigma float64
+}
+
+func (svi *SVI) TotalVariance(logMoneyness float64) float64 {
+	shifted := logMoneyness - svi.M
+	return svi.A + svi.B*(svi.Rho*shifted+math.Sqrt(shifted*shifted+svi.Sigma*svi.Sigma))
+}
+
+func (svi *SVI) Volatility(forward, strike, yearsToExpiry float64) (float64, error) {
+	variance := svi.TotalVariance(math.Log(strike / forward))
+	if !(variance > 0.0) || yearsToExpiry <= 0.0 {
+		return 0.0, fmt.Errorf("SVI total variance %v at strike %v is not positive", variance, strike)
+	}
+	return math.Sqrt(variance / yearsToExpiry), nil
+}
+
+// Validate checks the raw SVI constraints: B >= 0, |Rho| < 1, Sigma > 0 and a
+// minimum total variance A + B Sigma sqrt(1 - Rho^2) that is not negative
+func (svi *SVI) Validate() error {
+	if svi.B < 0.0 || math.Abs(svi.Rho) >= 1.0 || svi.Sigma <= 0.0 {
+		return fmt.Errorf("SVI needs B >= 0, |Rho| < 1 and Sigma > 0, got %v/%v/%v", svi.B, svi.Rho, svi.Sigma)
+	}
+	if svi.A+svi.B*svi.Sigma*math.Sqrt(1.0-svi.Rho*svi.Rho) < 0.0 {
+		return fmt.Errorf("SVI minimum total variance is negative")
+	}
+	return nil
+}
+
+// sviLinearFit finds A, B and Rho for fixed M and Sigma by least squares, which is
+// linear in A, B Rho and B.  The result is clamped to the raw SVI constraints.
+func sviLinearFit(logMoneyness, variances []float64, m, sigma float64) SVI {
+	// Normal equations for w = a + p x + b y, x = k - m, y = sqrt(x^2 + sigma^2)
+	var matrix [3][3]float64
+	var rhs [3]float64
+	for i, k := range logMoneyness {
+		x := k - m
+		row := [3]float64{1.0, x, math.Sqrt(x*x + sigma*sigma)}
+		for r := 0; r < 3; r++ {
+			for c := 0; c < 3; c++ {
+				matrix[r][c] += row[r] * row[c]
+			}
+			rhs[r] += row[r] * variances[i]
+		}
+	}
+	a, p, b := solve3(matrix, rhs)
+
+	svi := SVI{A: a, B: b, M: m, Sigma: sigma}
+	clamped := false
+	if !(b > 0.0) {
+		svi.B, svi.Rho, clamped = 0.0, 0.0, true
+	} else if rho := p / b; math.Abs(rho) > sviMaxRho {
+		svi.Rho, clamped = math.Copysign(sviMaxRho, rho), true
+	} else {
+		svi.Rho = rho
+	}
+	if clamped {
+		// Clamped: refit A alone as the mean remaining variance
+		svi.A = 0.0
+		for i, k := range logMoneyness {
+			svi.A += variances[i] - svi.TotalVariance(k)
+		}
+		svi.A /= float64(len(logMoneyness))
+	}
+	if floor := -svi.B * svi.Sigma * math.Sqrt(1.0-svi.Rho*svi.Rho); svi.A < floor {
+		svi.A = floor
+	}
+	return svi
+}
+
+// solve3 solves a 3x3 linear system by Cramer's rule, returning zeros when singular
+func solve3(matrix [3][3]float64, rhs [3]float64) (float64, float64, float64) {
+	determinant := func(m [3][3]float64) float64 {
+		return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+			m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+			m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	}
+	full := determinant(matrix)
+	if math.Abs(full) < 1e-300 {
+		return 0.0, 0.0, 0.0
+	}
+	var solution [3]float64
+	for c := 0; c < 3; c++ {
+		replaced := matrix
+		for r := 0; r < 3; r++ {
+			replaced[r][c] = rhs[r]
+		}
+		solution[c] = determinant(replaced) / full
+	}
+	return solution[0], solution[1], solution[2]
+}
+
+// FitSVI calibrates raw SVI to implied volatility quotes of one expiry with the
+// quasi-explicit method: A, B and Rho are solved by least squares for each M and
+// Sigma tried by a Nelder-Mead search, minimising squared total variance errors.
+func FitSVI(forward, yearsToExpiry float64, strikes, volatilities []float64) (*SVI, error) {
+	if err := validateQuotes(forward, yearsToExpiry, strikes, volatilities, sviMinimumQuotes); err != nil {
+		return nil, err
+	}
+	logMoneyness := make([]float64, len(strikes))
+	variances := make([]float64, len(strikes))
+	lowestVariance := 0
+	for i := range strikes {
+		logMoneyness[i] = math.Log(strikes[i] / forward)
+		variances[i] = volatilities[i] * volatilities[i] * yearsToExpiry
+		if variances[i] < variances[lowestVariance] {
+			lowestVariance = i
+		}
+	}
+
+	fit := func(point []float64) (SVI, float64) {
+		sigma := math.Max(math.Exp(point[1]), sviMinSigma)
+		svi := sviLinearFit(logMoneyness, variances, point[0], sigma)
+		errors := 0.0
+		for i, k := range logMoneyness {
+			difference := svi.TotalVariance(k) - variances[i]
+			errors += difference * difference
+		}
+		if svi.Validate() != nil {
+			errors += infeasiblePenalty
+		}
+		return svi, errors
+	}
+	objective := func(point []float64) float64 {
+		_, errors := fit(point)
+		return errors
+	}
+
+	// Start from a few smile curvatures and keep the best fit
+	var best []float64
+	bestErrors := math.Inf(1)
+	for _, sigma := range []float64{0.05, 0.2, 0.5} {
+		point, errors := util.NelderMead(objective, []float64{logMoneyness[lowestVariance], math.Log(sigma)},
+			[]float64{0.1, 0.5}, fitTolerance, fitIterations)
+		if errors < bestErrors {
+			best, bestErrors = point, errors
+		}
+	}
+	svi, _ := fit(best)
+	if err := svi.Validate(); err != nil {
+		return nil, fmt.Errorf("no valid SVI fit found: %w", err)
+	}
+	return &svi, nil
+}
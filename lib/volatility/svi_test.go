@@ -0,0 +1,72 @@
+package volatility
+
+import (
+	"math"
+	"testing"
+)
+
+var testSVI = SVI{A: 0.01, B: 0.1, Rho: -0.4, M: 0.05, Sigma: 0.15}
+
+// quotes prices a smile at strikes 60 to 150 on a forward of 100
+func quotes(t *testing.T, smile Smile, yearsToExpiry float64) ([]float64, []float64) {
+	t.Helper()
+	var strikes, volatilities []float64
+	for strike := 60.0; strike <= 150.0; strike += 10.0 {
+		volatility, err := smile.Volatility(100, strike, yearsToExpiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		strikes = append(strikes, strike)
+		volatilities = append(volatilities, volatility)
+	}
+	return strikes, volatilities
+}
+
+func TestSVITotalVariance(t *testing.T) {
+	tests := []struct {
+		logMoneyness, want float64
+	}{
+		// At M the square root is Sigma, and far out the wings are straight lines
+		{0.05, 0.01 + 0.1*0.15},
+		{0.05 + 0.2, 0.01 + 0.1*(-0.4*0.2+0.25)},
+		{0.05 - 0.2, 0.01 + 0.1*(0.4*0.2+0.25)},
+	}
+	for _, test := range tests {
+		if got := testSVI.TotalVariance(test.logMoneyness); math.Abs(got-test.want) > 1e-15 {
+			t.Errorf("k=%v: got %v, want %v", test.logMoneyness, got, test.want)
+		}
+	}
+	volatility, err := testSVI.Volatility(100, 100*math.Exp(0.05), 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := math.Sqrt(0.025 / 0.5); math.Abs(volatility-want) > 1e-15 {
+		t.Errorf("volatility at M: got %v, want %v", volatility, want)
+	}
+}
+
+// A fit to quotes from a known smile recovers its parameters
+func TestFitSVI(t *testing.T) {
+	strikes, volatilities := quotes(t, &testSVI, 0.5)
+	fitted, err := FitSVI(100, 0.5, strikes, volatilities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, parameter := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"A", fitted.A, testSVI.A},
+		{"B", fitted.B, testSVI.B},
+		{"Rho", fitted.Rho, testSVI.Rho},
+		{"M", fitted.M, testSVI.M},
+		{"Sigma", fitted.Sigma, testSVI.Sigma},
+	} {
+		if math.Abs(parameter.got-parameter.want) > 1e-6 {
+			t.Errorf("%s: got %v, want %v", parameter.name, parameter.got, parameter.want)
+		}
+	}
+	if _, rmse, err := Residuals(fitted, 100, 0.5, strikes, volatilities); err != nil || rmse > 1e-8 {
+		t.Errorf("RMS error %v (%v), want 0", rmse, err)
+	}
+}
@@ -0,0 +1,394 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jcdevguru/option-assistant/lib/calendar"
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// CalendarHoliday is a date a calendar does not trade on
+// @Description A holiday, as YYYY-MM-DD, and its name
+type CalendarHoliday struct {
+	Date string `json:"date" binding:"required"` // Date, YYYY-MM-DD
+	Name string `json:"name,omitempty"`          // Name of the holiday
+}
+
+// CalendarEarlyClose is a date whose session ends early
+// @Description A date, as YYYY-MM-DD, and the time its session closes, as HH:MM
+type CalendarEarlyClose struct {
+	Date  string `json:"date" binding:"required"`  // Date, YYYY-MM-DD
+	Close string `json:"close" binding:"required"` // Close, HH:MM local time
+}
+
+// CalendarDefinition defines a custom trading calendar
+// @Description Session times, weekend days and holidays, optionally starting from a built-in calendar's holiday rules
+type CalendarDefinition struct {
+	Base        string               `json:"base,omitempty" binding:"omitempty,oneof=NYSE CBOE"`                                                        // Built-in calendar whose holidays and session to start from
+	TimeZone    string               `json:"timeZone,omitempty"`                                                                                        // IANA time zone; default the base's, or America/New_York
+	Open        string               `json:"open,omitempty"`                                                                                            // Session open, HH:MM; default the base's, or 09:30
+	Close       string               `json:"close,omitempty"`                                                                                           // Session close, HH:MM; default the base's, or 16:00
+	Weekend     []string             `json:"weekend,omitempty" binding:"omitempty,dive,oneof=Sunday Monday Tuesday Wednesday Thursday Friday Saturday"` // Days never traded; default Saturday and Sunday
+	Holidays    []CalendarHoliday    `json:"holidays,omitempty" binding:"omitempty,dive"`                                                               // Extra holidays
+	EarlyCloses []CalendarEarlyClose `json:"earlyCloses,omitempty" binding:"omitempty,dive"`                                                            // Extra early closes
+}
+
+// CalendarYear describes a calendar and its holidays in one year
+// @Description A calendar's session, and the holidays, early closes and standard monthly option expirations of a year
+type CalendarYear struct {
+	Name               string               `json:"name"`                 // Calendar name
+	TimeZone           string               `json:"timeZone"`             // IANA time zone
+	Open               string               `json:"open"`                 // Session open, HH:MM
+	Close              string               `json:"close"`                // Regular session close, HH:MM
+	Year               int                  `json:"year"`                 // Year listed
+	Holidays           []CalendarHoliday    `json:"holidays"`             // Weekday holidays
+	EarlyCloses        []CalendarEarlyClose `json:"earlyCloses"`          // Early closes
+	MonthlyExpirations []string             `json:"monthlyExpirations"`   // Third Friday of each month, or the trading day before
+	Definition         *CalendarDefinition  `json:"definition,omitempty"` // How a custom calendar was defined
+}
+
+// CalendarNames lists the trading calendars
+// @Description Names of the built-in and stored calendars
+type CalendarNames struct {
+	Names []string `json:"names"` // Calendar names, sorted
+}
+
+var ErrCalendarNotFound = errors.New("calendar not found")
+
+// Day count conventions accepted by dayCount
+var dayCounts = map[string]int{
+	"ACT/365": calendar.Actual365,
+	"ACT/360": calendar.Actual360,
+	"BUS/252": calendar.Business252,
+}
+
+// registeredCalendar keeps a custom calendar's definition alongside the calendar;
+// built-in calendars have no definition
+type registeredCalendar struct {
+	definition *CalendarDefinition
+	calendar   *calendar.Calendar
+}
+
+// Trading calendars by name, the built-in ones first.  Custom calendars are kept
+// in memory for the life of the server.
+var (
+	calendarsMutex sync.RWMutex
+	calendars      = map[string]registeredCalendar{
+		"NYSE": {calendar: calendar.NYSE()},
+		"CBOE": {calendar: calendar.CBOE()},
+	}
+)
+
+// parseClock reads a time of day in HH:MM form as an offset from midnight
+func parseClock(name, value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%s %s must be a time of day as HH:MM", name, value)
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+}
+
+// newCalendar builds a custom calendar from its definition
+func newCalendar(name string, definition *CalendarDefinition) (*calendar.Calendar, error) {
+	var base *calendar.Calendar
+	var zone *time.Location
+	open, close := 9*time.Hour+30*time.Minute, 16*time.Hour
+	if definition.Base != "" {
+		var err error
+		if base, err = lookupCalendar(definition.Base); err != nil {
+			return nil, err
+		}
+		zone, open, close = base.Location, base.Open, base.Close
+	}
+
+	var err error
+	switch {
+	case definition.TimeZone != "":
+		if zone, err = time.LoadLocation(definition.TimeZone); err != nil {
+			return nil, fmt.Errorf("unknown time zone %s", definition.TimeZone)
+		}
+	case zone == nil:
+		if zone, err = time.LoadLocation("America/New_York"); err != nil {
+			return nil, err
+		}
+	}
+	if definition.Open != "" {
+		if open, err = parseClock("open", definition.Open); err != nil {
+			return nil, err
+		}
+	}
+	if definition.Close != "" {
+		if close, err = parseClock("close", definition.Close); err != nil {
+			return nil, err
+		}
+	}
+
+	weekend := []time.Weekday{time.Saturday, time.Sunday}
+	if len(definition.Weekend) > 0 {
+		weekend = nil
+		for _, day := range definition.Weekend {
+			for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+				if weekday.String() == day {
+					weekend = append(weekend, weekday)
+				}
+			}
+		}
+	}
+
+	custom, err := calendar.New(name, zone, open, close, weekend, base)
+	if err != nil {
+		return nil, err
+	}
+	for _, holiday := range definition.Holidays {
+		date, err := calendar.ParseDate(holiday.Date)
+		if err != nil {
+			return nil, err
+		}
+		custom.AddHoliday(date, holiday.Name)
+	}
+	for _, earlyClose := range definition.EarlyCloses {
+		date, err := calendar.ParseDate(earlyClose.Date)
+		if err != nil {
+			return nil, err
+		}
+		closeTime, err := parseClock("early close", earlyClose.Close)
+		if err != nil {
+			return nil, err
+		}
+		if err := custom.AddEarlyClose(date, closeTime); err != nil {
+			return nil, err
+		}
+	}
+	return custom, nil
+}
+
+// lookupCalendar finds a built-in or stored calendar by name
+func lookupCalendar(name string) (*calendar.Calendar, error) {
+	calendarsMutex.RLock()
+	defer calendarsMutex.RUnlock()
+	registered, ok := calendars[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCalendarNotFound, name)
+	}
+	return registered.calendar, nil
+}
+
+// dayCountClock measures time from a chain query's valuation time in days of
+// 365 to the year under its day count convention
+type dayCountClock struct {
+	calendar   *calendar.Calendar
+	convention int
+	valuation  time.Time
+}
+
+func newDayCountClock(query *OptionChainQuery) (*dayCountClock, error) {
+	tradingCalendar, err := lookupCalendar(query.Calendar)
+	if err != nil {
+		return nil, err
+	}
+	convention, ok := dayCounts[query.DayCount]
+	if !ok {
+		return nil, fmt.Errorf("unknown day count %s - use ACT/365, ACT/360 or BUS/252", query.DayCount)
+	}
+
+	valuation := time.Now()
+	if query.ValuationTime != "" {
+		if valuation, err = time.Parse(time.RFC3339, query.ValuationTime); err != nil {
+			return nil, fmt.Errorf("valuationTime %s must be an RFC 3339 timestamp such as 2025-01-10T14:30:00-05:00", query.ValuationTime)
+		}
+	}
+	return &dayCountClock{calendar: tradingCalendar, convention: convention, valuation: valuation}, nil
+}
+
+// years is the day count year fraction from the valuation time to a time
+func (clock *dayCountClock) years(to time.Time) (float64, error) {
+	return clock.calendar.YearFraction(clock.convention, clock.valuation, to)
+}
+
+// dividends restates the calendar days to each ex-date in day count days, so
+// ex-dates fall at the same point of the expiry axis as under ACT/365
+func (clock *dayCountClock) dividends(dividends []option.Dividend) ([]option.Dividend, error) {
+	var result []option.Dividend
+	for _, dividend := range dividends {
+		exDate := clock.valuation.Add(time.Duration(dividend.DaysToExDate * float64(24*time.Hour)))
+		years, err := clock.years(exDate)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, option.Dividend{DaysToExDate: util.Round(years*365, 6), Amount: dividend.Amount})
+	}
+	return result, nil
+}
+
+// expirationAxis measures the time from the valuation time to each expiration
+// date of a chain query, as days to expiry of 365 to the year under the query's
+// day count convention, and lists the expirations for the response
+func expirationAxis(query *OptionChainQuery, clock *dayCountClock) (option.ValueList, []Expiration, error) {
+	// A negative offset expires options at the session close
+	expiryOffset := time.Duration(-1)
+	if query.ExpiryTime != "" {
+		var err error
+		if expiryOffset, err = parseClock("expiryTime", query.ExpiryTime); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var axis option.ValueList
+	var expirations []Expiration
+	for _, entry := range strings.Split(query.ExpirationDates, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		date, err := calendar.ParseDate(entry)
+		if err != nil {
+			return nil, nil, fmt.Errorf("expirationDates: %w", err)
+		}
+		expiry, err := clock.calendar.ExpiryTime(date, expiryOffset)
+		if err != nil {
+			return nil, nil, fmt.Errorf("expirationDates: %w", err)
+		}
+		if !expiry.After(clock.valuation) {
+			return nil, nil, fmt.Errorf("expirationDates: %v expires at %v, before the valuation time %v",
+				date, expiry.Format(time.RFC3339), clock.valuation.Format(time.RFC3339))
+		}
+		years, err := clock.years(expiry)
+		if err != nil {
+			return nil, nil, err
+		}
+		days := util.Round(years*365, 6)
+		if days <= 0.0 {
+			return nil, nil, fmt.Errorf("expirationDates: %v has no %s time left to expiry", date, query.DayCount)
+		}
+		axis = append(axis, days)
+		expirations = append(expirations, Expiration{
+			ExpirationDate: date.String(),
+			ExpiryTime:     expiry.Format(time.RFC3339),
+			DaysToExpiry:   days,
+			YearsToExpiry:  util.Round(years, 8),
+		})
+	}
+	if len(axis) == 0 {
+		return nil, nil, fmt.Errorf("expirationDates lists no dates")
+	}
+	return axis, expirations, nil
+}
+
+// PutCalendar godoc
+// @Summary Store a trading calendar
+// @Description Stores a named custom calendar, replacing any custom calendar of the same name.  Option chains use it with calendar=name.
+// @Description The built-in NYSE and CBOE calendars cannot be replaced.  Custom calendars are kept in memory and are lost when the server restarts.
+// @Tags calendars
+// @Accept  json
+// @Produce  json
+// @Param name path string true "Calendar name (letters, digits, - and _)"
+// @Param calendar body CalendarDefinition true "Calendar definition"
+// @Success 200 {object} CalendarYear
+// @Router /calendars/{name} [put]
+func PutCalendar(name string, definition *CalendarDefinition) (CalendarYear, error) {
+	if err := validStoredName("calendar", name); err != nil {
+		return CalendarYear{}, err
+	}
+	custom, err := newCalendar(name, definition)
+	if err != nil {
+		return CalendarYear{}, err
+	}
+
+	calendarsMutex.Lock()
+	if registered, ok := calendars[name]; ok && registered.definition == nil {
+		calendarsMutex.Unlock()
+		return CalendarYear{}, fmt.Errorf("the built-in %s calendar cannot be replaced", name)
+	}
+	calendars[name] = registeredCalendar{definition: definition, calendar: custom}
+	calendarsMutex.Unlock()
+	return GetCalendar(name, time.Now().Year())
+}
+
+// GetCalendar godoc
+// @Summary Get a trading calendar
+// @Description Returns a calendar's session times with the holidays, early closes and monthly option expirations of a year.
+// @Tags calendars
+// @Produce  json
+// @Param name path string true "Calendar name"
+// @Param year query int false "Year to list; default the current year"
+// @Success 200 {object} CalendarYear
+// @Failure 404 {object} map[string]string "No calendar of that name"
+// @Router /calendars/{name} [get]
+func GetCalendar(name string, year int) (CalendarYear, error) {
+	calendarsMutex.RLock()
+	registered, ok := calendars[name]
+	calendarsMutex.RUnlock()
+	if !ok {
+		return CalendarYear{}, fmt.Errorf("%w: %s", ErrCalendarNotFound, name)
+	}
+
+	tradingCalendar := registered.calendar
+	result := CalendarYear{
+		Name:               name,
+		TimeZone:           tradingCalendar.Location.String(),
+		Open:               formatClock(tradingCalendar.Open),
+		Close:              formatClock(tradingCalendar.Close),
+		Year:               year,
+		Holidays:           []CalendarHoliday{},
+		EarlyCloses:        []CalendarEarlyClose{},
+		MonthlyExpirations: []string{},
+		Definition:         registered.definition,
+	}
+	holidays, earlyCloses := tradingCalendar.Holidays(year)
+	for _, holiday := range holidays {
+		result.Holidays = append(result.Holidays, CalendarHoliday{Date: holiday.Date.String(), Name: holiday.Name})
+	}
+	for _, earlyClose := range earlyCloses {
+		result.EarlyCloses = append(result.EarlyCloses, CalendarEarlyClose{Date: earlyClose.Date.String(), Close: formatClock(earlyClose.Close)})
+	}
+	for month := time.January; month <= time.December; month++ {
+		result.MonthlyExpirations = append(result.MonthlyExpirations, tradingCalendar.MonthlyExpiration(year, month).String())
+	}
+	return result, nil
+}
+
+// CalendarList godoc
+// @Summary List trading calendars
+// @Description Lists the names of the built-in and stored calendars.
+// @Tags calendars
+// @Produce  json
+// @Success 200 {object} CalendarNames
+// @Router /calendars [get]
+func CalendarList() CalendarNames {
+	calendarsMutex.RLock()
+	defer calendarsMutex.RUnlock()
+	names := CalendarNames{Names: []string{}}
+	for name := range calendars {
+		names.Names = append(names.Names, name)
+	}
+	sort.Strings(names.Names)
+	return names
+}
+
+// DeleteCalendar godoc
+// @Summary Remove a trading calendar
+// @Description Deletes a custom calendar.  The built-in calendars cannot be deleted.
+// @Tags calendars
+// @Param name path string true "Calendar name"
+// @Success 204
+// @Failure 404 {object} map[string]string "No custom calendar of that name"
+// @Router /calendars/{name} [delete]
+func DeleteCalendar(name string) error {
+	calendarsMutex.Lock()
+	defer calendarsMutex.Unlock()
+	if registered, ok := calendars[name]; !ok || registered.definition == nil {
+		return fmt.Errorf("%w: no custom calendar %s", ErrCalendarNotFound, name)
+	}
+	delete(calendars, name)
+	return nil
+}
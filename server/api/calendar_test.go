@@ -0,0 +1,34 @@
+package api
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+)
+
+// An ex-date a week after a Monday open is restated in each day count's days
+func TestDayCountDividends(t *testing.T) {
+	tests := []struct {
+		dayCount string
+		want     float64
+	}{
+		{"ACT/365", 7.0},
+		{"ACT/360", 7.0 * 365 / 360},
+		{"BUS/252", 5.0 * 365 / 252},
+	}
+	for _, test := range tests {
+		query := OptionChainQuery{Calendar: "NYSE", DayCount: test.dayCount, ValuationTime: "2025-01-06T09:30:00-05:00"}
+		clock, err := newDayCountClock(&query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dividends, err := clock.dividends([]option.Dividend{{DaysToExDate: 7, Amount: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := dividends[0].DaysToExDate; math.Abs(got-test.want) > 1e-6 {
+			t.Errorf("%s: got %v days to ex-date, want %v", test.dayCount, got, test.want)
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jcdevguru/option-assistant/lib/option"
 	"github.com/jcdevguru/option-assistant/lib/util"
@@ -10,8 +15,21 @@ import (
 // Position contains call and put prices for a specific expiry date
 // @Description Contains the call and put prices for a specific number of days to expiry
 type Position struct {
-	Price        float64 `json:"price"`        // Option price
-	DaysToExpiry float64 `json:"daysToExpiry"` // Days to expiry
+	Price        float64  `json:"price"`           // Option price
+	DaysToExpiry float64  `json:"daysToExpiry"`    // Days to expiry
+	Delta        *float64 `json:"delta,omitempty"` // Change in price per 1.0 change in asset price
+	Gamma        *float64 `json:"gamma,omitempty"` // Change in delta per 1.0 change in asset price
+	Theta        *float64 `json:"theta,omitempty"` // Change in price per calendar day
+	Vega         *float64 `json:"vega,omitempty"`  // Change in price per volatility point
+	Rho          *float64 `json:"rho,omitempty"`   // Change in price per percentage point of risk-free rate
+	// Value of early exercise over the Black-Scholes price, for American exercise
+	EarlyExercisePremium *float64 `json:"earlyExercisePremium,omitempty"`
+	// Exercising now is worth at least holding, for American exercise; a dividend going ex by tomorrow is only collected by exercising today
+	ExerciseNow bool `json:"exerciseNow,omitempty"`
+	// Black-Scholes price at the model's volatility, for compareBlackScholes
+	BlackScholesPrice *float64 `json:"blackScholesPrice,omitempty"`
+	// Price of the vanilla spread replicating a digital, for digitalSpreadWidth
+	ReplicationPrice *float64 `json:"replicationPrice,omitempty"`
 }
 
 // Strike_Positions contains option prices for a specific strike price and expiry dates
@@ -31,103 +49,880 @@ type AssetPrice_Strike_Positions struct {
 // OptionChainResponse represents the response structure for an option chain request
 // @Description The response object for the CalculateOptionChain endpoint
 type OptionChainResponse struct {
-	AssetName   string                        `json:"assetName"`   // Name of the asset
-	OptionChain []AssetPrice_Strike_Positions `json:"optionChain"` // Option price/dtes per asset price / strike
+	AssetName    string                        `json:"assetName"`              // Name of the asset
+	AssetPrices  []float64                     `json:"assetPrices,omitempty"`  // Asset prices resolved from a relative assetPriceMode
+	StrikePrices []float64                     `json:"strikePrices,omitempty"` // Strike prices resolved from a relative strikePriceMode
+	Expirations  []Expiration                  `json:"expirations,omitempty"`  // Days to expiry measured for expirationDates
+	OptionChain  []AssetPrice_Strike_Positions `json:"optionChain"`            // Option price/dtes per asset price / strike
+}
+
+// Expiration is the time to one expiration date of an option chain
+// @Description An expiration date, when options expiring on it stop trading, and the time to it as measured by the day count
+type Expiration struct {
+	ExpirationDate string  `json:"expirationDate"` // Expiration date, YYYY-MM-DD
+	ExpiryTime     string  `json:"expiryTime"`     // Time of expiry, RFC 3339
+	DaysToExpiry   float64 `json:"daysToExpiry"`   // Time to expiry in days of 365 to the year, as in the positions
+	YearsToExpiry  float64 `json:"yearsToExpiry"`  // Time to expiry in years
 }
 
 type OptionChainQuery struct {
-	AssetName        string  `form:"assetName" binding:"required,min=2,alphanum"`
-	OptionType       string  `form:"optionType" binding:"required,oneof=Call Put"`
-	AssetPriceLow    float64 `form:"assetPriceLow" binding:"required,gt=0"`
-	AssetPriceHigh   float64 `form:"assetPriceHigh" binding:"required,gtefield=AssetPriceLow"`
-	AssetPriceStep   float64 `form:"assetPriceStep,default=1.0" binding:"required,gt=0.0"`
-	StrikePriceLow   float64 `form:"strikePriceLow" binding:"required,gt=0"`
-	StrikePriceHigh  float64 `form:"strikePriceHigh" binding:"required,gtefield=StrikePriceLow"`
-	StrikePriceStep  float64 `form:"strikePriceStep,default=1.0" binding:"required,gt=0.0"`
-	DaysToExpiryLow  float64 `form:"daysToExpiryLow" binding:"required,gt=0"`
-	DaysToExpiryHigh float64 `form:"daysToExpiryHigh" binding:"required,gtefield=DaysToExpiryLow"`
-	DaysToExpiryStep float64 `form:"daysToExpiryStep,default=1.0" binding:"required,gt=0.0"`
-	RiskFreeRate     float64 `form:"riskFreeRate" binding:"required,gt=0"`
-	Volatility       float64 `form:"volatility" binding:"required,gt=0"`
-}
-
-func encodeResponse(assetPriceSpan option.ValueSpan, chain option.OptionChain) []AssetPrice_Strike_Positions {
+	AssetName             string   `form:"assetName" binding:"required,min=2,alphanum"`
+	OptionType            string   `form:"optionType" binding:"required,oneof=Call Put CashOrNothingCall CashOrNothingPut AssetOrNothingCall AssetOrNothingPut GapCall GapPut PowerCall PowerPut"`
+	CashPayout            *float64 `form:"cashPayout" binding:"omitempty,gt=0"`
+	GapTrigger            float64  `form:"gapTrigger" binding:"gte=0"`
+	PowerExponent         float64  `form:"powerExponent" binding:"gte=0"`
+	DigitalSpreadWidth    float64  `form:"digitalSpreadWidth" binding:"gte=0"`
+	SpotPrice             float64  `form:"spotPrice" binding:"gte=0"`
+	AssetPriceMode        string   `form:"assetPriceMode,default=Absolute" binding:"oneof=Absolute Percent StdDev"`
+	AssetPriceLow         float64  `form:"assetPriceLow"`
+	AssetPriceHigh        float64  `form:"assetPriceHigh" binding:"gtefield=AssetPriceLow"`
+	AssetPriceStep        float64  `form:"assetPriceStep,default=1.0" binding:"required,gt=0.0"`
+	AssetPriceSpacing     string   `form:"assetPriceSpacing,default=Linear" binding:"oneof=Linear Geometric"`
+	AssetPriceCount       int      `form:"assetPriceCount" binding:"gte=0"`
+	AssetPrices           string   `form:"assetPrices"`
+	StrikePriceMode       string   `form:"strikePriceMode,default=Absolute" binding:"oneof=Absolute Moneyness Delta"`
+	StrikePriceLow        *float64 `form:"strikePriceLow"`
+	StrikePriceHigh       *float64 `form:"strikePriceHigh"`
+	StrikePriceStep       float64  `form:"strikePriceStep,default=1.0" binding:"required,gt=0.0"`
+	StrikePriceSpacing    string   `form:"strikePriceSpacing,default=Linear" binding:"oneof=Linear Geometric"`
+	StrikePriceCount      int      `form:"strikePriceCount" binding:"gte=0"`
+	StrikePrices          string   `form:"strikePrices"`
+	DaysToExpiryLow       float64  `form:"daysToExpiryLow" binding:"required_without_all=DaysToExpiry ExpirationDates,omitempty,gt=0"`
+	DaysToExpiryHigh      float64  `form:"daysToExpiryHigh" binding:"required_without_all=DaysToExpiry ExpirationDates,omitempty,gtefield=DaysToExpiryLow"`
+	DaysToExpiryStep      float64  `form:"daysToExpiryStep,default=1.0" binding:"required,gt=0.0"`
+	DaysToExpirySpacing   string   `form:"daysToExpirySpacing,default=Linear" binding:"oneof=Linear Geometric"`
+	DaysToExpiryCount     int      `form:"daysToExpiryCount" binding:"gte=0"`
+	DaysToExpiry          string   `form:"daysToExpiry"`
+	ExpirationDates       string   `form:"expirationDates"`
+	ValuationTime         string   `form:"valuationTime"`
+	ExpiryTime            string   `form:"expiryTime"`
+	Calendar              string   `form:"calendar,default=NYSE"`
+	DayCount              string   `form:"dayCount,default=ACT/365" binding:"oneof=ACT/365 ACT/360 BUS/252"`
+	ReferenceDaysToExpiry float64  `form:"referenceDaysToExpiry" binding:"gte=0"`
+	RiskFreeRate          float64  `form:"riskFreeRate" binding:"required_without_all=RateCurve DomesticRate,omitempty,gt=0"`
+	RateCurve             string   `form:"rateCurve"`
+	Volatility            float64  `form:"volatility" binding:"required_without_all=VolSurface Heston,omitempty,gt=0"`
+	VolSurface            string   `form:"volSurface"`
+	Greeks                string   `form:"greeks"`
+	Model                 string   `form:"model,default=BlackScholes" binding:"oneof=BlackScholes Black76 Bachelier GarmanKohlhagen Heston Merton Kou"`
+	Heston                string   `form:"heston"`
+	JumpIntensity         float64  `form:"jumpIntensity" binding:"gte=0"`
+	JumpMean              float64  `form:"jumpMean"`
+	JumpVolatility        float64  `form:"jumpVolatility" binding:"gte=0"`
+	JumpUpProbability     float64  `form:"jumpUpProbability" binding:"gte=0,lte=1"`
+	JumpUpMean            float64  `form:"jumpUpMean" binding:"gte=0,lt=1"`
+	JumpDownMean          float64  `form:"jumpDownMean" binding:"gte=0"`
+	CompareBlackScholes   bool     `form:"compareBlackScholes"`
+	DomesticRate          *float64 `form:"domesticRate"`
+	ForeignRate           *float64 `form:"foreignRate"`
+	DeltaConvention       string   `form:"deltaConvention,default=Spot" binding:"oneof=Spot Forward PremiumAdjustedSpot PremiumAdjustedForward"`
+	PremiumConvention     string   `form:"premiumConvention,default=DomesticPips" binding:"oneof=DomesticPips ForeignPercent ForeignPips DomesticPercent"`
+	ExerciseStyle         string   `form:"exerciseStyle,default=European" binding:"oneof=European American"`
+	AmericanModel         string   `form:"americanModel,default=CRR" binding:"oneof=CRR LeisenReimer CrankNicolson BaroneAdesiWhaley BjerksundStensland"`
+	LatticeSteps          int      `form:"latticeSteps,default=100" binding:"gte=2,lte=5000"`
+	GridPoints            int      `form:"gridPoints,default=200" binding:"gte=10,lte=5000"`
+	BarrierType           string   `form:"barrierType" binding:"omitempty,oneof=UpAndOut DownAndOut UpAndIn DownAndIn"`
+	Barrier               float64  `form:"barrier" binding:"required_with=BarrierType,omitempty,gt=0"`
+	Rebate                float64  `form:"rebate" binding:"gte=0"`
+	DividendYield         float64  `form:"dividendYield" binding:"gte=0"`
+	Dividends             string   `form:"dividends"`
+	DryRun                bool     `form:"dryRun"`
+}
+
+// OptionChainEstimate projects the size and cost of an option chain request
+// @Description The projected size and compute time of an option chain, returned for dryRun=true
+type OptionChainEstimate struct {
+	AssetName             string `json:"assetName"`               // Name of the asset
+	AssetPrices           int    `json:"assetPrices"`             // Number of asset prices
+	StrikePrices          int    `json:"strikePrices"`            // Number of strike prices
+	DaysToExpiry          int    `json:"daysToExpiry"`            // Number of days to expiry
+	Cells                 int    `json:"cells"`                   // Number of prices to compute
+	EstimatedBytes        int64  `json:"estimatedBytes"`          // Approximate size of the JSON response
+	EstimatedMilliseconds int64  `json:"estimatedMilliseconds"`   // Approximate compute time
+	WithinLimits          bool   `json:"withinLimits"`            // Whether the request would be accepted
+	LimitExceeded         string `json:"limitExceeded,omitempty"` // Which limit the request exceeds
+}
+
+// parseDividends reads a comma-separated list of daysToExDate:amount pairs
+func parseDividends(dividends string) ([]option.Dividend, error) {
+	var result []option.Dividend
+	for _, entry := range strings.Split(dividends, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		days, amount, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("dividend %s must be given as daysToExDate:amount", entry)
+		}
+		daysToExDate, err := strconv.ParseFloat(strings.TrimSpace(days), 64)
+		if err != nil {
+			return nil, fmt.Errorf("dividend %s: invalid days to ex-date: %w", entry, err)
+		}
+		cashAmount, err := strconv.ParseFloat(strings.TrimSpace(amount), 64)
+		if err != nil {
+			return nil, fmt.Errorf("dividend %s: invalid amount: %w", entry, err)
+		}
+		result = append(result, option.Dividend{DaysToExDate: daysToExDate, Amount: cashAmount})
+	}
+	return result, nil
+}
+
+// parseValueList reads a comma-separated list of axis values
+func parseValueList(name, values string) (option.ValueList, error) {
+	var result option.ValueList
+	for _, entry := range strings.Split(values, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(entry, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid value %s: %w", name, entry, err)
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// axisFromQuery builds an axis from an explicit value list if one was given, and
+// otherwise from a linearly stepped or geometrically spaced range
+func axisFromQuery(name, values string, low, high *float64, step float64, spacing string, count int) (option.Axis, error) {
+	if strings.TrimSpace(values) != "" {
+		return parseValueList(name, values)
+	}
+	// Zero is a valid end of a range, so only a missing one is an error
+	if low == nil || high == nil {
+		return nil, fmt.Errorf("%s: give a list of values, or both the low and high ends of a range", name)
+	}
+	switch spacing {
+	case "Linear":
+		return &option.ValueSpan{Low: *low, High: *high, Step: step}, nil
+	case "Geometric":
+		return &option.GeometricSpan{Low: *low, High: *high, Points: count}, nil
+	default:
+		return nil, fmt.Errorf("%s: unknown spacing %s - use Linear or Geometric", name, spacing)
+	}
+}
+
+func modelFromName(model string) (int, error) {
+	switch model {
+	case "BlackScholes":
+		return option.BlackScholes, nil
+	case "Black76":
+		return option.Black76, nil
+	case "Bachelier":
+		return option.Bachelier, nil
+	case "GarmanKohlhagen":
+		return option.GarmanKohlhagen, nil
+	case "Heston":
+		return option.Heston, nil
+	case "Merton":
+		return option.Merton, nil
+	case "Kou":
+		return option.Kou, nil
+	}
+	return 0, fmt.Errorf("unknown model %s - use BlackScholes, Black76, Bachelier, GarmanKohlhagen, Heston, Merton or Kou", model)
+}
+
+// payoffFromName splits a chain option type into its call or put side and its payoff
+func payoffFromName(optionType string) (int, int, error) {
+	payoffs := []struct {
+		prefix string
+		payoff int
+	}{
+		{"CashOrNothing", option.CashOrNothing},
+		{"AssetOrNothing", option.AssetOrNothing},
+		{"Gap", option.Gap},
+		{"Power", option.Power},
+	}
+	sideName, payoff := optionType, option.Vanilla
+	for _, candidate := range payoffs {
+		if side, found := strings.CutPrefix(optionType, candidate.prefix); found {
+			sideName, payoff = side, candidate.payoff
+			break
+		}
+	}
+	side, err := optionTypeFromName(sideName)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unknown option type %s - use Call or Put, optionally after CashOrNothing, AssetOrNothing, Gap or Power", optionType)
+	}
+	return side, payoff, nil
+}
+
+// setChainPayoff sets the payoff of a chain query with the terms it gives for it
+func setChainPayoff(query *OptionChainQuery, chain *option.OptionChainCalculator, payoff int) error {
+	terms := option.PayoffTerms{Cash: 1.0, Trigger: query.GapTrigger, Exponent: query.PowerExponent}
+	if query.CashPayout != nil {
+		if payoff != option.CashOrNothing {
+			return fmt.Errorf("cashPayout applies to CashOrNothing options")
+		}
+		terms.Cash = *query.CashPayout
+	}
+	switch {
+	case query.GapTrigger != 0.0 && payoff != option.Gap:
+		return fmt.Errorf("gapTrigger applies to Gap options")
+	case query.PowerExponent != 0.0 && payoff != option.Power:
+		return fmt.Errorf("powerExponent applies to Power options")
+	case payoff == option.Gap && query.GapTrigger == 0.0:
+		return fmt.Errorf("gapTrigger is required for Gap options")
+	case payoff == option.Power && query.PowerExponent == 0.0:
+		return fmt.Errorf("powerExponent is required for Power options")
+	case payoff == option.Power && query.StrikePriceMode != "Absolute":
+		return fmt.Errorf("power option strikes are on the powered asset price - use Absolute strikes")
+	}
+	if payoff != option.Vanilla {
+		if err := chain.SetPayoff(payoff, terms); err != nil {
+			return err
+		}
+	}
+	return chain.SetDigitalSpread(query.DigitalSpreadWidth)
+}
+
+func exerciseFromNames(exerciseStyle, americanModel string) (int, int, error) {
+	var style, model int
+	switch exerciseStyle {
+	case "European":
+		style = option.European
+	case "American":
+		style = option.American
+	default:
+		return 0, 0, fmt.Errorf("unknown exercise style %s - use European or American", exerciseStyle)
+	}
+	switch americanModel {
+	case "CRR":
+		model = option.LatticeCRR
+	case "LeisenReimer":
+		model = option.LatticeLeisenReimer
+	case "CrankNicolson":
+		model = option.CrankNicolson
+	case "BaroneAdesiWhaley":
+		model = option.BaroneAdesiWhaley
+	case "BjerksundStensland":
+		model = option.BjerksundStensland
+	default:
+		return 0, 0, fmt.Errorf("unknown american model %s - use CRR, LeisenReimer, CrankNicolson, BaroneAdesiWhaley or BjerksundStensland", americanModel)
+	}
+	return style, model, nil
+}
+
+// setChainModel sets the pricing model of a chain query, with the stored
+// parameters it names for Heston and the jumps it gives for Merton and Kou
+func setChainModel(query *OptionChainQuery, chain *option.OptionChainCalculator, model int) error {
+	if model != option.Heston && query.Heston != "" {
+		return fmt.Errorf("heston applies to the Heston model")
+	}
+	mertonJumps := query.JumpMean != 0.0 || query.JumpVolatility != 0.0
+	kouJumps := query.JumpUpProbability != 0.0 || query.JumpUpMean != 0.0 || query.JumpDownMean != 0.0
+	if model != option.Merton && model != option.Kou && (query.JumpIntensity != 0.0 || mertonJumps || kouJumps) {
+		return fmt.Errorf("jump parameters apply to the Merton and Kou models")
+	}
+	if query.VolSurface != "" && (model == option.Merton || model == option.Kou) {
+		return fmt.Errorf("the %s model prices at volatility between jumps rather than volSurface", query.Model)
+	}
+	switch model {
+	case option.Heston:
+	case option.Merton:
+		if kouJumps {
+			return fmt.Errorf("jumpUpProbability, jumpUpMean and jumpDownMean apply to the Kou model")
+		}
+		return chain.SetMertonJumps(option.MertonJumps{
+			Intensity:  query.JumpIntensity,
+			Mean:       query.JumpMean,
+			Volatility: query.JumpVolatility,
+		})
+	case option.Kou:
+		if mertonJumps {
+			return fmt.Errorf("jumpMean and jumpVolatility apply to the Merton model")
+		}
+		return chain.SetKouJumps(option.KouJumps{
+			Intensity:     query.JumpIntensity,
+			UpProbability: query.JumpUpProbability,
+			UpMean:        query.JumpUpMean,
+			DownMean:      query.JumpDownMean,
+		})
+	default:
+		return chain.SetModel(model)
+	}
+	if query.Heston == "" {
+		return fmt.Errorf("heston, the name of a stored parameter set, is required for the Heston model")
+	}
+	if query.VolSurface != "" {
+		return fmt.Errorf("the Heston model prices from its own parameters rather than volSurface")
+	}
+	parameters, err := lookupHeston(query.Heston)
+	if err != nil {
+		return err
+	}
+	return chain.SetHeston(parameters)
+}
+
+// relativeAxes resolves asset price and strike price axes given relative to the
+// spot price.  Standard deviations and deltas are taken over the reference days to
+// expiry, by default the longest expiry of the chain, standard deviations at the
+// at-the-money volatility.
+func relativeAxes(query *OptionChainQuery, chain *option.OptionChainCalculator, assetPriceAxis, strikePriceAxis option.Axis) (option.Axis, option.Axis, error) {
+	if (query.AssetPriceMode != "Absolute" || query.StrikePriceMode != "Absolute") && query.SpotPrice <= 0.0 {
+		return nil, nil, fmt.Errorf("spotPrice must be > 0 for relative asset or strike price modes")
+	}
+	referenceDays := query.ReferenceDaysToExpiry
+	if referenceDays == 0.0 {
+		referenceDays = chain.ExpiryInDays
+	}
+
+	switch query.AssetPriceMode {
+	case "Absolute":
+		if strings.TrimSpace(query.AssetPrices) == "" && query.AssetPriceLow <= 0.0 && query.Model != "Bachelier" {
+			return nil, nil, fmt.Errorf("assetPriceLow must be > 0 unless assetPrices is given")
+		}
+	case "Percent":
+		assetPriceAxis = option.PercentMoveAxis(query.SpotPrice, assetPriceAxis)
+	case "StdDev":
+		volatility, err := chain.VolatilityAt(query.SpotPrice, query.SpotPrice, referenceDays)
+		if err != nil {
+			return nil, nil, err
+		}
+		if query.Model == "Bachelier" {
+			assetPriceAxis = option.NormalDeviationAxis(query.SpotPrice, volatility, referenceDays, assetPriceAxis)
+		} else {
+			assetPriceAxis = option.StandardDeviationAxis(query.SpotPrice, volatility, referenceDays, assetPriceAxis)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown asset price mode %s - use Absolute, Percent or StdDev", query.AssetPriceMode)
+	}
+
+	switch query.StrikePriceMode {
+	case "Absolute":
+	case "Moneyness":
+		strikePriceAxis = option.MoneynessAxis(query.SpotPrice, strikePriceAxis)
+	case "Delta":
+		strikePriceAxis = chain.DeltaStrikeAxis(query.SpotPrice, referenceDays, strikePriceAxis)
+	default:
+		return nil, nil, fmt.Errorf("unknown strike price mode %s - use Absolute, Moneyness or Delta", query.StrikePriceMode)
+	}
+	return assetPriceAxis, strikePriceAxis, nil
+}
+
+// Limits on the size of an option chain, checked before any pricing is done
+var (
+	MaxChainCells     = 2_000_000
+	MaxComputeSeconds = 60
+	ErrGridTooLarge   = errors.New("option chain has too many cells")
+	ErrAxisEmpty      = errors.New("option chain axis is empty")
+	ErrTooSlow        = errors.New("request would take too long to compute")
+	ErrInvalidQuery   = errors.New("invalid option chain query")
+)
+
+// checkComputeTime rejects requests projected to take over MaxComputeSeconds
+func checkComputeTime(estimate time.Duration) error {
+	if limit := time.Duration(MaxComputeSeconds) * time.Second; estimate > limit {
+		return fmt.Errorf("%w: about %v projected, at most %v allowed - reduce the steps, points or axes",
+			ErrTooSlow, estimate.Round(time.Second), limit)
+	}
+	return nil
+}
+
+// ChainWorkers is the number of goroutines computing each option chain; GOMAXPROCS when zero
+var ChainWorkers int
+
+// greekSelection records which Greeks were requested for the response
+type greekSelection struct {
+	delta, gamma, theta, vega, rho bool
+}
+
+func (selection greekSelection) any() bool {
+	return selection.delta || selection.gamma || selection.theta || selection.vega || selection.rho
+}
+
+// parseGreeks accepts a comma-separated list of Greek names, or "all"
+func parseGreeks(greeks string) (greekSelection, error) {
+	var selection greekSelection
+	for _, name := range strings.Split(greeks, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "":
+		case "all":
+			selection = greekSelection{true, true, true, true, true}
+		case "delta":
+			selection.delta = true
+		case "gamma":
+			selection.gamma = true
+		case "theta":
+			selection.theta = true
+		case "vega":
+			selection.vega = true
+		case "rho":
+			selection.rho = true
+		default:
+			return greekSelection{}, fmt.Errorf("unknown greek %s - use delta, gamma, theta, vega, rho or all", name)
+		}
+	}
+	return selection, nil
+}
+
+func roundedGreek(selected bool, value float64) *float64 {
+	if !selected {
+		return nil
+	}
+	rounded := util.Round(value, 4)
+	return &rounded
+}
+
+// positionEncoding records which fields of each position the response carries
+// and how finely prices are rounded
+type positionEncoding struct {
+	greeks       greekSelection
+	american     bool
+	blackScholes bool
+	replication  bool
+	pricePlaces  int32
+}
+
+// Cash-or-nothing digitals are worth a fraction of their payout, so are quoted more finely
+const digitalPricePlaces = 4
+
+func (encoding positionEncoding) roundedPrice(selected bool, value float64) *float64 {
+	if !selected {
+		return nil
+	}
+	rounded := util.Round(value, encoding.pricePlaces)
+	return &rounded
+}
+
+func encodePosition(position option.OptionPosition, encoding positionEncoding) Position {
+	greeks := encoding.greeks
+	return Position{
+		Price:        util.Round(position.Price, encoding.pricePlaces),
+		DaysToExpiry: position.DaysToExpiry,
+		Delta:        roundedGreek(greeks.delta, position.Greeks.Delta),
+		Gamma:        roundedGreek(greeks.gamma, position.Greeks.Gamma),
+		Theta:        roundedGreek(greeks.theta, position.Greeks.Theta),
+		Vega:         roundedGreek(greeks.vega, position.Greeks.Vega),
+		Rho:          roundedGreek(greeks.rho, position.Greeks.Rho),
+
+		EarlyExercisePremium: encoding.roundedPrice(encoding.american, position.EarlyExercisePremium),
+		ExerciseNow:          encoding.american && position.ExerciseNow,
+		BlackScholesPrice:    encoding.roundedPrice(encoding.blackScholes, position.BlackScholesPrice),
+		ReplicationPrice:     encoding.roundedPrice(encoding.replication, position.ReplicationPrice),
+	}
+}
+
+func encodeAssetPriceRow(assetPrice float64, strikesPerAssetPrice [][]option.OptionPosition, encoding positionEncoding) AssetPrice_Strike_Positions {
+	var strikePositions []Strike_Positions
+	for _, positionsPerStrike := range strikesPerAssetPrice {
+		positionsForStrike := Strike_Positions{StrikePrice: positionsPerStrike[0].Strike}
+		for _, position := range positionsPerStrike {
+			positionsForStrike.Positions = append(positionsForStrike.Positions, encodePosition(position, encoding))
+		}
+		strikePositions = append(strikePositions, positionsForStrike)
+	}
+	return AssetPrice_Strike_Positions{AssetPrice: assetPrice, StrikePositions: strikePositions}
+}
+
+func encodeResponse(assetPriceAxis option.Axis, chain option.OptionChain, encoding positionEncoding) ([]AssetPrice_Strike_Positions, error) {
+	assetPrices, err := assetPriceAxis.Values("assetPriceRange")
+	if err != nil {
+		return nil, err
+	}
 	var result []AssetPrice_Strike_Positions
-	assetIndex := 0
-	for assetPrice := assetPriceSpan.Low; assetPrice <= assetPriceSpan.High; assetPrice += assetPriceSpan.Step {
-		strikesPerAssetPrice := chain[assetIndex]
-		var strikePositions []Strike_Positions
-		for _, positionsPerStrike := range strikesPerAssetPrice {
-			positionsForStrike := Strike_Positions{StrikePrice: positionsPerStrike[0].Strike}
-			for _, position := range positionsPerStrike {
-				positionsForStrike.Positions = append(positionsForStrike.Positions, Position{Price: util.Round(position.Price, 2), DaysToExpiry: position.DaysToExpiry})
-			}
-			strikePositions = append(strikePositions, positionsForStrike)
+	for assetIndex, assetPrice := range assetPrices {
+		result = append(result, encodeAssetPriceRow(assetPrice, chain[assetIndex], encoding))
+	}
+	return result, nil
+}
+
+// chainRequest is a validated option chain query, ready to compute
+type chainRequest struct {
+	calculator       *option.OptionChainCalculator
+	assetPriceAxis   option.Axis
+	strikePriceAxis  option.Axis
+	daysToExpiryAxis option.Axis
+	expirations      []Expiration
+	encoding         positionEncoding
+	assetPrices      int
+	strikePrices     int
+	daysToExpiry     int
+}
+
+func (request *chainRequest) cells() int {
+	return request.assetPrices * request.strikePrices * request.daysToExpiry
+}
+
+// checkLimits rejects chains with an empty axis, over option.MaxAxisLength on any
+// axis, over MaxChainCells in total or over MaxComputeSeconds to price
+func (request *chainRequest) checkLimits() error {
+	for _, axis := range []struct {
+		name   string
+		length int
+	}{
+		{"asset price", request.assetPrices},
+		{"strike price", request.strikePrices},
+		{"days to expiry", request.daysToExpiry},
+	} {
+		if axis.length < 1 {
+			return fmt.Errorf("%w: no %s values requested", ErrAxisEmpty, axis.name)
+		}
+		if axis.length > option.MaxAxisLength {
+			return fmt.Errorf("%w: %d %s values requested, at most %d allowed - increase the step or narrow the range",
+				option.ErrAxisTooLong, axis.length, axis.name, option.MaxAxisLength)
 		}
-		result = append(result, AssetPrice_Strike_Positions{AssetPrice: assetPrice, StrikePositions: strikePositions})
 	}
-	return result
+	if cells := request.cells(); cells > MaxChainCells {
+		return fmt.Errorf("%w: %d x %d x %d = %d prices requested, at most %d allowed - increase a step or narrow a range",
+			ErrGridTooLarge, request.assetPrices, request.strikePrices, request.daysToExpiry, cells, MaxChainCells)
+	}
+	return checkComputeTime(request.calculator.EstimateComputeTime(request.assetPrices, request.strikePrices, request.daysToExpiry))
+}
+
+// Approximate JSON sizes of the parts of an option chain response
+const (
+	positionBytes             = 36
+	greekBytes                = 16
+	earlyExercisePremiumBytes = 30
+	blackScholesPriceBytes    = 28
+	replicationPriceBytes     = 27
+	strikeBytes               = 36
+	assetPriceBytes           = 40
+)
+
+func (request *chainRequest) estimatedBytes() int64 {
+	perPosition := int64(positionBytes)
+	greeks := request.encoding.greeks
+	for _, selected := range []bool{greeks.delta, greeks.gamma, greeks.theta, greeks.vega, greeks.rho} {
+		if selected {
+			perPosition += greekBytes
+		}
+	}
+	if request.encoding.american {
+		perPosition += earlyExercisePremiumBytes
+	}
+	if request.encoding.blackScholes {
+		perPosition += blackScholesPriceBytes
+	}
+	if request.encoding.replication {
+		perPosition += replicationPriceBytes
+	}
+	assetPrices, strikes := int64(request.assetPrices), int64(request.assetPrices*request.strikePrices)
+	return int64(request.cells())*perPosition + strikes*strikeBytes + assetPrices*assetPriceBytes
+}
+
+// newChainRequest prepares a chain query for pricing; every error it returns is
+// an ErrInvalidQuery
+func newChainRequest(query *OptionChainQuery) (*chainRequest, error) {
+	request, err := chainRequestFromQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+	return request, nil
+}
+
+func chainRequestFromQuery(query *OptionChainQuery) (*chainRequest, error) {
+	optionTypeNum, payoff, err := payoffFromName(query.OptionType)
+	if err != nil {
+		return nil, err
+	}
+
+	greekSelection, err := parseGreeks(query.Greeks)
+	if err != nil {
+		return nil, err
+	}
+
+	exerciseStyle, latticeModel, err := exerciseFromNames(query.ExerciseStyle, query.AmericanModel)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := modelFromName(query.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	dividends, err := parseDividends(query.Dividends)
+	if err != nil {
+		return nil, err
+	}
+
+	assetPriceAxis, err := axisFromQuery("assetPrices", query.AssetPrices,
+		&query.AssetPriceLow, &query.AssetPriceHigh, query.AssetPriceStep, query.AssetPriceSpacing, query.AssetPriceCount)
+	if err != nil {
+		return nil, err
+	}
+	strikePriceAxis, err := axisFromQuery("strikePrices", query.StrikePrices,
+		query.StrikePriceLow, query.StrikePriceHigh, query.StrikePriceStep, query.StrikePriceSpacing, query.StrikePriceCount)
+	if err != nil {
+		return nil, err
+	}
+	var daysToExpiryAxis option.Axis
+	var expirations []Expiration
+	if strings.TrimSpace(query.ExpirationDates) != "" {
+		var clock *dayCountClock
+		if clock, err = newDayCountClock(query); err != nil {
+			return nil, err
+		}
+		if daysToExpiryAxis, expirations, err = expirationAxis(query, clock); err != nil {
+			return nil, err
+		}
+		// Curve tenors are calendar days, which only ACT/365 expiries share
+		if query.RateCurve != "" && query.DayCount != "ACT/365" {
+			return nil, fmt.Errorf("rateCurve needs the ACT/365 day count, not %s", query.DayCount)
+		}
+		dividends, err = clock.dividends(dividends)
+	} else {
+		daysToExpiryAxis, err = axisFromQuery("daysToExpiry", query.DaysToExpiry,
+			&query.DaysToExpiryLow, &query.DaysToExpiryHigh, query.DaysToExpiryStep, query.DaysToExpirySpacing, query.DaysToExpiryCount)
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, expiryInDays, err := daysToExpiryAxis.Bounds("daysToExpiry")
+	if err != nil {
+		return nil, err
+	}
+
+	riskFreeRate, err := chainRiskFreeRate(query)
+	if err != nil {
+		return nil, err
+	}
+	optionChain, err := option.NewOptionChain(optionTypeNum, query.Volatility, riskFreeRate, expiryInDays)
+	if err != nil {
+		return nil, err
+	}
+
+	optionChain.WithGreeks = greekSelection.any()
+	optionChain.WithBlackScholes = query.CompareBlackScholes
+	optionChain.Workers = ChainWorkers
+	if err := optionChain.SetExerciseStyle(exerciseStyle, latticeModel, query.LatticeSteps); err != nil {
+		return nil, err
+	}
+	if err := optionChain.SetGridPoints(query.GridPoints); err != nil {
+		return nil, err
+	}
+	if err := optionChain.SetDividends(query.DividendYield, dividends); err != nil {
+		return nil, err
+	}
+	if err := setChainModel(query, optionChain, model); err != nil {
+		return nil, err
+	}
+	if query.CompareBlackScholes && model != option.Heston && model != option.Merton && model != option.Kou {
+		return nil, fmt.Errorf("compareBlackScholes applies to the Heston, Merton and Kou models")
+	}
+	if err := setFXConventions(query, optionChain); err != nil {
+		return nil, err
+	}
+	if query.BarrierType != "" {
+		barrierType, err := barrierTypeFromName(query.BarrierType)
+		if err != nil {
+			return nil, err
+		}
+		if err := optionChain.SetBarrier(barrierType, query.Barrier, query.Rebate); err != nil {
+			return nil, err
+		}
+	} else if query.Barrier != 0.0 || query.Rebate != 0.0 {
+		return nil, fmt.Errorf("barrier and rebate need a barrierType")
+	}
+	if err := setChainPayoff(query, optionChain, payoff); err != nil {
+		return nil, err
+	}
+	if query.VolSurface != "" {
+		surface, err := lookupSurface(query.VolSurface)
+		if err != nil {
+			return nil, err
+		}
+		optionChain.SetVolatilitySurface(surface)
+	}
+	if query.RateCurve != "" {
+		curve, err := lookupCurve(query.RateCurve)
+		if err != nil {
+			return nil, err
+		}
+		optionChain.SetRateCurve(curve)
+	}
+
+	assetPriceAxis, strikePriceAxis, err = relativeAxes(query, optionChain, assetPriceAxis, strikePriceAxis)
+	if err != nil {
+		return nil, err
+	}
+
+	request := chainRequest{
+		calculator:       optionChain,
+		assetPriceAxis:   assetPriceAxis,
+		strikePriceAxis:  strikePriceAxis,
+		daysToExpiryAxis: daysToExpiryAxis,
+		expirations:      expirations,
+		encoding: positionEncoding{
+			greeks:       greekSelection,
+			american:     exerciseStyle == option.American,
+			blackScholes: query.CompareBlackScholes,
+			replication:  query.DigitalSpreadWidth > 0.0,
+			pricePlaces:  2,
+		},
+	}
+	switch {
+	case model == option.GarmanKohlhagen:
+		request.encoding.pricePlaces = fxPricePlaces
+	case payoff == option.CashOrNothing:
+		request.encoding.pricePlaces = digitalPricePlaces
+	}
+	request.assetPrices, request.strikePrices, request.daysToExpiry, err = request.calculator.ChainSize(
+		request.assetPriceAxis, request.strikePriceAxis, request.daysToExpiryAxis,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
 }
 
 // OptionChain godoc
 // @Summary Calculate option chain
 // @Description Calculates option prices for a range of asset prices, strike prices, and days to expiry.
+// @Description With Accept: application/x-ndjson or text/event-stream, each asset price row is streamed as it is computed.
 // @Tags options
 // @Accept  json
 // @Produce  json
+// @Produce  application/x-ndjson
+// @Produce  text/event-stream
 // @Param assetName query string true "Name of asset"
-// @Param optionType query string true "Type of option (Call, Put)"
-// @Param assetPriceLow query float64 true "Low end of asset price range"
-// @Param assetPriceHigh query float64 true "High end of asset price range"
+// @Param optionType query string true "Type of option (Call, Put), or a digital, gap or power option (CashOrNothingCall, CashOrNothingPut, AssetOrNothingCall, AssetOrNothingPut, GapCall, GapPut, PowerCall, PowerPut)"
+// @Param cashPayout query float64 false "Paid by CashOrNothing options that finish in the money (default = 1.0)"
+// @Param gapTrigger query float64 false "Asset price beyond which Gap options pay the asset less the strike; required for Gap options"
+// @Param powerExponent query float64 false "Power of the asset price in Power options, which pay max(S^n - K, 0) or max(K - S^n, 0); required for Power options"
+// @Param digitalSpreadWidth query float64 false "Also price CashOrNothing and AssetOrNothing options by a vanilla spread this wide around each strike"
+// @Param spotPrice query float64 false "Current asset price, for relative asset or strike price modes"
+// @Param assetPriceMode query string false "Asset price range in prices (Absolute), percentage moves from spot (Percent) or standard deviations from spot (StdDev); default Absolute"
+// @Param assetPriceLow query float64 false "Low end of asset price range, unless assetPrices is given"
+// @Param assetPriceHigh query float64 false "High end of asset price range, unless assetPrices is given"
 // @Param assetPriceStep query float64 false "Step amount for asset price range (default = 1.0)"
-// @Param strikePriceLow query float64 true "Low end of strike price range"
-// @Param strikePriceHigh query float64 true "High end of strike price range"
+// @Param assetPriceSpacing query string false "Spacing of asset price range (Linear, Geometric); default Linear"
+// @Param assetPriceCount query int false "Number of asset prices for Geometric spacing"
+// @Param assetPrices query string false "Comma-separated asset prices, instead of a range"
+// @Param strikePriceMode query string false "Strike price range in prices (Absolute), strike over spot (Moneyness) or unsigned deltas (Delta); default Absolute"
+// @Param strikePriceLow query float64 false "Low end of strike price range, unless strikePrices is given; only Bachelier allows negative prices"
+// @Param strikePriceHigh query float64 false "High end of strike price range, unless strikePrices is given"
 // @Param strikePriceStep query float64 false "Step amount for strike price range (default = 1.0)"
-// @Param daysToExpiryLow query float64 true "Low end of days to expiry range"
-// @Param daysToExpiryHigh query float64 true "High end of days to expiry range"
+// @Param strikePriceSpacing query string false "Spacing of strike price range (Linear, Geometric); default Linear"
+// @Param strikePriceCount query int false "Number of strike prices for Geometric spacing"
+// @Param strikePrices query string false "Comma-separated strike prices, instead of a range"
+// @Param daysToExpiryLow query float64 false "Low end of days to expiry range, unless daysToExpiry is given"
+// @Param daysToExpiryHigh query float64 false "High end of days to expiry range, unless daysToExpiry is given"
 // @Param daysToExpiryStep query float64 false "Step amount for days to expiry range (default = 1.0)"
-// @Param riskFreeRate query float64 true "Risk-free interest rate"
-// @Param volatility query float64 true "Volatility of the asset"
+// @Param daysToExpirySpacing query string false "Spacing of days to expiry range (Linear, Geometric); default Linear"
+// @Param daysToExpiryCount query int false "Number of days to expiry for Geometric spacing"
+// @Param daysToExpiry query string false "Comma-separated days to expiry, instead of a range"
+// @Param expirationDates query string false "Comma-separated expiration dates (YYYY-MM-DD), instead of days to expiry"
+// @Param valuationTime query string false "Time to value expiration dates from (RFC 3339); default now"
+// @Param expiryTime query string false "Time of day options expire on their expiration date (HH:MM, calendar time zone); default the session close"
+// @Param calendar query string false "Trading calendar for expiration dates (NYSE, CBOE or a stored calendar); default NYSE"
+// @Param dayCount query string false "Time to expiration dates as ACT/365, ACT/360 or trading sessions (BUS/252); default ACT/365"
+// @Param referenceDaysToExpiry query float64 false "Days to expiry for StdDev asset prices and Delta strikes; default the longest expiry"
+// @Param riskFreeRate query float64 false "Risk-free interest rate, unless rateCurve or domesticRate is given"
+// @Param rateCurve query string false "Name of a stored rate curve to discount each expiry on; needs the ACT/365 day count with expirationDates"
+// @Param volatility query float64 false "Volatility of the asset, between jumps for Merton and Kou, unless volSurface or heston is given"
+// @Param volSurface query string false "Name of a stored volatility surface to price each option from"
+// @Param model query string false "Pricing model: BlackScholes on spot, Black76 or Bachelier (normal volatility, prices may be negative) on a futures price, GarmanKohlhagen on an exchange rate, Heston stochastic volatility, or Merton or Kou jump-diffusion; default BlackScholes"
+// @Param heston query string false "Name of a stored Heston parameter set, for model=Heston"
+// @Param jumpIntensity query float64 false "Jumps per year, for model=Merton or Kou"
+// @Param jumpMean query float64 false "Mean of the log of each jump, for model=Merton"
+// @Param jumpVolatility query float64 false "Standard deviation of the log of each jump, for model=Merton"
+// @Param jumpUpProbability query float64 false "Probability a jump is up, for model=Kou"
+// @Param jumpUpMean query float64 false "Mean log size of up jumps, below 1, for model=Kou"
+// @Param jumpDownMean query float64 false "Mean log size of down jumps, for model=Kou"
+// @Param compareBlackScholes query bool false "Also price each option by Black-Scholes at the model's volatility, for model=Heston, Merton or Kou"
+// @Param domesticRate query float64 false "Domestic interest rate for GarmanKohlhagen, unless rateCurve is given"
+// @Param foreignRate query float64 false "Foreign interest rate for GarmanKohlhagen"
+// @Param deltaConvention query string false "GarmanKohlhagen delta convention (Spot, Forward, PremiumAdjustedSpot, PremiumAdjustedForward); default Spot"
+// @Param premiumConvention query string false "GarmanKohlhagen premium convention (DomesticPips, ForeignPercent, ForeignPips, DomesticPercent); default DomesticPips"
+// @Param greeks query string false "Comma-separated Greeks to include (delta, gamma, theta, vega, rho) or all"
+// @Param exerciseStyle query string false "Exercise style (European, American); default European"
+// @Param americanModel query string false "Model for American exercise: lattice (CRR, LeisenReimer), finite-difference grid (CrankNicolson) or approximation (BaroneAdesiWhaley, BjerksundStensland); default CRR"
+// @Param latticeSteps query int false "Number of lattice or grid time steps for American exercise and barriers (default = 100)"
+// @Param gridPoints query int false "Number of asset price intervals of the finite-difference grid (default = 200)"
+// @Param barrierType query string false "Barrier type (UpAndOut, DownAndOut, UpAndIn, DownAndIn); continuously monitored, priced on the finite-difference grid"
+// @Param barrier query float64 false "Barrier level; required with barrierType"
+// @Param rebate query float64 false "Paid on knock-out, or at expiry if never knocked in"
+// @Param dividendYield query float64 false "Continuous dividend yield"
+// @Param dividends query string false "Discrete cash dividends as comma-separated daysToExDate:amount pairs, in calendar days"
+// @Param dryRun query bool false "Return the projected size and compute time instead of prices"
 // @Success 200 {object} OptionChainResponse
+// @Success 200 {object} OptionChainEstimate "With dryRun=true"
+// @Failure 413 {object} map[string]string "Too many cells in total, or too long to compute"
+// @Failure 404 {object} map[string]string "Volatility surface, rate curve, calendar or Heston parameter set not found"
+// @Failure 422 {object} map[string]string "An axis is too long, or the query is invalid"
 // @Router /optionChain [get]
-func OptionChain(
-	assetName, optionType string,
-	assetPriceLow, assetPriceHigh, assetPriceStep,
-	strikePriceLow, strikePriceHigh, strikePriceStep,
-	daysToExpiryLow, daysToExpiryHigh, daysToExpiryStep,
-	riskFreeRate, volatility float64,
-) (OptionChainResponse, error) {
-	var optionTypeNum int
-	var err error
-	switch optionType {
-	case "Call":
-		optionTypeNum = option.Call
-
-	case "Put":
-		optionTypeNum = option.Put
-
-	default:
-		return OptionChainResponse{}, fmt.Errorf("unknown option type %s - use Call or Put", optionType)
+func OptionChain(query *OptionChainQuery) (OptionChainResponse, error) {
+	request, err := newChainRequest(query)
+	if err != nil {
+		return OptionChainResponse{}, err
+	}
+	if err := request.checkLimits(); err != nil {
+		return OptionChainResponse{}, err
 	}
 
-	assetPriceSpan := option.ValueSpan{Low: assetPriceLow, High: assetPriceHigh, Step: assetPriceStep}
-	strikePriceSpan := option.ValueSpan{Low: strikePriceLow, High: strikePriceHigh, Step: strikePriceStep}
-	daysToExpirySpan := option.ValueSpan{Low: daysToExpiryLow, High: daysToExpiryHigh, Step: daysToExpiryStep}
-
-	optionChain, err := option.NewOptionChain(optionTypeNum, volatility, riskFreeRate, daysToExpiryHigh)
+	chainValues, err := request.calculator.ComputeOptionChain(request.assetPriceAxis, request.strikePriceAxis, request.daysToExpiryAxis)
 	if err != nil {
 		return OptionChainResponse{}, err
 	}
 
-	chainValues, err := optionChain.ComputeOptionChain(&assetPriceSpan, &strikePriceSpan, &daysToExpirySpan)
+	encoded, err := encodeResponse(request.assetPriceAxis, chainValues, request.encoding)
 	if err != nil {
 		return OptionChainResponse{}, err
 	}
-
 	response := OptionChainResponse{
-		AssetName:   assetName,
-		OptionChain: encodeResponse(assetPriceSpan, chainValues),
+		AssetName:   query.AssetName,
+		Expirations: request.expirations,
+		OptionChain: encoded,
+	}
+	if query.AssetPriceMode != "Absolute" {
+		if response.AssetPrices, err = request.assetPriceAxis.Values("assetPrices"); err != nil {
+			return OptionChainResponse{}, err
+		}
+	}
+	if query.StrikePriceMode != "Absolute" {
+		if response.StrikePrices, err = request.strikePriceAxis.Values("strikePrices"); err != nil {
+			return OptionChainResponse{}, err
+		}
 	}
 
 	return response, nil
 }
+
+// EstimateOptionChain sizes an option chain request without computing it
+func EstimateOptionChain(query *OptionChainQuery) (OptionChainEstimate, error) {
+	request, err := newChainRequest(query)
+	if err != nil {
+		return OptionChainEstimate{}, err
+	}
+
+	estimate := OptionChainEstimate{
+		AssetName:             query.AssetName,
+		AssetPrices:           request.assetPrices,
+		StrikePrices:          request.strikePrices,
+		DaysToExpiry:          request.daysToExpiry,
+		Cells:                 request.cells(),
+		EstimatedBytes:        request.estimatedBytes(),
+		EstimatedMilliseconds: request.calculator.EstimateComputeTime(request.assetPrices, request.strikePrices, request.daysToExpiry).Milliseconds(),
+		WithinLimits:          true,
+	}
+	if err := request.checkLimits(); err != nil {
+		estimate.WithinLimits = false
+		estimate.LimitExceeded = err.Error()
+	}
+	return estimate, nil
+}
+
+// StreamOptionChain computes the same chain as OptionChain, passing each asset
+// price row to emit as soon as it is computed.  Query errors are returned before
+// the first row is emitted.
+func StreamOptionChain(ctx context.Context, query *OptionChainQuery, emit func(row *AssetPrice_Strike_Positions) error) error {
+	request, err := newChainRequest(query)
+	if err != nil {
+		return err
+	}
+	if err := request.checkLimits(); err != nil {
+		return err
+	}
+
+	return request.calculator.StreamOptionChain(ctx, request.assetPriceAxis, request.strikePriceAxis, request.daysToExpiryAxis,
+		func(assetPrice float64, strikePositions [][]option.OptionPosition) error {
+			row := encodeAssetPriceRow(assetPrice, strikePositions, request.encoding)
+			return emit(&row)
+		})
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/jcdevguru/option-assistant/lib/option"
+)
+
+func TestCheckLimits(t *testing.T) {
+	tests := []struct {
+		assetPrices, strikePrices, daysToExpiry int
+		want                                    error
+	}{
+		{21, 21, 30, nil},
+		{21, 21, 0, ErrAxisEmpty},
+		{0, 21, 30, ErrAxisEmpty},
+		{option.MaxAxisLength + 1, 1, 1, option.ErrAxisTooLong},
+		{2000, 2000, 2, ErrGridTooLarge},
+	}
+	calculator, err := option.NewOptionChain(option.Call, 0.2, 0.05, 365)
+	if err != nil {
+		t.Fatal(err)
+	}
+	american, err := option.NewOptionChain(option.Put, 0.2, 0.05, 365)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := american.SetExerciseStyle(option.American, option.LatticeCRR, 5000); err != nil {
+		t.Fatal(err)
+	}
+	request := chainRequest{calculator: american, assetPrices: 100, strikePrices: 100, daysToExpiry: 10}
+	if err := request.checkLimits(); !errors.Is(err, ErrTooSlow) {
+		t.Errorf("5000-step lattices on 100 x 100 x 10: got %v, want %v", err, ErrTooSlow)
+	}
+
+	for _, test := range tests {
+		request := chainRequest{calculator: calculator, assetPrices: test.assetPrices, strikePrices: test.strikePrices, daysToExpiry: test.daysToExpiry}
+		if err := request.checkLimits(); !errors.Is(err, test.want) {
+			t.Errorf("%d x %d x %d: got %v, want %v", test.assetPrices, test.strikePrices, test.daysToExpiry, err, test.want)
+		}
+	}
+}
+
+// chainQuery is a one-price call chain query with the defaults binding fills in
+func chainQuery() OptionChainQuery {
+	return OptionChainQuery{
+		AssetName: "ACME", OptionType: "Call", AssetPrices: "100", AssetPriceMode: "Absolute", AssetPriceSpacing: "Linear",
+		StrikePrices: "100", StrikePriceMode: "Absolute", StrikePriceStep: 1.0, StrikePriceSpacing: "Linear",
+		DaysToExpiry: "30", DaysToExpiryStep: 1.0, DaysToExpirySpacing: "Linear", Calendar: "NYSE", DayCount: "ACT/365",
+		RiskFreeRate: 0.05, Volatility: 0.2, Model: "BlackScholes", DeltaConvention: "Spot", PremiumConvention: "DomesticPips",
+		ExerciseStyle: "European", AmericanModel: "CRR", LatticeSteps: 100, GridPoints: 200,
+	}
+}
+
+// Queries that cannot be priced are told apart from failures while pricing
+func TestInvalidQuery(t *testing.T) {
+	query := chainQuery()
+	if _, err := OptionChain(&query); err != nil {
+		t.Fatal(err)
+	}
+
+	query.ExpirationDates, query.ValuationTime, query.DayCount = "2025-01-17", "2025-01-10T14:30:00-05:00", "ACT/252"
+	if _, err := OptionChain(&query); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("day count ACT/252: got %v, want %v", err, ErrInvalidQuery)
+	}
+
+	query.DayCount, query.VolSurface = "ACT/365", "missing"
+	if _, err := OptionChain(&query); !errors.Is(err, ErrSurfaceNotFound) {
+		t.Errorf("volatility surface missing: got %v, want %v", err, ErrSurfaceNotFound)
+	}
+}
+
+// Bachelier strike ranges may start or end at zero or lie below it; only a
+// missing end is rejected
+func TestBachelierStrikeSpans(t *testing.T) {
+	tests := []struct {
+		url     string
+		strikes int
+	}{
+		{"strikePriceLow=-5&strikePriceHigh=0", 6},
+		{"strikePriceLow=0&strikePriceHigh=10", 11},
+		{"strikePriceLow=-10&strikePriceHigh=-5&strikePriceStep=5", 2},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, "/optionChain?assetName=CL&optionType=Put&model=Bachelier"+
+			"&assetPrices=-2,0,2&daysToExpiry=30&riskFreeRate=0.05&volatility=30&"+test.url, nil)
+		var query OptionChainQuery
+		if err := binding.Query.Bind(request, &query); err != nil {
+			t.Fatalf("%s: %v", test.url, err)
+		}
+		response, err := OptionChain(&query)
+		if err != nil {
+			t.Fatalf("%s: %v", test.url, err)
+		}
+		for _, row := range response.OptionChain {
+			if len(row.StrikePositions) != test.strikes {
+				t.Errorf("%s: got %d strikes, want %d", test.url, len(row.StrikePositions), test.strikes)
+			}
+			for _, strike := range row.StrikePositions {
+				if price := strike.Positions[0].Price; !(price > 0) {
+					t.Errorf("%s: asset %v strike %v: got price %v, want > 0", test.url, row.AssetPrice, strike.StrikePrice, price)
+				}
+			}
+		}
+	}
+
+	query := chainQuery()
+	query.StrikePrices, query.StrikePriceHigh = "", new(float64)
+	if _, err := OptionChain(&query); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("strikePriceHigh alone: got %v, want %v", err, ErrInvalidQuery)
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// ExerciseBoundaryQuery describes an American option and the days to expiry to trace its exercise boundary over
+type ExerciseBoundaryQuery struct {
+	AssetName           string  `form:"assetName" binding:"required,min=2,alphanum"`
+	OptionType          string  `form:"optionType" binding:"required,oneof=Call Put"`
+	StrikePrice         float64 `form:"strikePrice" binding:"required,gt=0"`
+	DaysToExpiryLow     float64 `form:"daysToExpiryLow" binding:"required_without=DaysToExpiry,omitempty,gt=0"`
+	DaysToExpiryHigh    float64 `form:"daysToExpiryHigh" binding:"required_without=DaysToExpiry,omitempty,gtefield=DaysToExpiryLow"`
+	DaysToExpiryStep    float64 `form:"daysToExpiryStep,default=1.0" binding:"required,gt=0.0"`
+	DaysToExpirySpacing string  `form:"daysToExpirySpacing,default=Linear" binding:"oneof=Linear Geometric"`
+	DaysToExpiryCount   int     `form:"daysToExpiryCount" binding:"gte=0"`
+	DaysToExpiry        string  `form:"daysToExpiry"`
+	RiskFreeRate        float64 `form:"riskFreeRate" binding:"required_without=RateCurve,omitempty,gt=0"`
+	RateCurve           string  `form:"rateCurve"`
+	Volatility          float64 `form:"volatility" binding:"required_without=VolSurface,omitempty,gt=0"`
+	VolSurface          string  `form:"volSurface"`
+	Model               string  `form:"model,default=BlackScholes" binding:"oneof=BlackScholes Black76"`
+	AmericanModel       string  `form:"americanModel,default=CRR" binding:"oneof=CRR LeisenReimer CrankNicolson BaroneAdesiWhaley BjerksundStensland"`
+	LatticeSteps        int     `form:"latticeSteps,default=100" binding:"gte=2,lte=5000"`
+	GridPoints          int     `form:"gridPoints,default=200" binding:"gte=10,lte=5000"`
+	DividendYield       float64 `form:"dividendYield" binding:"gte=0"`
+	Dividends           string  `form:"dividends"`
+}
+
+// ExercisePoint is the critical asset price of an American option with some days to go
+// @Description The asset price at or below which a put, or at or above which a call, is best exercised with daysToExpiry to go
+type ExercisePoint struct {
+	DaysToExpiry  float64  `json:"daysToExpiry"`            // Days to expiry
+	CriticalPrice *float64 `json:"criticalPrice,omitempty"` // Critical asset price; omitted when early exercise is never optimal
+	ExDividend    bool     `json:"exDividend,omitempty"`    // The last moment to exercise before a discrete dividend goes ex
+}
+
+// ExerciseBoundaryResponse is the early exercise boundary of an American option
+// @Description The critical asset prices of an American option from today to its expiry
+type ExerciseBoundaryResponse struct {
+	AssetName   string          `json:"assetName"`   // Name of the asset
+	OptionType  string          `json:"optionType"`  // Call or Put
+	StrikePrice float64         `json:"strikePrice"` // Strike price
+	Boundary    []ExercisePoint `json:"boundary"`    // Critical prices from the longest days to expiry to the shortest
+}
+
+// ExerciseBoundary godoc
+// @Summary Trace the early exercise boundary of an American option
+// @Description Finds the critical asset price at which exercising an American option becomes optimal, for the option expiring at the
+// @Description end of the days to expiry axis on each day it has that many days to go.  Each point takes the discrete dividends still
+// @Description to go ex by then, and the moment before each dividend goes ex gets a point of its own, when calls may be worth
+// @Description exercising to collect it.
+// @Tags options
+// @Produce  json
+// @Param assetName query string true "Name of asset"
+// @Param optionType query string true "Type of option (Call, Put)"
+// @Param strikePrice query float64 true "Strike price"
+// @Param daysToExpiryLow query float64 false "Low end of days to expiry range, unless daysToExpiry is given"
+// @Param daysToExpiryHigh query float64 false "High end of days to expiry range and the option's expiry, unless daysToExpiry is given"
+// @Param daysToExpiryStep query float64 false "Step amount for days to expiry range (default = 1.0)"
+// @Param daysToExpirySpacing query string false "Spacing of days to expiry range (Linear, Geometric); default Linear"
+// @Param daysToExpiryCount query int false "Number of days to expiry for Geometric spacing"
+// @Param daysToExpiry query string false "Comma-separated days to expiry, instead of a range"
+// @Param riskFreeRate query float64 false "Risk-free interest rate, unless rateCurve is given"
+// @Param rateCurve query string false "Name of a stored rate curve to discount on"
+// @Param volatility query float64 false "Volatility of the asset, unless volSurface is given"
+// @Param volSurface query string false "Name of a stored volatility surface"
+// @Param model query string false "Pricing model: BlackScholes on spot or Black76 on a futures price; default BlackScholes"
+// @Param americanModel query string false "Model for American exercise: lattice (CRR, LeisenReimer), finite-difference grid (CrankNicolson) or approximation (BaroneAdesiWhaley, BjerksundStensland); default CRR"
+// @Param latticeSteps query int false "Number of lattice or grid time steps (default = 100)"
+// @Param gridPoints query int false "Number of asset price intervals of the finite-difference grid (default = 200)"
+// @Param dividendYield query float64 false "Continuous dividend yield"
+// @Param dividends query string false "Discrete cash dividends as comma-separated daysToExDate:amount pairs"
+// @Success 200 {object} ExerciseBoundaryResponse
+// @Failure 413 {object} map[string]string "The boundary would take too long to trace"
+// @Failure 404 {object} map[string]string "Volatility surface or rate curve not found"
+// @Failure 422 {object} map[string]string "The days to expiry axis is too long or the option cannot be priced"
+// @Router /exerciseBoundary [get]
+func ExerciseBoundary(query *ExerciseBoundaryQuery) (ExerciseBoundaryResponse, error) {
+	optionType, err := optionTypeFromName(query.OptionType)
+	if err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	_, latticeModel, err := exerciseFromNames("American", query.AmericanModel)
+	if err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	model, err := modelFromName(query.Model)
+	if err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	dividends, err := parseDividends(query.Dividends)
+	if err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	daysToExpiryAxis, err := axisFromQuery("daysToExpiry", query.DaysToExpiry,
+		&query.DaysToExpiryLow, &query.DaysToExpiryHigh, query.DaysToExpiryStep, query.DaysToExpirySpacing, query.DaysToExpiryCount)
+	if err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	count, err := daysToExpiryAxis.Count("daysToExpiry")
+	if err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	if count > option.MaxAxisLength {
+		return ExerciseBoundaryResponse{}, fmt.Errorf("%w: %d days to expiry values requested, at most %d allowed - increase the step or narrow the range",
+			option.ErrAxisTooLong, count, option.MaxAxisLength)
+	}
+	_, expiryInDays, err := daysToExpiryAxis.Bounds("daysToExpiry")
+	if err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+
+	chain, err := option.NewOptionChain(optionType, query.Volatility, query.RiskFreeRate, expiryInDays)
+	if err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	chain.Workers = ChainWorkers
+	if err := chain.SetExerciseStyle(option.American, latticeModel, query.LatticeSteps); err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	if err := chain.SetGridPoints(query.GridPoints); err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	if err := chain.SetDividends(query.DividendYield, dividends); err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	if err := chain.SetModel(model); err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	if query.VolSurface != "" {
+		surface, err := lookupSurface(query.VolSurface)
+		if err != nil {
+			return ExerciseBoundaryResponse{}, err
+		}
+		chain.SetVolatilitySurface(surface)
+	}
+	if query.RateCurve != "" {
+		curve, err := lookupCurve(query.RateCurve)
+		if err != nil {
+			return ExerciseBoundaryResponse{}, err
+		}
+		chain.SetRateCurve(curve)
+	}
+
+	estimate, err := chain.EstimateBoundaryTime(daysToExpiryAxis)
+	if err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	if err := checkComputeTime(estimate); err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+
+	points, err := chain.ExerciseBoundary(query.StrikePrice, daysToExpiryAxis)
+	if err != nil {
+		return ExerciseBoundaryResponse{}, err
+	}
+	response := ExerciseBoundaryResponse{
+		AssetName:   query.AssetName,
+		OptionType:  query.OptionType,
+		StrikePrice: query.StrikePrice,
+		Boundary:    make([]ExercisePoint, len(points)),
+	}
+	for i, point := range points {
+		response.Boundary[i] = ExercisePoint{DaysToExpiry: util.Round(point.DaysToExpiry, 6), ExDividend: point.ExDividend}
+		if !point.Never {
+			critical := util.Round(point.CriticalPrice, 2)
+			response.Boundary[i].CriticalPrice = &critical
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"errors"
+	"testing"
+)
+
+// A boundary is held to the compute time limit before any point is searched for
+func TestExerciseBoundaryComputeTime(t *testing.T) {
+	query := ExerciseBoundaryQuery{
+		AssetName: "ACME", OptionType: "Put", StrikePrice: 100,
+		DaysToExpiryLow: 1, DaysToExpiryHigh: 1000, DaysToExpiryStep: 1, DaysToExpirySpacing: "Linear",
+		RiskFreeRate: 0.05, Volatility: 0.25, Model: "BlackScholes", AmericanModel: "CRR", LatticeSteps: 5000, GridPoints: 200,
+	}
+	if _, err := ExerciseBoundary(&query); !errors.Is(err, ErrTooSlow) {
+		t.Errorf("5000-step lattices over 1000 days: got %v, want %v", err, ErrTooSlow)
+	}
+
+	query.DaysToExpiryLow, query.DaysToExpiryHigh, query.DaysToExpiryStep, query.LatticeSteps = 30, 90, 30, 100
+	response, err := ExerciseBoundary(&query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Boundary) != 3 {
+		t.Errorf("got %d points, want 3", len(response.Boundary))
+	}
+	for _, point := range response.Boundary {
+		if point.CriticalPrice == nil || *point.CriticalPrice >= 100 {
+			t.Errorf("%v days: critical price %v, want one below the strike", point.DaysToExpiry, point.CriticalPrice)
+		}
+	}
+}
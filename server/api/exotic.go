@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// Limit on the simulation size of an exotic option, checked before any paths are drawn
+var (
+	MaxExoticPathSteps    = 100_000_000
+	ErrSimulationTooLarge = errors.New("simulation has too many path steps")
+)
+
+// ExoticRequest describes a path-dependent option and how to simulate it
+// @Description An Asian, barrier or lookback option with market inputs and Monte Carlo settings
+type ExoticRequest struct {
+	ExoticType           string  `json:"exoticType" binding:"required,oneof=Asian Barrier Lookback"`                  // Asian, Barrier or Lookback
+	OptionType           string  `json:"optionType" binding:"required,oneof=Call Put"`                                // Call or Put
+	AssetPrice           float64 `json:"assetPrice" binding:"required,gt=0"`                                          // Current asset price
+	StrikePrice          float64 `json:"strikePrice" binding:"gte=0"`                                                 // Strike price; not used by floating strike lookbacks
+	DaysToExpiry         float64 `json:"daysToExpiry" binding:"required,gt=0"`                                        // Days to expiry
+	RiskFreeRate         float64 `json:"riskFreeRate" binding:"gte=0"`                                                // Risk-free interest rate
+	DividendYield        float64 `json:"dividendYield" binding:"gte=0"`                                               // Continuous dividend yield
+	Volatility           float64 `json:"volatility" binding:"required,gt=0"`                                          // Volatility of the asset
+	Average              string  `json:"average" binding:"omitempty,oneof=Arithmetic Geometric"`                      // Asian average; default Arithmetic
+	BarrierType          string  `json:"barrierType" binding:"omitempty,oneof=UpAndOut DownAndOut UpAndIn DownAndIn"` // Barrier type
+	Barrier              float64 `json:"barrier" binding:"gte=0"`                                                     // Barrier level
+	Rebate               float64 `json:"rebate" binding:"gte=0"`                                                      // Paid on knock-out, or at expiry if never knocked in
+	ContinuousMonitoring bool    `json:"continuousMonitoring"`                                                        // Correct a barrier monitored at each step to continuous monitoring
+	LookbackStrike       string  `json:"lookbackStrike" binding:"omitempty,oneof=Floating Fixed"`                     // Lookback strike; default Floating
+	Paths                int     `json:"paths" binding:"omitempty,gte=2,lte=10000000"`                                // Number of simulated paths (default = 100000)
+	Steps                int     `json:"steps" binding:"omitempty,gte=1,lte=10000"`                                   // Time steps per path: fixings and monitoring dates (default = 252)
+	Seed                 *uint64 `json:"seed,omitempty"`                                                              // Random number seed; drawn from the clock when omitted
+	Antithetic           *bool   `json:"antithetic,omitempty"`                                                        // Pair each path with its mirror image (default = true)
+	ControlVariate       *bool   `json:"controlVariate,omitempty"`                                                    // Correct with a payoff of known price (default = true)
+}
+
+// ExoticResponse is a simulated exotic option price
+// @Description Monte Carlo price with its standard error and the settings that reproduce it
+type ExoticResponse struct {
+	Price          float64 `json:"price"`          // Simulated price
+	StandardError  float64 `json:"standardError"`  // Standard error of the price
+	ConfidenceLow  float64 `json:"confidenceLow"`  // Low end of the 95% confidence interval
+	ConfidenceHigh float64 `json:"confidenceHigh"` // High end of the 95% confidence interval
+	Paths          int     `json:"paths"`          // Paths simulated
+	Steps          int     `json:"steps"`          // Time steps per path
+	Seed           uint64  `json:"seed"`           // Seed that reproduces the price
+	Antithetic     bool    `json:"antithetic"`     // Whether antithetic paths were used
+	ControlVariate bool    `json:"controlVariate"` // Whether the control variate was used
+}
+
+// Defaults of the simulation and the normal quantile of a 95% confidence interval
+const (
+	defaultExoticPaths = 100_000
+	defaultExoticSteps = 252
+	confidence95       = 1.959964
+)
+
+func barrierTypeFromName(barrierType string) (int, error) {
+	switch barrierType {
+	case "UpAndOut":
+		return option.UpAndOut, nil
+	case "DownAndOut":
+		return option.DownAndOut, nil
+	case "UpAndIn":
+		return option.UpAndIn, nil
+	case "DownAndIn":
+		return option.DownAndIn, nil
+	}
+	return 0, fmt.Errorf("barrierType is required for barrier options - use UpAndOut, DownAndOut, UpAndIn or DownAndIn")
+}
+
+// exoticFromRequest maps the request's names to the library's option description
+func exoticFromRequest(request *ExoticRequest) (*option.ExoticOption, error) {
+	optionType, err := optionTypeFromName(request.OptionType)
+	if err != nil {
+		return nil, err
+	}
+	exotic := option.ExoticOption{
+		OptionType:           optionType,
+		Strike:               request.StrikePrice,
+		DaysToExpiry:         request.DaysToExpiry,
+		Barrier:              request.Barrier,
+		Rebate:               request.Rebate,
+		ContinuousMonitoring: request.ContinuousMonitoring,
+	}
+	switch request.ExoticType {
+	case "Asian":
+		exotic.Kind = option.AsianOption
+		if request.Average == "Geometric" {
+			exotic.Average = option.GeometricAverage
+		}
+	case "Barrier":
+		exotic.Kind = option.BarrierOption
+		if exotic.BarrierType, err = barrierTypeFromName(request.BarrierType); err != nil {
+			return nil, err
+		}
+	case "Lookback":
+		exotic.Kind = option.LookbackOption
+		if request.LookbackStrike == "Fixed" {
+			exotic.LookbackStrike = option.FixedStrike
+		}
+	default:
+		return nil, fmt.Errorf("unknown exotic type %s - use Asian, Barrier or Lookback", request.ExoticType)
+	}
+	return &exotic, nil
+}
+
+// ExoticPrice godoc
+// @Summary Price a path-dependent option by Monte Carlo
+// @Description Simulates lognormal asset price paths to price Asian (arithmetic or geometric average), barrier (knock-in or knock-out,
+// @Description with an optional rebate) and lookback (floating or fixed strike) options.  Prices are observed at each time step.  Antithetic
+// @Description paths and a control variate (the geometric Asian for arithmetic Asians, the asset for floating lookbacks and a European
+// @Description option otherwise) reduce the standard error, which is reported with every price.  The same seed reproduces the same price.
+// @Tags exotic
+// @Accept  json
+// @Produce  json
+// @Param exotic body ExoticRequest true "Option and simulation settings"
+// @Success 200 {object} ExoticResponse
+// @Router /exotic [post]
+func ExoticPrice(ctx context.Context, request *ExoticRequest) (ExoticResponse, error) {
+	exotic, err := exoticFromRequest(request)
+	if err != nil {
+		return ExoticResponse{}, err
+	}
+	simulation := option.MonteCarlo{
+		Paths:          request.Paths,
+		Steps:          request.Steps,
+		Antithetic:     request.Antithetic == nil || *request.Antithetic,
+		ControlVariate: request.ControlVariate == nil || *request.ControlVariate,
+		Workers:        ChainWorkers,
+	}
+	if simulation.Paths == 0 {
+		simulation.Paths = defaultExoticPaths
+	}
+	if simulation.Steps == 0 {
+		simulation.Steps = defaultExoticSteps
+	}
+	if request.Seed != nil {
+		simulation.Seed = *request.Seed
+	} else {
+		simulation.Seed = uint64(time.Now().UnixNano())
+	}
+	if pathSteps := simulation.Paths * simulation.Steps; pathSteps > MaxExoticPathSteps {
+		return ExoticResponse{}, fmt.Errorf("%w: %d paths x %d steps = %d requested, at most %d allowed - use fewer paths or steps",
+			ErrSimulationTooLarge, simulation.Paths, simulation.Steps, pathSteps, MaxExoticPathSteps)
+	}
+
+	result, err := simulation.Price(ctx, exotic, request.AssetPrice, request.Volatility, request.RiskFreeRate, request.DividendYield)
+	if err != nil {
+		return ExoticResponse{}, err
+	}
+	return ExoticResponse{
+		Price:          util.Round(result.Price, 4),
+		StandardError:  util.Round(result.StandardError, 6),
+		ConfidenceLow:  util.Round(result.Price-confidence95*result.StandardError, 4),
+		ConfidenceHigh: util.Round(result.Price+confidence95*result.StandardError, 4),
+		Paths:          result.Paths,
+		Steps:          simulation.Steps,
+		Seed:           simulation.Seed,
+		Antithetic:     simulation.Antithetic,
+		ControlVariate: simulation.ControlVariate,
+	}, nil
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// Decimal places of currency option premiums, which are quoted in pips
+const fxPricePlaces = 6
+
+// FXSmileWing quotes a currency smile at one delta
+// @Description Risk reversal (call less put volatility) and butterfly (their average over at-the-money) at one delta
+type FXSmileWing struct {
+	Delta        float64 `json:"delta" binding:"gt=0,lt=0.5"` // Unsigned delta of the wing, such as 0.25
+	RiskReversal float64 `json:"riskReversal"`                // Call volatility less put volatility
+	Butterfly    float64 `json:"butterfly"`                   // Smile strangle: wing volatilities' average less at-the-money
+}
+
+// FXSmileExpiry is the market quote of a currency smile at one expiry
+// @Description At-the-money volatility with risk reversals and butterflies by delta
+type FXSmileExpiry struct {
+	DaysToExpiry  float64       `json:"daysToExpiry" binding:"gt=0"`  // Days to expiry
+	AtmVolatility float64       `json:"atmVolatility" binding:"gt=0"` // At-the-money volatility
+	Wings         []FXSmileWing `json:"wings" binding:"dive"`         // Wings, such as 25 and 10 delta
+}
+
+// FXSmileRequest asks for the strikes of currency smile quotes
+// @Description Smile quotes per expiry with the spot rate, interest rates and quoting conventions
+type FXSmileRequest struct {
+	SpotPrice       float64         `json:"spotPrice" binding:"required,gt=0"`                                                                 // Spot rate, domestic per unit of foreign currency
+	DomesticRate    float64         `json:"domesticRate"`                                                                                      // Domestic interest rate
+	ForeignRate     float64         `json:"foreignRate"`                                                                                       // Foreign interest rate
+	DeltaConvention string          `json:"deltaConvention" binding:"omitempty,oneof=Spot Forward PremiumAdjustedSpot PremiumAdjustedForward"` // Delta convention; default Spot
+	AtmConvention   string          `json:"atmConvention" binding:"omitempty,oneof=DeltaNeutral Forward"`                                      // At-the-money strike: delta-neutral straddle (default) or forward
+	Expiries        []FXSmileExpiry `json:"expiries" binding:"required,min=1,dive"`                                                            // Quotes per expiry
+}
+
+// FXSmilePoint is one strike of a currency smile
+// @Description Strike and volatility of one quoted option, with its delta in the requested convention
+type FXSmilePoint struct {
+	OptionType string  `json:"optionType"` // Call or Put; the at-the-money strike is quoted as a call
+	Delta      float64 `json:"delta"`      // Delta, negative for puts
+	Strike     float64 `json:"strike"`     // Strike price
+	Volatility float64 `json:"volatility"` // Implied volatility
+}
+
+// FXSmileStrikes is a currency smile at one expiry as strikes
+// @Description Forward and the smile's strikes in ascending order for one expiry
+type FXSmileStrikes struct {
+	DaysToExpiry float64        `json:"daysToExpiry"` // Days to expiry
+	Forward      float64        `json:"forward"`      // Forward rate
+	Points       []FXSmilePoint `json:"points"`       // Strikes in ascending order
+}
+
+// FXSmileResponse lists the strikes of each quoted expiry
+// @Description Strikes and volatilities per expiry, ready for /volatility/fit
+type FXSmileResponse struct {
+	Expiries []FXSmileStrikes `json:"expiries"`
+}
+
+func deltaConventionFromName(convention string) (int, error) {
+	switch convention {
+	case "", "Spot":
+		return option.SpotDelta, nil
+	case "Forward":
+		return option.ForwardDelta, nil
+	case "PremiumAdjustedSpot":
+		return option.PremiumAdjustedSpotDelta, nil
+	case "PremiumAdjustedForward":
+		return option.PremiumAdjustedForwardDelta, nil
+	}
+	return 0, fmt.Errorf("unknown delta convention %s - use Spot, Forward, PremiumAdjustedSpot or PremiumAdjustedForward", convention)
+}
+
+func premiumConventionFromName(convention string) (int, error) {
+	switch convention {
+	case "", "DomesticPips":
+		return option.DomesticPips, nil
+	case "ForeignPercent":
+		return option.ForeignPercent, nil
+	case "ForeignPips":
+		return option.ForeignPips, nil
+	case "DomesticPercent":
+		return option.DomesticPercent, nil
+	}
+	return 0, fmt.Errorf("unknown premium convention %s - use DomesticPips, ForeignPercent, ForeignPips or DomesticPercent", convention)
+}
+
+// chainRiskFreeRate is the rate a chain query discounts at: domesticRate for
+// currency options, which take the foreign rate in place of dividends, and
+// riskFreeRate otherwise
+func chainRiskFreeRate(query *OptionChainQuery) (float64, error) {
+	if query.Model != "GarmanKohlhagen" {
+		if query.DomesticRate != nil || query.ForeignRate != nil {
+			return 0.0, fmt.Errorf("domesticRate and foreignRate apply to the GarmanKohlhagen model")
+		}
+		return query.RiskFreeRate, nil
+	}
+	if query.RiskFreeRate != 0.0 {
+		return 0.0, fmt.Errorf("currency options are discounted at domesticRate rather than riskFreeRate")
+	}
+	if query.DividendYield != 0.0 || query.Dividends != "" {
+		return 0.0, fmt.Errorf("currency options earn foreignRate rather than dividends")
+	}
+	if query.ForeignRate == nil {
+		return 0.0, fmt.Errorf("foreignRate is required for the GarmanKohlhagen model")
+	}
+	if query.DomesticRate == nil {
+		return 0.0, nil
+	}
+	return *query.DomesticRate, nil
+}
+
+// setFXConventions applies the foreign rate and quoting conventions of a chain query
+func setFXConventions(query *OptionChainQuery, chain *option.OptionChainCalculator) error {
+	deltaConvention, err := deltaConventionFromName(query.DeltaConvention)
+	if err != nil {
+		return err
+	}
+	premiumConvention, err := premiumConventionFromName(query.PremiumConvention)
+	if err != nil {
+		return err
+	}
+	if query.ForeignRate != nil {
+		chain.SetForeignRate(*query.ForeignRate)
+	}
+	return chain.SetFXConventions(deltaConvention, premiumConvention)
+}
+
+// FXSmile godoc
+// @Summary Convert currency smile quotes to strikes
+// @Description Converts at-the-money volatilities, risk reversals and butterflies by delta to strikes and volatilities with Garman-Kohlhagen,
+// @Description in the requested delta convention (spot or forward, premium-adjusted or not).  Butterflies are smile strangles, so the call
+// @Description wing is at atm + butterfly + riskReversal/2 and the put wing at atm + butterfly - riskReversal/2.
+// @Tags fx
+// @Accept  json
+// @Produce  json
+// @Param smile body FXSmileRequest true "Smile quotes"
+// @Success 200 {object} FXSmileResponse
+// @Router /fx/smile [post]
+func FXSmile(request *FXSmileRequest) (FXSmileResponse, error) {
+	deltaConvention, err := deltaConventionFromName(request.DeltaConvention)
+	if err != nil {
+		return FXSmileResponse{}, err
+	}
+	atmConvention := option.AtmDeltaNeutral
+	if request.AtmConvention == "Forward" {
+		atmConvention = option.AtmForward
+	}
+
+	var response FXSmileResponse
+	for _, expiry := range request.Expiries {
+		quote := option.FXSmileQuote{DaysToExpiry: expiry.DaysToExpiry, AtmVolatility: expiry.AtmVolatility}
+		for _, wing := range expiry.Wings {
+			quote.Wings = append(quote.Wings, option.FXSmileWing(wing))
+		}
+		points, err := option.FXSmileStrikes(quote, request.SpotPrice, request.DomesticRate, request.ForeignRate, deltaConvention, atmConvention)
+		if err != nil {
+			return FXSmileResponse{}, err
+		}
+		strikes := FXSmileStrikes{
+			DaysToExpiry: expiry.DaysToExpiry,
+			Forward:      util.Round(option.FXForward(request.SpotPrice, expiry.DaysToExpiry, request.DomesticRate, request.ForeignRate), fxPricePlaces),
+		}
+		for _, point := range points {
+			optionType := "Call"
+			if point.OptionType == option.Put {
+				optionType = "Put"
+			}
+			strikes.Points = append(strikes.Points, FXSmilePoint{
+				OptionType: optionType,
+				Delta:      util.Round(point.Delta, 4),
+				Strike:     util.Round(point.Strike, fxPricePlaces),
+				Volatility: util.Round(point.Volatility, 6),
+			})
+		}
+		response.Expiries = append(response.Expiries, strikes)
+	}
+	return response, nil
+}
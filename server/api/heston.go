@@ -0,0 +1,232 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// HestonParameters are the parameters of the Heston stochastic volatility model
+// @Description Mean reversion speed, long-run variance, volatility of variance, correlation and initial variance
+type HestonParameters struct {
+	Kappa float64 `json:"kappa" binding:"gt=0"`     // Speed the variance reverts at
+	Theta float64 `json:"theta" binding:"gt=0"`     // Long-run variance
+	Sigma float64 `json:"sigma" binding:"gt=0"`     // Volatility of the variance
+	Rho   float64 `json:"rho" binding:"gt=-1,lt=1"` // Correlation of the asset price and its variance
+	V0    float64 `json:"v0" binding:"gt=0"`        // Initial variance
+}
+
+// HestonParameterNames lists the stored Heston parameter sets
+// @Description Names of the stored Heston parameter sets
+type HestonParameterNames struct {
+	Names []string `json:"names"` // Parameter set names, sorted
+}
+
+// HestonCalibrationRequest asks for Heston parameters fitted to implied volatility quotes
+// @Description Quotes per expiry with the market data for forwards and an optional starting point
+type HestonCalibrationRequest struct {
+	SpotPrice     float64               `json:"spotPrice" binding:"required,gt=0"`      // Current asset price
+	RiskFreeRate  float64               `json:"riskFreeRate" binding:"gte=0"`           // Risk-free interest rate
+	DividendYield float64               `json:"dividendYield" binding:"gte=0"`          // Continuous dividend yield
+	Expiries      []VolatilityFitExpiry `json:"expiries" binding:"required,min=1,dive"` // Quotes per expiry, at most 1,000 strikes in all by default
+	Start         *HestonParameters     `json:"start,omitempty"`                        // Parameters to start the search from; several are tried when omitted
+	SaveAs        string                `json:"saveAs,omitempty"`                       // Store the parameters under this name
+}
+
+// HestonCalibrationResponse holds the fitted parameters and how well they fit
+// @Description Fitted parameters, whether they meet the Feller condition and the fit quality per expiry
+type HestonCalibrationResponse struct {
+	Parameters HestonParameters       `json:"parameters"`        // Fitted parameters
+	Feller     bool                   `json:"feller"`            // Whether 2 kappa theta >= sigma^2, so the variance stays positive
+	RMSE       float64                `json:"rmse"`              // Root mean square volatility error over all quotes
+	Fits       []VolatilityFitQuality `json:"fits"`              // Fit quality per expiry
+	SavedAs    string                 `json:"savedAs,omitempty"` // Name the parameters were stored under
+}
+
+var ErrHestonNotFound = errors.New("Heston parameter set not found")
+
+// Named Heston parameter sets, kept in memory for the life of the server
+var (
+	hestonMutex      sync.RWMutex
+	hestonParameters = make(map[string]option.HestonParameters)
+)
+
+// registerHeston validates parameters and stores them under a name, replacing any set of that name
+func registerHeston(name string, parameters option.HestonParameters) error {
+	if err := validStoredName("Heston parameter set", name); err != nil {
+		return err
+	}
+	if err := parameters.Validate(); err != nil {
+		return err
+	}
+	hestonMutex.Lock()
+	defer hestonMutex.Unlock()
+	hestonParameters[name] = parameters
+	return nil
+}
+
+// lookupHeston finds the parameters stored under a name
+func lookupHeston(name string) (option.HestonParameters, error) {
+	hestonMutex.RLock()
+	defer hestonMutex.RUnlock()
+	parameters, ok := hestonParameters[name]
+	if !ok {
+		return option.HestonParameters{}, fmt.Errorf("%w: %s", ErrHestonNotFound, name)
+	}
+	return parameters, nil
+}
+
+// PutHestonParameters godoc
+// @Summary Store Heston parameters
+// @Description Stores a named Heston parameter set, replacing any set of the same name.  Option chains use it with model=Heston&heston=name.
+// @Description Parameter sets are kept in memory and are lost when the server restarts.
+// @Tags heston
+// @Accept  json
+// @Produce  json
+// @Param name path string true "Parameter set name (letters, digits, - and _)"
+// @Param parameters body HestonParameters true "Heston parameters"
+// @Success 200 {object} HestonParameters
+// @Router /heston/parameters/{name} [put]
+func PutHestonParameters(name string, parameters *HestonParameters) (HestonParameters, error) {
+	if err := registerHeston(name, option.HestonParameters(*parameters)); err != nil {
+		return HestonParameters{}, err
+	}
+	return *parameters, nil
+}
+
+// GetHestonParameters godoc
+// @Summary Get Heston parameters
+// @Description Returns a stored Heston parameter set.
+// @Tags heston
+// @Produce  json
+// @Param name path string true "Parameter set name"
+// @Success 200 {object} HestonParameters
+// @Failure 404 {object} map[string]string "No parameter set of that name"
+// @Router /heston/parameters/{name} [get]
+func GetHestonParameters(name string) (HestonParameters, error) {
+	parameters, err := lookupHeston(name)
+	if err != nil {
+		return HestonParameters{}, err
+	}
+	return HestonParameters(parameters), nil
+}
+
+// HestonParameterList godoc
+// @Summary List Heston parameter sets
+// @Description Lists the names of the stored Heston parameter sets.
+// @Tags heston
+// @Produce  json
+// @Success 200 {object} HestonParameterNames
+// @Router /heston/parameters [get]
+func HestonParameterList() HestonParameterNames {
+	hestonMutex.RLock()
+	defer hestonMutex.RUnlock()
+	names := HestonParameterNames{Names: []string{}}
+	for name := range hestonParameters {
+		names.Names = append(names.Names, name)
+	}
+	sort.Strings(names.Names)
+	return names
+}
+
+// DeleteHestonParameters godoc
+// @Summary Remove Heston parameters
+// @Description Deletes a stored Heston parameter set.
+// @Tags heston
+// @Param name path string true "Parameter set name"
+// @Success 204
+// @Failure 404 {object} map[string]string "No parameter set of that name"
+// @Router /heston/parameters/{name} [delete]
+func DeleteHestonParameters(name string) error {
+	hestonMutex.Lock()
+	defer hestonMutex.Unlock()
+	if _, ok := hestonParameters[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrHestonNotFound, name)
+	}
+	delete(hestonParameters, name)
+	return nil
+}
+
+// CalibrateHeston godoc
+// @Summary Calibrate the Heston model
+// @Description Fits the Heston parameters to implied volatility quotes across strikes and expiries by Nelder-Mead, pricing each quote's
+// @Description out-of-the-money option by the Fourier-cosine method, and reports the fitted volatility at each quote.  With saveAs the
+// @Description parameters are stored for option chains.
+// @Tags heston
+// @Accept  json
+// @Produce  json
+// @Param calibration body HestonCalibrationRequest true "Quotes"
+// @Success 200 {object} HestonCalibrationResponse
+// @Failure 413 {object} map[string]string "Too many quotes"
+// @Router /heston/calibrate [post]
+func CalibrateHeston(request *HestonCalibrationRequest) (HestonCalibrationResponse, error) {
+	if err := checkQuoteCount(fitQuoteCount(request.Expiries), MaxFitQuotes); err != nil {
+		return HestonCalibrationResponse{}, err
+	}
+	var quotes []option.HestonQuote
+	for _, expiry := range request.Expiries {
+		if len(expiry.Strikes) != len(expiry.Volatilities) {
+			return HestonCalibrationResponse{}, fmt.Errorf("expiry %v days: %d strikes but %d volatilities",
+				expiry.DaysToExpiry, len(expiry.Strikes), len(expiry.Volatilities))
+		}
+		for i, strike := range expiry.Strikes {
+			quotes = append(quotes, option.HestonQuote{Strike: strike, DaysToExpiry: expiry.DaysToExpiry, Volatility: expiry.Volatilities[i]})
+		}
+	}
+	var start *option.HestonParameters
+	if request.Start != nil {
+		parameters := option.HestonParameters(*request.Start)
+		start = &parameters
+	}
+	fitted, err := option.CalibrateHeston(quotes, request.SpotPrice, request.RiskFreeRate, request.DividendYield, start)
+	if err != nil {
+		return HestonCalibrationResponse{}, err
+	}
+
+	response := HestonCalibrationResponse{
+		Parameters: HestonParameters{
+			Kappa: util.Round(fitted.Kappa, 6),
+			Theta: util.Round(fitted.Theta, 6),
+			Sigma: util.Round(fitted.Sigma, 6),
+			Rho:   util.Round(fitted.Rho, 6),
+			V0:    util.Round(fitted.V0, 6),
+		},
+		Feller: fitted.Feller(),
+	}
+	totalSquares := 0.0
+	for _, expiry := range request.Expiries {
+		quality := VolatilityFitQuality{DaysToExpiry: expiry.DaysToExpiry}
+		squares := 0.0
+		for i, strike := range expiry.Strikes {
+			volatility, err := fitted.ImpliedVolatility(request.SpotPrice, strike, expiry.DaysToExpiry, request.RiskFreeRate, request.DividendYield)
+			if err != nil {
+				return HestonCalibrationResponse{}, fmt.Errorf("expiry %v days, strike %v: %w", expiry.DaysToExpiry, strike, err)
+			}
+			residual := volatility - expiry.Volatilities[i]
+			squares += residual * residual
+			quality.Residuals = append(quality.Residuals, VolatilityFitResidual{
+				Strike:     strike,
+				Volatility: expiry.Volatilities[i],
+				Fitted:     util.Round(volatility, 6),
+				Residual:   util.Round(residual, 6),
+			})
+		}
+		quality.RMSE = util.Round(math.Sqrt(squares/float64(len(expiry.Strikes))), 6)
+		totalSquares += squares
+		response.Fits = append(response.Fits, quality)
+	}
+	response.RMSE = util.Round(math.Sqrt(totalSquares/float64(len(quotes))), 6)
+
+	if request.SaveAs != "" {
+		if err := registerHeston(request.SaveAs, *fitted); err != nil {
+			return HestonCalibrationResponse{}, err
+		}
+		response.SavedAs = request.SaveAs
+	}
+	return response, nil
+}
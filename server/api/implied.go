@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// Limits on the quotes of a request, checked before any is solved or fitted
+var (
+	MaxBatchQuotes   = 10_000
+	MaxFitQuotes     = 1_000
+	ErrTooManyQuotes = errors.New("request has too many quotes")
+)
+
+// checkQuoteCount rejects requests with over limit quotes
+func checkQuoteCount(quotes, limit int) error {
+	if quotes > limit {
+		return fmt.Errorf("%w: %d requested, at most %d allowed - split the request", ErrTooManyQuotes, quotes, limit)
+	}
+	return nil
+}
+
+// ImpliedVolatilityQuote is a single market quote to solve for implied volatility
+// @Description A market option premium with the inputs needed to solve for its implied volatility
+type ImpliedVolatilityQuote struct {
+	OptionType   string  `json:"optionType" form:"optionType" binding:"required,oneof=Call Put"` // Type of option (Call, Put)
+	Price        float64 `json:"price" form:"price" binding:"required,gt=0"`                     // Market premium
+	AssetPrice   float64 `json:"assetPrice" form:"assetPrice" binding:"required,gt=0"`           // Asset price
+	StrikePrice  float64 `json:"strikePrice" form:"strikePrice" binding:"required,gt=0"`         // Strike price
+	DaysToExpiry float64 `json:"daysToExpiry" form:"daysToExpiry" binding:"required,gt=0"`       // Days to expiry
+	RiskFreeRate float64 `json:"riskFreeRate" form:"riskFreeRate" binding:"gte=0"`               // Risk-free interest rate
+}
+
+// ImpliedVolatilityBatch is a list of quotes to solve in one request
+// @Description A batch of market quotes
+type ImpliedVolatilityBatch struct {
+	Quotes []ImpliedVolatilityQuote `json:"quotes" binding:"required,min=1,dive"` // Quotes to solve, at most 10,000 by default
+}
+
+// ImpliedVolatilityResult is the solved volatility for a quote
+// @Description The implied volatility for a quote, or the reason none exists
+type ImpliedVolatilityResult struct {
+	ImpliedVolatilityQuote
+	ImpliedVolatility *float64 `json:"impliedVolatility,omitempty"` // Volatility reproducing the price
+	Error             string   `json:"error,omitempty"`             // Why no volatility was found
+}
+
+// ImpliedVolatilityBatchResponse holds results in the order of the submitted quotes
+// @Description Implied volatility results for a batch of quotes
+type ImpliedVolatilityBatchResponse struct {
+	Results []ImpliedVolatilityResult `json:"results"` // One result per quote
+}
+
+func optionTypeFromName(optionType string) (int, error) {
+	switch optionType {
+	case "Call":
+		return option.Call, nil
+	case "Put":
+		return option.Put, nil
+	}
+	return 0, fmt.Errorf("unknown option type %s - use Call or Put", optionType)
+}
+
+// ImpliedVolatility godoc
+// @Summary Solve implied volatility for a quote
+// @Description Finds the Black-Scholes volatility that reproduces a market option premium.
+// @Tags volatility
+// @Produce  json
+// @Param optionType query string true "Type of option (Call, Put)"
+// @Param price query float64 true "Market premium"
+// @Param assetPrice query float64 true "Asset price"
+// @Param strikePrice query float64 true "Strike price"
+// @Param daysToExpiry query float64 true "Days to expiry"
+// @Param riskFreeRate query float64 false "Risk-free interest rate"
+// @Success 200 {object} ImpliedVolatilityResult
+// @Router /impliedVolatility [get]
+func ImpliedVolatility(quote ImpliedVolatilityQuote) (ImpliedVolatilityResult, error) {
+	optionType, err := optionTypeFromName(quote.OptionType)
+	if err != nil {
+		return ImpliedVolatilityResult{}, err
+	}
+	volatility, err := option.ImpliedVolatility(
+		optionType, quote.Price, quote.AssetPrice, quote.StrikePrice, quote.DaysToExpiry, quote.RiskFreeRate,
+	)
+	if err != nil {
+		return ImpliedVolatilityResult{}, err
+	}
+	volatility = util.Round(volatility, 6)
+	return ImpliedVolatilityResult{ImpliedVolatilityQuote: quote, ImpliedVolatility: &volatility}, nil
+}
+
+// ImpliedVolatilityBatchSolve godoc
+// @Summary Solve implied volatility for a batch of quotes
+// @Description Finds the Black-Scholes implied volatility of each quote.  Quotes without a solution carry an error instead of failing the batch.
+// @Tags volatility
+// @Accept  json
+// @Produce  json
+// @Param quotes body ImpliedVolatilityBatch true "Quotes to solve"
+// @Success 200 {object} ImpliedVolatilityBatchResponse
+// @Failure 413 {object} map[string]string "Too many quotes"
+// @Router /impliedVolatility [post]
+func ImpliedVolatilityBatchSolve(batch ImpliedVolatilityBatch) (ImpliedVolatilityBatchResponse, error) {
+	if err := checkQuoteCount(len(batch.Quotes), MaxBatchQuotes); err != nil {
+		return ImpliedVolatilityBatchResponse{}, err
+	}
+	response := ImpliedVolatilityBatchResponse{Results: make([]ImpliedVolatilityResult, 0, len(batch.Quotes))}
+	for _, quote := range batch.Quotes {
+		result, err := ImpliedVolatility(quote)
+		if err != nil {
+			result = ImpliedVolatilityResult{ImpliedVolatilityQuote: quote, Error: err.Error()}
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"testing"
+)
+
+// Batches and fits are held to their quote limits before anything is solved
+func TestQuoteLimits(t *testing.T) {
+	quote := ImpliedVolatilityQuote{OptionType: "Call", Price: 10.45, AssetPrice: 100, StrikePrice: 100, DaysToExpiry: 365, RiskFreeRate: 0.05}
+	batch := ImpliedVolatilityBatch{Quotes: make([]ImpliedVolatilityQuote, MaxBatchQuotes+1)}
+	for i := range batch.Quotes {
+		batch.Quotes[i] = quote
+	}
+	if _, err := ImpliedVolatilityBatchSolve(batch); !errors.Is(err, ErrTooManyQuotes) {
+		t.Errorf("%d quotes: got %v, want %v", len(batch.Quotes), err, ErrTooManyQuotes)
+	}
+	batch.Quotes = batch.Quotes[:3]
+	response, err := ImpliedVolatilityBatchSolve(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Results) != 3 {
+		t.Errorf("got %d results, want 3", len(response.Results))
+	}
+
+	strikes := make([]float64, MaxFitQuotes/2+1)
+	expiry := VolatilityFitExpiry{DaysToExpiry: 30, Strikes: strikes, Volatilities: strikes}
+	request := HestonCalibrationRequest{SpotPrice: 100, Expiries: []VolatilityFitExpiry{expiry, expiry}}
+	if _, err := CalibrateHeston(&request); !errors.Is(err, ErrTooManyQuotes) {
+		t.Errorf("Heston calibration to %d quotes: got %v, want %v", 2*len(strikes), err, ErrTooManyQuotes)
+	}
+	fit := VolatilityFitRequest{Model: "SVI", SpotPrice: 100, Expiries: request.Expiries}
+	if _, err := FitVolatility(&fit); !errors.Is(err, ErrTooManyQuotes) {
+		t.Errorf("SVI fit to %d quotes: got %v, want %v", 2*len(strikes), err, ErrTooManyQuotes)
+	}
+}
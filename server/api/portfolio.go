@@ -0,0 +1,245 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jcdevguru/option-assistant/lib/portfolio"
+	"github.com/jcdevguru/option-assistant/lib/strategy"
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+const expiryDateLayout = "2006-01-02"
+
+// PortfolioHolding is a stored position
+// @Description A call, put or stock position on an underlying.  Options expire at the end of their expiry date (UTC).
+type PortfolioHolding struct {
+	ID          string   `json:"id"`                                                 // Assigned by the store
+	AssetName   string   `json:"assetName" binding:"required,min=2,alphanum"`        // Name of the underlying asset
+	Instrument  string   `json:"instrument" binding:"required,oneof=Call Put Stock"` // Call, Put or Stock
+	StrikePrice float64  `json:"strikePrice" binding:"gte=0"`                        // Strike price (options only)
+	ExpiryDate  string   `json:"expiryDate,omitempty"`                               // Expiry date as YYYY-MM-DD (options only)
+	Quantity    float64  `json:"quantity" binding:"required,gt=0"`                   // Number of contracts or shares
+	Side        string   `json:"side" binding:"required,oneof=Long Short"`           // Long or Short
+	EntryPrice  *float64 `json:"entryPrice,omitempty" binding:"omitempty,gte=0"`     // Price paid or received per unit (default for trades = model price)
+	Multiplier  float64  `json:"multiplier" binding:"gte=0"`                         // Contract multiplier (default = 1)
+}
+
+// PortfolioHoldings lists the stored holdings
+// @Description All holdings in the portfolio
+type PortfolioHoldings struct {
+	Holdings []PortfolioHolding `json:"holdings"` // Stored holdings
+}
+
+// PortfolioMarket holds pricing inputs for one underlying
+// @Description Spot price, volatility and risk-free rate of an underlying
+type PortfolioMarket struct {
+	SpotPrice    float64 `json:"spotPrice" binding:"required,gt=0"`  // Current asset price
+	Volatility   float64 `json:"volatility" binding:"required,gt=0"` // Volatility of the asset
+	RiskFreeRate float64 `json:"riskFreeRate" binding:"gte=0"`       // Risk-free interest rate
+}
+
+// WhatIfRequest proposes a trade against the stored portfolio
+// @Description Market data for every underlying held or traded, and the proposed trade
+type WhatIfRequest struct {
+	Markets       map[string]PortfolioMarket `json:"markets" binding:"required,dive"`     // Market data by asset name
+	Trade         []PortfolioHolding         `json:"trade" binding:"required,min=1,dive"` // Proposed holdings to add
+	ValuationTime string                     `json:"valuationTime,omitempty"`             // RFC 3339 valuation time (default = now)
+}
+
+// PortfolioValuation is the value, P&L and Greeks of a set of holdings
+// @Description Value, profit/loss against entry prices and net Greeks
+type PortfolioValuation struct {
+	Value      float64        `json:"value"`      // Market value
+	ProfitLoss float64        `json:"profitLoss"` // Value less entry cost
+	Greeks     StrategyGreeks `json:"greeks"`     // Net Greeks
+}
+
+// PortfolioReport values the portfolio per underlying and in total
+// @Description Valuation per underlying and in total
+type PortfolioReport struct {
+	Underlyings map[string]PortfolioValuation `json:"underlyings"` // Valuation by asset name
+	Total       PortfolioValuation            `json:"total"`       // Valuation of all holdings
+}
+
+// WhatIfResponse compares the portfolio before and after the trade
+// @Description Portfolio valuation before and after the trade, and the difference
+type WhatIfResponse struct {
+	Before PortfolioReport    `json:"before"` // Stored holdings only
+	After  PortfolioReport    `json:"after"`  // Stored holdings with the trade
+	Impact PortfolioValuation `json:"impact"` // After less before
+}
+
+func decodeHolding(request *PortfolioHolding) (portfolio.Holding, error) {
+	instrument, err := instrumentFromName(request.Instrument)
+	if err != nil {
+		return portfolio.Holding{}, err
+	}
+	side, err := sideFromName(request.Side)
+	if err != nil {
+		return portfolio.Holding{}, err
+	}
+	holding := portfolio.Holding{
+		ID:         request.ID,
+		AssetName:  request.AssetName,
+		Instrument: instrument,
+		Strike:     request.StrikePrice,
+		Quantity:   request.Quantity,
+		Side:       side,
+		Multiplier: request.Multiplier,
+	}
+	if request.EntryPrice != nil {
+		holding.EntryPrice = *request.EntryPrice
+	}
+	if request.ExpiryDate != "" {
+		expiryDate, err := time.Parse(expiryDateLayout, request.ExpiryDate)
+		if err != nil {
+			return portfolio.Holding{}, err
+		}
+		holding.Expiry = expiryDate.Add(24 * time.Hour)
+	}
+	return holding, nil
+}
+
+func encodeHolding(holding *portfolio.Holding) PortfolioHolding {
+	entryPrice := holding.EntryPrice
+	encoded := PortfolioHolding{
+		ID:          holding.ID,
+		AssetName:   holding.AssetName,
+		Instrument:  instrumentName(holding.Instrument),
+		StrikePrice: holding.Strike,
+		Quantity:    holding.Quantity,
+		Side:        sideName(holding.Side),
+		EntryPrice:  &entryPrice,
+		Multiplier:  holding.Multiplier,
+	}
+	if !holding.Expiry.IsZero() {
+		encoded.ExpiryDate = holding.Expiry.Add(-24 * time.Hour).Format(expiryDateLayout)
+	}
+	return encoded
+}
+
+func encodeValuation(valuation *strategy.Valuation) PortfolioValuation {
+	return PortfolioValuation{
+		Value:      util.Round(valuation.Value, 2),
+		ProfitLoss: util.Round(valuation.ProfitLoss, 2),
+		Greeks: StrategyGreeks{
+			Delta: util.Round(valuation.Greeks.Delta, 4),
+			Gamma: util.Round(valuation.Greeks.Gamma, 4),
+			Theta: util.Round(valuation.Greeks.Theta, 4),
+			Vega:  util.Round(valuation.Greeks.Vega, 4),
+			Rho:   util.Round(valuation.Greeks.Rho, 4),
+		},
+	}
+}
+
+func encodeReport(report *portfolio.Report) PortfolioReport {
+	encoded := PortfolioReport{Underlyings: make(map[string]PortfolioValuation), Total: encodeValuation(&report.Total)}
+	for assetName, valuation := range report.Underlyings {
+		encoded.Underlyings[assetName] = encodeValuation(&valuation)
+	}
+	return encoded
+}
+
+// Holdings godoc
+// @Summary List portfolio holdings
+// @Description Lists the holdings stored in the portfolio.
+// @Tags portfolio
+// @Produce  json
+// @Success 200 {object} PortfolioHoldings
+// @Router /portfolio [get]
+func Holdings(store *portfolio.Store) PortfolioHoldings {
+	response := PortfolioHoldings{Holdings: []PortfolioHolding{}}
+	for _, holding := range store.Holdings() {
+		response.Holdings = append(response.Holdings, encodeHolding(&holding))
+	}
+	return response
+}
+
+// AddHolding godoc
+// @Summary Add a portfolio holding
+// @Description Stores a call, put or stock holding in the portfolio.
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param holding body PortfolioHolding true "Holding to store; id is ignored"
+// @Success 201 {object} PortfolioHolding
+// @Router /portfolio/holdings [post]
+func AddHolding(store *portfolio.Store, request *PortfolioHolding) (PortfolioHolding, error) {
+	if request.EntryPrice == nil {
+		return PortfolioHolding{}, fmt.Errorf("entryPrice is required for stored holdings")
+	}
+	holding, err := decodeHolding(request)
+	if err != nil {
+		return PortfolioHolding{}, err
+	}
+	holding, err = store.Add(holding)
+	if err != nil {
+		return PortfolioHolding{}, err
+	}
+	return encodeHolding(&holding), nil
+}
+
+// RemoveHolding godoc
+// @Summary Remove a portfolio holding
+// @Description Deletes a holding from the portfolio.
+// @Tags portfolio
+// @Param id path string true "Holding id"
+// @Success 204
+// @Router /portfolio/holdings/{id} [delete]
+func RemoveHolding(store *portfolio.Store, id string) error {
+	return store.Remove(id)
+}
+
+// WhatIf godoc
+// @Summary Evaluate the impact of a trade on the portfolio
+// @Description Values the stored portfolio before and after adding a proposed trade, per underlying and in total.  Trade holdings
+// @Description without an entryPrice are entered at their current Black-Scholes value, so the trade alone adds no profit or loss.
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param whatIf body WhatIfRequest true "Market data and proposed trade"
+// @Success 200 {object} WhatIfResponse
+// @Router /portfolio/whatIf [post]
+func WhatIf(store *portfolio.Store, request *WhatIfRequest) (WhatIfResponse, error) {
+	valuationTime := time.Now()
+	if request.ValuationTime != "" {
+		var err error
+		if valuationTime, err = time.Parse(time.RFC3339, request.ValuationTime); err != nil {
+			return WhatIfResponse{}, err
+		}
+	}
+
+	markets := make(map[string]portfolio.Market)
+	for assetName, market := range request.Markets {
+		markets[assetName] = portfolio.Market{
+			SpotPrice:    market.SpotPrice,
+			Volatility:   market.Volatility,
+			RiskFreeRate: market.RiskFreeRate,
+		}
+	}
+
+	var trade []portfolio.Holding
+	for i := range request.Trade {
+		holding, err := decodeHolding(&request.Trade[i])
+		if err != nil {
+			return WhatIfResponse{}, err
+		}
+		if request.Trade[i].EntryPrice == nil {
+			if holding.EntryPrice, err = portfolio.TheoreticalPrice(&holding, markets, valuationTime); err != nil {
+				return WhatIfResponse{}, fmt.Errorf("trade leg %d: %w", i, err)
+			}
+		}
+		trade = append(trade, holding)
+	}
+
+	whatIf, err := portfolio.Impact(store.Holdings(), trade, markets, valuationTime)
+	if err != nil {
+		return WhatIfResponse{}, err
+	}
+	return WhatIfResponse{
+		Before: encodeReport(whatIf.Before),
+		After:  encodeReport(whatIf.After),
+		Impact: encodeValuation(&whatIf.Impact),
+	}, nil
+}
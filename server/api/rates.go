@@ -0,0 +1,232 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/jcdevguru/option-assistant/lib/rates"
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// RateCurvePoint is a zero rate to a number of days ahead
+// @Description A continuously compounded zero rate for one tenor
+type RateCurvePoint struct {
+	DaysToMaturity float64 `json:"daysToMaturity" binding:"required,gt=0"` // Tenor in days
+	Rate           float64 `json:"rate"`                                   // Continuously compounded zero rate
+}
+
+// RateQuote is the market rate of one instrument a curve is bootstrapped from
+// @Description Deposits are simple ACT/360 rates; swaps and Treasuries are par rates with coupons paid frequency times a year
+type RateQuote struct {
+	Instrument     string  `json:"instrument" binding:"required,oneof=Deposit Swap Treasury"` // Deposit, Swap or Treasury
+	DaysToMaturity float64 `json:"daysToMaturity" binding:"required,gt=0"`                    // Days to maturity
+	Rate           float64 `json:"rate"`                                                      // Quoted rate
+	Frequency      int     `json:"frequency,omitempty" binding:"gte=0,lte=12"`                // Payments per year; default 1 for swaps, 2 for Treasuries
+}
+
+// RateCurve defines a risk-free rate term structure
+// @Description Zero rates by tenor, or market quotes to bootstrap them from, and how to interpolate between tenors
+type RateCurve struct {
+	ZeroRates     []RateCurvePoint `json:"zeroRates,omitempty" binding:"required_without=Quotes,omitempty,dive"`                  // Zero rates by tenor
+	Quotes        []RateQuote      `json:"quotes,omitempty" binding:"omitempty,dive"`                                             // Quotes to bootstrap, instead of zero rates
+	Interpolation string           `json:"interpolation,omitempty" binding:"omitempty,oneof=LinearZero LogLinearDiscount Spline"` // LinearZero (default), LogLinearDiscount or Spline
+}
+
+// RateCurvePillar is the curve at one of its tenors
+// @Description Zero rate and discount factor at a tenor of the curve
+type RateCurvePillar struct {
+	DaysToMaturity float64 `json:"daysToMaturity"` // Tenor in days
+	ZeroRate       float64 `json:"zeroRate"`       // Continuously compounded zero rate
+	DiscountFactor float64 `json:"discountFactor"` // Value today of one paid at the tenor
+}
+
+// StoredRateCurve is a rate curve as defined with its bootstrapped pillars
+// @Description A stored rate curve's definition and the zero rates and discount factors at its tenors
+type StoredRateCurve struct {
+	RateCurve
+	Pillars []RateCurvePillar `json:"pillars"` // Curve at each tenor
+}
+
+// RateCurveNames lists the stored rate curves
+// @Description Names of the stored rate curves
+type RateCurveNames struct {
+	Names []string `json:"names"` // Curve names, sorted
+}
+
+var ErrCurveNotFound = errors.New("rate curve not found")
+
+// registeredCurve keeps the curve as it was defined alongside its interpolator
+type registeredCurve struct {
+	stored StoredRateCurve
+	curve  *rates.Curve
+}
+
+// Named rate curves, kept in memory for the life of the server
+var (
+	curvesMutex sync.RWMutex
+	curves      = make(map[string]registeredCurve)
+)
+
+func curveInterpolationFromName(name string) (int, error) {
+	switch name {
+	case "", "LinearZero":
+		return rates.LinearZero, nil
+	case "LogLinearDiscount":
+		return rates.LogLinearDiscount, nil
+	case "Spline":
+		return rates.SplineZero, nil
+	}
+	return 0, fmt.Errorf("unknown rate curve interpolation %s - use LinearZero, LogLinearDiscount or Spline", name)
+}
+
+func rateInstrumentFromName(name string) (int, error) {
+	switch name {
+	case "Deposit":
+		return rates.Deposit, nil
+	case "Swap":
+		return rates.Swap, nil
+	case "Treasury":
+		return rates.Treasury, nil
+	}
+	return 0, fmt.Errorf("unknown instrument %s - use Deposit, Swap or Treasury", name)
+}
+
+// newRateCurve builds a curve from zero rates or by bootstrapping quotes
+func newRateCurve(definition *RateCurve) (*rates.Curve, error) {
+	interpolation, err := curveInterpolationFromName(definition.Interpolation)
+	if err != nil {
+		return nil, err
+	}
+	if len(definition.Quotes) > 0 {
+		if len(definition.ZeroRates) > 0 {
+			return nil, fmt.Errorf("rate curve takes either zeroRates or quotes, not both")
+		}
+		quotes := make([]rates.Quote, len(definition.Quotes))
+		for i, quote := range definition.Quotes {
+			instrument, err := rateInstrumentFromName(quote.Instrument)
+			if err != nil {
+				return nil, err
+			}
+			quotes[i] = rates.Quote{
+				Instrument:     instrument,
+				DaysToMaturity: quote.DaysToMaturity,
+				Rate:           quote.Rate,
+				Frequency:      quote.Frequency,
+			}
+		}
+		return rates.Bootstrap(quotes, interpolation)
+	}
+
+	points := append([]RateCurvePoint(nil), definition.ZeroRates...)
+	sort.SliceStable(points, func(i, j int) bool { return points[i].DaysToMaturity < points[j].DaysToMaturity })
+	days := make([]float64, len(points))
+	zeroRates := make([]float64, len(points))
+	for i, point := range points {
+		days[i], zeroRates[i] = point.DaysToMaturity, point.Rate
+	}
+	return rates.NewCurve(days, zeroRates, interpolation)
+}
+
+// lookupCurve finds the rate curve stored under a name
+func lookupCurve(name string) (*rates.Curve, error) {
+	curvesMutex.RLock()
+	defer curvesMutex.RUnlock()
+	curve, ok := curves[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCurveNotFound, name)
+	}
+	return curve.curve, nil
+}
+
+// PutRateCurve godoc
+// @Summary Store a rate curve
+// @Description Stores a named risk-free rate curve, given as zero rates or bootstrapped from deposit, swap and Treasury quotes,
+// @Description replacing any curve of the same name.  Option chains discount each expiry on it with rateCurve=name.
+// @Description Curves are kept in memory and are lost when the server restarts.
+// @Tags rates
+// @Accept  json
+// @Produce  json
+// @Param name path string true "Curve name (letters, digits, - and _)"
+// @Param curve body RateCurve true "Zero rates or quotes"
+// @Success 200 {object} StoredRateCurve
+// @Router /rates/curves/{name} [put]
+func PutRateCurve(name string, definition *RateCurve) (StoredRateCurve, error) {
+	if err := validStoredName("rate curve", name); err != nil {
+		return StoredRateCurve{}, err
+	}
+	curve, err := newRateCurve(definition)
+	if err != nil {
+		return StoredRateCurve{}, err
+	}
+
+	stored := StoredRateCurve{RateCurve: *definition}
+	for i, days := range curve.Days {
+		stored.Pillars = append(stored.Pillars, RateCurvePillar{
+			DaysToMaturity: days,
+			ZeroRate:       util.Round(curve.ZeroRates[i], 8),
+			DiscountFactor: util.Round(curve.DiscountFactor(days), 8),
+		})
+	}
+
+	curvesMutex.Lock()
+	defer curvesMutex.Unlock()
+	curves[name] = registeredCurve{stored: stored, curve: curve}
+	return stored, nil
+}
+
+// GetRateCurve godoc
+// @Summary Get a rate curve
+// @Description Returns a stored rate curve as it was defined, with the zero rate and discount factor at each tenor.
+// @Tags rates
+// @Produce  json
+// @Param name path string true "Curve name"
+// @Success 200 {object} StoredRateCurve
+// @Failure 404 {object} map[string]string "No curve of that name"
+// @Router /rates/curves/{name} [get]
+func GetRateCurve(name string) (StoredRateCurve, error) {
+	curvesMutex.RLock()
+	defer curvesMutex.RUnlock()
+	curve, ok := curves[name]
+	if !ok {
+		return StoredRateCurve{}, fmt.Errorf("%w: %s", ErrCurveNotFound, name)
+	}
+	return curve.stored, nil
+}
+
+// RateCurveList godoc
+// @Summary List rate curves
+// @Description Lists the names of the stored rate curves.
+// @Tags rates
+// @Produce  json
+// @Success 200 {object} RateCurveNames
+// @Router /rates/curves [get]
+func RateCurveList() RateCurveNames {
+	curvesMutex.RLock()
+	defer curvesMutex.RUnlock()
+	names := RateCurveNames{Names: []string{}}
+	for name := range curves {
+		names.Names = append(names.Names, name)
+	}
+	sort.Strings(names.Names)
+	return names
+}
+
+// DeleteRateCurve godoc
+// @Summary Remove a rate curve
+// @Description Deletes a stored rate curve.
+// @Tags rates
+// @Param name path string true "Curve name"
+// @Success 204
+// @Failure 404 {object} map[string]string "No curve of that name"
+// @Router /rates/curves/{name} [delete]
+func DeleteRateCurve(name string) error {
+	curvesMutex.Lock()
+	defer curvesMutex.Unlock()
+	if _, ok := curves[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrCurveNotFound, name)
+	}
+	delete(curves, name)
+	return nil
+}
@@ -0,0 +1,309 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/strategy"
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// StrategyLeg is one instrument in a strategy request
+// @Description A call, put or stock leg.  Entry price defaults to the current theoretical price.
+type StrategyLeg struct {
+	Instrument   string   `json:"instrument" binding:"required,oneof=Call Put Stock"` // Call, Put or Stock
+	StrikePrice  float64  `json:"strikePrice" binding:"gte=0"`                        // Strike price (options only)
+	DaysToExpiry float64  `json:"daysToExpiry" binding:"gte=0"`                       // Days to expiry (options only)
+	Quantity     float64  `json:"quantity" binding:"required,gt=0"`                   // Number of contracts or shares
+	Side         string   `json:"side" binding:"required,oneof=Long Short"`           // Long or Short
+	EntryPrice   *float64 `json:"entryPrice,omitempty"`                               // Price paid or received per unit
+}
+
+// StrategyTemplate builds legs from a common strategy
+// @Description A built-in strategy; strikes are low to high, a put may share its strike with the call above it, and a Calendar takes near and far expiries
+type StrategyTemplate struct {
+	Name         string    `json:"name" binding:"required,oneof=Vertical IronCondor Calendar Butterfly Strangle Collar Straddle"` // Template name
+	OptionType   string    `json:"optionType"`                                                                                    // Call or Put, for Vertical, Calendar and Butterfly
+	StrikePrices []float64 `json:"strikePrices" binding:"required,min=1"`                                                         // Strike prices, low to high
+	DaysToExpiry []float64 `json:"daysToExpiry" binding:"required,min=1"`                                                         // Expiry, or near and far expiries for Calendar
+	Quantity     float64   `json:"quantity" binding:"required,gt=0"`                                                              // Quantity of each unit leg
+	Side         string    `json:"side" binding:"required,oneof=Long Short"`                                                      // Direction of the structure
+}
+
+// StrategyRequest describes the legs to evaluate and the grid to evaluate them on
+// @Description Legs, or a template, with market inputs and the evaluation grid
+type StrategyRequest struct {
+	AssetName        string            `json:"assetName" binding:"required,min=2,alphanum"`              // Name of the asset
+	SpotPrice        float64           `json:"spotPrice" binding:"required,gt=0"`                        // Current asset price
+	RiskFreeRate     float64           `json:"riskFreeRate" binding:"gte=0"`                             // Risk-free interest rate
+	Volatility       float64           `json:"volatility" binding:"required,gt=0"`                       // Volatility of the asset
+	Multiplier       float64           `json:"multiplier" binding:"gte=0"`                               // Contract multiplier (default = 1)
+	Legs             []StrategyLeg     `json:"legs" binding:"max=20,dive"`                               // Explicit legs, at most 20
+	Template         *StrategyTemplate `json:"template,omitempty"`                                       // Template legs, added to any explicit legs
+	AssetPriceLow    float64           `json:"assetPriceLow" binding:"required,gt=0"`                    // Low end of asset price range
+	AssetPriceHigh   float64           `json:"assetPriceHigh" binding:"required,gtefield=AssetPriceLow"` // High end of asset price range
+	AssetPriceStep   float64           `json:"assetPriceStep" binding:"required,gt=0"`                   // Step amount for asset price range
+	DaysToExpiryLow  float64           `json:"daysToExpiryLow" binding:"gte=0"`                          // Low end of front leg days to expiry range
+	DaysToExpiryHigh float64           `json:"daysToExpiryHigh" binding:"gtefield=DaysToExpiryLow"`      // High end of front leg days to expiry range
+	DaysToExpiryStep float64           `json:"daysToExpiryStep" binding:"required,gt=0"`                 // Step amount for days to expiry range
+}
+
+// StrategyPoint is the strategy value and P&L at one asset price and days to expiry
+// @Description Combined value and profit/loss of the legs
+type StrategyPoint struct {
+	DaysToExpiry float64 `json:"daysToExpiry"` // Days to expiry of the front leg
+	Value        float64 `json:"value"`        // Combined market value of the legs
+	ProfitLoss   float64 `json:"profitLoss"`   // Value less entry cost
+}
+
+// StrategyAssetPrice holds strategy values for a specific asset price
+// @Description Strategy values per days to expiry at a given asset price
+type StrategyAssetPrice struct {
+	AssetPrice float64         `json:"assetPrice"` // Asset price
+	Points     []StrategyPoint `json:"points"`     // Values per front leg days to expiry
+}
+
+// StrategyGreeks are the net Greeks of all legs
+// @Description Net Greeks at the spot price, scaled by quantity, side and multiplier
+type StrategyGreeks struct {
+	Delta float64 `json:"delta"` // Change in value per 1.0 change in asset price
+	Gamma float64 `json:"gamma"` // Change in delta per 1.0 change in asset price
+	Theta float64 `json:"theta"` // Change in value per calendar day
+	Vega  float64 `json:"vega"`  // Change in value per volatility point
+	Rho   float64 `json:"rho"`   // Change in value per percentage point of risk-free rate
+}
+
+// StrategyResponse is the evaluation of a multi-leg strategy
+// @Description Resolved legs, expiry analysis, net Greeks and the value grid
+type StrategyResponse struct {
+	AssetName    string               `json:"assetName"`           // Name of the asset
+	Legs         []StrategyLeg        `json:"legs"`                // Legs with resolved entry prices
+	NetEntryCost float64              `json:"netEntryCost"`        // Net debit (positive) or credit (negative)
+	Breakevens   []float64            `json:"breakevens"`          // Asset prices with zero P&L at front expiry
+	MaxProfit    *float64             `json:"maxProfit,omitempty"` // Largest P&L at front expiry; absent when unbounded
+	MaxLoss      *float64             `json:"maxLoss,omitempty"`   // Most negative P&L at front expiry; absent when unbounded
+	Greeks       StrategyGreeks       `json:"greeks"`              // Net Greeks at spot
+	Grid         []StrategyAssetPrice `json:"grid"`                // Values per asset price and days to expiry
+}
+
+func sideFromName(side string) (int, error) {
+	switch side {
+	case "Long":
+		return strategy.Long, nil
+	case "Short":
+		return strategy.Short, nil
+	}
+	return 0, fmt.Errorf("unknown side %s - use Long or Short", side)
+}
+
+func instrumentFromName(instrument string) (int, error) {
+	if instrument == "Stock" {
+		return strategy.Stock, nil
+	}
+	return optionTypeFromName(instrument)
+}
+
+func templateFromName(name string) (int, error) {
+	switch name {
+	case "Vertical":
+		return strategy.Vertical, nil
+	case "IronCondor":
+		return strategy.IronCondor, nil
+	case "Calendar":
+		return strategy.Calendar, nil
+	case "Butterfly":
+		return strategy.Butterfly, nil
+	case "Strangle":
+		return strategy.Strangle, nil
+	case "Collar":
+		return strategy.Collar, nil
+	case "Straddle":
+		return strategy.Straddle, nil
+	}
+	return 0, fmt.Errorf("unknown template %s", name)
+}
+
+func instrumentName(instrument int) string {
+	switch instrument {
+	case option.Call:
+		return "Call"
+	case option.Put:
+		return "Put"
+	}
+	return "Stock"
+}
+
+func sideName(side int) string {
+	if side == strategy.Short {
+		return "Short"
+	}
+	return "Long"
+}
+
+func decodeLegs(request *StrategyRequest) ([]strategy.Leg, []bool, error) {
+	var legs []strategy.Leg
+	var priced []bool
+	for i, requestLeg := range request.Legs {
+		instrument, err := instrumentFromName(requestLeg.Instrument)
+		if err != nil {
+			return nil, nil, fmt.Errorf("leg %d: %w", i, err)
+		}
+		side, err := sideFromName(requestLeg.Side)
+		if err != nil {
+			return nil, nil, fmt.Errorf("leg %d: %w", i, err)
+		}
+		leg := strategy.Leg{
+			Instrument: instrument,
+			Option:     option.Option{Type: instrument, Strike: requestLeg.StrikePrice, Expiry: requestLeg.DaysToExpiry},
+			Quantity:   requestLeg.Quantity,
+			Side:       side,
+		}
+		if requestLeg.EntryPrice != nil {
+			leg.EntryPrice = *requestLeg.EntryPrice
+		}
+		legs = append(legs, leg)
+		priced = append(priced, requestLeg.EntryPrice != nil)
+	}
+
+	if request.Template != nil {
+		template, err := templateFromName(request.Template.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		side, err := sideFromName(request.Template.Side)
+		if err != nil {
+			return nil, nil, err
+		}
+		optionType := option.Call
+		if request.Template.OptionType != "" {
+			if optionType, err = optionTypeFromName(request.Template.OptionType); err != nil {
+				return nil, nil, err
+			}
+		}
+		templateLegs, err := strategy.TemplateLegs(template, &strategy.TemplateParams{
+			OptionType: optionType,
+			Strikes:    request.Template.StrikePrices,
+			Expiries:   request.Template.DaysToExpiry,
+			Quantity:   request.Template.Quantity,
+			Side:       side,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		legs = append(legs, templateLegs...)
+		priced = append(priced, make([]bool, len(templateLegs))...)
+	}
+	return legs, priced, nil
+}
+
+// checkStrategyLimits rejects grids that would price over MaxChainCells legs in
+// total or take over MaxComputeSeconds
+func checkStrategyLimits(assetPriceSpan, daysToExpirySpan *option.ValueSpan, legs int) error {
+	assetPrices, err := assetPriceSpan.Count("assetPriceSpan")
+	if err != nil {
+		return err
+	}
+	daysToExpiry, err := daysToExpirySpan.Count("daysToExpirySpan")
+	if err != nil {
+		return err
+	}
+	prices := assetPrices * daysToExpiry * legs
+	if prices > MaxChainCells {
+		return fmt.Errorf("%w: %d x %d x %d legs = %d prices requested, at most %d allowed - increase a step or narrow a range",
+			ErrGridTooLarge, assetPrices, daysToExpiry, legs, prices, MaxChainCells)
+	}
+	return checkComputeTime(option.EstimatePricingTime(prices))
+}
+
+// Strategy godoc
+// @Summary Evaluate a multi-leg strategy
+// @Description Values a set of call, put and stock legs over a grid of asset prices and front leg days to expiry, with breakevens, maximum profit and loss at front expiry and net Greeks at spot.
+// @Tags strategies
+// @Accept  json
+// @Produce  json
+// @Param strategy body StrategyRequest true "Legs or template with market inputs and grid"
+// @Success 200 {object} StrategyResponse
+// @Router /strategy [post]
+func Strategy(request *StrategyRequest) (StrategyResponse, error) {
+	legs, priced, err := decodeLegs(request)
+	if err != nil {
+		return StrategyResponse{}, err
+	}
+
+	multiplier := request.Multiplier
+	if multiplier == 0.0 {
+		multiplier = 1.0
+	}
+	asset := option.Asset{Name: request.AssetName, Volatility: request.Volatility, RfReturn: request.RiskFreeRate}
+	evaluated, err := strategy.NewStrategy(asset, request.SpotPrice, multiplier, legs)
+	if err != nil {
+		return StrategyResponse{}, err
+	}
+
+	assetPriceSpan := option.ValueSpan{Low: request.AssetPriceLow, High: request.AssetPriceHigh, Step: request.AssetPriceStep}
+	daysToExpirySpan := option.ValueSpan{Low: request.DaysToExpiryLow, High: request.DaysToExpiryHigh, Step: request.DaysToExpiryStep}
+	if err := checkStrategyLimits(&assetPriceSpan, &daysToExpirySpan, len(legs)); err != nil {
+		return StrategyResponse{}, err
+	}
+
+	response := StrategyResponse{AssetName: request.AssetName}
+	for i := range evaluated.Legs {
+		leg := &evaluated.Legs[i]
+		if !priced[i] {
+			if leg.EntryPrice, err = evaluated.TheoreticalPrice(leg); err != nil {
+				return StrategyResponse{}, err
+			}
+		}
+		entryPrice := util.Round(leg.EntryPrice, 2)
+		response.Legs = append(response.Legs, StrategyLeg{
+			Instrument:   instrumentName(leg.Instrument),
+			StrikePrice:  leg.Option.Strike,
+			DaysToExpiry: leg.Option.Expiry,
+			Quantity:     leg.Quantity,
+			Side:         sideName(leg.Side),
+			EntryPrice:   &entryPrice,
+		})
+	}
+
+	analysis, err := evaluated.Analyze()
+	if err != nil {
+		return StrategyResponse{}, err
+	}
+	response.NetEntryCost = util.Round(analysis.NetEntryCost, 2)
+	response.Breakevens = []float64{}
+	for _, breakeven := range analysis.Breakevens {
+		response.Breakevens = append(response.Breakevens, util.Round(breakeven, 2))
+	}
+	if !analysis.MaxProfitUnbounded {
+		maxProfit := util.Round(analysis.MaxProfit, 2)
+		response.MaxProfit = &maxProfit
+	}
+	if !analysis.MaxLossUnbounded {
+		maxLoss := util.Round(analysis.MaxLoss, 2)
+		response.MaxLoss = &maxLoss
+	}
+	response.Greeks = StrategyGreeks{
+		Delta: util.Round(analysis.Greeks.Delta, 4),
+		Gamma: util.Round(analysis.Greeks.Gamma, 4),
+		Theta: util.Round(analysis.Greeks.Theta, 4),
+		Vega:  util.Round(analysis.Greeks.Vega, 4),
+		Rho:   util.Round(analysis.Greeks.Rho, 4),
+	}
+
+	grid, err := evaluated.Evaluate(&assetPriceSpan, &daysToExpirySpan)
+	if err != nil {
+		return StrategyResponse{}, err
+	}
+	for _, row := range grid {
+		assetPriceRow := StrategyAssetPrice{AssetPrice: row[0].AssetPrice}
+		for _, value := range row {
+			assetPriceRow.Points = append(assetPriceRow.Points, StrategyPoint{
+				DaysToExpiry: value.DaysToExpiry,
+				Value:        util.Round(value.Value, 2),
+				ProfitLoss:   util.Round(value.ProfitLoss, 2),
+			})
+		}
+		response.Grid = append(response.Grid, assetPriceRow)
+	}
+	return response, nil
+}
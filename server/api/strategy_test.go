@@ -0,0 +1,28 @@
+package api
+
+import (
+	"errors"
+	"testing"
+)
+
+// Strategy grids are held to the chain cell limit, counting every leg priced
+func TestStrategyGridLimit(t *testing.T) {
+	request := StrategyRequest{
+		AssetName: "ACME", SpotPrice: 100, RiskFreeRate: 0.05, Volatility: 0.25,
+		Template:      &StrategyTemplate{Name: "IronCondor", StrikePrices: []float64{80, 90, 110, 120}, DaysToExpiry: []float64{30}, Quantity: 1, Side: "Short"},
+		AssetPriceLow: 0.25, AssetPriceHigh: 1000, AssetPriceStep: 0.25,
+		DaysToExpiryLow: 0, DaysToExpiryHigh: 199, DaysToExpiryStep: 1,
+	}
+	if _, err := Strategy(&request); !errors.Is(err, ErrGridTooLarge) {
+		t.Errorf("4000 x 200 x 4 legs: got %v, want %v", err, ErrGridTooLarge)
+	}
+
+	request.AssetPriceLow, request.AssetPriceHigh, request.AssetPriceStep, request.DaysToExpiryHigh = 80, 120, 10, 30
+	response, err := Strategy(&request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Grid) != 5 || len(response.Grid[0].Points) != 31 {
+		t.Errorf("got %d asset prices of %d points, want 5 of 31", len(response.Grid), len(response.Grid[0].Points))
+	}
+}
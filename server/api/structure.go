@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/util"
+)
+
+// StructureRequest describes a compound, chooser or exchange option and its market inputs
+// @Description A Compound, SimpleChooser, ComplexChooser or Exchange option; each type reads only its own fields
+type StructureRequest struct {
+	StructureType          string  `json:"structureType" binding:"required,oneof=Compound SimpleChooser ComplexChooser Exchange"`                         // Compound, SimpleChooser, ComplexChooser or Exchange
+	OptionType             string  `json:"optionType" binding:"required_if=StructureType Compound,omitempty,oneof=Call Put"`                              // Compound: Call or Put on the underlying option
+	UnderlyingType         string  `json:"underlyingType" binding:"required_if=StructureType Compound,omitempty,oneof=Call Put"`                          // Compound: underlying Call or Put
+	AssetPrice             float64 `json:"assetPrice" binding:"required,gt=0"`                                                                            // Current asset price; the asset received in an exchange
+	StrikePrice            float64 `json:"strikePrice" binding:"required_if=StructureType Compound,required_if=StructureType SimpleChooser,gte=0"`        // Compound: price of the underlying option; SimpleChooser: strike of the call and put
+	DaysToExpiry           float64 `json:"daysToExpiry" binding:"required_unless=StructureType ComplexChooser,gte=0"`                                     // Compound, SimpleChooser and Exchange: days to expiry
+	UnderlyingStrike       float64 `json:"underlyingStrike" binding:"required_if=StructureType Compound,gte=0"`                                           // Compound: strike of the underlying option
+	UnderlyingDaysToExpiry float64 `json:"underlyingDaysToExpiry" binding:"required_if=StructureType Compound,gte=0"`                                     // Compound: days to expiry of the underlying option
+	DaysToChoice           float64 `json:"daysToChoice" binding:"required_if=StructureType SimpleChooser,required_if=StructureType ComplexChooser,gte=0"` // Choosers: days until the call or put is chosen
+	CallStrike             float64 `json:"callStrike" binding:"required_if=StructureType ComplexChooser,gte=0"`                                           // ComplexChooser: strike of the call
+	CallDaysToExpiry       float64 `json:"callDaysToExpiry" binding:"required_if=StructureType ComplexChooser,gte=0"`                                     // ComplexChooser: days to expiry of the call
+	PutStrike              float64 `json:"putStrike" binding:"required_if=StructureType ComplexChooser,gte=0"`                                            // ComplexChooser: strike of the put
+	PutDaysToExpiry        float64 `json:"putDaysToExpiry" binding:"required_if=StructureType ComplexChooser,gte=0"`                                      // ComplexChooser: days to expiry of the put
+	Volatility             float64 `json:"volatility" binding:"required,gt=0"`                                                                            // Volatility of the asset
+	RiskFreeRate           float64 `json:"riskFreeRate" binding:"gte=0"`                                                                                  // Risk-free interest rate; not used by Exchange
+	DividendYield          float64 `json:"dividendYield" binding:"gte=0"`                                                                                 // Continuous dividend yield
+	SecondAssetPrice       float64 `json:"secondAssetPrice" binding:"required_if=StructureType Exchange,gte=0"`                                           // Exchange: price of the asset given up
+	SecondVolatility       float64 `json:"secondVolatility" binding:"required_if=StructureType Exchange,gte=0"`                                           // Exchange: volatility of the asset given up
+	SecondDividendYield    float64 `json:"secondDividendYield" binding:"gte=0"`                                                                           // Exchange: dividend yield of the asset given up
+	Correlation            float64 `json:"correlation" binding:"gte=-1,lte=1"`                                                                            // Exchange: correlation of the two assets
+	Quantity               float64 `json:"quantity" binding:"gte=0"`                                                                                      // Exchange: units received (default = 1)
+	SecondQuantity         float64 `json:"secondQuantity" binding:"gte=0"`                                                                                // Exchange: units given up (default = 1)
+}
+
+// StructureResponse is a closed-form structure price
+// @Description Price with the critical asset price of compound options and complex choosers and the volatility of an exchange
+type StructureResponse struct {
+	Price              float64  `json:"price"`                        // Option price
+	CriticalPrice      *float64 `json:"criticalPrice,omitempty"`      // Asset price at the compound expiry or choice at which the holder is indifferent
+	ExchangeVolatility *float64 `json:"exchangeVolatility,omitempty"` // Volatility of the ratio of the two assets
+}
+
+// defaultQuantity reads an exchange quantity, one when not given
+func defaultQuantity(quantity float64) float64 {
+	if quantity == 0.0 {
+		return 1.0
+	}
+	return quantity
+}
+
+// StructurePrice godoc
+// @Summary Price a compound, chooser or exchange option in closed form
+// @Description Prices an option on a call or put (Geske), a simple chooser whose call and put share a strike and expiry (Rubinstein), a complex
+// @Description chooser whose call and put differ, or the option to exchange one asset for another (Margrabe, with correlation).  Compound
+// @Description options and complex choosers also return the critical asset price, at which exercising the compound option or choosing the
+// @Description call or put makes no difference.
+// @Tags exotic
+// @Accept  json
+// @Produce  json
+// @Param structure body StructureRequest true "Option and market inputs"
+// @Success 200 {object} StructureResponse
+// @Router /structure [post]
+func StructurePrice(request *StructureRequest) (StructureResponse, error) {
+	var price, critical float64
+	var response StructureResponse
+	var err error
+	switch request.StructureType {
+	case "Compound":
+		var compound option.CompoundOption
+		if compound.OptionType, err = optionTypeFromName(request.OptionType); err != nil {
+			return StructureResponse{}, err
+		}
+		if compound.Underlying, err = optionTypeFromName(request.UnderlyingType); err != nil {
+			return StructureResponse{}, err
+		}
+		compound.Strike, compound.DaysToExpiry = request.StrikePrice, request.DaysToExpiry
+		compound.UnderlyingStrike, compound.UnderlyingDaysToExpiry = request.UnderlyingStrike, request.UnderlyingDaysToExpiry
+		price, critical, err = compound.Price(request.AssetPrice, request.Volatility, request.RiskFreeRate, request.DividendYield)
+	case "SimpleChooser", "ComplexChooser":
+		chooser := option.ChooserOption{
+			DaysToChoice:     request.DaysToChoice,
+			CallStrike:       request.CallStrike,
+			CallDaysToExpiry: request.CallDaysToExpiry,
+			PutStrike:        request.PutStrike,
+			PutDaysToExpiry:  request.PutDaysToExpiry,
+		}
+		if request.StructureType == "SimpleChooser" {
+			chooser.CallStrike, chooser.CallDaysToExpiry = request.StrikePrice, request.DaysToExpiry
+			chooser.PutStrike, chooser.PutDaysToExpiry = request.StrikePrice, request.DaysToExpiry
+		}
+		price, critical, err = chooser.Price(request.AssetPrice, request.Volatility, request.RiskFreeRate, request.DividendYield)
+	case "Exchange":
+		exchange := option.ExchangeOption{
+			Quantity1:    defaultQuantity(request.Quantity),
+			Quantity2:    defaultQuantity(request.SecondQuantity),
+			DaysToExpiry: request.DaysToExpiry,
+		}
+		var volatility float64
+		price, volatility, err = exchange.Price(
+			option.ExchangeAsset{Price: request.AssetPrice, Volatility: request.Volatility, DividendYield: request.DividendYield},
+			option.ExchangeAsset{Price: request.SecondAssetPrice, Volatility: request.SecondVolatility, DividendYield: request.SecondDividendYield},
+			request.Correlation,
+		)
+		volatility = util.Round(volatility, 6)
+		response.ExchangeVolatility = &volatility
+	default:
+		return StructureResponse{}, fmt.Errorf("unknown structure type %s - use Compound, SimpleChooser, ComplexChooser or Exchange", request.StructureType)
+	}
+	if err != nil {
+		return StructureResponse{}, err
+	}
+	response.Price = util.Round(price, 4)
+	if critical > 0.0 {
+		critical = util.Round(critical, 4)
+		response.CriticalPrice = &critical
+	}
+	return response, nil
+}
@@ -0,0 +1,433 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/util"
+	"github.com/jcdevguru/option-assistant/lib/volatility"
+)
+
+// Points in the log strike over forward grid checked for arbitrage after a fit
+const arbitrageGridPoints = 101
+
+// SVIParameters are raw SVI parameters of total variance in log strike over forward
+// @Description Raw SVI: w(k) = a + b (rho (k - m) + sqrt((k - m)^2 + sigma^2))
+type SVIParameters struct {
+	A     float64 `json:"a"`     // Vertical level of total variance
+	B     float64 `json:"b"`     // Slope of the wings
+	Rho   float64 `json:"rho"`   // Skew, between -1 and 1
+	M     float64 `json:"m"`     // Horizontal shift
+	Sigma float64 `json:"sigma"` // Curvature at the minimum
+}
+
+// SABRParameters are the parameters of Hagan's SABR model
+// @Description SABR initial volatility, elasticity, correlation and volatility of volatility
+type SABRParameters struct {
+	Alpha float64 `json:"alpha"` // Initial volatility
+	Beta  float64 `json:"beta"`  // Elasticity, between 0 and 1
+	Rho   float64 `json:"rho"`   // Correlation of forward and volatility
+	Nu    float64 `json:"nu"`    // Volatility of volatility
+}
+
+// VolatilitySmile is a parametric smile for one expiry
+// @Description An SVI or SABR smile for one expiry, fitted at the given forward
+type VolatilitySmile struct {
+	DaysToExpiry float64         `json:"daysToExpiry" binding:"required,gt=0"` // Days to expiry
+	Forward      float64         `json:"forward" binding:"required,gt=0"`      // Forward price the smile was fitted at
+	SVI          *SVIParameters  `json:"svi,omitempty"`                        // SVI parameters, or
+	SABR         *SABRParameters `json:"sabr,omitempty"`                       // SABR parameters
+}
+
+// VolatilitySurface is a grid of implied volatilities by strike and days to expiry,
+// or a set of fitted smiles
+// @Description Implied volatilities per expiry (rows) and strike (columns), with interpolation and extrapolation settings,
+// @Description or SVI/SABR smiles per expiry with the spot price they were fitted at
+type VolatilitySurface struct {
+	SpotPrice           float64           `json:"spotPrice,omitempty" binding:"gte=0"`                                              // Spot price the smiles were fitted at
+	Smiles              []VolatilitySmile `json:"smiles,omitempty" binding:"omitempty,dive"`                                        // Fitted smiles, instead of a grid
+	StrikeMode          string            `json:"strikeMode,omitempty" binding:"omitempty,oneof=Strike Moneyness"`                  // Strikes as prices (Strike, default) or strike over asset price (Moneyness)
+	Strikes             []float64         `json:"strikes,omitempty" binding:"required_without=Smiles"`                              // Strike prices or moneyness
+	DaysToExpiry        []float64         `json:"daysToExpiry,omitempty" binding:"required_without=Smiles"`                         // Days to expiry of each row
+	Volatilities        [][]float64       `json:"volatilities,omitempty" binding:"required_without=Smiles"`                         // Volatilities, one row per expiry and one column per strike
+	StrikeInterpolation string            `json:"strikeInterpolation,omitempty" binding:"omitempty,oneof=Linear Spline"`            // Across strikes: Linear (default) or natural cubic Spline
+	ExpiryInterpolation string            `json:"expiryInterpolation,omitempty" binding:"omitempty,oneof=TotalVariance Volatility"` // Across expiries: linear in TotalVariance (default) or Volatility
+	Extrapolation       string            `json:"extrapolation,omitempty" binding:"omitempty,oneof=Flat Linear"`                    // Beyond the grid: Flat (default) or Linear
+}
+
+// VolatilitySurfaceNames lists the stored volatility surfaces
+// @Description Names of the stored volatility surfaces
+type VolatilitySurfaceNames struct {
+	Names []string `json:"names"` // Surface names, sorted
+}
+
+var ErrSurfaceNotFound = errors.New("volatility surface not found")
+
+// registeredSurface keeps the surface as it was defined alongside its interpolator
+type registeredSurface struct {
+	definition VolatilitySurface
+	source     option.VolatilitySource
+}
+
+// Named volatility surfaces, kept in memory for the life of the server
+var (
+	surfacesMutex sync.RWMutex
+	surfaces      = make(map[string]registeredSurface)
+)
+
+// validStoredName checks the name a surface or curve is stored under
+func validStoredName(kind, name string) error {
+	if name == "" || len(name) > 64 {
+		return fmt.Errorf("%s name must be 1 to 64 characters", kind)
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("%s name %s may only contain letters, digits, - and _", kind, name)
+		}
+	}
+	return nil
+}
+
+// newSmileSurface builds a surface from fitted smiles
+func newSmileSurface(definition *VolatilitySurface) (*volatility.SmileSurface, error) {
+	var expiries []volatility.ExpirySmile
+	for _, smile := range definition.Smiles {
+		expiry := volatility.ExpirySmile{DaysToExpiry: smile.DaysToExpiry, Forward: smile.Forward}
+		switch {
+		case smile.SVI != nil && smile.SABR == nil:
+			svi := volatility.SVI(*smile.SVI)
+			if err := svi.Validate(); err != nil {
+				return nil, fmt.Errorf("smile for %v days: %w", smile.DaysToExpiry, err)
+			}
+			expiry.Smile = &svi
+		case smile.SABR != nil && smile.SVI == nil:
+			sabr := volatility.SABR(*smile.SABR)
+			if err := sabr.Validate(); err != nil {
+				return nil, fmt.Errorf("smile for %v days: %w", smile.DaysToExpiry, err)
+			}
+			expiry.Smile = &sabr
+		default:
+			return nil, fmt.Errorf("smile for %v days needs either svi or sabr parameters", smile.DaysToExpiry)
+		}
+		expiries = append(expiries, expiry)
+	}
+	return volatility.NewSmileSurface(definition.SpotPrice, expiries)
+}
+
+// newVolatilitySurface builds a surface from a grid or from fitted smiles
+func newVolatilitySurface(definition *VolatilitySurface) (option.VolatilitySource, error) {
+	if len(definition.Smiles) > 0 {
+		return newSmileSurface(definition)
+	}
+	moneyness := definition.StrikeMode == "Moneyness"
+
+	strikeInterpolation := option.StrikeLinear
+	switch definition.StrikeInterpolation {
+	case "", "Linear":
+	case "Spline":
+		strikeInterpolation = option.StrikeSpline
+	default:
+		return nil, fmt.Errorf("unknown strike interpolation %s - use Linear or Spline", definition.StrikeInterpolation)
+	}
+
+	expiryInterpolation := option.ExpiryTotalVariance
+	switch definition.ExpiryInterpolation {
+	case "", "TotalVariance":
+	case "Volatility":
+		expiryInterpolation = option.ExpiryVolatility
+	default:
+		return nil, fmt.Errorf("unknown expiry interpolation %s - use TotalVariance or Volatility", definition.ExpiryInterpolation)
+	}
+
+	extrapolation := option.ExtrapolateFlat
+	switch definition.Extrapolation {
+	case "", "Flat":
+	case "Linear":
+		extrapolation = option.ExtrapolateLinear
+	default:
+		return nil, fmt.Errorf("unknown extrapolation %s - use Flat or Linear", definition.Extrapolation)
+	}
+
+	return option.NewVolatilitySurface(moneyness, definition.Strikes, definition.DaysToExpiry, definition.Volatilities,
+		strikeInterpolation, expiryInterpolation, extrapolation)
+}
+
+// registerSurface stores a volatility source under a name, replacing any surface of that name
+func registerSurface(name string, definition VolatilitySurface, source option.VolatilitySource) error {
+	if err := validStoredName("volatility surface", name); err != nil {
+		return err
+	}
+	surfacesMutex.Lock()
+	defer surfacesMutex.Unlock()
+	surfaces[name] = registeredSurface{definition: definition, source: source}
+	return nil
+}
+
+// lookupSurface finds the volatility source stored under a name
+func lookupSurface(name string) (option.VolatilitySource, error) {
+	surfacesMutex.RLock()
+	defer surfacesMutex.RUnlock()
+	surface, ok := surfaces[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSurfaceNotFound, name)
+	}
+	return surface.source, nil
+}
+
+// PutVolatilitySurface godoc
+// @Summary Store a volatility surface
+// @Description Stores a named grid of implied volatilities or set of fitted smiles, replacing any surface of the same name.  Option chains use it with volSurface=name.
+// @Description Surfaces are kept in memory and are lost when the server restarts.
+// @Tags volatility
+// @Accept  json
+// @Produce  json
+// @Param name path string true "Surface name (letters, digits, - and _)"
+// @Param surface body VolatilitySurface true "Volatility grid"
+// @Success 200 {object} VolatilitySurface
+// @Router /volatility/surfaces/{name} [put]
+func PutVolatilitySurface(name string, definition *VolatilitySurface) (VolatilitySurface, error) {
+	surface, err := newVolatilitySurface(definition)
+	if err != nil {
+		return VolatilitySurface{}, err
+	}
+	if err := registerSurface(name, *definition, surface); err != nil {
+		return VolatilitySurface{}, err
+	}
+	return *definition, nil
+}
+
+// GetVolatilitySurface godoc
+// @Summary Get a volatility surface
+// @Description Returns a stored volatility surface as it was defined.
+// @Tags volatility
+// @Produce  json
+// @Param name path string true "Surface name"
+// @Success 200 {object} VolatilitySurface
+// @Failure 404 {object} map[string]string "No surface of that name"
+// @Router /volatility/surfaces/{name} [get]
+func GetVolatilitySurface(name string) (VolatilitySurface, error) {
+	surfacesMutex.RLock()
+	defer surfacesMutex.RUnlock()
+	surface, ok := surfaces[name]
+	if !ok {
+		return VolatilitySurface{}, fmt.Errorf("%w: %s", ErrSurfaceNotFound, name)
+	}
+	return surface.definition, nil
+}
+
+// VolatilitySurfaceList godoc
+// @Summary List volatility surfaces
+// @Description Lists the names of the stored volatility surfaces.
+// @Tags volatility
+// @Produce  json
+// @Success 200 {object} VolatilitySurfaceNames
+// @Router /volatility/surfaces [get]
+func VolatilitySurfaceList() VolatilitySurfaceNames {
+	surfacesMutex.RLock()
+	defer surfacesMutex.RUnlock()
+	names := VolatilitySurfaceNames{Names: []string{}}
+	for name := range surfaces {
+		names.Names = append(names.Names, name)
+	}
+	sort.Strings(names.Names)
+	return names
+}
+
+// DeleteVolatilitySurface godoc
+// @Summary Remove a volatility surface
+// @Description Deletes a stored volatility surface.
+// @Tags volatility
+// @Param name path string true "Surface name"
+// @Success 204
+// @Failure 404 {object} map[string]string "No surface of that name"
+// @Router /volatility/surfaces/{name} [delete]
+func DeleteVolatilitySurface(name string) error {
+	surfacesMutex.Lock()
+	defer surfacesMutex.Unlock()
+	if _, ok := surfaces[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrSurfaceNotFound, name)
+	}
+	delete(surfaces, name)
+	return nil
+}
+
+// VolatilityFitExpiry holds the implied volatility quotes of one expiry
+// @Description Quoted implied volatilities by strike for one expiry
+type VolatilityFitExpiry struct {
+	DaysToExpiry float64   `json:"daysToExpiry" binding:"required,gt=0"`  // Days to expiry
+	Strikes      []float64 `json:"strikes" binding:"required,min=3"`      // Quoted strike prices
+	Volatilities []float64 `json:"volatilities" binding:"required,min=3"` // Implied volatility per strike
+}
+
+// fitQuoteCount is the number of strikes quoted over all expiries
+func fitQuoteCount(expiries []VolatilityFitExpiry) int {
+	quotes := 0
+	for _, expiry := range expiries {
+		quotes += len(expiry.Strikes)
+	}
+	return quotes
+}
+
+// VolatilityFitRequest asks for smiles fitted to implied volatility quotes
+// @Description Quotes per expiry, the model to fit and the market data for forwards
+type VolatilityFitRequest struct {
+	Model         string                `json:"model" binding:"required,oneof=SVI SABR"`        // SVI or SABR
+	SpotPrice     float64               `json:"spotPrice" binding:"required,gt=0"`              // Current asset price
+	RiskFreeRate  float64               `json:"riskFreeRate" binding:"gte=0"`                   // Risk-free interest rate
+	DividendYield float64               `json:"dividendYield" binding:"gte=0"`                  // Continuous dividend yield
+	Beta          *float64              `json:"beta,omitempty" binding:"omitempty,gte=0,lte=1"` // SABR beta; fitted when omitted
+	Expiries      []VolatilityFitExpiry `json:"expiries" binding:"required,min=1,dive"`         // Quotes per expiry, at most 1,000 strikes in all by default
+	SaveAs        string                `json:"saveAs,omitempty"`                               // Store the fit as a volatility surface of this name
+}
+
+// VolatilityFitResidual compares a quote with the fitted smile
+// @Description Quoted and fitted implied volatility at one strike
+type VolatilityFitResidual struct {
+	Strike     float64 `json:"strike"`     // Strike price
+	Volatility float64 `json:"volatility"` // Quoted volatility
+	Fitted     float64 `json:"fitted"`     // Fitted volatility
+	Residual   float64 `json:"residual"`   // Fitted less quoted volatility
+}
+
+// VolatilityFitQuality summarises the fit of one expiry
+// @Description Root mean square error and residuals of the fit for one expiry
+type VolatilityFitQuality struct {
+	DaysToExpiry float64                 `json:"daysToExpiry"` // Days to expiry
+	RMSE         float64                 `json:"rmse"`         // Root mean square volatility error
+	Residuals    []VolatilityFitResidual `json:"residuals"`    // Per quote
+}
+
+// ArbitrageViolation is a range of log strike over forward where a no-arbitrage condition fails
+// @Description For butterfly arbitrage the condition is Gatheral's g(k); for calendar arbitrage the change in total variance from the previous expiry
+type ArbitrageViolation struct {
+	DaysToExpiry     float64 `json:"daysToExpiry"`     // Expiry the violation is found at
+	LogMoneynessLow  float64 `json:"logMoneynessLow"`  // Start of the range, as log strike over forward
+	LogMoneynessHigh float64 `json:"logMoneynessHigh"` // End of the range
+	Worst            float64 `json:"worst"`            // Most negative value of the condition
+}
+
+// VolatilityFitResponse holds the fitted smiles and how well they fit
+// @Description Fitted surface, fit quality per expiry and arbitrage checks over the quoted strikes
+type VolatilityFitResponse struct {
+	Surface            VolatilitySurface      `json:"surface"`            // Fitted smiles, usable as a stored volatility surface
+	Fits               []VolatilityFitQuality `json:"fits"`               // Fit quality per expiry
+	ButterflyArbitrage []ArbitrageViolation   `json:"butterflyArbitrage"` // Negative densities within an expiry
+	CalendarArbitrage  []ArbitrageViolation   `json:"calendarArbitrage"`  // Total variance falling between expiries
+	ArbitrageFree      bool                   `json:"arbitrageFree"`      // Whether no violations were found
+	SavedAs            string                 `json:"savedAs,omitempty"`  // Name the surface was stored under
+}
+
+func encodeViolations(violations []volatility.Violation) []ArbitrageViolation {
+	encoded := []ArbitrageViolation{}
+	for _, violation := range violations {
+		encoded = append(encoded, ArbitrageViolation{
+			DaysToExpiry:     violation.DaysToExpiry,
+			LogMoneynessLow:  util.Round(violation.LogMoneynessLow, 4),
+			LogMoneynessHigh: util.Round(violation.LogMoneynessHigh, 4),
+			Worst:            violation.Worst,
+		})
+	}
+	return encoded
+}
+
+// FitVolatility godoc
+// @Summary Fit volatility smiles
+// @Description Calibrates raw SVI or SABR (with beta fixed or fitted) to implied volatility quotes per expiry, reports residuals,
+// @Description and checks the fitted smiles for butterfly and calendar arbitrage over the quoted strikes.  With saveAs the fit is
+// @Description stored as a volatility surface for option chains.
+// @Tags volatility
+// @Accept  json
+// @Produce  json
+// @Param fit body VolatilityFitRequest true "Quotes and model"
+// @Success 200 {object} VolatilityFitResponse
+// @Failure 413 {object} map[string]string "Too many quotes"
+// @Router /volatility/fit [post]
+func FitVolatility(request *VolatilityFitRequest) (VolatilityFitResponse, error) {
+	if err := checkQuoteCount(fitQuoteCount(request.Expiries), MaxFitQuotes); err != nil {
+		return VolatilityFitResponse{}, err
+	}
+	response := VolatilityFitResponse{Surface: VolatilitySurface{SpotPrice: request.SpotPrice}}
+	var expiries []volatility.ExpirySmile
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, quotes := range request.Expiries {
+		years := quotes.DaysToExpiry / 365
+		forward := request.SpotPrice * math.Exp((request.RiskFreeRate-request.DividendYield)*years)
+		smile := VolatilitySmile{DaysToExpiry: quotes.DaysToExpiry, Forward: forward}
+
+		var fitted volatility.Smile
+		switch request.Model {
+		case "SVI":
+			svi, err := volatility.FitSVI(forward, years, quotes.Strikes, quotes.Volatilities)
+			if err != nil {
+				return VolatilityFitResponse{}, fmt.Errorf("expiry %v days: %w", quotes.DaysToExpiry, err)
+			}
+			parameters := SVIParameters(*svi)
+			smile.SVI, fitted = &parameters, svi
+		case "SABR":
+			beta, fitBeta := 1.0, true
+			if request.Beta != nil {
+				beta, fitBeta = *request.Beta, false
+			}
+			sabr, err := volatility.FitSABR(forward, years, quotes.Strikes, quotes.Volatilities, beta, fitBeta)
+			if err != nil {
+				return VolatilityFitResponse{}, fmt.Errorf("expiry %v days: %w", quotes.DaysToExpiry, err)
+			}
+			parameters := SABRParameters(*sabr)
+			smile.SABR, fitted = &parameters, sabr
+		default:
+			return VolatilityFitResponse{}, fmt.Errorf("unknown model %s - use SVI or SABR", request.Model)
+		}
+
+		volatilities, rmse, err := volatility.Residuals(fitted, forward, years, quotes.Strikes, quotes.Volatilities)
+		if err != nil {
+			return VolatilityFitResponse{}, fmt.Errorf("expiry %v days: %w", quotes.DaysToExpiry, err)
+		}
+		quality := VolatilityFitQuality{DaysToExpiry: quotes.DaysToExpiry, RMSE: util.Round(rmse, 6)}
+		for i, strike := range quotes.Strikes {
+			quality.Residuals = append(quality.Residuals, VolatilityFitResidual{
+				Strike:     strike,
+				Volatility: quotes.Volatilities[i],
+				Fitted:     util.Round(volatilities[i], 6),
+				Residual:   util.Round(volatilities[i]-quotes.Volatilities[i], 6),
+			})
+			lowest = math.Min(lowest, math.Log(strike/forward))
+			highest = math.Max(highest, math.Log(strike/forward))
+		}
+
+		response.Surface.Smiles = append(response.Surface.Smiles, smile)
+		response.Fits = append(response.Fits, quality)
+		expiries = append(expiries, volatility.ExpirySmile{DaysToExpiry: quotes.DaysToExpiry, Forward: forward, Smile: fitted})
+	}
+
+	surface, err := volatility.NewSmileSurface(request.SpotPrice, expiries)
+	if err != nil {
+		return VolatilityFitResponse{}, err
+	}
+	grid := make([]float64, arbitrageGridPoints)
+	for i := range grid {
+		grid[i] = lowest + (highest-lowest)*float64(i)/float64(arbitrageGridPoints-1)
+	}
+	butterfly, err := surface.ButterflyArbitrage(grid)
+	if err != nil {
+		return VolatilityFitResponse{}, err
+	}
+	calendar, err := surface.CalendarArbitrage(grid)
+	if err != nil {
+		return VolatilityFitResponse{}, err
+	}
+	response.ButterflyArbitrage = encodeViolations(butterfly)
+	response.CalendarArbitrage = encodeViolations(calendar)
+	response.ArbitrageFree = len(butterfly) == 0 && len(calendar) == 0
+
+	if request.SaveAs != "" {
+		if err := registerSurface(request.SaveAs, response.Surface, surface); err != nil {
+			return VolatilityFitResponse{}, err
+		}
+		response.SavedAs = request.SaveAs
+	}
+	return response, nil
+}
@@ -15,19 +15,149 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/optionChain": {
+        "/calendars": {
+            "get": {
+                "description": "Lists the names of the built-in and stored calendars.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendars"
+                ],
+                "summary": "List trading calendars",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.CalendarNames"
+                        }
+                    }
+                }
+            }
+        },
+        "/calendars/{name}": {
             "get": {
-                "description": "Calculates option prices for a range of asset prices, strike prices, and days to expiry.",
+                "description": "Returns a calendar's session times with the holidays, early closes and monthly option expirations of a year.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendars"
+                ],
+                "summary": "Get a trading calendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Calendar name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Year to list; default the current year",
+                        "name": "year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.CalendarYear"
+                        }
+                    },
+                    "404": {
+                        "description": "No calendar of that name",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Stores a named custom calendar, replacing any custom calendar of the same name.  Option chains use it with calendar=name.\nThe built-in NYSE and CBOE calendars cannot be replaced.  Custom calendars are kept in memory and are lost when the server restarts.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "calendars"
+                ],
+                "summary": "Store a trading calendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Calendar name (letters, digits, - and _)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Calendar definition",
+                        "name": "calendar",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CalendarDefinition"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.CalendarYear"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a custom calendar.  The built-in calendars cannot be deleted.",
+                "tags": [
+                    "calendars"
+                ],
+                "summary": "Remove a trading calendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Calendar name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "No custom calendar of that name",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/exerciseBoundary": {
+            "get": {
+                "description": "Finds the critical asset price at which exercising an American option becomes optimal, for the option expiring at the\nend of the days to expiry axis on each day it has that many days to go.  Each point takes the discrete dividends still\nto go ex by then, and the moment before each dividend goes ex gets a point of its own, when calls may be worth\nexercising to collect it.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "options"
                 ],
-                "summary": "Calculate option chain",
+                "summary": "Trace the early exercise boundary of an American option",
                 "parameters": [
                     {
                         "type": "string",
@@ -45,153 +175,3454 @@ const docTemplate = `{
                     },
                     {
                         "type": "number",
-                        "description": "Low end of asset price range",
-                        "name": "assetPriceLow",
+                        "description": "Strike price",
+                        "name": "strikePrice",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "number",
-                        "description": "High end of asset price range",
-                        "name": "assetPriceHigh",
-                        "in": "query",
-                        "required": true
+                        "description": "Low end of days to expiry range, unless daysToExpiry is given",
+                        "name": "daysToExpiryLow",
+                        "in": "query"
                     },
                     {
                         "type": "number",
-                        "description": "Step amount for asset price range (default = 1.0)",
-                        "name": "assetPriceStep",
+                        "description": "High end of days to expiry range and the option's expiry, unless daysToExpiry is given",
+                        "name": "daysToExpiryHigh",
                         "in": "query"
                     },
                     {
                         "type": "number",
-                        "description": "Low end of strike price range",
-                        "name": "strikePriceLow",
-                        "in": "query",
-                        "required": true
+                        "description": "Step amount for days to expiry range (default = 1.0)",
+                        "name": "daysToExpiryStep",
+                        "in": "query"
                     },
                     {
-                        "type": "number",
-                        "description": "High end of strike price range",
-                        "name": "strikePriceHigh",
-                        "in": "query",
-                        "required": true
+                        "type": "string",
+                        "description": "Spacing of days to expiry range (Linear, Geometric); default Linear",
+                        "name": "daysToExpirySpacing",
+                        "in": "query"
                     },
                     {
-                        "type": "number",
-                        "description": "Step amount for strike price range (default = 1.0)",
-                        "name": "strikePriceStep",
+                        "type": "integer",
+                        "description": "Number of days to expiry for Geometric spacing",
+                        "name": "daysToExpiryCount",
                         "in": "query"
                     },
                     {
-                        "type": "number",
-                        "description": "Low end of days to expiry range",
-                        "name": "daysToExpiryLow",
-                        "in": "query",
-                        "required": true
+                        "type": "string",
+                        "description": "Comma-separated days to expiry, instead of a range",
+                        "name": "daysToExpiry",
+                        "in": "query"
                     },
                     {
                         "type": "number",
-                        "description": "High end of days to expiry range",
-                        "name": "daysToExpiryHigh",
-                        "in": "query",
-                        "required": true
+                        "description": "Risk-free interest rate, unless rateCurve is given",
+                        "name": "riskFreeRate",
+                        "in": "query"
                     },
                     {
-                        "type": "number",
-                        "description": "Step amount for days to expiry range (default = 1.0)",
-                        "name": "daysToExpiryStep",
+                        "type": "string",
+                        "description": "Name of a stored rate curve to discount on",
+                        "name": "rateCurve",
                         "in": "query"
                     },
                     {
                         "type": "number",
-                        "description": "Risk-free interest rate",
-                        "name": "riskFreeRate",
-                        "in": "query",
-                        "required": true
+                        "description": "Volatility of the asset, unless volSurface is given",
+                        "name": "volatility",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of a stored volatility surface",
+                        "name": "volSurface",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pricing model: BlackScholes on spot or Black76 on a futures price; default BlackScholes",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Model for American exercise: lattice (CRR, LeisenReimer), finite-difference grid (CrankNicolson) or approximation (BaroneAdesiWhaley, BjerksundStensland); default CRR",
+                        "name": "americanModel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of lattice or grid time steps (default = 100)",
+                        "name": "latticeSteps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of asset price intervals of the finite-difference grid (default = 200)",
+                        "name": "gridPoints",
+                        "in": "query"
                     },
                     {
                         "type": "number",
-                        "description": "Volatility of the asset",
-                        "name": "volatility",
-                        "in": "query",
-                        "required": true
+                        "description": "Continuous dividend yield",
+                        "name": "dividendYield",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Discrete cash dividends as comma-separated daysToExDate:amount pairs",
+                        "name": "dividends",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.OptionChainResponse"
+                            "$ref": "#/definitions/api.ExerciseBoundaryResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Volatility surface or rate curve not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "The boundary would take too long to trace",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "The days to expiry axis is too long or the option cannot be priced",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "api.AssetPrice_Strike_Positions": {
-            "description": "Contains strike and expiry prices for a given asset price",
-            "type": "object",
-            "properties": {
-                "assetPrice": {
-                    "description": "Asset price",
-                    "type": "number"
-                },
-                "strikePositions": {
-                    "description": "Option price/dtes per strike",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/api.Strike_Positions"
                     }
                 }
             }
         },
-        "api.OptionChainResponse": {
-            "description": "The response object for the CalculateOptionChain endpoint",
-            "type": "object",
-            "properties": {
-                "assetName": {
-                    "description": "Name of the asset",
-                    "type": "string"
-                },
-                "optionChain": {
-                    "description": "Option price/dtes per asset price / strike",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/api.AssetPrice_Strike_Positions"
+        "/exotic": {
+            "post": {
+                "description": "Simulates lognormal asset price paths to price Asian (arithmetic or geometric average), barrier (knock-in or knock-out,\nwith an optional rebate) and lookback (floating or fixed strike) options.  Prices are observed at each time step.  Antithetic\npaths and a control variate (the geometric Asian for arithmetic Asians, the asset for floating lookbacks and a European\noption otherwise) reduce the standard error, which is reported with every price.  The same seed reproduces the same price.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exotic"
+                ],
+                "summary": "Price a path-dependent option by Monte Carlo",
+                "parameters": [
+                    {
+                        "description": "Option and simulation settings",
+                        "name": "exotic",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ExoticRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ExoticResponse"
+                        }
                     }
                 }
             }
         },
-        "api.Position": {
-            "description": "Contains the call and put prices for a specific number of days to expiry",
-            "type": "object",
-            "properties": {
-                "daysToExpiry": {
-                    "description": "Days to expiry",
-                    "type": "number"
-                },
-                "price": {
-                    "description": "Option price",
-                    "type": "number"
+        "/fx/smile": {
+            "post": {
+                "description": "Converts at-the-money volatilities, risk reversals and butterflies by delta to strikes and volatilities with Garman-Kohlhagen,\nin the requested delta convention (spot or forward, premium-adjusted or not).  Butterflies are smile strangles, so the call\nwing is at atm + butterfly + riskReversal/2 and the put wing at atm + butterfly - riskReversal/2.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fx"
+                ],
+                "summary": "Convert currency smile quotes to strikes",
+                "parameters": [
+                    {
+                        "description": "Smile quotes",
+                        "name": "smile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.FXSmileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.FXSmileResponse"
+                        }
+                    }
                 }
             }
         },
-        "api.Strike_Positions": {
-            "description": "Contains option prices for different expiry dates at a given strike price",
-            "type": "object",
-            "properties": {
-                "positions": {
-                    "description": "Option prices per expiry date",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/api.Position"
-                    }
-                },
-                "strikePrice": {
-                    "description": "Strike price",
-                    "type": "number"
+        "/heston/calibrate": {
+            "post": {
+                "description": "Fits the Heston parameters to implied volatility quotes across strikes and expiries by Nelder-Mead, pricing each quote's\nout-of-the-money option by the Fourier-cosine method, and reports the fitted volatility at each quote.  With saveAs the\nparameters are stored for option chains.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "heston"
+                ],
+                "summary": "Calibrate the Heston model",
+                "parameters": [
+                    {
+                        "description": "Quotes",
+                        "name": "calibration",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.HestonCalibrationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.HestonCalibrationResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Too many quotes",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/heston/parameters": {
+            "get": {
+                "description": "Lists the names of the stored Heston parameter sets.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "heston"
+                ],
+                "summary": "List Heston parameter sets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.HestonParameterNames"
+                        }
+                    }
+                }
+            }
+        },
+        "/heston/parameters/{name}": {
+            "get": {
+                "description": "Returns a stored Heston parameter set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "heston"
+                ],
+                "summary": "Get Heston parameters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Parameter set name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.HestonParameters"
+                        }
+                    },
+                    "404": {
+                        "description": "No parameter set of that name",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Stores a named Heston parameter set, replacing any set of the same name.  Option chains use it with model=Heston\u0026heston=name.\nParameter sets are kept in memory and are lost when the server restarts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "heston"
+                ],
+                "summary": "Store Heston parameters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Parameter set name (letters, digits, - and _)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Heston parameters",
+                        "name": "parameters",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.HestonParameters"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.HestonParameters"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a stored Heston parameter set.",
+                "tags": [
+                    "heston"
+                ],
+                "summary": "Remove Heston parameters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Parameter set name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "No parameter set of that name",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/impliedVolatility": {
+            "get": {
+                "description": "Finds the Black-Scholes volatility that reproduces a market option premium.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "volatility"
+                ],
+                "summary": "Solve implied volatility for a quote",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Type of option (Call, Put)",
+                        "name": "optionType",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Market premium",
+                        "name": "price",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Asset price",
+                        "name": "assetPrice",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Strike price",
+                        "name": "strikePrice",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Days to expiry",
+                        "name": "daysToExpiry",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Risk-free interest rate",
+                        "name": "riskFreeRate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ImpliedVolatilityResult"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Finds the Black-Scholes implied volatility of each quote.  Quotes without a solution carry an error instead of failing the batch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "volatility"
+                ],
+                "summary": "Solve implied volatility for a batch of quotes",
+                "parameters": [
+                    {
+                        "description": "Quotes to solve",
+                        "name": "quotes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ImpliedVolatilityBatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ImpliedVolatilityBatchResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Too many quotes",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/optionChain": {
+            "get": {
+                "description": "Calculates option prices for a range of asset prices, strike prices, and days to expiry.\nWith Accept: application/x-ndjson or text/event-stream, each asset price row is streamed as it is computed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "options"
+                ],
+                "summary": "Calculate option chain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of asset",
+                        "name": "assetName",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Type of option (Call, Put), or a digital, gap or power option (CashOrNothingCall, CashOrNothingPut, AssetOrNothingCall, AssetOrNothingPut, GapCall, GapPut, PowerCall, PowerPut)",
+                        "name": "optionType",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Paid by CashOrNothing options that finish in the money (default = 1.0)",
+                        "name": "cashPayout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Asset price beyond which Gap options pay the asset less the strike; required for Gap options",
+                        "name": "gapTrigger",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Power of the asset price in Power options, which pay max(S^n - K, 0) or max(K - S^n, 0); required for Power options",
+                        "name": "powerExponent",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Also price CashOrNothing and AssetOrNothing options by a vanilla spread this wide around each strike",
+                        "name": "digitalSpreadWidth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Current asset price, for relative asset or strike price modes",
+                        "name": "spotPrice",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Asset price range in prices (Absolute), percentage moves from spot (Percent) or standard deviations from spot (StdDev); default Absolute",
+                        "name": "assetPriceMode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Low end of asset price range, unless assetPrices is given",
+                        "name": "assetPriceLow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "High end of asset price range, unless assetPrices is given",
+                        "name": "assetPriceHigh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Step amount for asset price range (default = 1.0)",
+                        "name": "assetPriceStep",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Spacing of asset price range (Linear, Geometric); default Linear",
+                        "name": "assetPriceSpacing",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of asset prices for Geometric spacing",
+                        "name": "assetPriceCount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated asset prices, instead of a range",
+                        "name": "assetPrices",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Strike price range in prices (Absolute), strike over spot (Moneyness) or unsigned deltas (Delta); default Absolute",
+                        "name": "strikePriceMode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Low end of strike price range, unless strikePrices is given; only Bachelier allows negative prices",
+                        "name": "strikePriceLow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "High end of strike price range, unless strikePrices is given",
+                        "name": "strikePriceHigh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Step amount for strike price range (default = 1.0)",
+                        "name": "strikePriceStep",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Spacing of strike price range (Linear, Geometric); default Linear",
+                        "name": "strikePriceSpacing",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of strike prices for Geometric spacing",
+                        "name": "strikePriceCount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated strike prices, instead of a range",
+                        "name": "strikePrices",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Low end of days to expiry range, unless daysToExpiry is given",
+                        "name": "daysToExpiryLow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "High end of days to expiry range, unless daysToExpiry is given",
+                        "name": "daysToExpiryHigh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Step amount for days to expiry range (default = 1.0)",
+                        "name": "daysToExpiryStep",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Spacing of days to expiry range (Linear, Geometric); default Linear",
+                        "name": "daysToExpirySpacing",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of days to expiry for Geometric spacing",
+                        "name": "daysToExpiryCount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated days to expiry, instead of a range",
+                        "name": "daysToExpiry",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated expiration dates (YYYY-MM-DD), instead of days to expiry",
+                        "name": "expirationDates",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time to value expiration dates from (RFC 3339); default now",
+                        "name": "valuationTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time of day options expire on their expiration date (HH:MM, calendar time zone); default the session close",
+                        "name": "expiryTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading calendar for expiration dates (NYSE, CBOE or a stored calendar); default NYSE",
+                        "name": "calendar",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time to expiration dates as ACT/365, ACT/360 or trading sessions (BUS/252); default ACT/365",
+                        "name": "dayCount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Days to expiry for StdDev asset prices and Delta strikes; default the longest expiry",
+                        "name": "referenceDaysToExpiry",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Risk-free interest rate, unless rateCurve or domesticRate is given",
+                        "name": "riskFreeRate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of a stored rate curve to discount each expiry on; needs the ACT/365 day count with expirationDates",
+                        "name": "rateCurve",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Volatility of the asset, between jumps for Merton and Kou, unless volSurface or heston is given",
+                        "name": "volatility",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of a stored volatility surface to price each option from",
+                        "name": "volSurface",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pricing model: BlackScholes on spot, Black76 or Bachelier (normal volatility, prices may be negative) on a futures price, GarmanKohlhagen on an exchange rate, Heston stochastic volatility, or Merton or Kou jump-diffusion; default BlackScholes",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of a stored Heston parameter set, for model=Heston",
+                        "name": "heston",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Jumps per year, for model=Merton or Kou",
+                        "name": "jumpIntensity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Mean of the log of each jump, for model=Merton",
+                        "name": "jumpMean",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Standard deviation of the log of each jump, for model=Merton",
+                        "name": "jumpVolatility",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Probability a jump is up, for model=Kou",
+                        "name": "jumpUpProbability",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Mean log size of up jumps, below 1, for model=Kou",
+                        "name": "jumpUpMean",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Mean log size of down jumps, for model=Kou",
+                        "name": "jumpDownMean",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also price each option by Black-Scholes at the model's volatility, for model=Heston, Merton or Kou",
+                        "name": "compareBlackScholes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Domestic interest rate for GarmanKohlhagen, unless rateCurve is given",
+                        "name": "domesticRate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Foreign interest rate for GarmanKohlhagen",
+                        "name": "foreignRate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "GarmanKohlhagen delta convention (Spot, Forward, PremiumAdjustedSpot, PremiumAdjustedForward); default Spot",
+                        "name": "deltaConvention",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "GarmanKohlhagen premium convention (DomesticPips, ForeignPercent, ForeignPips, DomesticPercent); default DomesticPips",
+                        "name": "premiumConvention",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated Greeks to include (delta, gamma, theta, vega, rho) or all",
+                        "name": "greeks",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exercise style (European, American); default European",
+                        "name": "exerciseStyle",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Model for American exercise: lattice (CRR, LeisenReimer), finite-difference grid (CrankNicolson) or approximation (BaroneAdesiWhaley, BjerksundStensland); default CRR",
+                        "name": "americanModel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of lattice or grid time steps for American exercise and barriers (default = 100)",
+                        "name": "latticeSteps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of asset price intervals of the finite-difference grid (default = 200)",
+                        "name": "gridPoints",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Barrier type (UpAndOut, DownAndOut, UpAndIn, DownAndIn); continuously monitored, priced on the finite-difference grid",
+                        "name": "barrierType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Barrier level; required with barrierType",
+                        "name": "barrier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Paid on knock-out, or at expiry if never knocked in",
+                        "name": "rebate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Continuous dividend yield",
+                        "name": "dividendYield",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Discrete cash dividends as comma-separated daysToExDate:amount pairs, in calendar days",
+                        "name": "dividends",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return the projected size and compute time instead of prices",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "With dryRun=true",
+                        "schema": {
+                            "$ref": "#/definitions/api.OptionChainEstimate"
+                        }
+                    },
+                    "404": {
+                        "description": "Volatility surface, rate curve, calendar or Heston parameter set not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Too many cells in total, or too long to compute",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "An axis is too long, or the query is invalid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio": {
+            "get": {
+                "description": "Lists the holdings stored in the portfolio.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "List portfolio holdings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.PortfolioHoldings"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/holdings": {
+            "post": {
+                "description": "Stores a call, put or stock holding in the portfolio.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Add a portfolio holding",
+                "parameters": [
+                    {
+                        "description": "Holding to store; id is ignored",
+                        "name": "holding",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.PortfolioHolding"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.PortfolioHolding"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/holdings/{id}": {
+            "delete": {
+                "description": "Deletes a holding from the portfolio.",
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Remove a portfolio holding",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Holding id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/portfolio/whatIf": {
+            "post": {
+                "description": "Values the stored portfolio before and after adding a proposed trade, per underlying and in total.  Trade holdings\nwithout an entryPrice are entered at their current Black-Scholes value, so the trade alone adds no profit or loss.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Evaluate the impact of a trade on the portfolio",
+                "parameters": [
+                    {
+                        "description": "Market data and proposed trade",
+                        "name": "whatIf",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.WhatIfRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.WhatIfResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/curves": {
+            "get": {
+                "description": "Lists the names of the stored rate curves.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rates"
+                ],
+                "summary": "List rate curves",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.RateCurveNames"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/curves/{name}": {
+            "get": {
+                "description": "Returns a stored rate curve as it was defined, with the zero rate and discount factor at each tenor.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rates"
+                ],
+                "summary": "Get a rate curve",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Curve name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StoredRateCurve"
+                        }
+                    },
+                    "404": {
+                        "description": "No curve of that name",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Stores a named risk-free rate curve, given as zero rates or bootstrapped from deposit, swap and Treasury quotes,\nreplacing any curve of the same name.  Option chains discount each expiry on it with rateCurve=name.\nCurves are kept in memory and are lost when the server restarts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rates"
+                ],
+                "summary": "Store a rate curve",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Curve name (letters, digits, - and _)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Zero rates or quotes",
+                        "name": "curve",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RateCurve"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StoredRateCurve"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a stored rate curve.",
+                "tags": [
+                    "rates"
+                ],
+                "summary": "Remove a rate curve",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Curve name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "No curve of that name",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/strategy": {
+            "post": {
+                "description": "Values a set of call, put and stock legs over a grid of asset prices and front leg days to expiry, with breakevens, maximum profit and loss at front expiry and net Greeks at spot.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "strategies"
+                ],
+                "summary": "Evaluate a multi-leg strategy",
+                "parameters": [
+                    {
+                        "description": "Legs or template with market inputs and grid",
+                        "name": "strategy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.StrategyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StrategyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/structure": {
+            "post": {
+                "description": "Prices an option on a call or put (Geske), a simple chooser whose call and put share a strike and expiry (Rubinstein), a complex\nchooser whose call and put differ, or the option to exchange one asset for another (Margrabe, with correlation).  Compound\noptions and complex choosers also return the critical asset price, at which exercising the compound option or choosing the\ncall or put makes no difference.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exotic"
+                ],
+                "summary": "Price a compound, chooser or exchange option in closed form",
+                "parameters": [
+                    {
+                        "description": "Option and market inputs",
+                        "name": "structure",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.StructureRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StructureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/volatility/fit": {
+            "post": {
+                "description": "Calibrates raw SVI or SABR (with beta fixed or fitted) to implied volatility quotes per expiry, reports residuals,\nand checks the fitted smiles for butterfly and calendar arbitrage over the quoted strikes.  With saveAs the fit is\nstored as a volatility surface for option chains.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "volatility"
+                ],
+                "summary": "Fit volatility smiles",
+                "parameters": [
+                    {
+                        "description": "Quotes and model",
+                        "name": "fit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.VolatilityFitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.VolatilityFitResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Too many quotes",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/volatility/surfaces": {
+            "get": {
+                "description": "Lists the names of the stored volatility surfaces.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "volatility"
+                ],
+                "summary": "List volatility surfaces",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.VolatilitySurfaceNames"
+                        }
+                    }
+                }
+            }
+        },
+        "/volatility/surfaces/{name}": {
+            "get": {
+                "description": "Returns a stored volatility surface as it was defined.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "volatility"
+                ],
+                "summary": "Get a volatility surface",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Surface name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.VolatilitySurface"
+                        }
+                    },
+                    "404": {
+                        "description": "No surface of that name",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Stores a named grid of implied volatilities or set of fitted smiles, replacing any surface of the same name.  Option chains use it with volSurface=name.\nSurfaces are kept in memory and are lost when the server restarts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "volatility"
+                ],
+                "summary": "Store a volatility surface",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Surface name (letters, digits, - and _)",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Volatility grid",
+                        "name": "surface",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.VolatilitySurface"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.VolatilitySurface"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a stored volatility surface.",
+                "tags": [
+                    "volatility"
+                ],
+                "summary": "Remove a volatility surface",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Surface name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "No surface of that name",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.ArbitrageViolation": {
+            "description": "For butterfly arbitrage the condition is Gatheral's g(k); for calendar arbitrage the change in total variance from the previous expiry",
+            "type": "object",
+            "properties": {
+                "daysToExpiry": {
+                    "description": "Expiry the violation is found at",
+                    "type": "number"
+                },
+                "logMoneynessHigh": {
+                    "description": "End of the range",
+                    "type": "number"
+                },
+                "logMoneynessLow": {
+                    "description": "Start of the range, as log strike over forward",
+                    "type": "number"
+                },
+                "worst": {
+                    "description": "Most negative value of the condition",
+                    "type": "number"
+                }
+            }
+        },
+        "api.AssetPrice_Strike_Positions": {
+            "description": "Contains strike and expiry prices for a given asset price",
+            "type": "object",
+            "properties": {
+                "assetPrice": {
+                    "description": "Asset price",
+                    "type": "number"
+                },
+                "strikePositions": {
+                    "description": "Option price/dtes per strike",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Strike_Positions"
+                    }
+                }
+            }
+        },
+        "api.CalendarDefinition": {
+            "description": "Session times, weekend days and holidays, optionally starting from a built-in calendar's holiday rules",
+            "type": "object",
+            "properties": {
+                "base": {
+                    "description": "Built-in calendar whose holidays and session to start from",
+                    "type": "string",
+                    "enum": [
+                        "NYSE",
+                        "CBOE"
+                    ]
+                },
+                "close": {
+                    "description": "Session close, HH:MM; default the base's, or 16:00",
+                    "type": "string"
+                },
+                "earlyCloses": {
+                    "description": "Extra early closes",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CalendarEarlyClose"
+                    }
+                },
+                "holidays": {
+                    "description": "Extra holidays",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CalendarHoliday"
+                    }
+                },
+                "open": {
+                    "description": "Session open, HH:MM; default the base's, or 09:30",
+                    "type": "string"
+                },
+                "timeZone": {
+                    "description": "IANA time zone; default the base's, or America/New_York",
+                    "type": "string"
+                },
+                "weekend": {
+                    "description": "Days never traded; default Saturday and Sunday",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.CalendarEarlyClose": {
+            "description": "A date, as YYYY-MM-DD, and the time its session closes, as HH:MM",
+            "type": "object",
+            "required": [
+                "close",
+                "date"
+            ],
+            "properties": {
+                "close": {
+                    "description": "Close, HH:MM local time",
+                    "type": "string"
+                },
+                "date": {
+                    "description": "Date, YYYY-MM-DD",
+                    "type": "string"
+                }
+            }
+        },
+        "api.CalendarHoliday": {
+            "description": "A holiday, as YYYY-MM-DD, and its name",
+            "type": "object",
+            "required": [
+                "date"
+            ],
+            "properties": {
+                "date": {
+                    "description": "Date, YYYY-MM-DD",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name of the holiday",
+                    "type": "string"
+                }
+            }
+        },
+        "api.CalendarNames": {
+            "description": "Names of the built-in and stored calendars",
+            "type": "object",
+            "properties": {
+                "names": {
+                    "description": "Calendar names, sorted",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.CalendarYear": {
+            "description": "A calendar's session, and the holidays, early closes and standard monthly option expirations of a year",
+            "type": "object",
+            "properties": {
+                "close": {
+                    "description": "Regular session close, HH:MM",
+                    "type": "string"
+                },
+                "definition": {
+                    "description": "How a custom calendar was defined",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.CalendarDefinition"
+                        }
+                    ]
+                },
+                "earlyCloses": {
+                    "description": "Early closes",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CalendarEarlyClose"
+                    }
+                },
+                "holidays": {
+                    "description": "Weekday holidays",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CalendarHoliday"
+                    }
+                },
+                "monthlyExpirations": {
+                    "description": "Third Friday of each month, or the trading day before",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "Calendar name",
+                    "type": "string"
+                },
+                "open": {
+                    "description": "Session open, HH:MM",
+                    "type": "string"
+                },
+                "timeZone": {
+                    "description": "IANA time zone",
+                    "type": "string"
+                },
+                "year": {
+                    "description": "Year listed",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.ExerciseBoundaryResponse": {
+            "description": "The critical asset prices of an American option from today to its expiry",
+            "type": "object",
+            "properties": {
+                "assetName": {
+                    "description": "Name of the asset",
+                    "type": "string"
+                },
+                "boundary": {
+                    "description": "Critical prices from the longest days to expiry to the shortest",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ExercisePoint"
+                    }
+                },
+                "optionType": {
+                    "description": "Call or Put",
+                    "type": "string"
+                },
+                "strikePrice": {
+                    "description": "Strike price",
+                    "type": "number"
+                }
+            }
+        },
+        "api.ExercisePoint": {
+            "description": "The asset price at or below which a put, or at or above which a call, is best exercised with daysToExpiry to go",
+            "type": "object",
+            "properties": {
+                "criticalPrice": {
+                    "description": "Critical asset price; omitted when early exercise is never optimal",
+                    "type": "number"
+                },
+                "daysToExpiry": {
+                    "description": "Days to expiry",
+                    "type": "number"
+                },
+                "exDividend": {
+                    "description": "The last moment to exercise before a discrete dividend goes ex",
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.ExoticRequest": {
+            "description": "An Asian, barrier or lookback option with market inputs and Monte Carlo settings",
+            "type": "object",
+            "required": [
+                "assetPrice",
+                "daysToExpiry",
+                "exoticType",
+                "optionType",
+                "volatility"
+            ],
+            "properties": {
+                "antithetic": {
+                    "description": "Pair each path with its mirror image (default = true)",
+                    "type": "boolean"
+                },
+                "assetPrice": {
+                    "description": "Current asset price",
+                    "type": "number"
+                },
+                "average": {
+                    "description": "Asian average; default Arithmetic",
+                    "type": "string",
+                    "enum": [
+                        "Arithmetic",
+                        "Geometric"
+                    ]
+                },
+                "barrier": {
+                    "description": "Barrier level",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "barrierType": {
+                    "description": "Barrier type",
+                    "type": "string",
+                    "enum": [
+                        "UpAndOut",
+                        "DownAndOut",
+                        "UpAndIn",
+                        "DownAndIn"
+                    ]
+                },
+                "continuousMonitoring": {
+                    "description": "Correct a barrier monitored at each step to continuous monitoring",
+                    "type": "boolean"
+                },
+                "controlVariate": {
+                    "description": "Correct with a payoff of known price (default = true)",
+                    "type": "boolean"
+                },
+                "daysToExpiry": {
+                    "description": "Days to expiry",
+                    "type": "number"
+                },
+                "dividendYield": {
+                    "description": "Continuous dividend yield",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "exoticType": {
+                    "description": "Asian, Barrier or Lookback",
+                    "type": "string",
+                    "enum": [
+                        "Asian",
+                        "Barrier",
+                        "Lookback"
+                    ]
+                },
+                "lookbackStrike": {
+                    "description": "Lookback strike; default Floating",
+                    "type": "string",
+                    "enum": [
+                        "Floating",
+                        "Fixed"
+                    ]
+                },
+                "optionType": {
+                    "description": "Call or Put",
+                    "type": "string",
+                    "enum": [
+                        "Call",
+                        "Put"
+                    ]
+                },
+                "paths": {
+                    "description": "Number of simulated paths (default = 100000)",
+                    "type": "integer",
+                    "maximum": 10000000,
+                    "minimum": 2
+                },
+                "rebate": {
+                    "description": "Paid on knock-out, or at expiry if never knocked in",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "riskFreeRate": {
+                    "description": "Risk-free interest rate",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "seed": {
+                    "description": "Random number seed; drawn from the clock when omitted",
+                    "type": "integer"
+                },
+                "steps": {
+                    "description": "Time steps per path: fixings and monitoring dates (default = 252)",
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 1
+                },
+                "strikePrice": {
+                    "description": "Strike price; not used by floating strike lookbacks",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "volatility": {
+                    "description": "Volatility of the asset",
+                    "type": "number"
+                }
+            }
+        },
+        "api.ExoticResponse": {
+            "description": "Monte Carlo price with its standard error and the settings that reproduce it",
+            "type": "object",
+            "properties": {
+                "antithetic": {
+                    "description": "Whether antithetic paths were used",
+                    "type": "boolean"
+                },
+                "confidenceHigh": {
+                    "description": "High end of the 95% confidence interval",
+                    "type": "number"
+                },
+                "confidenceLow": {
+                    "description": "Low end of the 95% confidence interval",
+                    "type": "number"
+                },
+                "controlVariate": {
+                    "description": "Whether the control variate was used",
+                    "type": "boolean"
+                },
+                "paths": {
+                    "description": "Paths simulated",
+                    "type": "integer"
+                },
+                "price": {
+                    "description": "Simulated price",
+                    "type": "number"
+                },
+                "seed": {
+                    "description": "Seed that reproduces the price",
+                    "type": "integer"
+                },
+                "standardError": {
+                    "description": "Standard error of the price",
+                    "type": "number"
+                },
+                "steps": {
+                    "description": "Time steps per path",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.Expiration": {
+            "description": "An expiration date, when options expiring on it stop trading, and the time to it as measured by the day count",
+            "type": "object",
+            "properties": {
+                "daysToExpiry": {
+                    "description": "Time to expiry in days of 365 to the year, as in the positions",
+                    "type": "number"
+                },
+                "expirationDate": {
+                    "description": "Expiration date, YYYY-MM-DD",
+                    "type": "string"
+                },
+                "expiryTime": {
+                    "description": "Time of expiry, RFC 3339",
+                    "type": "string"
+                },
+                "yearsToExpiry": {
+                    "description": "Time to expiry in years",
+                    "type": "number"
+                }
+            }
+        },
+        "api.FXSmileExpiry": {
+            "description": "At-the-money volatility with risk reversals and butterflies by delta",
+            "type": "object",
+            "properties": {
+                "atmVolatility": {
+                    "description": "At-the-money volatility",
+                    "type": "number"
+                },
+                "daysToExpiry": {
+                    "description": "Days to expiry",
+                    "type": "number"
+                },
+                "wings": {
+                    "description": "Wings, such as 25 and 10 delta",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.FXSmileWing"
+                    }
+                }
+            }
+        },
+        "api.FXSmilePoint": {
+            "description": "Strike and volatility of one quoted option, with its delta in the requested convention",
+            "type": "object",
+            "properties": {
+                "delta": {
+                    "description": "Delta, negative for puts",
+                    "type": "number"
+                },
+                "optionType": {
+                    "description": "Call or Put; the at-the-money strike is quoted as a call",
+                    "type": "string"
+                },
+                "strike": {
+                    "description": "Strike price",
+                    "type": "number"
+                },
+                "volatility": {
+                    "description": "Implied volatility",
+                    "type": "number"
+                }
+            }
+        },
+        "api.FXSmileRequest": {
+            "description": "Smile quotes per expiry with the spot rate, interest rates and quoting conventions",
+            "type": "object",
+            "required": [
+                "expiries",
+                "spotPrice"
+            ],
+            "properties": {
+                "atmConvention": {
+                    "description": "At-the-money strike: delta-neutral straddle (default) or forward",
+                    "type": "string",
+                    "enum": [
+                        "DeltaNeutral",
+                        "Forward"
+                    ]
+                },
+                "deltaConvention": {
+                    "description": "Delta convention; default Spot",
+                    "type": "string",
+                    "enum": [
+                        "Spot",
+                        "Forward",
+                        "PremiumAdjustedSpot",
+                        "PremiumAdjustedForward"
+                    ]
+                },
+                "domesticRate": {
+                    "description": "Domestic interest rate",
+                    "type": "number"
+                },
+                "expiries": {
+                    "description": "Quotes per expiry",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/api.FXSmileExpiry"
+                    }
+                },
+                "foreignRate": {
+                    "description": "Foreign interest rate",
+                    "type": "number"
+                },
+                "spotPrice": {
+                    "description": "Spot rate, domestic per unit of foreign currency",
+                    "type": "number"
+                }
+            }
+        },
+        "api.FXSmileResponse": {
+            "description": "Strikes and volatilities per expiry, ready for /volatility/fit",
+            "type": "object",
+            "properties": {
+                "expiries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.FXSmileStrikes"
+                    }
+                }
+            }
+        },
+        "api.FXSmileStrikes": {
+            "description": "Forward and the smile's strikes in ascending order for one expiry",
+            "type": "object",
+            "properties": {
+                "daysToExpiry": {
+                    "description": "Days to expiry",
+                    "type": "number"
+                },
+                "forward": {
+                    "description": "Forward rate",
+                    "type": "number"
+                },
+                "points": {
+                    "description": "Strikes in ascending order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.FXSmilePoint"
+                    }
+                }
+            }
+        },
+        "api.FXSmileWing": {
+            "description": "Risk reversal (call less put volatility) and butterfly (their average over at-the-money) at one delta",
+            "type": "object",
+            "properties": {
+                "butterfly": {
+                    "description": "Smile strangle: wing volatilities' average less at-the-money",
+                    "type": "number"
+                },
+                "delta": {
+                    "description": "Unsigned delta of the wing, such as 0.25",
+                    "type": "number"
+                },
+                "riskReversal": {
+                    "description": "Call volatility less put volatility",
+                    "type": "number"
+                }
+            }
+        },
+        "api.HestonCalibrationRequest": {
+            "description": "Quotes per expiry with the market data for forwards and an optional starting point",
+            "type": "object",
+            "required": [
+                "expiries",
+                "spotPrice"
+            ],
+            "properties": {
+                "dividendYield": {
+                    "description": "Continuous dividend yield",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "expiries": {
+                    "description": "Quotes per expiry, at most 1,000 strikes in all by default",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/api.VolatilityFitExpiry"
+                    }
+                },
+                "riskFreeRate": {
+                    "description": "Risk-free interest rate",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "saveAs": {
+                    "description": "Store the parameters under this name",
+                    "type": "string"
+                },
+                "spotPrice": {
+                    "description": "Current asset price",
+                    "type": "number"
+                },
+                "start": {
+                    "description": "Parameters to start the search from; several are tried when omitted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.HestonParameters"
+                        }
+                    ]
+                }
+            }
+        },
+        "api.HestonCalibrationResponse": {
+            "description": "Fitted parameters, whether they meet the Feller condition and the fit quality per expiry",
+            "type": "object",
+            "properties": {
+                "feller": {
+                    "description": "Whether 2 kappa theta \u003e= sigma^2, so the variance stays positive",
+                    "type": "boolean"
+                },
+                "fits": {
+                    "description": "Fit quality per expiry",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.VolatilityFitQuality"
+                    }
+                },
+                "parameters": {
+                    "description": "Fitted parameters",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.HestonParameters"
+                        }
+                    ]
+                },
+                "rmse": {
+                    "description": "Root mean square volatility error over all quotes",
+                    "type": "number"
+                },
+                "savedAs": {
+                    "description": "Name the parameters were stored under",
+                    "type": "string"
+                }
+            }
+        },
+        "api.HestonParameterNames": {
+            "description": "Names of the stored Heston parameter sets",
+            "type": "object",
+            "properties": {
+                "names": {
+                    "description": "Parameter set names, sorted",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.HestonParameters": {
+            "description": "Mean reversion speed, long-run variance, volatility of variance, correlation and initial variance",
+            "type": "object",
+            "properties": {
+                "kappa": {
+                    "description": "Speed the variance reverts at",
+                    "type": "number"
+                },
+                "rho": {
+                    "description": "Correlation of the asset price and its variance",
+                    "type": "number"
+                },
+                "sigma": {
+                    "description": "Volatility of the variance",
+                    "type": "number"
+                },
+                "theta": {
+                    "description": "Long-run variance",
+                    "type": "number"
+                },
+                "v0": {
+                    "description": "Initial variance",
+                    "type": "number"
+                }
+            }
+        },
+        "api.ImpliedVolatilityBatch": {
+            "description": "A batch of market quotes",
+            "type": "object",
+            "required": [
+                "quotes"
+            ],
+            "properties": {
+                "quotes": {
+                    "description": "Quotes to solve, at most 10,000 by default",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/api.ImpliedVolatilityQuote"
+                    }
+                }
+            }
+        },
+        "api.ImpliedVolatilityBatchResponse": {
+            "description": "Implied volatility results for a batch of quotes",
+            "type": "object",
+            "properties": {
+                "results": {
+                    "description": "One result per quote",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ImpliedVolatilityResult"
+                    }
+                }
+            }
+        },
+        "api.ImpliedVolatilityQuote": {
+            "description": "A market option premium with the inputs needed to solve for its implied volatility",
+            "type": "object",
+            "required": [
+                "assetPrice",
+                "daysToExpiry",
+                "optionType",
+                "price",
+                "strikePrice"
+            ],
+            "properties": {
+                "assetPrice": {
+                    "description": "Asset price",
+                    "type": "number"
+                },
+                "daysToExpiry": {
+                    "description": "Days to expiry",
+                    "type": "number"
+                },
+                "optionType": {
+                    "description": "Type of option (Call, Put)",
+                    "type": "string",
+                    "enum": [
+                        "Call",
+                        "Put"
+                    ]
+                },
+                "price": {
+                    "description": "Market premium",
+                    "type": "number"
+                },
+                "riskFreeRate": {
+                    "description": "Risk-free interest rate",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "strikePrice": {
+                    "description": "Strike price",
+                    "type": "number"
+                }
+            }
+        },
+        "api.ImpliedVolatilityResult": {
+            "description": "The implied volatility for a quote, or the reason none exists",
+            "type": "object",
+            "required": [
+                "assetPrice",
+                "daysToExpiry",
+                "optionType",
+                "price",
+                "strikePrice"
+            ],
+            "properties": {
+                "assetPrice": {
+                    "description": "Asset price",
+                    "type": "number"
+                },
+                "daysToExpiry": {
+                    "description": "Days to expiry",
+                    "type": "number"
+                },
+                "error": {
+                    "description": "Why no volatility was found",
+                    "type": "string"
+                },
+                "impliedVolatility": {
+                    "description": "Volatility reproducing the price",
+                    "type": "number"
+                },
+                "optionType": {
+                    "description": "Type of option (Call, Put)",
+                    "type": "string",
+                    "enum": [
+                        "Call",
+                        "Put"
+                    ]
+                },
+                "price": {
+                    "description": "Market premium",
+                    "type": "number"
+                },
+                "riskFreeRate": {
+                    "description": "Risk-free interest rate",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "strikePrice": {
+                    "description": "Strike price",
+                    "type": "number"
+                }
+            }
+        },
+        "api.OptionChainEstimate": {
+            "description": "The projected size and compute time of an option chain, returned for dryRun=true",
+            "type": "object",
+            "properties": {
+                "assetName": {
+                    "description": "Name of the asset",
+                    "type": "string"
+                },
+                "assetPrices": {
+                    "description": "Number of asset prices",
+                    "type": "integer"
+                },
+                "cells": {
+                    "description": "Number of prices to compute",
+                    "type": "integer"
+                },
+                "daysToExpiry": {
+                    "description": "Number of days to expiry",
+                    "type": "integer"
+                },
+                "estimatedBytes": {
+                    "description": "Approximate size of the JSON response",
+                    "type": "integer"
+                },
+                "estimatedMilliseconds": {
+                    "description": "Approximate compute time",
+                    "type": "integer"
+                },
+                "limitExceeded": {
+                    "description": "Which limit the request exceeds",
+                    "type": "string"
+                },
+                "strikePrices": {
+                    "description": "Number of strike prices",
+                    "type": "integer"
+                },
+                "withinLimits": {
+                    "description": "Whether the request would be accepted",
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.OptionChainResponse": {
+            "description": "The response object for the CalculateOptionChain endpoint",
+            "type": "object",
+            "properties": {
+                "assetName": {
+                    "description": "Name of the asset",
+                    "type": "string"
+                },
+                "assetPrices": {
+                    "description": "Asset prices resolved from a relative assetPriceMode",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "expirations": {
+                    "description": "Days to expiry measured for expirationDates",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Expiration"
+                    }
+                },
+                "optionChain": {
+                    "description": "Option price/dtes per asset price / strike",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AssetPrice_Strike_Positions"
+                    }
+                },
+                "strikePrices": {
+                    "description": "Strike prices resolved from a relative strikePriceMode",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "api.PortfolioHolding": {
+            "description": "A call, put or stock position on an underlying.  Options expire at the end of their expiry date (UTC).",
+            "type": "object",
+            "required": [
+                "assetName",
+                "instrument",
+                "quantity",
+                "side"
+            ],
+            "properties": {
+                "assetName": {
+                    "description": "Name of the underlying asset",
+                    "type": "string",
+                    "minLength": 2
+                },
+                "entryPrice": {
+                    "description": "Price paid or received per unit (default for trades = model price)",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "expiryDate": {
+                    "description": "Expiry date as YYYY-MM-DD (options only)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Assigned by the store",
+                    "type": "string"
+                },
+                "instrument": {
+                    "description": "Call, Put or Stock",
+                    "type": "string",
+                    "enum": [
+                        "Call",
+                        "Put",
+                        "Stock"
+                    ]
+                },
+                "multiplier": {
+                    "description": "Contract multiplier (default = 1)",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "quantity": {
+                    "description": "Number of contracts or shares",
+                    "type": "number"
+                },
+                "side": {
+                    "description": "Long or Short",
+                    "type": "string",
+                    "enum": [
+                        "Long",
+                        "Short"
+                    ]
+                },
+                "strikePrice": {
+                    "description": "Strike price (options only)",
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "api.PortfolioHoldings": {
+            "description": "All holdings in the portfolio",
+            "type": "object",
+            "properties": {
+                "holdings": {
+                    "description": "Stored holdings",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.PortfolioHolding"
+                    }
+                }
+            }
+        },
+        "api.PortfolioMarket": {
+            "description": "Spot price, volatility and risk-free rate of an underlying",
+            "type": "object",
+            "required": [
+                "spotPrice",
+                "volatility"
+            ],
+            "properties": {
+                "riskFreeRate": {
+                    "description": "Risk-free interest rate",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "spotPrice": {
+                    "description": "Current asset price",
+                    "type": "number"
+                },
+                "volatility": {
+                    "description": "Volatility of the asset",
+                    "type": "number"
+                }
+            }
+        },
+        "api.PortfolioReport": {
+            "description": "Valuation per underlying and in total",
+            "type": "object",
+            "properties": {
+                "total": {
+                    "description": "Valuation of all holdings",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.PortfolioValuation"
+                        }
+                    ]
+                },
+                "underlyings": {
+                    "description": "Valuation by asset name",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/api.PortfolioValuation"
+                    }
+                }
+            }
+        },
+        "api.PortfolioValuation": {
+            "description": "Value, profit/loss against entry prices and net Greeks",
+            "type": "object",
+            "properties": {
+                "greeks": {
+                    "description": "Net Greeks",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.StrategyGreeks"
+                        }
+                    ]
+                },
+                "profitLoss": {
+                    "description": "Value less entry cost",
+                    "type": "number"
+                },
+                "value": {
+                    "description": "Market value",
+                    "type": "number"
+                }
+            }
+        },
+        "api.Position": {
+            "description": "Contains the call and put prices for a specific number of days to expiry",
+            "type": "object",
+            "properties": {
+                "blackScholesPrice": {
+                    "description": "Black-Scholes price at the model's volatility, for compareBlackScholes",
+                    "type": "number"
+                },
+                "daysToExpiry": {
+                    "description": "Days to expiry",
+                    "type": "number"
+                },
+                "delta": {
+                    "description": "Change in price per 1.0 change in asset price",
+                    "type": "number"
+                },
+                "earlyExercisePremium": {
+                    "description": "Value of early exercise over the Black-Scholes price, for American exercise",
+                    "type": "number"
+                },
+                "exerciseNow": {
+                    "description": "Exercising now is worth at least holding, for American exercise; a dividend going ex by tomorrow is only collected by exercising today",
+                    "type": "boolean"
+                },
+                "gamma": {
+                    "description": "Change in delta per 1.0 change in asset price",
+                    "type": "number"
+                },
+                "price": {
+                    "description": "Option price",
+                    "type": "number"
+                },
+                "replicationPrice": {
+                    "description": "Price of the vanilla spread replicating a digital, for digitalSpreadWidth",
+                    "type": "number"
+                },
+                "rho": {
+                    "description": "Change in price per percentage point of risk-free rate",
+                    "type": "number"
+                },
+                "theta": {
+                    "description": "Change in price per calendar day",
+                    "type": "number"
+                },
+                "vega": {
+                    "description": "Change in price per volatility point",
+                    "type": "number"
+                }
+            }
+        },
+        "api.RateCurve": {
+            "description": "Zero rates by tenor, or market quotes to bootstrap them from, and how to interpolate between tenors",
+            "type": "object",
+            "properties": {
+                "interpolation": {
+                    "description": "LinearZero (default), LogLinearDiscount or Spline",
+                    "type": "string",
+                    "enum": [
+                        "LinearZero",
+                        "LogLinearDiscount",
+                        "Spline"
+                    ]
+                },
+                "quotes": {
+                    "description": "Quotes to bootstrap, instead of zero rates",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RateQuote"
+                    }
+                },
+                "zeroRates": {
+                    "description": "Zero rates by tenor",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RateCurvePoint"
+                    }
+                }
+            }
+        },
+        "api.RateCurveNames": {
+            "description": "Names of the stored rate curves",
+            "type": "object",
+            "properties": {
+                "names": {
+                    "description": "Curve names, sorted",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.RateCurvePillar": {
+            "description": "Zero rate and discount factor at a tenor of the curve",
+            "type": "object",
+            "properties": {
+                "daysToMaturity": {
+                    "description": "Tenor in days",
+                    "type": "number"
+                },
+                "discountFactor": {
+                    "description": "Value today of one paid at the tenor",
+                    "type": "number"
+                },
+                "zeroRate": {
+                    "description": "Continuously compounded zero rate",
+                    "type": "number"
+                }
+            }
+        },
+        "api.RateCurvePoint": {
+            "description": "A continuously compounded zero rate for one tenor",
+            "type": "object",
+            "required": [
+                "daysToMaturity"
+            ],
+            "properties": {
+                "daysToMaturity": {
+                    "description": "Tenor in days",
+                    "type": "number"
+                },
+                "rate": {
+                    "description": "Continuously compounded zero rate",
+                    "type": "number"
+                }
+            }
+        },
+        "api.RateQuote": {
+            "description": "Deposits are simple ACT/360 rates; swaps and Treasuries are par rates with coupons paid frequency times a year",
+            "type": "object",
+            "required": [
+                "daysToMaturity",
+                "instrument"
+            ],
+            "properties": {
+                "daysToMaturity": {
+                    "description": "Days to maturity",
+                    "type": "number"
+                },
+                "frequency": {
+                    "description": "Payments per year; default 1 for swaps, 2 for Treasuries",
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 0
+                },
+                "instrument": {
+                    "description": "Deposit, Swap or Treasury",
+                    "type": "string",
+                    "enum": [
+                        "Deposit",
+                        "Swap",
+                        "Treasury"
+                    ]
+                },
+                "rate": {
+                    "description": "Quoted rate",
+                    "type": "number"
+                }
+            }
+        },
+        "api.SABRParameters": {
+            "description": "SABR initial volatility, elasticity, correlation and volatility of volatility",
+            "type": "object",
+            "properties": {
+                "alpha": {
+                    "description": "Initial volatility",
+                    "type": "number"
+                },
+                "beta": {
+                    "description": "Elasticity, between 0 and 1",
+                    "type": "number"
+                },
+                "nu": {
+                    "description": "Volatility of volatility",
+                    "type": "number"
+                },
+                "rho": {
+                    "description": "Correlation of forward and volatility",
+                    "type": "number"
+                }
+            }
+        },
+        "api.SVIParameters": {
+            "description": "Raw SVI: w(k) = a + b (rho (k - m) + sqrt((k - m)^2 + sigma^2))",
+            "type": "object",
+            "properties": {
+                "a": {
+                    "description": "Vertical level of total variance",
+                    "type": "number"
+                },
+                "b": {
+                    "description": "Slope of the wings",
+                    "type": "number"
+                },
+                "m": {
+                    "description": "Horizontal shift",
+                    "type": "number"
+                },
+                "rho": {
+                    "description": "Skew, between -1 and 1",
+                    "type": "number"
+                },
+                "sigma": {
+                    "description": "Curvature at the minimum",
+                    "type": "number"
+                }
+            }
+        },
+        "api.StoredRateCurve": {
+            "description": "A stored rate curve's definition and the zero rates and discount factors at its tenors",
+            "type": "object",
+            "properties": {
+                "interpolation": {
+                    "description": "LinearZero (default), LogLinearDiscount or Spline",
+                    "type": "string",
+                    "enum": [
+                        "LinearZero",
+                        "LogLinearDiscount",
+                        "Spline"
+                    ]
+                },
+                "pillars": {
+                    "description": "Curve at each tenor",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RateCurvePillar"
+                    }
+                },
+                "quotes": {
+                    "description": "Quotes to bootstrap, instead of zero rates",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RateQuote"
+                    }
+                },
+                "zeroRates": {
+                    "description": "Zero rates by tenor",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RateCurvePoint"
+                    }
+                }
+            }
+        },
+        "api.StrategyAssetPrice": {
+            "description": "Strategy values per days to expiry at a given asset price",
+            "type": "object",
+            "properties": {
+                "assetPrice": {
+                    "description": "Asset price",
+                    "type": "number"
+                },
+                "points": {
+                    "description": "Values per front leg days to expiry",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.StrategyPoint"
+                    }
+                }
+            }
+        },
+        "api.StrategyGreeks": {
+            "description": "Net Greeks at the spot price, scaled by quantity, side and multiplier",
+            "type": "object",
+            "properties": {
+                "delta": {
+                    "description": "Change in value per 1.0 change in asset price",
+                    "type": "number"
+                },
+                "gamma": {
+                    "description": "Change in delta per 1.0 change in asset price",
+                    "type": "number"
+                },
+                "rho": {
+                    "description": "Change in value per percentage point of risk-free rate",
+                    "type": "number"
+                },
+                "theta": {
+                    "description": "Change in value per calendar day",
+                    "type": "number"
+                },
+                "vega": {
+                    "description": "Change in value per volatility point",
+                    "type": "number"
+                }
+            }
+        },
+        "api.StrategyLeg": {
+            "description": "A call, put or stock leg.  Entry price defaults to the current theoretical price.",
+            "type": "object",
+            "required": [
+                "instrument",
+                "quantity",
+                "side"
+            ],
+            "properties": {
+                "daysToExpiry": {
+                    "description": "Days to expiry (options only)",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "entryPrice": {
+                    "description": "Price paid or received per unit",
+                    "type": "number"
+                },
+                "instrument": {
+                    "description": "Call, Put or Stock",
+                    "type": "string",
+                    "enum": [
+                        "Call",
+                        "Put",
+                        "Stock"
+                    ]
+                },
+                "quantity": {
+                    "description": "Number of contracts or shares",
+                    "type": "number"
+                },
+                "side": {
+                    "description": "Long or Short",
+                    "type": "string",
+                    "enum": [
+                        "Long",
+                        "Short"
+                    ]
+                },
+                "strikePrice": {
+                    "description": "Strike price (options only)",
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "api.StrategyPoint": {
+            "description": "Combined value and profit/loss of the legs",
+            "type": "object",
+            "properties": {
+                "daysToExpiry": {
+                    "description": "Days to expiry of the front leg",
+                    "type": "number"
+                },
+                "profitLoss": {
+                    "description": "Value less entry cost",
+                    "type": "number"
+                },
+                "value": {
+                    "description": "Combined market value of the legs",
+                    "type": "number"
+                }
+            }
+        },
+        "api.StrategyRequest": {
+            "description": "Legs, or a template, with market inputs and the evaluation grid",
+            "type": "object",
+            "required": [
+                "assetName",
+                "assetPriceHigh",
+                "assetPriceLow",
+                "assetPriceStep",
+                "daysToExpiryStep",
+                "spotPrice",
+                "volatility"
+            ],
+            "properties": {
+                "assetName": {
+                    "description": "Name of the asset",
+                    "type": "string",
+                    "minLength": 2
+                },
+                "assetPriceHigh": {
+                    "description": "High end of asset price range",
+                    "type": "number"
+                },
+                "assetPriceLow": {
+                    "description": "Low end of asset price range",
+                    "type": "number"
+                },
+                "assetPriceStep": {
+                    "description": "Step amount for asset price range",
+                    "type": "number"
+                },
+                "daysToExpiryHigh": {
+                    "description": "High end of front leg days to expiry range",
+                    "type": "number"
+                },
+                "daysToExpiryLow": {
+                    "description": "Low end of front leg days to expiry range",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "daysToExpiryStep": {
+                    "description": "Step amount for days to expiry range",
+                    "type": "number"
+                },
+                "legs": {
+                    "description": "Explicit legs, at most 20",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/api.StrategyLeg"
+                    }
+                },
+                "multiplier": {
+                    "description": "Contract multiplier (default = 1)",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "riskFreeRate": {
+                    "description": "Risk-free interest rate",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "spotPrice": {
+                    "description": "Current asset price",
+                    "type": "number"
+                },
+                "template": {
+                    "description": "Template legs, added to any explicit legs",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.StrategyTemplate"
+                        }
+                    ]
+                },
+                "volatility": {
+                    "description": "Volatility of the asset",
+                    "type": "number"
+                }
+            }
+        },
+        "api.StrategyResponse": {
+            "description": "Resolved legs, expiry analysis, net Greeks and the value grid",
+            "type": "object",
+            "properties": {
+                "assetName": {
+                    "description": "Name of the asset",
+                    "type": "string"
+                },
+                "breakevens": {
+                    "description": "Asset prices with zero P\u0026L at front expiry",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "greeks": {
+                    "description": "Net Greeks at spot",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.StrategyGreeks"
+                        }
+                    ]
+                },
+                "grid": {
+                    "description": "Values per asset price and days to expiry",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.StrategyAssetPrice"
+                    }
+                },
+                "legs": {
+                    "description": "Legs with resolved entry prices",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.StrategyLeg"
+                    }
+                },
+                "maxLoss": {
+                    "description": "Most negative P\u0026L at front expiry; absent when unbounded",
+                    "type": "number"
+                },
+                "maxProfit": {
+                    "description": "Largest P\u0026L at front expiry; absent when unbounded",
+                    "type": "number"
+                },
+                "netEntryCost": {
+                    "description": "Net debit (positive) or credit (negative)",
+                    "type": "number"
+                }
+            }
+        },
+        "api.StrategyTemplate": {
+            "description": "A built-in strategy; strikes are low to high, a put may share its strike with the call above it, and a Calendar takes near and far expiries",
+            "type": "object",
+            "required": [
+                "daysToExpiry",
+                "name",
+                "quantity",
+                "side",
+                "strikePrices"
+            ],
+            "properties": {
+                "daysToExpiry": {
+                    "description": "Expiry, or near and far expiries for Calendar",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "name": {
+                    "description": "Template name",
+                    "type": "string",
+                    "enum": [
+                        "Vertical",
+                        "IronCondor",
+                        "Calendar",
+                        "Butterfly",
+                        "Strangle",
+                        "Collar",
+                        "Straddle"
+                    ]
+                },
+                "optionType": {
+                    "description": "Call or Put, for Vertical, Calendar and Butterfly",
+                    "type": "string"
+                },
+                "quantity": {
+                    "description": "Quantity of each unit leg",
+                    "type": "number"
+                },
+                "side": {
+                    "description": "Direction of the structure",
+                    "type": "string",
+                    "enum": [
+                        "Long",
+                        "Short"
+                    ]
+                },
+                "strikePrices": {
+                    "description": "Strike prices, low to high",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "api.Strike_Positions": {
+            "description": "Contains option prices for different expiry dates at a given strike price",
+            "type": "object",
+            "properties": {
+                "positions": {
+                    "description": "Option prices per expiry date",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Position"
+                    }
+                },
+                "strikePrice": {
+                    "description": "Strike price",
+                    "type": "number"
+                }
+            }
+        },
+        "api.StructureRequest": {
+            "description": "A Compound, SimpleChooser, ComplexChooser or Exchange option; each type reads only its own fields",
+            "type": "object",
+            "required": [
+                "assetPrice",
+                "structureType",
+                "volatility"
+            ],
+            "properties": {
+                "assetPrice": {
+                    "description": "Current asset price; the asset received in an exchange",
+                    "type": "number"
+                },
+                "callDaysToExpiry": {
+                    "description": "ComplexChooser: days to expiry of the call",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "callStrike": {
+                    "description": "ComplexChooser: strike of the call",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "correlation": {
+                    "description": "Exchange: correlation of the two assets",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": -1
+                },
+                "daysToChoice": {
+                    "description": "Choosers: days until the call or put is chosen",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "daysToExpiry": {
+                    "description": "Compound, SimpleChooser and Exchange: days to expiry",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "dividendYield": {
+                    "description": "Continuous dividend yield",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "optionType": {
+                    "description": "Compound: Call or Put on the underlying option",
+                    "type": "string",
+                    "enum": [
+                        "Call",
+                        "Put"
+                    ]
+                },
+                "putDaysToExpiry": {
+                    "description": "ComplexChooser: days to expiry of the put",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "putStrike": {
+                    "description": "ComplexChooser: strike of the put",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "quantity": {
+                    "description": "Exchange: units received (default = 1)",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "riskFreeRate": {
+                    "description": "Risk-free interest rate; not used by Exchange",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "secondAssetPrice": {
+                    "description": "Exchange: price of the asset given up",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "secondDividendYield": {
+                    "description": "Exchange: dividend yield of the asset given up",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "secondQuantity": {
+                    "description": "Exchange: units given up (default = 1)",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "secondVolatility": {
+                    "description": "Exchange: volatility of the asset given up",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "strikePrice": {
+                    "description": "Compound: price of the underlying option; SimpleChooser: strike of the call and put",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "structureType": {
+                    "description": "Compound, SimpleChooser, ComplexChooser or Exchange",
+                    "type": "string",
+                    "enum": [
+                        "Compound",
+                        "SimpleChooser",
+                        "ComplexChooser",
+                        "Exchange"
+                    ]
+                },
+                "underlyingDaysToExpiry": {
+                    "description": "Compound: days to expiry of the underlying option",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "underlyingStrike": {
+                    "description": "Compound: strike of the underlying option",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "underlyingType": {
+                    "description": "Compound: underlying Call or Put",
+                    "type": "string",
+                    "enum": [
+                        "Call",
+                        "Put"
+                    ]
+                },
+                "volatility": {
+                    "description": "Volatility of the asset",
+                    "type": "number"
+                }
+            }
+        },
+        "api.StructureResponse": {
+            "description": "Price with the critical asset price of compound options and complex choosers and the volatility of an exchange",
+            "type": "object",
+            "properties": {
+                "criticalPrice": {
+                    "description": "Asset price at the compound expiry or choice at which the holder is indifferent",
+                    "type": "number"
+                },
+                "exchangeVolatility": {
+                    "description": "Volatility of the ratio of the two assets",
+                    "type": "number"
+                },
+                "price": {
+                    "description": "Option price",
+                    "type": "number"
+                }
+            }
+        },
+        "api.VolatilityFitExpiry": {
+            "description": "Quoted implied volatilities by strike for one expiry",
+            "type": "object",
+            "required": [
+                "daysToExpiry",
+                "strikes",
+                "volatilities"
+            ],
+            "properties": {
+                "daysToExpiry": {
+                    "description": "Days to expiry",
+                    "type": "number"
+                },
+                "strikes": {
+                    "description": "Quoted strike prices",
+                    "type": "array",
+                    "minItems": 3,
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "volatilities": {
+                    "description": "Implied volatility per strike",
+                    "type": "array",
+                    "minItems": 3,
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "api.VolatilityFitQuality": {
+            "description": "Root mean square error and residuals of the fit for one expiry",
+            "type": "object",
+            "properties": {
+                "daysToExpiry": {
+                    "description": "Days to expiry",
+                    "type": "number"
+                },
+                "residuals": {
+                    "description": "Per quote",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.VolatilityFitResidual"
+                    }
+                },
+                "rmse": {
+                    "description": "Root mean square volatility error",
+                    "type": "number"
+                }
+            }
+        },
+        "api.VolatilityFitRequest": {
+            "description": "Quotes per expiry, the model to fit and the market data for forwards",
+            "type": "object",
+            "required": [
+                "expiries",
+                "model",
+                "spotPrice"
+            ],
+            "properties": {
+                "beta": {
+                    "description": "SABR beta; fitted when omitted",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "dividendYield": {
+                    "description": "Continuous dividend yield",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "expiries": {
+                    "description": "Quotes per expiry, at most 1,000 strikes in all by default",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/api.VolatilityFitExpiry"
+                    }
+                },
+                "model": {
+                    "description": "SVI or SABR",
+                    "type": "string",
+                    "enum": [
+                        "SVI",
+                        "SABR"
+                    ]
+                },
+                "riskFreeRate": {
+                    "description": "Risk-free interest rate",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "saveAs": {
+                    "description": "Store the fit as a volatility surface of this name",
+                    "type": "string"
+                },
+                "spotPrice": {
+                    "description": "Current asset price",
+                    "type": "number"
+                }
+            }
+        },
+        "api.VolatilityFitResidual": {
+            "description": "Quoted and fitted implied volatility at one strike",
+            "type": "object",
+            "properties": {
+                "fitted": {
+                    "description": "Fitted volatility",
+                    "type": "number"
+                },
+                "residual": {
+                    "description": "Fitted less quoted volatility",
+                    "type": "number"
+                },
+                "strike": {
+                    "description": "Strike price",
+                    "type": "number"
+                },
+                "volatility": {
+                    "description": "Quoted volatility",
+                    "type": "number"
+                }
+            }
+        },
+        "api.VolatilityFitResponse": {
+            "description": "Fitted surface, fit quality per expiry and arbitrage checks over the quoted strikes",
+            "type": "object",
+            "properties": {
+                "arbitrageFree": {
+                    "description": "Whether no violations were found",
+                    "type": "boolean"
+                },
+                "butterflyArbitrage": {
+                    "description": "Negative densities within an expiry",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ArbitrageViolation"
+                    }
+                },
+                "calendarArbitrage": {
+                    "description": "Total variance falling between expiries",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ArbitrageViolation"
+                    }
+                },
+                "fits": {
+                    "description": "Fit quality per expiry",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.VolatilityFitQuality"
+                    }
+                },
+                "savedAs": {
+                    "description": "Name the surface was stored under",
+                    "type": "string"
+                },
+                "surface": {
+                    "description": "Fitted smiles, usable as a stored volatility surface",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.VolatilitySurface"
+                        }
+                    ]
+                }
+            }
+        },
+        "api.VolatilitySmile": {
+            "description": "An SVI or SABR smile for one expiry, fitted at the given forward",
+            "type": "object",
+            "required": [
+                "daysToExpiry",
+                "forward"
+            ],
+            "properties": {
+                "daysToExpiry": {
+                    "description": "Days to expiry",
+                    "type": "number"
+                },
+                "forward": {
+                    "description": "Forward price the smile was fitted at",
+                    "type": "number"
+                },
+                "sabr": {
+                    "description": "SABR parameters",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.SABRParameters"
+                        }
+                    ]
+                },
+                "svi": {
+                    "description": "SVI parameters, or",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.SVIParameters"
+                        }
+                    ]
+                }
+            }
+        },
+        "api.VolatilitySurface": {
+            "description": "Implied volatilities per expiry (rows) and strike (columns), with interpolation and extrapolation settings, or SVI/SABR smiles per expiry with the spot price they were fitted at",
+            "type": "object",
+            "properties": {
+                "daysToExpiry": {
+                    "description": "Days to expiry of each row",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "expiryInterpolation": {
+                    "description": "Across expiries: linear in TotalVariance (default) or Volatility",
+                    "type": "string",
+                    "enum": [
+                        "TotalVariance",
+                        "Volatility"
+                    ]
+                },
+                "extrapolation": {
+                    "description": "Beyond the grid: Flat (default) or Linear",
+                    "type": "string",
+                    "enum": [
+                        "Flat",
+                        "Linear"
+                    ]
+                },
+                "smiles": {
+                    "description": "Fitted smiles, instead of a grid",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.VolatilitySmile"
+                    }
+                },
+                "spotPrice": {
+                    "description": "Spot price the smiles were fitted at",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "strikeInterpolation": {
+                    "description": "Across strikes: Linear (default) or natural cubic Spline",
+                    "type": "string",
+                    "enum": [
+                        "Linear",
+                        "Spline"
+                    ]
+                },
+                "strikeMode": {
+                    "description": "Strikes as prices (Strike, default) or strike over asset price (Moneyness)",
+                    "type": "string",
+                    "enum": [
+                        "Strike",
+                        "Moneyness"
+                    ]
+                },
+                "strikes": {
+                    "description": "Strike prices or moneyness",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "volatilities": {
+                    "description": "Volatilities, one row per expiry and one column per strike",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        }
+                    }
+                }
+            }
+        },
+        "api.VolatilitySurfaceNames": {
+            "description": "Names of the stored volatility surfaces",
+            "type": "object",
+            "properties": {
+                "names": {
+                    "description": "Surface names, sorted",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.WhatIfRequest": {
+            "description": "Market data for every underlying held or traded, and the proposed trade",
+            "type": "object",
+            "required": [
+                "markets",
+                "trade"
+            ],
+            "properties": {
+                "markets": {
+                    "description": "Market data by asset name",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/api.PortfolioMarket"
+                    }
+                },
+                "trade": {
+                    "description": "Proposed holdings to add",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/api.PortfolioHolding"
+                    }
+                },
+                "valuationTime": {
+                    "description": "RFC 3339 valuation time (default = now)",
+                    "type": "string"
+                }
+            }
+        },
+        "api.WhatIfResponse": {
+            "description": "Portfolio valuation before and after the trade, and the difference",
+            "type": "object",
+            "properties": {
+                "after": {
+                    "description": "Stored holdings with the trade",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.PortfolioReport"
+                        }
+                    ]
+                },
+                "before": {
+                    "description": "Stored holdings only",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.PortfolioReport"
+                        }
+                    ]
+                },
+                "impact": {
+                    "description": "After less before",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.PortfolioValuation"
+                        }
+                    ]
                 }
             }
         }
@@ -1,15 +1,34 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jcdevguru/option-assistant/lib/option"
+	"github.com/jcdevguru/option-assistant/lib/portfolio"
 	"github.com/jcdevguru/option-assistant/server/api"
 	docs "github.com/jcdevguru/option-assistant/server/docs" // import generated docs
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// Streaming media types for /optionChain
+const (
+	mimeNDJSON      = "application/x-ndjson"
+	mimeEventStream = "text/event-stream"
+)
+
+// Portfolio holdings are kept in this file unless PORTFOLIO_STORE names another
+const defaultPortfolioStore = "portfolio.json"
+
+var portfolioStore *portfolio.Store
+
 func getOptionChain(c *gin.Context) {
 	var query api.OptionChainQuery
 	if err := c.ShouldBindQuery(&query); err != nil {
@@ -17,17 +36,30 @@ func getOptionChain(c *gin.Context) {
 		return
 	}
 
+	if query.DryRun {
+		estimate, err := api.EstimateOptionChain(&query)
+		if err != nil {
+			c.JSON(chainErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, estimate)
+		return
+	}
+
+	switch c.NegotiateFormat(gin.MIMEJSON, mimeNDJSON, mimeEventStream) {
+	case mimeNDJSON:
+		streamOptionChain(c, &query, mimeNDJSON)
+		return
+	case mimeEventStream:
+		streamOptionChain(c, &query, mimeEventStream)
+		return
+	}
+
 	// Call CalculateOptionChain with the extracted parameters
-	optionChain, err := api.OptionChain(
-		query.AssetName, query.OptionType,
-		query.AssetPriceLow, query.AssetPriceHigh, query.AssetPriceStep,
-		query.StrikePriceLow, query.StrikePriceHigh, query.StrikePriceStep,
-		query.DaysToExpiryLow, query.DaysToExpiryHigh, query.DaysToExpiryStep,
-		query.RiskFreeRate, query.Volatility,
-	)
+	optionChain, err := api.OptionChain(&query)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(chainErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -35,12 +67,523 @@ func getOptionChain(c *gin.Context) {
 	c.JSON(http.StatusOK, optionChain)
 }
 
+// chainErrorStatus distinguishes requests rejected for their size or content from pricing failures
+func chainErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, api.ErrGridTooLarge), errors.Is(err, api.ErrTooSlow):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, option.ErrAxisTooLong), errors.Is(err, api.ErrAxisEmpty):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, api.ErrSurfaceNotFound), errors.Is(err, api.ErrCurveNotFound), errors.Is(err, api.ErrCalendarNotFound),
+		errors.Is(err, api.ErrHestonNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, api.ErrInvalidQuery):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// streamOptionChain writes one asset price row per NDJSON line or server-sent
+// event.  Errors before the first row get a normal JSON error response; later
+// errors are reported in the stream.  Computation stops when the client goes away.
+func streamOptionChain(c *gin.Context, query *api.OptionChainQuery, mime string) {
+	started := false
+	rows := 0
+	start := func() {
+		if !started {
+			c.Header("Content-Type", mime)
+			c.Header("Cache-Control", "no-cache")
+			c.Status(http.StatusOK)
+			started = true
+		}
+	}
+	err := api.StreamOptionChain(c.Request.Context(), query, func(row *api.AssetPrice_Strike_Positions) error {
+		start()
+		if mime == mimeEventStream {
+			c.SSEvent("row", row)
+		} else {
+			line, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			if _, err := c.Writer.Write(append(line, '\n')); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		rows++
+		return nil
+	})
+
+	if c.Request.Context().Err() != nil {
+		return
+	}
+	if err != nil && !started {
+		c.JSON(chainErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	start()
+	if mime == mimeEventStream {
+		if err != nil {
+			c.SSEvent("error", gin.H{"error": err.Error()})
+		} else {
+			c.SSEvent("end", gin.H{"rows": rows})
+		}
+	} else if err != nil {
+		line, _ := json.Marshal(gin.H{"error": err.Error()})
+		c.Writer.Write(append(line, '\n'))
+	}
+	c.Writer.Flush()
+}
+
+func getExerciseBoundary(c *gin.Context) {
+	var query api.ExerciseBoundaryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	boundary, err := api.ExerciseBoundary(&query)
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, api.ErrSurfaceNotFound) || errors.Is(err, api.ErrCurveNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, api.ErrTooSlow) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, boundary)
+}
+
+func getImpliedVolatility(c *gin.Context) {
+	var quote api.ImpliedVolatilityQuote
+	if err := c.ShouldBindQuery(&quote); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := api.ImpliedVolatility(quote)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func postImpliedVolatility(c *gin.Context) {
+	var batch api.ImpliedVolatilityBatch
+	if err := c.ShouldBindJSON(&batch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := api.ImpliedVolatilityBatchSolve(batch)
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func getVolatilitySurfaces(c *gin.Context) {
+	c.JSON(http.StatusOK, api.VolatilitySurfaceList())
+}
+
+func getVolatilitySurface(c *gin.Context) {
+	surface, err := api.GetVolatilitySurface(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, surface)
+}
+
+func putVolatilitySurface(c *gin.Context) {
+	var request api.VolatilitySurface
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	surface, err := api.PutVolatilitySurface(c.Param("name"), &request)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, surface)
+}
+
+func deleteVolatilitySurface(c *gin.Context) {
+	if err := api.DeleteVolatilitySurface(c.Param("name")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func getHestonParameterList(c *gin.Context) {
+	c.JSON(http.StatusOK, api.HestonParameterList())
+}
+
+func getHestonParameters(c *gin.Context) {
+	parameters, err := api.GetHestonParameters(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, parameters)
+}
+
+func putHestonParameters(c *gin.Context) {
+	var request api.HestonParameters
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	parameters, err := api.PutHestonParameters(c.Param("name"), &request)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, parameters)
+}
+
+func deleteHestonParameters(c *gin.Context) {
+	if err := api.DeleteHestonParameters(c.Param("name")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func postHestonCalibrate(c *gin.Context) {
+	var request api.HestonCalibrationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := api.CalibrateHeston(&request)
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, api.ErrTooManyQuotes) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func postVolatilityFit(c *gin.Context) {
+	var request api.VolatilityFitRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := api.FitVolatility(&request)
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, api.ErrTooManyQuotes) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func postFXSmile(c *gin.Context) {
+	var request api.FXSmileRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := api.FXSmile(&request)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func getRateCurves(c *gin.Context) {
+	c.JSON(http.StatusOK, api.RateCurveList())
+}
+
+func getRateCurve(c *gin.Context) {
+	curve, err := api.GetRateCurve(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, curve)
+}
+
+func putRateCurve(c *gin.Context) {
+	var request api.RateCurve
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	curve, err := api.PutRateCurve(c.Param("name"), &request)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, curve)
+}
+
+func deleteRateCurve(c *gin.Context) {
+	if err := api.DeleteRateCurve(c.Param("name")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func getCalendars(c *gin.Context) {
+	c.JSON(http.StatusOK, api.CalendarList())
+}
+
+func getCalendar(c *gin.Context) {
+	year := time.Now().Year()
+	if setting := c.Query("year"); setting != "" {
+		var err error
+		if year, err = strconv.Atoi(setting); err != nil || year < 1900 || year > 2200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "year must be between 1900 and 2200"})
+			return
+		}
+	}
+
+	calendar, err := api.GetCalendar(c.Param("name"), year)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+func putCalendar(c *gin.Context) {
+	var request api.CalendarDefinition
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	calendar, err := api.PutCalendar(c.Param("name"), &request)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+func deleteCalendar(c *gin.Context) {
+	if err := api.DeleteCalendar(c.Param("name")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func postStrategy(c *gin.Context) {
+	var request api.StrategyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := api.Strategy(&request)
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, api.ErrGridTooLarge) || errors.Is(err, api.ErrTooSlow) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func postExotic(c *gin.Context) {
+	var request api.ExoticRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := api.ExoticPrice(c.Request.Context(), &request)
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, api.ErrSimulationTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func postStructure(c *gin.Context) {
+	var request api.StructureRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := api.StructurePrice(&request)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func getPortfolio(c *gin.Context) {
+	c.JSON(http.StatusOK, api.Holdings(portfolioStore))
+}
+
+func postPortfolioHolding(c *gin.Context) {
+	var request api.PortfolioHolding
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	holding, err := api.AddHolding(portfolioStore, &request)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, holding)
+}
+
+func deletePortfolioHolding(c *gin.Context) {
+	if err := api.RemoveHolding(portfolioStore, c.Param("id")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, portfolio.ErrHoldingNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func postPortfolioWhatIf(c *gin.Context) {
+	var request api.WhatIfRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := api.WhatIf(portfolioStore, &request)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 func main() {
+	storePath := os.Getenv("PORTFOLIO_STORE")
+	if storePath == "" {
+		storePath = defaultPortfolioStore
+	}
+	var err error
+	if portfolioStore, err = portfolio.OpenStore(storePath); err != nil {
+		log.Fatalf("opening portfolio store: %v", err)
+	}
+
+	if workers := os.Getenv("CHAIN_WORKERS"); workers != "" {
+		if api.ChainWorkers, err = strconv.Atoi(workers); err != nil || api.ChainWorkers < 0 {
+			log.Fatalf("CHAIN_WORKERS must be a non-negative integer, got %q", workers)
+		}
+	}
+
+	for _, limit := range []struct {
+		env   string
+		value *int
+	}{
+		{"CHAIN_MAX_CELLS", &api.MaxChainCells},
+		{"MAX_COMPUTE_SECONDS", &api.MaxComputeSeconds},
+		{"CHAIN_MAX_AXIS_LENGTH", &option.MaxAxisLength},
+		{"EXOTIC_MAX_PATH_STEPS", &api.MaxExoticPathSteps},
+		{"BATCH_MAX_QUOTES", &api.MaxBatchQuotes},
+		{"FIT_MAX_QUOTES", &api.MaxFitQuotes},
+	} {
+		if setting := os.Getenv(limit.env); setting != "" {
+			if *limit.value, err = strconv.Atoi(setting); err != nil || *limit.value <= 0 {
+				log.Fatalf("%s must be a positive integer, got %q", limit.env, setting)
+			}
+		}
+	}
+
 	router := gin.Default()
 	docs.SwaggerInfo.BasePath = "/"
 	router.SetTrustedProxies(nil)
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	router.GET("/optionChain", getOptionChain)
+	router.GET("/exerciseBoundary", getExerciseBoundary)
+	router.GET("/impliedVolatility", getImpliedVolatility)
+	router.POST("/impliedVolatility", postImpliedVolatility)
+	router.GET("/volatility/surfaces", getVolatilitySurfaces)
+	router.GET("/volatility/surfaces/:name", getVolatilitySurface)
+	router.PUT("/volatility/surfaces/:name", putVolatilitySurface)
+	router.DELETE("/volatility/surfaces/:name", deleteVolatilitySurface)
+	router.POST("/volatility/fit", postVolatilityFit)
+	router.POST("/fx/smile", postFXSmile)
+	router.GET("/heston/parameters", getHestonParameterList)
+	router.GET("/heston/parameters/:name", getHestonParameters)
+	router.PUT("/heston/parameters/:name", putHestonParameters)
+	router.DELETE("/heston/parameters/:name", deleteHestonParameters)
+	router.POST("/heston/calibrate", postHestonCalibrate)
+	router.GET("/rates/curves", getRateCurves)
+	router.GET("/rates/curves/:name", getRateCurve)
+	router.PUT("/rates/curves/:name", putRateCurve)
+	router.DELETE("/rates/curves/:name", deleteRateCurve)
+	router.GET("/calendars", getCalendars)
+	router.GET("/calendars/:name", getCalendar)
+	router.PUT("/calendars/:name", putCalendar)
+	router.DELETE("/calendars/:name", deleteCalendar)
+	router.POST("/strategy", postStrategy)
+	router.POST("/exotic", postExotic)
+	router.POST("/structure", postStructure)
+	router.GET("/portfolio", getPortfolio)
+	router.POST("/portfolio/holdings", postPortfolioHolding)
+	router.DELETE("/portfolio/holdings/:id", deletePortfolioHolding)
+	router.POST("/portfolio/whatIf", postPortfolioWhatIf)
 
 	router.Run("localhost:8080")
 }